@@ -91,18 +91,35 @@ func main() {
 	}
 	defer uploadClient.Close()
 
-	uploadUseCase := service.NewUpload(uploadClient)
+	uploadUseCase := service.NewUpload(uploadClient, cockroach.NewStorageQuota(DBConn))
 
 	// запускаем сервисы репозиториев (подключение к базе данных)
 	eventRepo, err := kafka.NewCommentEventKafkaRepository(strings.Split(kafkaBrokers, ","))
 	if err != nil {
 		log.Fatalf("Ошибка при создании Kafka репозитория: %v", err)
 	}
+	dmEventRepo, err := kafka.NewDirectMessageEventKafkaRepository(strings.Split(kafkaBrokers, ","))
+	if err != nil {
+		log.Fatalf("Ошибка при создании Kafka репозитория личных сообщений: %v", err)
+	}
+	teamEventRepo, err := kafka.NewTeamEventKafkaRepository(strings.Split(kafkaBrokers, ","))
+	if err != nil {
+		log.Fatalf("Ошибка при создании Kafka репозитория событий команды: %v", err)
+	}
 	userRepo := cockroach.NewUser(DBConn)
 	teamRepo := cockroach.NewTeam(DBConn)
 	postRepo := cockroach.NewPost(DBConn)
 	commentRepo := cockroach.NewComment(DBConn)
 	analyticsRepo := cockroach.NewAnalytics(DBConn)
+	mediaLibraryRepo := cockroach.NewMediaLibrary(DBConn)
+	moderationRepo := cockroach.NewModeration(DBConn)
+	ticketRepo := cockroach.NewTicket(DBConn)
+	cannedReplyRepo := cockroach.NewCannedReply(DBConn)
+	faqRepo := cockroach.NewFAQ(DBConn)
+	commenterRepo := cockroach.NewCommenter(DBConn)
+	banRepo := cockroach.NewBan(DBConn)
+	dmRepo := cockroach.NewDirectMessage(DBConn)
+	summaryRepo := cockroach.NewCommentSummary(DBConn)
 
 	// запускаем сервисы usecase (бизнес-логика)
 	// -- telegram --
@@ -110,20 +127,22 @@ func main() {
 	if err != nil {
 		log.Fatalf("Ошибка при создании Telegram бота: %v", err)
 	}
-	telegramPostPlatformUseCase := telegram.NewTelegramPost(tgBot, postRepo, teamRepo, uploadUseCase)
+	telegramPostPlatformUseCase := telegram.NewTelegramPost(tgBot, postRepo, teamRepo, uploadUseCase, teamEventRepo)
 	telegramCommentUseCase := telegram.NewTelegramComment(tgBot, commentRepo, teamRepo, uploadUseCase, eventRepo)
 	telegramAnalytics := telegram.NewTelegramAnalytics(teamRepo, postRepo, analyticsRepo)
 	// -- vk --
-	vkPostPlatformUseCase := vkontakte.NewPost(postRepo, teamRepo, uploadUseCase)
+	vkPostPlatformUseCase := vkontakte.NewPost(postRepo, teamRepo, uploadUseCase, teamEventRepo)
 	vkCommentUseCase := vkontakte.NewVkontakteComment(commentRepo, teamRepo, uploadUseCase, eventRepo)
 	vkAnalytics := vkontakte.NewVkontakteAnalytics(teamRepo, postRepo, analyticsRepo)
 	postUseCase := service.NewPostUnion(
 		postRepo,
 		teamRepo,
 		uploadUseCase,
+		mediaLibraryRepo,
 		analyticsRepo,
 		telegramPostPlatformUseCase,
 		vkPostPlatformUseCase,
+		teamEventRepo,
 		generatePostURL,
 		fixPostTextURL,
 	)
@@ -141,27 +160,59 @@ func main() {
 	}()
 
 	teamUseCase := service.NewTeam(teamRepo)
+	// сводки обновляются в фоне воркером статистики, gateway только отдаёт их и обновляет по запросу
+	summarizer := service.NewCommentSummarizer(summaryRepo, commentRepo, teamRepo, eventRepo, summarizeURL, 0)
 	commentUseCase := service.NewComment(
 		commentRepo,
 		postRepo,
 		teamRepo,
 		telegramCommentUseCase,
 		vkCommentUseCase,
-		summarizeURL,
+		summarizer,
 		replyIdeasURL,
 		eventRepo,
+		ticketRepo,
+		cannedReplyRepo,
+		banRepo,
+		teamEventRepo,
 	)
-	analyticsUseCase := service.NewAnalytics(analyticsRepo, teamRepo, postRepo, telegramAnalytics, vkAnalytics)
+	analyticsUseCase := service.NewAnalytics(analyticsRepo, teamRepo, postRepo, telegramAnalytics, vkAnalytics, teamEventRepo)
+	mediaLibraryUseCase := service.NewMediaLibrary(mediaLibraryRepo, teamRepo, uploadUseCase)
+	moderationUseCase := service.NewModeration(moderationRepo, commentRepo, teamRepo, eventRepo, ticketRepo, banRepo, teamEventRepo)
+	ticketUseCase := service.NewTicket(ticketRepo, commentRepo, teamRepo, teamEventRepo)
+	cannedReplyUseCase := service.NewCannedReply(cannedReplyRepo, teamRepo, mediaLibraryRepo)
+	// автоответы отправляют слушатели платформ, здесь только управление правилами
+	faqUseCase := service.NewFAQ(faqRepo, teamRepo, nil, "")
+	commenterUseCase := service.NewCommenter(commenterRepo, commentRepo, teamRepo, banRepo)
+	banUseCase := service.NewBan(banRepo, commentRepo, commenterRepo, teamRepo, telegramCommentUseCase, vkCommentUseCase)
+	dmUseCase := service.NewDirectMessage(
+		dmRepo,
+		teamRepo,
+		mediaLibraryRepo,
+		dmEventRepo,
+		telegram.NewTelegramDirectMessage(tgBot, uploadUseCase),
+		vkontakte.NewVkontakteDirectMessage(teamRepo, uploadUseCase),
+		tgBot.Self.UserName,
+	)
+	teamEventsUseCase := service.NewTeamEvents(teamRepo, teamEventRepo, eventRepo)
 
 	// запускаем сервисы delivery (обработка запросов)
 	cookieManager := utils.NewCookieManager(false)
 	authManager := utils.NewAuthManager([]byte(jwtSecret), userRepo, time.Hour*24*365)
 	postDelivery := delivery.NewPost(authManager, postUseCase)
 	userDelivery := delivery.NewUser(userUseCase, authManager, cookieManager, vkSuccessURL, vkErrorURL)
-	uploadDelivery := delivery.NewUpload(uploadUseCase, authManager)
+	uploadDelivery := delivery.NewUpload(mediaLibraryUseCase, authManager)
 	teamDelivery := delivery.NewTeam(teamUseCase, authManager)
 	commentDelivery := delivery.NewComment(sysCtx, commentUseCase, authManager)
 	analyticsDelivery := delivery.NewAnalytics(analyticsUseCase, authManager)
+	moderationDelivery := delivery.NewModeration(moderationUseCase, authManager)
+	ticketDelivery := delivery.NewTicket(ticketUseCase, authManager)
+	cannedReplyDelivery := delivery.NewCannedReply(cannedReplyUseCase, authManager)
+	faqDelivery := delivery.NewFAQ(faqUseCase, authManager)
+	commenterDelivery := delivery.NewCommenter(commenterUseCase, authManager)
+	banDelivery := delivery.NewBan(banUseCase, authManager)
+	dmDelivery := delivery.NewDirectMessage(sysCtx, dmUseCase, authManager)
+	teamEventsDelivery := delivery.NewTeamEvents(sysCtx, teamEventsUseCase, authManager)
 
 	// REST API
 	echoServer := echo.New()
@@ -171,8 +222,13 @@ func main() {
 	// echoServer.Server.ReadHeaderTimeout = 60 * time.Second
 	// echoServer.Server.WriteTimeout = 60 * time.Second
 	// echoServer.Server.IdleTimeout = 60 * time.Second
-	// Не более 50 МБ
-	echoServer.Use(middleware.BodyLimit("50M"))
+	// Не более 50 МБ. Части tus-загрузки потоком уходят в upload-service, их размер ограничивает сама сессия
+	echoServer.Use(middleware.BodyLimitWithConfig(middleware.BodyLimitConfig{
+		Skipper: func(c echo.Context) bool {
+			return strings.HasPrefix(c.Path(), "/api/upload/tus")
+		},
+		Limit: "50M",
+	}))
 	// gzip на прием
 	// echoServer.Use(middleware.Decompress())
 	// gzip на отдачу
@@ -191,6 +247,8 @@ func main() {
 				http.MethodPost,
 				http.MethodDelete,
 				http.MethodOptions,
+				http.MethodHead,
+				http.MethodPatch,
 			}, ","))
 			ctx.Response().Header().Set(echo.HeaderAccessControlAllowHeaders, strings.Join([]string{
 				echo.HeaderOrigin,
@@ -201,6 +259,22 @@ func main() {
 				echo.HeaderAccessControlRequestHeaders,
 				echo.HeaderCookie,
 				"X-Csrf",
+				"Tus-Resumable",
+				"Upload-Length",
+				"Upload-Offset",
+				"Upload-Metadata",
+			}, ","))
+			// заголовки возобновляемой загрузки должны быть доступны клиенту tus в браузере
+			ctx.Response().Header().Set(echo.HeaderAccessControlExposeHeaders, strings.Join([]string{
+				echo.HeaderLocation,
+				"Tus-Resumable",
+				"Tus-Version",
+				"Tus-Extension",
+				"Tus-Max-Size",
+				"Upload-Offset",
+				"Upload-Length",
+				"Upload-Expires",
+				"Upload-File-Id",
 			}, ","))
 			ctx.Response().Header().Set(echo.HeaderAccessControlAllowCredentials, "true")
 			ctx.Response().Header().Set(echo.HeaderAccessControlMaxAge, "86400")
@@ -260,6 +334,37 @@ func main() {
 	// analytics
 	analytics := api.Group("/analytics")
 	analyticsDelivery.Configure(analytics)
+	// moderation
+	moderation := api.Group("/moderation")
+	moderationDelivery.Configure(moderation)
+
+	// tickets
+	tickets := api.Group("/tickets")
+	ticketDelivery.Configure(tickets)
+
+	// canned replies
+	cannedReplies := api.Group("/canned-replies")
+	cannedReplyDelivery.Configure(cannedReplies)
+
+	// faq auto-replies
+	faq := api.Group("/faq")
+	faqDelivery.Configure(faq)
+
+	// commenter profiles
+	commenters := api.Group("/commenters")
+	commenterDelivery.Configure(commenters)
+
+	// commenter bans
+	bans := api.Group("/bans")
+	banDelivery.Configure(bans)
+
+	// direct messages
+	dm := api.Group("/dm")
+	dmDelivery.Configure(dm)
+
+	// team-wide event stream
+	teamEvents := api.Group("/events")
+	teamEventsDelivery.Configure(teamEvents)
 
 	go func(server *echo.Echo) {
 		if err := server.Start("0.0.0.0:80"); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"time"
 
+	"postic-backend/internal/repo"
 	"postic-backend/internal/repo/cockroach"
+	"postic-backend/internal/repo/kafka"
 	"postic-backend/internal/usecase/service"
 	"postic-backend/internal/usecase/service/telegram"
 	"postic-backend/internal/usecase/service/vkontakte"
@@ -95,6 +99,16 @@ func main() {
 	telegramAnalytics := telegram.NewTelegramAnalytics(teamRepo, postRepo, analyticsRepo)
 	vkAnalytics := vkontakte.NewVkontakteAnalytics(teamRepo, postRepo, analyticsRepo)
 
+	// События об обновлении статистики публикуются в общий поток команды, если доступна Kafka
+	kafkaBrokers := os.Getenv("KAFKA_BROKERS")
+	var teamEventRepo repo.TeamEventRepository
+	if kafkaBrokers != "" {
+		teamEventRepo, err = kafka.NewTeamEventKafkaRepository(strings.Split(kafkaBrokers, ","))
+		if err != nil {
+			log.Fatalf("Ошибка при создании Kafka репозитория событий команды: %v", err)
+		}
+	}
+
 	// Инициализация основного сервиса аналитики
 	analyticsUseCase := service.NewAnalytics(
 		analyticsRepo,
@@ -102,8 +116,44 @@ func main() {
 		postRepo,
 		telegramAnalytics,
 		vkAnalytics,
+		teamEventRepo,
 	)
 
+	// Классификация комментариев включается, если задан ML-сервис и доступна Kafka
+	classifyURL := os.Getenv("CLASSIFY_URL")
+	if classifyURL != "" && kafkaBrokers != "" {
+		eventRepo, err := kafka.NewCommentEventKafkaRepository(strings.Split(kafkaBrokers, ","))
+		if err != nil {
+			log.Fatalf("Ошибка при создании Kafka репозитория: %v", err)
+		}
+		classifier := service.NewCommentClassifier(cockroach.NewComment(dbConn), teamRepo, eventRepo, classifyURL)
+		go classifier.Start(ctx)
+	} else {
+		log.Info("CLASSIFY_URL или KAFKA_BROKERS не заданы, классификация комментариев отключена")
+	}
+
+	// Фоновое обновление сводок комментариев по новым комментариям
+	summarizeURL := os.Getenv("SUMMARIZE_URL")
+	if summarizeURL != "" && kafkaBrokers != "" {
+		eventRepo, err := kafka.NewCommentEventKafkaRepository(strings.Split(kafkaBrokers, ","))
+		if err != nil {
+			log.Fatalf("Ошибка при создании Kafka репозитория: %v", err)
+		}
+		// SUMMARY_REFRESH_COMMENTS — после скольких новых комментариев сводка поста обновляется
+		refreshComments, _ := strconv.Atoi(os.Getenv("SUMMARY_REFRESH_COMMENTS"))
+		summarizer := service.NewCommentSummarizer(
+			cockroach.NewCommentSummary(dbConn),
+			cockroach.NewComment(dbConn),
+			teamRepo,
+			eventRepo,
+			summarizeURL,
+			refreshComments,
+		)
+		go summarizer.Start(ctx)
+	} else {
+		log.Info("SUMMARIZE_URL или KAFKA_BROKERS не заданы, фоновое обновление сводок отключено")
+	}
+
 	// Создание и запуск воркера
 	statsWorker := service.NewStatsWorker(analyticsUseCase, workerID, workerInterval)
 
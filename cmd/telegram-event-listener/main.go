@@ -13,6 +13,7 @@ import (
 	"postic-backend/pkg/goosehelper"
 	"strings"
 
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/joho/godotenv"
 	"github.com/labstack/gommon/log"
 )
@@ -67,11 +68,20 @@ func main() {
 	if err != nil {
 		log.Fatalf("Ошибка при создании Kafka репозитория: %v", err)
 	}
+	dmEventRepo, err := kafka.NewDirectMessageEventKafkaRepository(strings.Split(kafkaBrokers, ","))
+	if err != nil {
+		log.Fatalf("Ошибка при создании Kafka репозитория личных сообщений: %v", err)
+	}
+	teamEventRepo, err := kafka.NewTeamEventKafkaRepository(strings.Split(kafkaBrokers, ","))
+	if err != nil {
+		log.Fatalf("Ошибка при создании Kafka репозитория событий команды: %v", err)
+	}
 	teamRepo := cockroach.NewTeam(DBConn)
 	postRepo := cockroach.NewPost(DBConn)
 	commentRepo := cockroach.NewComment(DBConn)
 	analyticsRepo := cockroach.NewAnalytics(DBConn)
 	telegramListenerRepo := cockroach.NewTelegramListener(DBConn)
+	dmRepo := cockroach.NewDirectMessage(DBConn)
 
 	// gRPC upload client
 	uploadClient, err := uploadservice.NewClient(uploadServiceAddr)
@@ -79,7 +89,16 @@ func main() {
 		log.Fatalf("Ошибка при создании gRPC клиента для upload service: %v", err)
 	}
 	defer uploadClient.Close()
-	uploadUseCase := service.NewUpload(uploadClient)
+	uploadUseCase := service.NewUpload(uploadClient, cockroach.NewStorageQuota(DBConn))
+	moderation := service.NewModeration(cockroach.NewModeration(DBConn), commentRepo, teamRepo, eventRepo, cockroach.NewTicket(DBConn), cockroach.NewBan(DBConn), teamEventRepo)
+
+	// автоответы FAQ отправляются через Bot API от имени канала
+	tgBot, err := tgbotapi.NewBotAPI(tgToken)
+	if err != nil {
+		log.Fatalf("Ошибка при создании Telegram бота: %v", err)
+	}
+	tgCommentAction := telegram.NewTelegramComment(tgBot, commentRepo, teamRepo, uploadUseCase, eventRepo)
+	faq := service.NewFAQ(cockroach.NewFAQ(DBConn), teamRepo, tgCommentAction, os.Getenv("FAQ_INTENT_URL"))
 
 	tgEventListener, err := telegram.NewTelegramEventListener(
 		tgToken,
@@ -91,6 +110,10 @@ func main() {
 		commentRepo,
 		analyticsRepo,
 		eventRepo,
+		moderation,
+		faq,
+		dmRepo,
+		dmEventRepo,
 	)
 	if err != nil {
 		log.Fatalf("Ошибка при создании Telegram Event Listener: %v", err)
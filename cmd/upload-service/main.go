@@ -6,15 +6,18 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"time"
 
 	uploadservice "postic-backend/internal/delivery/grpc/upload-service"
 	uploadpb "postic-backend/internal/delivery/grpc/upload-service/proto"
 	"postic-backend/internal/repo/cockroach"
 	"postic-backend/pkg/connector"
 	"postic-backend/pkg/goosehelper"
+	"postic-backend/pkg/storage"
 
 	"github.com/joho/godotenv"
 	"github.com/labstack/gommon/log"
+	"github.com/minio/minio-go/v7"
 	"google.golang.org/grpc"
 )
 
@@ -37,6 +40,54 @@ func init() {
 	}
 }
 
+// mediaGCGrace возвращает, сколько неиспользуемый медиафайл хранится до удаления (MEDIA_GC_GRACE, по умолчанию сутки)
+func mediaGCGrace() time.Duration {
+	grace, err := time.ParseDuration(os.Getenv("MEDIA_GC_GRACE"))
+	if err != nil || grace <= 0 {
+		return 24 * time.Hour
+	}
+	return grace
+}
+
+// newStorage выбирает хранилище файлов по STORAGE_BACKEND: minio (по умолчанию) или fs.
+// Для fs файлы хранятся в каталоге STORAGE_FS_ROOT
+func newStorage() (storage.Storage, error) {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "minio":
+		minioEndpoint := os.Getenv("MINIO_ENDPOINT")
+		minioAccessKey := os.Getenv("MINIO_ACCESS_KEY")
+		minioSecretKey := os.Getenv("MINIO_SECRET_KEY")
+		minioUseSSL := os.Getenv("MINIO_USE_SSL") == "true"
+
+		minioClient, err := connector.GetMinioConnector(minioEndpoint, minioAccessKey, minioSecretKey, minioUseSSL)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при подключении к MinIO: %w", err)
+		}
+		// Ссылки для прямой загрузки подписываются на адрес, доступный клиентам
+		var presignClient *minio.Client
+		if publicEndpoint := os.Getenv("MINIO_PUBLIC_ENDPOINT"); publicEndpoint != "" {
+			presignClient, err = connector.GetMinioPresignConnector(
+				publicEndpoint,
+				minioAccessKey,
+				minioSecretKey,
+				os.Getenv("MINIO_PUBLIC_USE_SSL") == "true",
+			)
+			if err != nil {
+				return nil, fmt.Errorf("ошибка при создании клиента MinIO для публичных ссылок: %w", err)
+			}
+		}
+		return storage.NewMinio(minioClient, presignClient, "mediafiles")
+	case "fs":
+		root := os.Getenv("STORAGE_FS_ROOT")
+		if root == "" {
+			root = "./data/mediafiles"
+		}
+		return storage.NewFS(root)
+	default:
+		return nil, fmt.Errorf("неизвестное хранилище %s: допустимы minio и fs", backend)
+	}
+}
+
 func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer stop()
@@ -57,22 +108,28 @@ func main() {
 		}
 	}()
 
-	minioEndpoint := os.Getenv("MINIO_ENDPOINT")
-	minioAccessKey := os.Getenv("MINIO_ACCESS_KEY")
-	minioSecretKey := os.Getenv("MINIO_SECRET_KEY")
-	minioUseSSL := os.Getenv("MINIO_USE_SSL") == "true"
-
-	minioClient, err := connector.GetMinioConnector(minioEndpoint, minioAccessKey, minioSecretKey, minioUseSSL)
+	mediaStorage, err := newStorage()
 	if err != nil {
-		log.Fatalf("Ошибка при подключении к MinIO: %v", err)
+		log.Fatalf("Ошибка при инициализации хранилища: %v", err)
 	}
 
-	uploadRepo, err := cockroach.NewUpload(dbConn, minioClient)
-	if err != nil {
-		log.Fatalf("Ошибка при инициализации uploadRepo: %v", err)
-	}
+	uploadRepo := cockroach.NewUpload(dbConn, mediaStorage)
+	uploadSessionRepo := cockroach.NewUploadSession(dbConn, mediaStorage)
+
+	// Размер старых файлов нужен для учёта квот команд, заполняем его в фоне
+	go func() {
+		filled, err := uploadRepo.FillMissingSizes()
+		if err != nil {
+			log.Errorf("Ошибка при заполнении размеров файлов: %v", err)
+		}
+		if filled > 0 {
+			log.Infof("Заполнен размер %d файлов", filled)
+		}
+	}()
 
-	uploadServiceServer := uploadservice.NewUploadServiceServer(uploadRepo)
+	uploadServiceServer := uploadservice.NewUploadServiceServer(uploadRepo, uploadSessionRepo)
+	go uploadServiceServer.CleanupUploadSessions(ctx, 10*time.Minute)
+	go uploadServiceServer.CollectOrphanUploads(ctx, time.Hour, mediaGCGrace())
 	grpcServer := grpc.NewServer()
 	uploadpb.RegisterUploadServiceServer(grpcServer, uploadServiceServer)
 
@@ -65,10 +65,19 @@ func main() {
 	if err != nil {
 		log.Fatalf("Ошибка при создании Kafka репозитория: %v", err)
 	}
+	dmEventRepo, err := kafka.NewDirectMessageEventKafkaRepository(strings.Split(kafkaBrokers, ","))
+	if err != nil {
+		log.Fatalf("Ошибка при создании Kafka репозитория личных сообщений: %v", err)
+	}
+	teamEventRepo, err := kafka.NewTeamEventKafkaRepository(strings.Split(kafkaBrokers, ","))
+	if err != nil {
+		log.Fatalf("Ошибка при создании Kafka репозитория событий команды: %v", err)
+	}
 	teamRepo := cockroach.NewTeam(DBConn)
 	postRepo := cockroach.NewPost(DBConn)
 	commentRepo := cockroach.NewComment(DBConn)
 	vkontakteListenerRepo := cockroach.NewVkontakteListener(DBConn)
+	dmRepo := cockroach.NewDirectMessage(DBConn)
 
 	// gRPC upload client
 	uploadClient, err := uploadservice.NewClient(uploadServiceAddr)
@@ -76,9 +85,14 @@ func main() {
 		log.Fatalf("Ошибка при создании gRPC клиента для upload service: %v", err)
 	}
 	defer uploadClient.Close()
-	uploadUseCase := service.NewUpload(uploadClient)
+	uploadUseCase := service.NewUpload(uploadClient, cockroach.NewStorageQuota(DBConn))
+
+	moderation := service.NewModeration(cockroach.NewModeration(DBConn), commentRepo, teamRepo, eventRepo, cockroach.NewTicket(DBConn), cockroach.NewBan(DBConn), teamEventRepo)
+
+	vkCommentAction := vkontakte.NewVkontakteComment(commentRepo, teamRepo, uploadUseCase, eventRepo)
+	faq := service.NewFAQ(cockroach.NewFAQ(DBConn), teamRepo, vkCommentAction, os.Getenv("FAQ_INTENT_URL"))
 
-	vkEventListener := vkontakte.NewVKEventListener(vkontakteListenerRepo, teamRepo, postRepo, uploadUseCase, commentRepo, eventRepo)
+	vkEventListener := vkontakte.NewVKEventListener(vkontakteListenerRepo, teamRepo, postRepo, uploadUseCase, commentRepo, eventRepo, moderation, faq, dmRepo, dmEventRepo)
 	go vkEventListener.StartListener()
 	log.Infof("VK Event Listener запущен, слушаем события...")
 	defer vkEventListener.StopListener()
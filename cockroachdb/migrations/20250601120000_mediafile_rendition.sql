@@ -0,0 +1,21 @@
+-- +goose Up
+-- Метаданные исходного медиафайла, полученные при обработке
+ALTER TABLE mediafile
+    ADD COLUMN IF NOT EXISTS width INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS height INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS duration_ms INT NOT NULL DEFAULT 0; -- только для видео
+
+-- Производные версии медиафайла (уменьшенные копии, превью видео, перекодированное видео).
+-- Каждая версия сама является медиафайлом со своим объектом в MinIO
+CREATE TABLE IF NOT EXISTS mediafile_rendition (
+    mediafile_id INT NOT NULL,
+    FOREIGN KEY (mediafile_id) REFERENCES mediafile (id) ON DELETE CASCADE,
+    rendition_mediafile_id INT NOT NULL UNIQUE,
+    FOREIGN KEY (rendition_mediafile_id) REFERENCES mediafile (id) ON DELETE CASCADE,
+    kind STRING(32) NOT NULL, -- jpeg_2560 / jpeg_1280 / thumbnail / mp4_720
+    mime_type STRING(64) NOT NULL,
+    width INT NOT NULL DEFAULT 0,
+    height INT NOT NULL DEFAULT 0,
+    size INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (mediafile_id, kind)
+);
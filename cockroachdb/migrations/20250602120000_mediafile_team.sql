@@ -0,0 +1,21 @@
+-- +goose Up
+-- Медиафайлы принадлежат команде; название и теги задаются в медиатеке
+ALTER TABLE mediafile
+    ADD COLUMN IF NOT EXISTS team_id INT DEFAULT NULL REFERENCES team (id) ON DELETE SET NULL, -- NULL для файлов, загруженных не через медиатеку
+    ADD COLUMN IF NOT EXISTS display_name STRING(256) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tags STRING(64)[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_mediafile_team_id_created_at
+ON mediafile (team_id, created_at);
+
+-- Индексы для проверки, где используется медиафайл
+CREATE INDEX IF NOT EXISTS idx_post_union_mediafile_mediafile_id ON post_union_mediafile (mediafile_id);
+CREATE INDEX IF NOT EXISTS idx_post_comment_attachment_mediafile_id ON post_comment_attachment (mediafile_id);
+CREATE INDEX IF NOT EXISTS idx_post_comment_avatar_mediafile_id ON post_comment (avatar_mediafile_id);
+
+-- Файлы, уже прикреплённые к постам, переносим в медиатеку команды поста
+UPDATE mediafile
+SET team_id = post_union.team_id
+FROM post_union_mediafile
+JOIN post_union ON post_union.id = post_union_mediafile.post_union_id
+WHERE post_union_mediafile.mediafile_id = mediafile.id AND mediafile.team_id IS NULL;
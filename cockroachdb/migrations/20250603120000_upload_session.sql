@@ -0,0 +1,31 @@
+-- +goose Up
+-- Сессии возобновляемой загрузки (tus). Данные приходят частями, каждая часть хранится отдельным объектом в MinIO,
+-- после получения последней части части склеиваются в обычный медиафайл
+CREATE TABLE IF NOT EXISTS upload_session (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id INT NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES "user" (id) ON DELETE CASCADE,
+    team_id INT NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES team (id) ON DELETE CASCADE,
+    file_name STRING(256) NOT NULL, -- имя объекта итогового медиафайла
+    file_type STRING(32) NOT NULL,
+    display_name STRING(256) NOT NULL DEFAULT '',
+    size INT NOT NULL, -- ожидаемый размер файла в байтах
+    upload_offset INT NOT NULL DEFAULT 0, -- сколько байт уже получено
+    mediafile_id INT DEFAULT NULL, -- заполняется после сборки файла
+    FOREIGN KEY (mediafile_id) REFERENCES mediafile (id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL, -- продлевается при каждой полученной части
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_session_expires_at ON upload_session (expires_at);
+
+-- Части сессии в порядке смещения
+CREATE TABLE IF NOT EXISTS upload_session_part (
+    session_id INT NOT NULL,
+    FOREIGN KEY (session_id) REFERENCES upload_session (id) ON DELETE CASCADE,
+    part_offset INT NOT NULL,
+    object_name STRING(256) NOT NULL,
+    size INT NOT NULL,
+    PRIMARY KEY (session_id, part_offset)
+);
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Хэш содержимого: одинаковые файлы хранятся в MinIO одним объектом sha256/<хэш>.
+-- Для старых файлов хэш пустой, их объект по-прежнему лежит по file_path
+ALTER TABLE mediafile
+    ADD COLUMN IF NOT EXISTS content_hash STRING(64) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_mediafile_content_hash ON mediafile (content_hash);
+CREATE INDEX IF NOT EXISTS idx_mediafile_file_path ON mediafile (file_path);
+CREATE INDEX IF NOT EXISTS idx_mediafile_created_at ON mediafile (created_at);
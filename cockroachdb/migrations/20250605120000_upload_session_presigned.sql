@@ -0,0 +1,4 @@
+-- +goose Up
+-- Сессия может загружаться одним объектом по временной ссылке напрямую в хранилище
+ALTER TABLE upload_session
+    ADD COLUMN IF NOT EXISTS presigned BOOL NOT NULL DEFAULT false;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Размер файла хранится в базе, чтобы считать занятое командой место без обращения к хранилищу.
+-- Для старых файлов размер заполняет upload-service при запуске
+ALTER TABLE mediafile
+    ADD COLUMN IF NOT EXISTS size INT8 NOT NULL DEFAULT 0;
+
+-- Индивидуальные квоты команд; для команд без записи действуют квоты по умолчанию
+CREATE TABLE IF NOT EXISTS team_storage_quota (
+    team_id INT PRIMARY KEY,
+    FOREIGN KEY (team_id) REFERENCES team (id) ON DELETE CASCADE,
+    max_storage_bytes INT8 NOT NULL,
+    max_files INT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_comment_team_id ON post_comment (team_id);
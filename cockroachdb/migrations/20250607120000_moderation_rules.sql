@@ -0,0 +1,44 @@
+-- +goose Up
+-- Правила автоматической модерации комментариев, которые задаёт команда
+CREATE TABLE IF NOT EXISTS moderation_rule (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    team_id INT NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES team (id) ON DELETE CASCADE,
+    name STRING(256) NOT NULL,
+    type STRING(32) NOT NULL, -- keywords / regex / link / phone / flood / repeat / new_account
+    keywords STRING[] NOT NULL DEFAULT ARRAY[], -- для keywords
+    pattern STRING(1024) NOT NULL DEFAULT '', -- для regex
+    threshold INT NOT NULL DEFAULT 0, -- для flood, repeat и new_account
+    window_seconds INT NOT NULL DEFAULT 0, -- для flood и repeat
+    action STRING(32) NOT NULL, -- delete / ban / ticket / hold
+    enabled BOOL NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_rule_team_id ON moderation_rule (team_id);
+
+-- Журнал автоматических действий модерации
+CREATE TABLE IF NOT EXISTS moderation_log (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    team_id INT NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES team (id) ON DELETE CASCADE,
+    rule_id INT DEFAULT NULL, -- NULL, если правило уже удалено
+    FOREIGN KEY (rule_id) REFERENCES moderation_rule (id) ON DELETE SET NULL,
+    comment_id INT DEFAULT NULL,
+    FOREIGN KEY (comment_id) REFERENCES post_comment (id) ON DELETE SET NULL,
+    platform STRING(32) NOT NULL,
+    user_platform_id INT NOT NULL,
+    action STRING(32) NOT NULL,
+    reason STRING(1024) NOT NULL,
+    succeeded BOOL NOT NULL DEFAULT TRUE, -- FALSE, если платформа не выполнила действие
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_log_team_id_created_at ON moderation_log (team_id, created_at);
+
+-- Комментарии, отложенные до проверки модератором, не показываются в ленте и не рассылаются подписчикам
+ALTER TABLE post_comment
+    ADD COLUMN IF NOT EXISTS held_for_review BOOL NOT NULL DEFAULT FALSE;
+
+-- Для поиска флуда и новых авторов
+CREATE INDEX IF NOT EXISTS idx_post_comment_team_user_created_at ON post_comment (team_id, platform, user_platform_id, created_at);
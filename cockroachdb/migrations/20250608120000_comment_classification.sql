@@ -0,0 +1,12 @@
+-- +goose Up
+-- Результат классификации комментария ML-сервисом. NULL, пока комментарий не классифицирован
+ALTER TABLE post_comment
+    ADD COLUMN IF NOT EXISTS sentiment STRING(16) DEFAULT NULL, -- positive / neutral / negative
+    ADD COLUMN IF NOT EXISTS topic STRING(16) DEFAULT NULL, -- question / complaint / praise / spam / other
+    ADD COLUMN IF NOT EXISTS classified_at TIMESTAMPTZ DEFAULT NULL;
+
+-- Для фильтрации ленты и аналитики настроений по посту
+CREATE INDEX IF NOT EXISTS idx_post_comment_post_union_id_created_at ON post_comment (post_union_id, created_at) STORING (sentiment);
+
+-- Для повторной классификации комментариев, пропущенных обработчиком событий
+CREATE INDEX IF NOT EXISTS idx_post_comment_unclassified ON post_comment (created_at) WHERE classified_at IS NULL;
@@ -0,0 +1,71 @@
+-- +goose Up
+-- Тикеты поддержки. Раньше тикетом был просто флаг post_comment.marked_as_ticket,
+-- теперь флаг означает, что комментарий привязан к тикету
+CREATE TABLE IF NOT EXISTS ticket (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    team_id INT NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES team (id) ON DELETE CASCADE,
+    source_comment_id INT DEFAULT NULL, -- комментарий, с которого открыт тикет
+    FOREIGN KEY (source_comment_id) REFERENCES post_comment (id) ON DELETE SET NULL,
+    title STRING(256) NOT NULL,
+    status STRING(16) NOT NULL DEFAULT 'open', -- open / in_progress / waiting / resolved
+    priority STRING(16) NOT NULL DEFAULT 'normal', -- low / normal / high / urgent
+    assignee_id INT DEFAULT NULL, -- NULL, если тикет никому не назначен
+    FOREIGN KEY (assignee_id) REFERENCES "user" (id) ON DELETE SET NULL,
+    created_by INT DEFAULT NULL, -- NULL, если тикет открыт правилом модерации
+    FOREIGN KEY (created_by) REFERENCES "user" (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    first_response_at TIMESTAMPTZ DEFAULT NULL, -- первый ответ команды на комментарий тикета
+    resolved_at TIMESTAMPTZ DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_team_id_created_at ON ticket (team_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_ticket_assignee_id ON ticket (assignee_id);
+
+-- Комментарии, привязанные к тикету. Комментарий может относиться только к одному тикету
+CREATE TABLE IF NOT EXISTS ticket_comment (
+    ticket_id INT NOT NULL,
+    FOREIGN KEY (ticket_id) REFERENCES ticket (id) ON DELETE CASCADE,
+    comment_id INT NOT NULL PRIMARY KEY,
+    FOREIGN KEY (comment_id) REFERENCES post_comment (id) ON DELETE CASCADE,
+    linked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_comment_ticket_id ON ticket_comment (ticket_id);
+
+-- Внутренние заметки команды, авторам комментариев не видны
+CREATE TABLE IF NOT EXISTS ticket_note (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    ticket_id INT NOT NULL,
+    FOREIGN KEY (ticket_id) REFERENCES ticket (id) ON DELETE CASCADE,
+    user_id INT DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES "user" (id) ON DELETE SET NULL,
+    text STRING(4096) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ticket_note_ticket_id ON ticket_note (ticket_id);
+
+-- Переносим помеченные комментарии: каждый становится открытым тикетом
+INSERT INTO ticket (team_id, source_comment_id, title, created_at, updated_at, first_response_at)
+SELECT
+    pc.team_id,
+    pc.id,
+    COALESCE(NULLIF(substring(pc.text, 1, 100), ''), 'Комментарий #' || pc.id::STRING),
+    pc.created_at,
+    pc.created_at,
+    (
+        SELECT MIN(reply.created_at)
+        FROM post_comment reply
+        WHERE reply.reply_to_comment_id = pc.id AND reply.is_team_reply AND NOT reply.is_deleted
+    )
+FROM post_comment pc
+WHERE pc.marked_as_ticket AND NOT pc.is_deleted
+  AND NOT EXISTS (SELECT 1 FROM ticket t WHERE t.source_comment_id = pc.id);
+
+INSERT INTO ticket_comment (ticket_id, comment_id, linked_at)
+SELECT id, source_comment_id, created_at
+FROM ticket
+WHERE source_comment_id IS NOT NULL
+ON CONFLICT (comment_id) DO NOTHING;
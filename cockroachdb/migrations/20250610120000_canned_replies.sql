@@ -0,0 +1,26 @@
+-- +goose Up
+-- Сохранённые ответы команды на комментарии. В тексте можно использовать переменные {{name}} и {{username}}
+CREATE TABLE IF NOT EXISTS canned_reply (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    team_id INT NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES team (id) ON DELETE CASCADE,
+    title STRING(256) NOT NULL,
+    text STRING(4096) NOT NULL DEFAULT '', -- может быть пустым, если есть вложения
+    usage_count INT NOT NULL DEFAULT 0,
+    last_used_at TIMESTAMPTZ DEFAULT NULL,
+    created_by INT DEFAULT NULL,
+    FOREIGN KEY (created_by) REFERENCES "user" (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_canned_reply_team_id ON canned_reply (team_id);
+
+CREATE TABLE IF NOT EXISTS canned_reply_mediafile (
+    canned_reply_id INT NOT NULL,
+    FOREIGN KEY (canned_reply_id) REFERENCES canned_reply (id) ON DELETE CASCADE,
+    mediafile_id INT NOT NULL,
+    FOREIGN KEY (mediafile_id) REFERENCES mediafile (id) ON DELETE CASCADE,
+    position INT NOT NULL DEFAULT 0, -- порядок вложений в ответе
+    PRIMARY KEY (canned_reply_id, mediafile_id)
+);
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Правила автоматических ответов на частые вопросы
+CREATE TABLE IF NOT EXISTS faq_rule (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    team_id INT NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES team (id) ON DELETE CASCADE,
+    name STRING(256) NOT NULL,
+    trigger_type STRING(16) NOT NULL, -- keywords / regex / intent
+    keywords STRING[] NOT NULL DEFAULT ARRAY[], -- для keywords
+    pattern STRING(1024) NOT NULL DEFAULT '', -- для regex
+    intent STRING(64) NOT NULL DEFAULT '', -- для intent: намерение, которое вернул ML-сервис
+    response_text STRING(4096) NOT NULL, -- шаблон ответа с переменными {{name}} и {{username}}
+    cooldown_seconds INT NOT NULL DEFAULT 3600, -- как часто правило может отвечать в одном обсуждении
+    enabled BOOL NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_faq_rule_team_id ON faq_rule (team_id);
+
+-- Общий выключатель автоответов команды
+ALTER TABLE team
+    ADD COLUMN IF NOT EXISTS faq_enabled BOOL NOT NULL DEFAULT TRUE;
+
+-- Источник ответа команды: manual — ответил участник команды, faq — автоответ
+ALTER TABLE post_comment
+    ADD COLUMN IF NOT EXISTS reply_source STRING(16) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS faq_rule_id INT DEFAULT NULL REFERENCES faq_rule (id) ON DELETE SET NULL;
+
+UPDATE post_comment SET reply_source = 'manual' WHERE is_team_reply AND reply_source = '';
+
+-- Для проверки паузы между автоответами
+CREATE INDEX IF NOT EXISTS idx_post_comment_faq_rule_id_created_at ON post_comment (faq_rule_id, created_at);
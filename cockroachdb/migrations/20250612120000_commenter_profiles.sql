@@ -0,0 +1,29 @@
+-- +goose Up
+-- Метки, которые команда ставит авторам комментариев. Автор определяется парой (platform, user_platform_id)
+CREATE TABLE IF NOT EXISTS commenter_tag (
+    team_id INT NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES team (id) ON DELETE CASCADE,
+    platform STRING(32) NOT NULL,
+    user_platform_id INT NOT NULL,
+    tag STRING(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (team_id, platform, user_platform_id, tag)
+);
+
+-- Внутренние заметки команды об авторе комментариев
+CREATE TABLE IF NOT EXISTS commenter_note (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    team_id INT NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES team (id) ON DELETE CASCADE,
+    platform STRING(32) NOT NULL,
+    user_platform_id INT NOT NULL,
+    user_id INT DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES "user" (id) ON DELETE SET NULL,
+    text STRING(4096) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_commenter_note_commenter ON commenter_note (team_id, platform, user_platform_id, created_at);
+
+-- Для поиска банов автора в журнале модерации
+CREATE INDEX IF NOT EXISTS idx_moderation_log_team_user ON moderation_log (team_id, platform, user_platform_id, created_at);
@@ -0,0 +1,42 @@
+-- +goose Up
+-- Баны авторов комментариев в обсуждениях команды. Снятые и истёкшие баны остаются в истории
+CREATE TABLE IF NOT EXISTS commenter_ban (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    team_id INT NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES team (id) ON DELETE CASCADE,
+    platform STRING(32) NOT NULL,
+    user_platform_id INT NOT NULL,
+    full_name STRING(256) NOT NULL DEFAULT '', -- имя автора на момент бана
+    username STRING(256) NOT NULL DEFAULT '',
+    reason STRING(1024) NOT NULL DEFAULT '',
+    comment_id INT DEFAULT NULL, -- комментарий, за который забанили
+    FOREIGN KEY (comment_id) REFERENCES post_comment (id) ON DELETE SET NULL,
+    banned_by INT DEFAULT NULL, -- NULL, если забанило правило модерации
+    FOREIGN KEY (banned_by) REFERENCES "user" (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ DEFAULT NULL, -- NULL, если бан бессрочный
+    lifted_at TIMESTAMPTZ DEFAULT NULL, -- бан снят командой или заменён новым
+    lifted_by INT DEFAULT NULL,
+    FOREIGN KEY (lifted_by) REFERENCES "user" (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_commenter_ban_team_id_created_at ON commenter_ban (team_id, created_at);
+-- У автора может быть только один не снятый бан
+CREATE UNIQUE INDEX IF NOT EXISTS idx_commenter_ban_not_lifted ON commenter_ban (team_id, platform, user_platform_id) WHERE lifted_at IS NULL;
+
+-- Переносим баны, выполненные правилами модерации, из журнала
+INSERT INTO commenter_ban (team_id, platform, user_platform_id, full_name, username, reason, comment_id, created_at)
+SELECT DISTINCT ON (ml.team_id, ml.platform, ml.user_platform_id)
+    ml.team_id,
+    ml.platform,
+    ml.user_platform_id,
+    COALESCE(pc.full_name, ''),
+    COALESCE(pc.username, ''),
+    ml.reason,
+    ml.comment_id,
+    ml.created_at
+FROM moderation_log ml
+LEFT JOIN post_comment pc ON pc.id = ml.comment_id
+WHERE ml.action = 'ban' AND ml.succeeded
+ORDER BY ml.team_id, ml.platform, ml.user_platform_id, ml.created_at DESC
+ON CONFLICT DO NOTHING;
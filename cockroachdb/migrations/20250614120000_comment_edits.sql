@@ -0,0 +1,13 @@
+-- +goose Up
+-- Предыдущие версии текста ответов команды, изменённых через Postic
+CREATE TABLE IF NOT EXISTS post_comment_edit (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    comment_id INT NOT NULL,
+    FOREIGN KEY (comment_id) REFERENCES post_comment (id) ON DELETE CASCADE,
+    previous_text STRING NOT NULL,
+    edited_by INT DEFAULT NULL,
+    FOREIGN KEY (edited_by) REFERENCES "user" (id) ON DELETE SET NULL,
+    edited_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_comment_edit_comment_id ON post_comment_edit (comment_id, edited_at);
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Реакция команды на комментарий, поставленная из Postic
+ALTER TABLE post_comment
+    ADD COLUMN IF NOT EXISTS team_reaction STRING(16) DEFAULT NULL,
+    ADD COLUMN IF NOT EXISTS team_reacted_by INT DEFAULT NULL REFERENCES "user" (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS team_reacted_at TIMESTAMPTZ DEFAULT NULL;
@@ -0,0 +1,43 @@
+-- +goose Up
+-- Личные переписки с командой: бот Telegram и сообщения сообщества ВКонтакте
+CREATE TABLE IF NOT EXISTS dm_conversation (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    team_id INT NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES team (id) ON DELETE CASCADE,
+    platform STRING(16) NOT NULL, -- tg / vk
+    peer_id INT8 NOT NULL, -- ID чата с пользователем в Telegram или ID пользователя ВКонтакте
+    full_name STRING(256) NOT NULL DEFAULT '',
+    username STRING(256) NOT NULL DEFAULT '',
+    avatar_mediafile_id INT DEFAULT NULL,
+    FOREIGN KEY (avatar_mediafile_id) REFERENCES mediafile (id) ON DELETE SET NULL,
+    unread_count INT NOT NULL DEFAULT 0, -- входящие сообщения, которые команда ещё не прочитала
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    opened_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), -- когда пользователь последний раз открыл переписку по ссылке
+    last_message_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (team_id, platform, peer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dm_conversation_team_id_last_message_at ON dm_conversation (team_id, last_message_at);
+CREATE INDEX IF NOT EXISTS idx_dm_conversation_platform_peer_id ON dm_conversation (platform, peer_id, opened_at);
+
+CREATE TABLE IF NOT EXISTS dm_message (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    conversation_id INT NOT NULL,
+    FOREIGN KEY (conversation_id) REFERENCES dm_conversation (id) ON DELETE CASCADE,
+    message_platform_id INT8 NOT NULL DEFAULT 0,
+    text STRING NOT NULL DEFAULT '',
+    is_team_reply BOOL NOT NULL DEFAULT FALSE,
+    sent_by INT DEFAULT NULL, -- участник команды, отправивший ответ
+    FOREIGN KEY (sent_by) REFERENCES "user" (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dm_message_conversation_id_created_at ON dm_message (conversation_id, created_at);
+
+CREATE TABLE IF NOT EXISTS dm_message_attachment (
+    message_id INT NOT NULL,
+    FOREIGN KEY (message_id) REFERENCES dm_message (id) ON DELETE CASCADE,
+    mediafile_id INT NOT NULL,
+    FOREIGN KEY (mediafile_id) REFERENCES mediafile (id) ON DELETE CASCADE,
+    PRIMARY KEY (message_id, mediafile_id)
+);
@@ -0,0 +1,3 @@
+-- +goose Up
+-- Для общей ленты обсуждений команды, в том числе сообщений вне веток постов
+CREATE INDEX IF NOT EXISTS idx_post_comment_team_id_created_at ON post_comment (team_id, created_at) WHERE NOT held_for_review;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Сохранённая сводка комментариев поста. last_comment_id — последний комментарий, учтённый в сводке:
+-- при обновлении в ML-сервис отправляются только комментарии после него
+CREATE TABLE IF NOT EXISTS post_comment_summary (
+    post_union_id INT NOT NULL PRIMARY KEY,
+    FOREIGN KEY (post_union_id) REFERENCES post_union (id) ON DELETE CASCADE,
+    team_id INT NOT NULL,
+    FOREIGN KEY (team_id) REFERENCES team (id) ON DELETE CASCADE,
+    markdown STRING NOT NULL,
+    last_comment_id INT NOT NULL,
+    comment_count INT NOT NULL DEFAULT 0, -- сколько комментариев учтено во всех обновлениях сводки
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
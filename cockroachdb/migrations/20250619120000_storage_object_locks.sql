@@ -0,0 +1,6 @@
+-- +goose Up
+-- Блокировки объектов хранилища. Загрузка файла и сборщик мусора берут блокировку строки с именем объекта
+-- в транзакции, поэтому объект не удаляется между проверкой его наличия и добавлением ссылающейся строки mediafile
+CREATE TABLE IF NOT EXISTS storage_object_lock (
+    object_name STRING NOT NULL PRIMARY KEY
+);
@@ -0,0 +1,4 @@
+-- +goose Up
+-- Индексы для проверки, используется ли медиафайл в личных переписках
+CREATE INDEX IF NOT EXISTS idx_dm_message_attachment_mediafile_id ON dm_message_attachment (mediafile_id);
+CREATE INDEX IF NOT EXISTS idx_dm_conversation_avatar_mediafile_id ON dm_conversation (avatar_mediafile_id);
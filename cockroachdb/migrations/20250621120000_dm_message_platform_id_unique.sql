@@ -0,0 +1,13 @@
+-- +goose Up
+-- Сообщение платформы сохраняется в переписке один раз, даже если событие пришло повторно.
+-- Перед созданием индекса удаляем уже сохранённые повторы, оставляя первое сообщение
+DELETE FROM dm_message
+WHERE message_platform_id != 0
+    AND id NOT IN (
+        SELECT MIN(id) FROM dm_message WHERE message_platform_id != 0 GROUP BY conversation_id, message_platform_id
+    );
+
+-- 0 означает, что ID сообщения на платформе неизвестен
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dm_message_conversation_id_message_platform_id
+ON dm_message (conversation_id, message_platform_id)
+WHERE message_platform_id != 0;
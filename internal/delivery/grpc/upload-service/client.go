@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
 	uploadpb "postic-backend/internal/delivery/grpc/upload-service/proto"
+	"postic-backend/internal/repo"
 )
 
 type Client struct {
@@ -30,8 +35,8 @@ func (c *Client) Close() error {
 	return c.conn.Close()
 }
 
-// UploadFile отправляет файл чанками
-func (c *Client) UploadFile(ctx context.Context, fileName, fileType string, userID int, r io.Reader) (*uploadpb.UploadFileResponse, error) {
+// UploadFile отправляет файл чанками. Метаданные из meta (имя, тип, пользователь, команда) передаются в каждом чанке
+func (c *Client) UploadFile(ctx context.Context, meta *uploadpb.UploadFileChunk, r io.Reader) (*uploadpb.UploadFileResponse, error) {
 	stream, err := c.client.UploadFile(ctx)
 	if err != nil {
 		return nil, err
@@ -41,10 +46,12 @@ func (c *Client) UploadFile(ctx context.Context, fileName, fileType string, user
 		n, err := r.Read(buf)
 		if n > 0 {
 			chunk := &uploadpb.UploadFileChunk{
-				FileName: fileName,
-				FileType: fileType,
-				UserId:   int32(userID),
-				Data:     buf[:n],
+				FileName:    meta.FileName,
+				FileType:    meta.FileType,
+				UserId:      meta.UserId,
+				TeamId:      meta.TeamId,
+				DisplayName: meta.DisplayName,
+				Data:        buf[:n],
 			}
 			if err := stream.Send(chunk); err != nil {
 				return nil, err
@@ -60,6 +67,115 @@ func (c *Client) UploadFile(ctx context.Context, fileName, fileType string, user
 	return stream.CloseAndRecv()
 }
 
+// UploadSessionChunk дописывает данные из r в сессию возобновляемой загрузки начиная с offset.
+// Если чтение r прервалось, уже отправленные чанки всё равно фиксируются в сессии,
+// а ошибка чтения возвращается вместе с ответом
+func (c *Client) UploadSessionChunk(ctx context.Context, sessionID, offset int64, r io.Reader) (*uploadpb.UploadFileResponse, error) {
+	stream, err := c.client.UploadFile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var readErr error
+	sent := false
+	buf := make([]byte, 1024*1024) // 1MB chunk
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&uploadpb.UploadFileChunk{Id: sessionID, Offset: offset, Data: buf[:n]}); err != nil {
+				return nil, sessionStatusError(err)
+			}
+			offset += int64(n)
+			sent = true
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+	}
+	if !sent {
+		// пустой чанк нужен, чтобы сервер узнал id сессии
+		if err := stream.Send(&uploadpb.UploadFileChunk{Id: sessionID, Offset: offset}); err != nil {
+			return nil, sessionStatusError(err)
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, sessionStatusError(err)
+	}
+	return resp, readErr
+}
+
+// CreateUploadSession создаёт сессию возобновляемой загрузки
+func (c *Client) CreateUploadSession(ctx context.Context, req *uploadpb.CreateUploadSessionRequest) (*uploadpb.UploadSessionInfo, error) {
+	return c.client.CreateUploadSession(ctx, req)
+}
+
+// GetUploadSession возвращает состояние сессии возобновляемой загрузки
+func (c *Client) GetUploadSession(ctx context.Context, id int64) (*uploadpb.UploadSessionInfo, error) {
+	info, err := c.client.GetUploadSession(ctx, &uploadpb.GetUploadSessionRequest{Id: id})
+	if err != nil {
+		return nil, sessionStatusError(err)
+	}
+	return info, nil
+}
+
+// DeleteUploadSession удаляет сессию возобновляемой загрузки
+func (c *Client) DeleteUploadSession(ctx context.Context, id int64) error {
+	_, err := c.client.DeleteUploadSession(ctx, &uploadpb.DeleteUploadSessionRequest{Id: id})
+	return err
+}
+
+// CreatePresignedUpload создаёт сессию и ссылку для загрузки файла напрямую в хранилище
+func (c *Client) CreatePresignedUpload(ctx context.Context, req *uploadpb.CreateUploadSessionRequest) (*uploadpb.PresignedUpload, error) {
+	resp, err := c.client.CreatePresignedUpload(ctx, req)
+	if err != nil {
+		return nil, sessionStatusError(err)
+	}
+	return resp, nil
+}
+
+// FinalizePresignedUpload проверяет загруженный по ссылке файл и создаёт медиафайл
+func (c *Client) FinalizePresignedUpload(ctx context.Context, id int64) (*uploadpb.UploadSessionInfo, error) {
+	info, err := c.client.FinalizePresignedUpload(ctx, &uploadpb.FinalizePresignedUploadRequest{Id: id})
+	if err != nil {
+		return nil, sessionStatusError(err)
+	}
+	return info, nil
+}
+
+// PresignDownload возвращает временную ссылку на скачивание файла. expires 0 - время жизни по умолчанию
+func (c *Client) PresignDownload(ctx context.Context, id int64, expires time.Duration) (*uploadpb.PresignedURL, error) {
+	resp, err := c.client.PresignDownload(ctx, &uploadpb.PresignDownloadRequest{
+		Id:             id,
+		ExpiresSeconds: int64(expires.Seconds()),
+	})
+	switch status.Code(err) {
+	case codes.OK:
+		return resp, nil
+	case codes.NotFound:
+		return nil, repo.ErrMediaFileNotFound
+	}
+	return nil, sessionStatusError(err)
+}
+
+// sessionStatusError переводит gRPC-статусы обратно в ошибки репозитория сессий
+func sessionStatusError(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return repo.ErrUploadSessionNotFound
+	case codes.FailedPrecondition:
+		return repo.ErrUploadSessionOffsetMismatch
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: %s", repo.ErrInvalidUploadContent, status.Convert(err).Message())
+	case codes.Unimplemented:
+		return repo.ErrPresignNotSupported
+	}
+	return err
+}
+
 // DownloadChunk читает кусок файла по offset/length
 func (c *Client) DownloadChunk(ctx context.Context, id int64, offset, length int64) ([]byte, error) {
 	resp, err := c.client.DownloadChunk(ctx, &uploadpb.DownloadChunkRequest{
@@ -0,0 +1,64 @@
+package uploadservice
+
+import (
+	"context"
+	"postic-backend/internal/entity"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// CollectOrphanUploads периодически удаляет медиафайлы и объекты хранилища, которые ни к чему не привязаны
+// дольше grace. Отсрочка нужна, потому что файл сохраняется раньше, чем пост или комментарий, который на него ссылается
+func (s *UploadServiceServer) CollectOrphanUploads(ctx context.Context, interval, grace time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report := s.collectOrphanUploads(time.Now().Add(-grace))
+			if report.DeletedFiles > 0 || report.DeletedObjects > 0 {
+				log.Infof(
+					"Сборка неиспользуемых медиафайлов: удалено файлов %d, объектов %d, освобождено %d байт",
+					report.DeletedFiles, report.DeletedObjects, report.ReclaimedBytes,
+				)
+			}
+		}
+	}
+}
+
+func (s *UploadServiceServer) collectOrphanUploads(before time.Time) *entity.MediaGCReport {
+	total := &entity.MediaGCReport{}
+	add := func(report *entity.MediaGCReport) {
+		if report == nil {
+			return
+		}
+		total.DeletedFiles += report.DeletedFiles
+		total.DeletedObjects += report.DeletedObjects
+		total.ReclaimedBytes += report.ReclaimedBytes
+	}
+
+	ids, err := s.uploadRepo.GetOrphanUploads(before)
+	if err != nil {
+		log.Errorf("Ошибка при получении неиспользуемых медиафайлов: %v", err)
+		return total
+	}
+	for _, id := range ids {
+		report, err := s.uploadRepo.DeleteOrphanUpload(id)
+		add(report)
+		if err != nil {
+			log.Errorf("Ошибка при удалении неиспользуемого медиафайла %d: %v", id, err)
+		}
+	}
+
+	// Объекты без строк остаются после сбоев между сохранением объекта и записью в базу
+	report, err := s.uploadRepo.DeleteOrphanObjects(before)
+	add(report)
+	if err != nil {
+		log.Errorf("Ошибка при удалении неиспользуемых объектов хранилища: %v", err)
+	}
+	return total
+}
@@ -0,0 +1,140 @@
+package uploadservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	uploadpb "postic-backend/internal/delivery/grpc/upload-service/proto"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"postic-backend/pkg/media"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Ссылки выдаются на короткое время: для загрузки этого хватает, а утёкшая ссылка быстро становится бесполезной
+	presignedUploadTTL   = time.Hour
+	presignedDownloadTTL = 5 * time.Minute
+	maxPresignedTTL      = time.Hour
+)
+
+// presignError переводит ошибки подписи ссылок в gRPC-статусы
+func presignError(err error) error {
+	switch {
+	case errors.Is(err, repo.ErrPresignNotSupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, repo.ErrInvalidUploadContent):
+		return status.Error(codes.InvalidArgument, strings.TrimPrefix(err.Error(), repo.ErrInvalidUploadContent.Error()+": "))
+	case errors.Is(err, repo.ErrMediaFileNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return sessionError(err)
+}
+
+func (s *UploadServiceServer) CreatePresignedUpload(ctx context.Context, req *uploadpb.CreateUploadSessionRequest) (*uploadpb.PresignedUpload, error) {
+	session := &entity.UploadSession{
+		UserID:      int(req.UserId),
+		TeamID:      int(req.TeamId),
+		FileName:    req.FileName,
+		FileType:    req.FileType,
+		DisplayName: req.DisplayName,
+		Size:        req.Size,
+		Presigned:   true,
+		ExpiresAt:   time.Now().Add(uploadSessionTTL),
+	}
+	id, err := s.uploadSessionRepo.AddUploadSession(session)
+	if err != nil {
+		return nil, err
+	}
+	session.ID = id
+
+	link, err := s.uploadSessionRepo.PresignUploadSession(id, session.Size, presignedUploadTTL)
+	if err != nil {
+		_ = s.uploadSessionRepo.DeleteUploadSession(id)
+		return nil, presignError(err)
+	}
+	return &uploadpb.PresignedUpload{
+		Session: sessionInfo(session),
+		UploadUrl: &uploadpb.PresignedURL{
+			Url:       link,
+			ExpiresAt: time.Now().Add(presignedUploadTTL).Format("2006-01-02T15:04:05Z07:00"),
+		},
+	}, nil
+}
+
+func (s *UploadServiceServer) FinalizePresignedUpload(ctx context.Context, req *uploadpb.FinalizePresignedUploadRequest) (*uploadpb.UploadSessionInfo, error) {
+	session, err := s.uploadSessionRepo.GetUploadSession(int(req.Id))
+	if err != nil {
+		return nil, sessionError(err)
+	}
+	if !session.Presigned {
+		return nil, status.Error(codes.FailedPrecondition, "сессия загружается чанками")
+	}
+	if session.MediaFileID != nil {
+		// повторный вызов после успешной сборки
+		return sessionInfo(session), nil
+	}
+
+	if session.Offset == 0 {
+		if err := s.uploadSessionRepo.AttachPresignedObject(session.ID, session.Size); err != nil {
+			return nil, presignError(err)
+		}
+		session.Offset = session.Size
+	} else if err := s.uploadSessionRepo.CheckPresignedObject(session.ID, session.Size); err != nil {
+		// при повторном вызове объект могли перезаписать по той же ссылке
+		return nil, presignError(err)
+	}
+	if err := s.checkSessionContent(session); err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			// файл неподходящего типа не будет принят и при повторе, поэтому место освобождаем сразу
+			_ = s.uploadSessionRepo.DeleteUploadSession(session.ID)
+		}
+		return nil, err
+	}
+
+	id, err := s.completeUploadSession(session)
+	if err != nil {
+		return nil, err
+	}
+	session.MediaFileID = &id
+	return sessionInfo(session), nil
+}
+
+// checkSessionContent проверяет по первым байтам, что содержимое соответствует заявленному типу файла
+func (s *UploadServiceServer) checkSessionContent(session *entity.UploadSession) error {
+	reader, err := s.uploadSessionRepo.ReadUploadSession(session.ID)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(reader, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if err := media.CheckContentType(session.FileType, header[:n]); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+func (s *UploadServiceServer) PresignDownload(ctx context.Context, req *uploadpb.PresignDownloadRequest) (*uploadpb.PresignedURL, error) {
+	expires := presignedDownloadTTL
+	if req.ExpiresSeconds > 0 {
+		expires = min(time.Duration(req.ExpiresSeconds)*time.Second, maxPresignedTTL)
+	}
+	link, err := s.uploadRepo.PresignDownload(int(req.Id), expires)
+	if err != nil {
+		return nil, presignError(fmt.Errorf("файл %d: %w", req.Id, err))
+	}
+	return &uploadpb.PresignedURL{
+		Url:       link,
+		ExpiresAt: time.Now().Add(expires).Format("2006-01-02T15:04:05Z07:00"),
+	}, nil
+}
@@ -23,12 +23,14 @@ const (
 
 type UploadFileChunk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"` // для продолжения загрузки (опционально)
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"` // id сессии для продолжения загрузки (опционально)
 	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
 	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
 	FileType      string                 `protobuf:"bytes,4,opt,name=file_type,json=fileType,proto3" json:"file_type,omitempty"`
 	UserId        int32                  `protobuf:"varint,5,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	FileName      string                 `protobuf:"bytes,6,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	TeamId        int32                  `protobuf:"varint,7,opt,name=team_id,json=teamId,proto3" json:"team_id,omitempty"`               // команда-владелец (опционально)
+	DisplayName   string                 `protobuf:"bytes,8,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"` // название файла в медиатеке
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -105,10 +107,25 @@ func (x *UploadFileChunk) GetFileName() string {
 	return ""
 }
 
+func (x *UploadFileChunk) GetTeamId() int32 {
+	if x != nil {
+		return x.TeamId
+	}
+	return 0
+}
+
+func (x *UploadFileChunk) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
 type UploadFileResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"` // 0, если сессия загрузки ещё не завершена
 	FilePath      string                 `protobuf:"bytes,2,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"` // смещение сессии после записи чанков
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -157,6 +174,13 @@ func (x *UploadFileResponse) GetFilePath() string {
 	return ""
 }
 
+func (x *UploadFileResponse) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
 type DownloadChunkRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -329,6 +353,11 @@ type GetUploadInfoResponse struct {
 	UserId        int32                  `protobuf:"varint,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	Size          int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
 	CreatedAt     string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Width         int32                  `protobuf:"varint,7,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,8,opt,name=height,proto3" json:"height,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,9,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"` // только для видео
+	Renditions    []*Rendition           `protobuf:"bytes,10,rep,name=renditions,proto3" json:"renditions,omitempty"`
+	TeamId        int32                  `protobuf:"varint,11,opt,name=team_id,json=teamId,proto3" json:"team_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -405,6 +434,126 @@ func (x *GetUploadInfoResponse) GetCreatedAt() string {
 	return ""
 }
 
+func (x *GetUploadInfoResponse) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *GetUploadInfoResponse) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *GetUploadInfoResponse) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *GetUploadInfoResponse) GetRenditions() []*Rendition {
+	if x != nil {
+		return x.Renditions
+	}
+	return nil
+}
+
+func (x *GetUploadInfoResponse) GetTeamId() int32 {
+	if x != nil {
+		return x.TeamId
+	}
+	return 0
+}
+
+// Производная версия файла (уменьшенная копия, превью, перекодированное видео)
+type Rendition struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"` // id медиафайла версии, читается через DownloadChunk
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	MimeType      string                 `protobuf:"bytes,3,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	Width         int32                  `protobuf:"varint,4,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,5,opt,name=height,proto3" json:"height,omitempty"`
+	Size          int64                  `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Rendition) Reset() {
+	*x = Rendition{}
+	mi := &file_upload_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rendition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rendition) ProtoMessage() {}
+
+func (x *Rendition) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rendition.ProtoReflect.Descriptor instead.
+func (*Rendition) Descriptor() ([]byte, []int) {
+	return file_upload_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Rendition) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Rendition) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Rendition) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *Rendition) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Rendition) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Rendition) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
 type DeleteUploadRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -414,7 +563,7 @@ type DeleteUploadRequest struct {
 
 func (x *DeleteUploadRequest) Reset() {
 	*x = DeleteUploadRequest{}
-	mi := &file_upload_service_proto_msgTypes[6]
+	mi := &file_upload_service_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -426,7 +575,7 @@ func (x *DeleteUploadRequest) String() string {
 func (*DeleteUploadRequest) ProtoMessage() {}
 
 func (x *DeleteUploadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_upload_service_proto_msgTypes[6]
+	mi := &file_upload_service_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -439,7 +588,7 @@ func (x *DeleteUploadRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteUploadRequest.ProtoReflect.Descriptor instead.
 func (*DeleteUploadRequest) Descriptor() ([]byte, []int) {
-	return file_upload_service_proto_rawDescGZIP(), []int{6}
+	return file_upload_service_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *DeleteUploadRequest) GetId() int64 {
@@ -458,7 +607,7 @@ type DeleteUploadResponse struct {
 
 func (x *DeleteUploadResponse) Reset() {
 	*x = DeleteUploadResponse{}
-	mi := &file_upload_service_proto_msgTypes[7]
+	mi := &file_upload_service_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -470,7 +619,7 @@ func (x *DeleteUploadResponse) String() string {
 func (*DeleteUploadResponse) ProtoMessage() {}
 
 func (x *DeleteUploadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_upload_service_proto_msgTypes[7]
+	mi := &file_upload_service_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -483,7 +632,7 @@ func (x *DeleteUploadResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteUploadResponse.ProtoReflect.Descriptor instead.
 func (*DeleteUploadResponse) Descriptor() ([]byte, []int) {
-	return file_upload_service_proto_rawDescGZIP(), []int{7}
+	return file_upload_service_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *DeleteUploadResponse) GetSuccess() bool {
@@ -493,21 +642,556 @@ func (x *DeleteUploadResponse) GetSuccess() bool {
 	return false
 }
 
+type CreateUploadSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int32                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	TeamId        int32                  `protobuf:"varint,2,opt,name=team_id,json=teamId,proto3" json:"team_id,omitempty"`
+	FileName      string                 `protobuf:"bytes,3,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	FileType      string                 `protobuf:"bytes,4,opt,name=file_type,json=fileType,proto3" json:"file_type,omitempty"`
+	DisplayName   string                 `protobuf:"bytes,5,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Size          int64                  `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUploadSessionRequest) Reset() {
+	*x = CreateUploadSessionRequest{}
+	mi := &file_upload_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUploadSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUploadSessionRequest) ProtoMessage() {}
+
+func (x *CreateUploadSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUploadSessionRequest.ProtoReflect.Descriptor instead.
+func (*CreateUploadSessionRequest) Descriptor() ([]byte, []int) {
+	return file_upload_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CreateUploadSessionRequest) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *CreateUploadSessionRequest) GetTeamId() int32 {
+	if x != nil {
+		return x.TeamId
+	}
+	return 0
+}
+
+func (x *CreateUploadSessionRequest) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *CreateUploadSessionRequest) GetFileType() string {
+	if x != nil {
+		return x.FileType
+	}
+	return ""
+}
+
+func (x *CreateUploadSessionRequest) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *CreateUploadSessionRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type GetUploadSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUploadSessionRequest) Reset() {
+	*x = GetUploadSessionRequest{}
+	mi := &file_upload_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUploadSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUploadSessionRequest) ProtoMessage() {}
+
+func (x *GetUploadSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUploadSessionRequest.ProtoReflect.Descriptor instead.
+func (*GetUploadSessionRequest) Descriptor() ([]byte, []int) {
+	return file_upload_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetUploadSessionRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type UploadSessionInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        int32                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	TeamId        int32                  `protobuf:"varint,3,opt,name=team_id,json=teamId,proto3" json:"team_id,omitempty"`
+	FileType      string                 `protobuf:"bytes,4,opt,name=file_type,json=fileType,proto3" json:"file_type,omitempty"`
+	DisplayName   string                 `protobuf:"bytes,5,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Size          int64                  `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`
+	Offset        int64                  `protobuf:"varint,7,opt,name=offset,proto3" json:"offset,omitempty"`
+	MediafileId   int64                  `protobuf:"varint,8,opt,name=mediafile_id,json=mediafileId,proto3" json:"mediafile_id,omitempty"` // 0, пока файл не собран
+	ExpiresAt     string                 `protobuf:"bytes,9,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Presigned     bool                   `protobuf:"varint,10,opt,name=presigned,proto3" json:"presigned,omitempty"` // файл загружается по временной ссылке, а не чанками
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadSessionInfo) Reset() {
+	*x = UploadSessionInfo{}
+	mi := &file_upload_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSessionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSessionInfo) ProtoMessage() {}
+
+func (x *UploadSessionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSessionInfo.ProtoReflect.Descriptor instead.
+func (*UploadSessionInfo) Descriptor() ([]byte, []int) {
+	return file_upload_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UploadSessionInfo) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UploadSessionInfo) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *UploadSessionInfo) GetTeamId() int32 {
+	if x != nil {
+		return x.TeamId
+	}
+	return 0
+}
+
+func (x *UploadSessionInfo) GetFileType() string {
+	if x != nil {
+		return x.FileType
+	}
+	return ""
+}
+
+func (x *UploadSessionInfo) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *UploadSessionInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *UploadSessionInfo) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *UploadSessionInfo) GetMediafileId() int64 {
+	if x != nil {
+		return x.MediafileId
+	}
+	return 0
+}
+
+func (x *UploadSessionInfo) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+func (x *UploadSessionInfo) GetPresigned() bool {
+	if x != nil {
+		return x.Presigned
+	}
+	return false
+}
+
+type DeleteUploadSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteUploadSessionRequest) Reset() {
+	*x = DeleteUploadSessionRequest{}
+	mi := &file_upload_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUploadSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUploadSessionRequest) ProtoMessage() {}
+
+func (x *DeleteUploadSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUploadSessionRequest.ProtoReflect.Descriptor instead.
+func (*DeleteUploadSessionRequest) Descriptor() ([]byte, []int) {
+	return file_upload_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeleteUploadSessionRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteUploadSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteUploadSessionResponse) Reset() {
+	*x = DeleteUploadSessionResponse{}
+	mi := &file_upload_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUploadSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUploadSessionResponse) ProtoMessage() {}
+
+func (x *DeleteUploadSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUploadSessionResponse.ProtoReflect.Descriptor instead.
+func (*DeleteUploadSessionResponse) Descriptor() ([]byte, []int) {
+	return file_upload_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeleteUploadSessionResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type PresignedURL struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	ExpiresAt     string                 `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PresignedURL) Reset() {
+	*x = PresignedURL{}
+	mi := &file_upload_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PresignedURL) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PresignedURL) ProtoMessage() {}
+
+func (x *PresignedURL) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresignedURL.ProtoReflect.Descriptor instead.
+func (*PresignedURL) Descriptor() ([]byte, []int) {
+	return file_upload_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *PresignedURL) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *PresignedURL) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+type PresignedUpload struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Session       *UploadSessionInfo     `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	UploadUrl     *PresignedURL          `protobuf:"bytes,2,opt,name=upload_url,json=uploadUrl,proto3" json:"upload_url,omitempty"` // ссылка для PUT-запроса с содержимым файла
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PresignedUpload) Reset() {
+	*x = PresignedUpload{}
+	mi := &file_upload_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PresignedUpload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PresignedUpload) ProtoMessage() {}
+
+func (x *PresignedUpload) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresignedUpload.ProtoReflect.Descriptor instead.
+func (*PresignedUpload) Descriptor() ([]byte, []int) {
+	return file_upload_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *PresignedUpload) GetSession() *UploadSessionInfo {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+func (x *PresignedUpload) GetUploadUrl() *PresignedURL {
+	if x != nil {
+		return x.UploadUrl
+	}
+	return nil
+}
+
+type FinalizePresignedUploadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"` // id сессии
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FinalizePresignedUploadRequest) Reset() {
+	*x = FinalizePresignedUploadRequest{}
+	mi := &file_upload_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FinalizePresignedUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinalizePresignedUploadRequest) ProtoMessage() {}
+
+func (x *FinalizePresignedUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FinalizePresignedUploadRequest.ProtoReflect.Descriptor instead.
+func (*FinalizePresignedUploadRequest) Descriptor() ([]byte, []int) {
+	return file_upload_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *FinalizePresignedUploadRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type PresignDownloadRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                               // id медиафайла
+	ExpiresSeconds int64                  `protobuf:"varint,2,opt,name=expires_seconds,json=expiresSeconds,proto3" json:"expires_seconds,omitempty"` // 0 - время жизни по умолчанию
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PresignDownloadRequest) Reset() {
+	*x = PresignDownloadRequest{}
+	mi := &file_upload_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PresignDownloadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PresignDownloadRequest) ProtoMessage() {}
+
+func (x *PresignDownloadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_upload_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresignDownloadRequest.ProtoReflect.Descriptor instead.
+func (*PresignDownloadRequest) Descriptor() ([]byte, []int) {
+	return file_upload_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *PresignDownloadRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PresignDownloadRequest) GetExpiresSeconds() int64 {
+	if x != nil {
+		return x.ExpiresSeconds
+	}
+	return 0
+}
+
 var File_upload_service_proto protoreflect.FileDescriptor
 
 const file_upload_service_proto_rawDesc = "" +
 	"\n" +
-	"\x14upload-service.proto\x12\ruploadservice\"\xa0\x01\n" +
+	"\x14upload-service.proto\x12\ruploadservice\"\xdc\x01\n" +
 	"\x0fUploadFileChunk\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x16\n" +
 	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x12\n" +
 	"\x04data\x18\x03 \x01(\fR\x04data\x12\x1b\n" +
 	"\tfile_type\x18\x04 \x01(\tR\bfileType\x12\x17\n" +
 	"\auser_id\x18\x05 \x01(\x05R\x06userId\x12\x1b\n" +
-	"\tfile_name\x18\x06 \x01(\tR\bfileName\"A\n" +
+	"\tfile_name\x18\x06 \x01(\tR\bfileName\x12\x17\n" +
+	"\ateam_id\x18\a \x01(\x05R\x06teamId\x12!\n" +
+	"\fdisplay_name\x18\b \x01(\tR\vdisplayName\"Y\n" +
 	"\x12UploadFileResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1b\n" +
-	"\tfile_path\x18\x02 \x01(\tR\bfilePath\"V\n" +
+	"\tfile_path\x18\x02 \x01(\tR\bfilePath\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\"V\n" +
 	"\x14DownloadChunkRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x16\n" +
 	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x16\n" +
@@ -518,7 +1202,7 @@ const file_upload_service_proto_rawDesc = "" +
 	"\n" +
 	"total_size\x18\x03 \x01(\x03R\ttotalSize\"&\n" +
 	"\x14GetUploadInfoRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"\xad\x01\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\xcf\x02\n" +
 	"\x15GetUploadInfoResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1b\n" +
 	"\tfile_path\x18\x02 \x01(\tR\bfilePath\x12\x1b\n" +
@@ -526,17 +1210,78 @@ const file_upload_service_proto_rawDesc = "" +
 	"\auser_id\x18\x04 \x01(\x05R\x06userId\x12\x12\n" +
 	"\x04size\x18\x05 \x01(\x03R\x04size\x12\x1d\n" +
 	"\n" +
-	"created_at\x18\x06 \x01(\tR\tcreatedAt\"%\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12\x14\n" +
+	"\x05width\x18\a \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\b \x01(\x05R\x06height\x12\x1f\n" +
+	"\vduration_ms\x18\t \x01(\x03R\n" +
+	"durationMs\x128\n" +
+	"\n" +
+	"renditions\x18\n" +
+	" \x03(\v2\x18.uploadservice.RenditionR\n" +
+	"renditions\x12\x17\n" +
+	"\ateam_id\x18\v \x01(\x05R\x06teamId\"\x8e\x01\n" +
+	"\tRendition\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x1b\n" +
+	"\tmime_type\x18\x03 \x01(\tR\bmimeType\x12\x14\n" +
+	"\x05width\x18\x04 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x05 \x01(\x05R\x06height\x12\x12\n" +
+	"\x04size\x18\x06 \x01(\x03R\x04size\"%\n" +
 	"\x13DeleteUploadRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\"0\n" +
 	"\x14DeleteUploadResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2\xf3\x02\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xbf\x01\n" +
+	"\x1aCreateUploadSessionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x05R\x06userId\x12\x17\n" +
+	"\ateam_id\x18\x02 \x01(\x05R\x06teamId\x12\x1b\n" +
+	"\tfile_name\x18\x03 \x01(\tR\bfileName\x12\x1b\n" +
+	"\tfile_type\x18\x04 \x01(\tR\bfileType\x12!\n" +
+	"\fdisplay_name\x18\x05 \x01(\tR\vdisplayName\x12\x12\n" +
+	"\x04size\x18\x06 \x01(\x03R\x04size\")\n" +
+	"\x17GetUploadSessionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\xa1\x02\n" +
+	"\x11UploadSessionInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x05R\x06userId\x12\x17\n" +
+	"\ateam_id\x18\x03 \x01(\x05R\x06teamId\x12\x1b\n" +
+	"\tfile_type\x18\x04 \x01(\tR\bfileType\x12!\n" +
+	"\fdisplay_name\x18\x05 \x01(\tR\vdisplayName\x12\x12\n" +
+	"\x04size\x18\x06 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06offset\x18\a \x01(\x03R\x06offset\x12!\n" +
+	"\fmediafile_id\x18\b \x01(\x03R\vmediafileId\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\t \x01(\tR\texpiresAt\x12\x1c\n" +
+	"\tpresigned\x18\n" +
+	" \x01(\bR\tpresigned\",\n" +
+	"\x1aDeleteUploadSessionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"7\n" +
+	"\x1bDeleteUploadSessionResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"?\n" +
+	"\fPresignedURL\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\tR\texpiresAt\"\x89\x01\n" +
+	"\x0fPresignedUpload\x12:\n" +
+	"\asession\x18\x01 \x01(\v2 .uploadservice.UploadSessionInfoR\asession\x12:\n" +
+	"\n" +
+	"upload_url\x18\x02 \x01(\v2\x1b.uploadservice.PresignedURLR\tuploadUrl\"0\n" +
+	"\x1eFinalizePresignedUploadRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"Q\n" +
+	"\x16PresignDownloadRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12'\n" +
+	"\x0fexpires_seconds\x18\x02 \x01(\x03R\x0eexpiresSeconds2\xca\a\n" +
 	"\rUploadService\x12Q\n" +
 	"\n" +
 	"UploadFile\x12\x1e.uploadservice.UploadFileChunk\x1a!.uploadservice.UploadFileResponse(\x01\x12Z\n" +
 	"\rDownloadChunk\x12#.uploadservice.DownloadChunkRequest\x1a$.uploadservice.DownloadChunkResponse\x12Z\n" +
 	"\rGetUploadInfo\x12#.uploadservice.GetUploadInfoRequest\x1a$.uploadservice.GetUploadInfoResponse\x12W\n" +
-	"\fDeleteUpload\x12\".uploadservice.DeleteUploadRequest\x1a#.uploadservice.DeleteUploadResponseB\x12Z\x10./;uploadserviceb\x06proto3"
+	"\fDeleteUpload\x12\".uploadservice.DeleteUploadRequest\x1a#.uploadservice.DeleteUploadResponse\x12b\n" +
+	"\x13CreateUploadSession\x12).uploadservice.CreateUploadSessionRequest\x1a .uploadservice.UploadSessionInfo\x12\\\n" +
+	"\x10GetUploadSession\x12&.uploadservice.GetUploadSessionRequest\x1a .uploadservice.UploadSessionInfo\x12l\n" +
+	"\x13DeleteUploadSession\x12).uploadservice.DeleteUploadSessionRequest\x1a*.uploadservice.DeleteUploadSessionResponse\x12b\n" +
+	"\x15CreatePresignedUpload\x12).uploadservice.CreateUploadSessionRequest\x1a\x1e.uploadservice.PresignedUpload\x12j\n" +
+	"\x17FinalizePresignedUpload\x12-.uploadservice.FinalizePresignedUploadRequest\x1a .uploadservice.UploadSessionInfo\x12U\n" +
+	"\x0fPresignDownload\x12%.uploadservice.PresignDownloadRequest\x1a\x1b.uploadservice.PresignedURLB\x12Z\x10./;uploadserviceb\x06proto3"
 
 var (
 	file_upload_service_proto_rawDescOnce sync.Once
@@ -550,31 +1295,56 @@ func file_upload_service_proto_rawDescGZIP() []byte {
 	return file_upload_service_proto_rawDescData
 }
 
-var file_upload_service_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_upload_service_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
 var file_upload_service_proto_goTypes = []any{
-	(*UploadFileChunk)(nil),       // 0: uploadservice.UploadFileChunk
-	(*UploadFileResponse)(nil),    // 1: uploadservice.UploadFileResponse
-	(*DownloadChunkRequest)(nil),  // 2: uploadservice.DownloadChunkRequest
-	(*DownloadChunkResponse)(nil), // 3: uploadservice.DownloadChunkResponse
-	(*GetUploadInfoRequest)(nil),  // 4: uploadservice.GetUploadInfoRequest
-	(*GetUploadInfoResponse)(nil), // 5: uploadservice.GetUploadInfoResponse
-	(*DeleteUploadRequest)(nil),   // 6: uploadservice.DeleteUploadRequest
-	(*DeleteUploadResponse)(nil),  // 7: uploadservice.DeleteUploadResponse
+	(*UploadFileChunk)(nil),                // 0: uploadservice.UploadFileChunk
+	(*UploadFileResponse)(nil),             // 1: uploadservice.UploadFileResponse
+	(*DownloadChunkRequest)(nil),           // 2: uploadservice.DownloadChunkRequest
+	(*DownloadChunkResponse)(nil),          // 3: uploadservice.DownloadChunkResponse
+	(*GetUploadInfoRequest)(nil),           // 4: uploadservice.GetUploadInfoRequest
+	(*GetUploadInfoResponse)(nil),          // 5: uploadservice.GetUploadInfoResponse
+	(*Rendition)(nil),                      // 6: uploadservice.Rendition
+	(*DeleteUploadRequest)(nil),            // 7: uploadservice.DeleteUploadRequest
+	(*DeleteUploadResponse)(nil),           // 8: uploadservice.DeleteUploadResponse
+	(*CreateUploadSessionRequest)(nil),     // 9: uploadservice.CreateUploadSessionRequest
+	(*GetUploadSessionRequest)(nil),        // 10: uploadservice.GetUploadSessionRequest
+	(*UploadSessionInfo)(nil),              // 11: uploadservice.UploadSessionInfo
+	(*DeleteUploadSessionRequest)(nil),     // 12: uploadservice.DeleteUploadSessionRequest
+	(*DeleteUploadSessionResponse)(nil),    // 13: uploadservice.DeleteUploadSessionResponse
+	(*PresignedURL)(nil),                   // 14: uploadservice.PresignedURL
+	(*PresignedUpload)(nil),                // 15: uploadservice.PresignedUpload
+	(*FinalizePresignedUploadRequest)(nil), // 16: uploadservice.FinalizePresignedUploadRequest
+	(*PresignDownloadRequest)(nil),         // 17: uploadservice.PresignDownloadRequest
 }
 var file_upload_service_proto_depIdxs = []int32{
-	0, // 0: uploadservice.UploadService.UploadFile:input_type -> uploadservice.UploadFileChunk
-	2, // 1: uploadservice.UploadService.DownloadChunk:input_type -> uploadservice.DownloadChunkRequest
-	4, // 2: uploadservice.UploadService.GetUploadInfo:input_type -> uploadservice.GetUploadInfoRequest
-	6, // 3: uploadservice.UploadService.DeleteUpload:input_type -> uploadservice.DeleteUploadRequest
-	1, // 4: uploadservice.UploadService.UploadFile:output_type -> uploadservice.UploadFileResponse
-	3, // 5: uploadservice.UploadService.DownloadChunk:output_type -> uploadservice.DownloadChunkResponse
-	5, // 6: uploadservice.UploadService.GetUploadInfo:output_type -> uploadservice.GetUploadInfoResponse
-	7, // 7: uploadservice.UploadService.DeleteUpload:output_type -> uploadservice.DeleteUploadResponse
-	4, // [4:8] is the sub-list for method output_type
-	0, // [0:4] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	6,  // 0: uploadservice.GetUploadInfoResponse.renditions:type_name -> uploadservice.Rendition
+	11, // 1: uploadservice.PresignedUpload.session:type_name -> uploadservice.UploadSessionInfo
+	14, // 2: uploadservice.PresignedUpload.upload_url:type_name -> uploadservice.PresignedURL
+	0,  // 3: uploadservice.UploadService.UploadFile:input_type -> uploadservice.UploadFileChunk
+	2,  // 4: uploadservice.UploadService.DownloadChunk:input_type -> uploadservice.DownloadChunkRequest
+	4,  // 5: uploadservice.UploadService.GetUploadInfo:input_type -> uploadservice.GetUploadInfoRequest
+	7,  // 6: uploadservice.UploadService.DeleteUpload:input_type -> uploadservice.DeleteUploadRequest
+	9,  // 7: uploadservice.UploadService.CreateUploadSession:input_type -> uploadservice.CreateUploadSessionRequest
+	10, // 8: uploadservice.UploadService.GetUploadSession:input_type -> uploadservice.GetUploadSessionRequest
+	12, // 9: uploadservice.UploadService.DeleteUploadSession:input_type -> uploadservice.DeleteUploadSessionRequest
+	9,  // 10: uploadservice.UploadService.CreatePresignedUpload:input_type -> uploadservice.CreateUploadSessionRequest
+	16, // 11: uploadservice.UploadService.FinalizePresignedUpload:input_type -> uploadservice.FinalizePresignedUploadRequest
+	17, // 12: uploadservice.UploadService.PresignDownload:input_type -> uploadservice.PresignDownloadRequest
+	1,  // 13: uploadservice.UploadService.UploadFile:output_type -> uploadservice.UploadFileResponse
+	3,  // 14: uploadservice.UploadService.DownloadChunk:output_type -> uploadservice.DownloadChunkResponse
+	5,  // 15: uploadservice.UploadService.GetUploadInfo:output_type -> uploadservice.GetUploadInfoResponse
+	8,  // 16: uploadservice.UploadService.DeleteUpload:output_type -> uploadservice.DeleteUploadResponse
+	11, // 17: uploadservice.UploadService.CreateUploadSession:output_type -> uploadservice.UploadSessionInfo
+	11, // 18: uploadservice.UploadService.GetUploadSession:output_type -> uploadservice.UploadSessionInfo
+	13, // 19: uploadservice.UploadService.DeleteUploadSession:output_type -> uploadservice.DeleteUploadSessionResponse
+	15, // 20: uploadservice.UploadService.CreatePresignedUpload:output_type -> uploadservice.PresignedUpload
+	11, // 21: uploadservice.UploadService.FinalizePresignedUpload:output_type -> uploadservice.UploadSessionInfo
+	14, // 22: uploadservice.UploadService.PresignDownload:output_type -> uploadservice.PresignedURL
+	13, // [13:23] is the sub-list for method output_type
+	3,  // [3:13] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_upload_service_proto_init() }
@@ -588,7 +1358,7 @@ func file_upload_service_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_upload_service_proto_rawDesc), len(file_upload_service_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   18,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
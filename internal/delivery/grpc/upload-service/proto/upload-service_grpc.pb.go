@@ -19,10 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	UploadService_UploadFile_FullMethodName    = "/uploadservice.UploadService/UploadFile"
-	UploadService_DownloadChunk_FullMethodName = "/uploadservice.UploadService/DownloadChunk"
-	UploadService_GetUploadInfo_FullMethodName = "/uploadservice.UploadService/GetUploadInfo"
-	UploadService_DeleteUpload_FullMethodName  = "/uploadservice.UploadService/DeleteUpload"
+	UploadService_UploadFile_FullMethodName              = "/uploadservice.UploadService/UploadFile"
+	UploadService_DownloadChunk_FullMethodName           = "/uploadservice.UploadService/DownloadChunk"
+	UploadService_GetUploadInfo_FullMethodName           = "/uploadservice.UploadService/GetUploadInfo"
+	UploadService_DeleteUpload_FullMethodName            = "/uploadservice.UploadService/DeleteUpload"
+	UploadService_CreateUploadSession_FullMethodName     = "/uploadservice.UploadService/CreateUploadSession"
+	UploadService_GetUploadSession_FullMethodName        = "/uploadservice.UploadService/GetUploadSession"
+	UploadService_DeleteUploadSession_FullMethodName     = "/uploadservice.UploadService/DeleteUploadSession"
+	UploadService_CreatePresignedUpload_FullMethodName   = "/uploadservice.UploadService/CreatePresignedUpload"
+	UploadService_FinalizePresignedUpload_FullMethodName = "/uploadservice.UploadService/FinalizePresignedUpload"
+	UploadService_PresignDownload_FullMethodName         = "/uploadservice.UploadService/PresignDownload"
 )
 
 // UploadServiceClient is the client API for UploadService service.
@@ -37,6 +43,18 @@ type UploadServiceClient interface {
 	GetUploadInfo(ctx context.Context, in *GetUploadInfoRequest, opts ...grpc.CallOption) (*GetUploadInfoResponse, error)
 	// Удаление файла
 	DeleteUpload(ctx context.Context, in *DeleteUploadRequest, opts ...grpc.CallOption) (*DeleteUploadResponse, error)
+	// Создание сессии возобновляемой загрузки, чанки которой затем передаются в UploadFile с её id
+	CreateUploadSession(ctx context.Context, in *CreateUploadSessionRequest, opts ...grpc.CallOption) (*UploadSessionInfo, error)
+	// Получение состояния сессии возобновляемой загрузки
+	GetUploadSession(ctx context.Context, in *GetUploadSessionRequest, opts ...grpc.CallOption) (*UploadSessionInfo, error)
+	// Удаление сессии возобновляемой загрузки
+	DeleteUploadSession(ctx context.Context, in *DeleteUploadSessionRequest, opts ...grpc.CallOption) (*DeleteUploadSessionResponse, error)
+	// Создание сессии с временной ссылкой для загрузки файла напрямую в хранилище
+	CreatePresignedUpload(ctx context.Context, in *CreateUploadSessionRequest, opts ...grpc.CallOption) (*PresignedUpload, error)
+	// Проверка загруженного по ссылке файла и создание медиафайла
+	FinalizePresignedUpload(ctx context.Context, in *FinalizePresignedUploadRequest, opts ...grpc.CallOption) (*UploadSessionInfo, error)
+	// Временная ссылка на скачивание файла напрямую из хранилища
+	PresignDownload(ctx context.Context, in *PresignDownloadRequest, opts ...grpc.CallOption) (*PresignedURL, error)
 }
 
 type uploadServiceClient struct {
@@ -90,6 +108,66 @@ func (c *uploadServiceClient) DeleteUpload(ctx context.Context, in *DeleteUpload
 	return out, nil
 }
 
+func (c *uploadServiceClient) CreateUploadSession(ctx context.Context, in *CreateUploadSessionRequest, opts ...grpc.CallOption) (*UploadSessionInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadSessionInfo)
+	err := c.cc.Invoke(ctx, UploadService_CreateUploadSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uploadServiceClient) GetUploadSession(ctx context.Context, in *GetUploadSessionRequest, opts ...grpc.CallOption) (*UploadSessionInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadSessionInfo)
+	err := c.cc.Invoke(ctx, UploadService_GetUploadSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uploadServiceClient) DeleteUploadSession(ctx context.Context, in *DeleteUploadSessionRequest, opts ...grpc.CallOption) (*DeleteUploadSessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteUploadSessionResponse)
+	err := c.cc.Invoke(ctx, UploadService_DeleteUploadSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uploadServiceClient) CreatePresignedUpload(ctx context.Context, in *CreateUploadSessionRequest, opts ...grpc.CallOption) (*PresignedUpload, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PresignedUpload)
+	err := c.cc.Invoke(ctx, UploadService_CreatePresignedUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uploadServiceClient) FinalizePresignedUpload(ctx context.Context, in *FinalizePresignedUploadRequest, opts ...grpc.CallOption) (*UploadSessionInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadSessionInfo)
+	err := c.cc.Invoke(ctx, UploadService_FinalizePresignedUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uploadServiceClient) PresignDownload(ctx context.Context, in *PresignDownloadRequest, opts ...grpc.CallOption) (*PresignedURL, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PresignedURL)
+	err := c.cc.Invoke(ctx, UploadService_PresignDownload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UploadServiceServer is the server API for UploadService service.
 // All implementations must embed UnimplementedUploadServiceServer
 // for forward compatibility.
@@ -102,6 +180,18 @@ type UploadServiceServer interface {
 	GetUploadInfo(context.Context, *GetUploadInfoRequest) (*GetUploadInfoResponse, error)
 	// Удаление файла
 	DeleteUpload(context.Context, *DeleteUploadRequest) (*DeleteUploadResponse, error)
+	// Создание сессии возобновляемой загрузки, чанки которой затем передаются в UploadFile с её id
+	CreateUploadSession(context.Context, *CreateUploadSessionRequest) (*UploadSessionInfo, error)
+	// Получение состояния сессии возобновляемой загрузки
+	GetUploadSession(context.Context, *GetUploadSessionRequest) (*UploadSessionInfo, error)
+	// Удаление сессии возобновляемой загрузки
+	DeleteUploadSession(context.Context, *DeleteUploadSessionRequest) (*DeleteUploadSessionResponse, error)
+	// Создание сессии с временной ссылкой для загрузки файла напрямую в хранилище
+	CreatePresignedUpload(context.Context, *CreateUploadSessionRequest) (*PresignedUpload, error)
+	// Проверка загруженного по ссылке файла и создание медиафайла
+	FinalizePresignedUpload(context.Context, *FinalizePresignedUploadRequest) (*UploadSessionInfo, error)
+	// Временная ссылка на скачивание файла напрямую из хранилища
+	PresignDownload(context.Context, *PresignDownloadRequest) (*PresignedURL, error)
 	mustEmbedUnimplementedUploadServiceServer()
 }
 
@@ -124,6 +214,24 @@ func (UnimplementedUploadServiceServer) GetUploadInfo(context.Context, *GetUploa
 func (UnimplementedUploadServiceServer) DeleteUpload(context.Context, *DeleteUploadRequest) (*DeleteUploadResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteUpload not implemented")
 }
+func (UnimplementedUploadServiceServer) CreateUploadSession(context.Context, *CreateUploadSessionRequest) (*UploadSessionInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUploadSession not implemented")
+}
+func (UnimplementedUploadServiceServer) GetUploadSession(context.Context, *GetUploadSessionRequest) (*UploadSessionInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUploadSession not implemented")
+}
+func (UnimplementedUploadServiceServer) DeleteUploadSession(context.Context, *DeleteUploadSessionRequest) (*DeleteUploadSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteUploadSession not implemented")
+}
+func (UnimplementedUploadServiceServer) CreatePresignedUpload(context.Context, *CreateUploadSessionRequest) (*PresignedUpload, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePresignedUpload not implemented")
+}
+func (UnimplementedUploadServiceServer) FinalizePresignedUpload(context.Context, *FinalizePresignedUploadRequest) (*UploadSessionInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FinalizePresignedUpload not implemented")
+}
+func (UnimplementedUploadServiceServer) PresignDownload(context.Context, *PresignDownloadRequest) (*PresignedURL, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PresignDownload not implemented")
+}
 func (UnimplementedUploadServiceServer) mustEmbedUnimplementedUploadServiceServer() {}
 func (UnimplementedUploadServiceServer) testEmbeddedByValue()                       {}
 
@@ -206,6 +314,114 @@ func _UploadService_DeleteUpload_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UploadService_CreateUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UploadServiceServer).CreateUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UploadService_CreateUploadSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UploadServiceServer).CreateUploadSession(ctx, req.(*CreateUploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UploadService_GetUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UploadServiceServer).GetUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UploadService_GetUploadSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UploadServiceServer).GetUploadSession(ctx, req.(*GetUploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UploadService_DeleteUploadSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteUploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UploadServiceServer).DeleteUploadSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UploadService_DeleteUploadSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UploadServiceServer).DeleteUploadSession(ctx, req.(*DeleteUploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UploadService_CreatePresignedUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUploadSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UploadServiceServer).CreatePresignedUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UploadService_CreatePresignedUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UploadServiceServer).CreatePresignedUpload(ctx, req.(*CreateUploadSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UploadService_FinalizePresignedUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FinalizePresignedUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UploadServiceServer).FinalizePresignedUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UploadService_FinalizePresignedUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UploadServiceServer).FinalizePresignedUpload(ctx, req.(*FinalizePresignedUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UploadService_PresignDownload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PresignDownloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UploadServiceServer).PresignDownload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UploadService_PresignDownload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UploadServiceServer).PresignDownload(ctx, req.(*PresignDownloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // UploadService_ServiceDesc is the grpc.ServiceDesc for UploadService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -225,6 +441,30 @@ var UploadService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteUpload",
 			Handler:    _UploadService_DeleteUpload_Handler,
 		},
+		{
+			MethodName: "CreateUploadSession",
+			Handler:    _UploadService_CreateUploadSession_Handler,
+		},
+		{
+			MethodName: "GetUploadSession",
+			Handler:    _UploadService_GetUploadSession_Handler,
+		},
+		{
+			MethodName: "DeleteUploadSession",
+			Handler:    _UploadService_DeleteUploadSession_Handler,
+		},
+		{
+			MethodName: "CreatePresignedUpload",
+			Handler:    _UploadService_CreatePresignedUpload_Handler,
+		},
+		{
+			MethodName: "FinalizePresignedUpload",
+			Handler:    _UploadService_FinalizePresignedUpload_Handler,
+		},
+		{
+			MethodName: "PresignDownload",
+			Handler:    _UploadService_PresignDownload_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -0,0 +1,183 @@
+package uploadservice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"postic-backend/internal/entity"
+	"postic-backend/pkg/media"
+	"strings"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	// Максимальный размер видео, которое Telegram Bot API принимает при загрузке
+	maxPlatformVideoSize = 50 * 1024 * 1024
+	// Фото декодируется в памяти целиком, поэтому версии для файлов крупнее не строятся
+	maxDecodedPhotoSize = 64 * 1024 * 1024
+	jpegQuality         = 85
+	thumbnailMaxSide    = 1280
+	transcodeMaxHeight  = 720
+	thumbnailTimeout    = 30 * time.Second
+	transcodeTimeout    = 10 * time.Minute
+)
+
+// preparedRendition производная версия, которую нужно сохранить как отдельный медиафайл
+type preparedRendition struct {
+	kind   string
+	mime   string
+	ext    string
+	data   []byte
+	width  int
+	height int
+}
+
+// prepareRenditions заполняет метаданные upload и строит производные версии, которые можно получить быстро.
+// Второе значение сообщает, что видео нужно перекодировать в фоне
+func prepareRenditions(upload *entity.Upload, content *spooledUpload) ([]preparedRendition, bool) {
+	switch upload.FileType {
+	case "photo":
+		return preparePhotoRenditions(upload, content), false
+	case "video":
+		return prepareVideoRenditions(upload, content)
+	}
+	return nil, false
+}
+
+func preparePhotoRenditions(upload *entity.Upload, content *spooledUpload) []preparedRendition {
+	if content.size > maxDecodedPhotoSize {
+		log.Warnf("изображение %s слишком большое для производных версий: %d байт", upload.FilePath, content.size)
+		return nil
+	}
+	data, err := io.ReadAll(content.reader())
+	if err != nil {
+		log.Errorf("ошибка при чтении изображения %s: %v", upload.FilePath, err)
+		return nil
+	}
+	img, err := media.DecodeImage(data)
+	if err != nil {
+		log.Warnf("не удалось декодировать изображение %s: %v", upload.FilePath, err)
+		return nil
+	}
+	upload.Width, upload.Height = img.Bounds().Dx(), img.Bounds().Dy()
+
+	var renditions []preparedRendition
+	for _, variant := range []struct {
+		kind    string
+		maxSide int
+	}{
+		{entity.RenditionJPEG2560, 2560},
+		{entity.RenditionJPEG1280, 1280},
+	} {
+		// jpeg_2560 создаём всегда: это копия без EXIF, даже если размер не меняется
+		if variant.kind != entity.RenditionJPEG2560 && max(upload.Width, upload.Height) <= variant.maxSide {
+			continue
+		}
+		resized := media.Fit(img, variant.maxSide)
+		encoded, err := media.EncodeJPEG(resized, jpegQuality)
+		if err != nil {
+			log.Errorf("ошибка при кодировании %s для %s: %v", variant.kind, upload.FilePath, err)
+			continue
+		}
+		renditions = append(renditions, preparedRendition{
+			kind:   variant.kind,
+			mime:   "image/jpeg",
+			ext:    "jpg",
+			data:   encoded,
+			width:  resized.Bounds().Dx(),
+			height: resized.Bounds().Dy(),
+		})
+	}
+	return renditions
+}
+
+func prepareVideoRenditions(upload *entity.Upload, content *spooledUpload) ([]preparedRendition, bool) {
+	info, err := media.ProbeMP4At(content.file, content.size)
+	if err != nil {
+		log.Warnf("не удалось прочитать метаданные видео %s: %v", upload.FilePath, err)
+		return nil, false
+	}
+	upload.Width, upload.Height = info.Width, info.Height
+	upload.DurationMs = int(info.Duration.Milliseconds())
+	needTranscode := !info.PlatformFriendlyCodec() || content.size > maxPlatformVideoSize
+
+	// Кадр берём с первой секунды, если видео достаточно длинное - нулевой кадр часто чёрный
+	at := time.Duration(0)
+	if info.Duration > 2*time.Second {
+		at = time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), thumbnailTimeout)
+	defer cancel()
+	thumb, err := media.VideoThumbnail(ctx, content.file.Name(), at, thumbnailMaxSide)
+	if err != nil {
+		if !errors.Is(err, media.ErrFFmpegNotAvailable) {
+			log.Errorf("ошибка при создании превью для %s: %v", upload.FilePath, err)
+		}
+		return nil, needTranscode
+	}
+	rendition := preparedRendition{kind: entity.RenditionThumbnail, mime: "image/jpeg", ext: "jpg", data: thumb}
+	if img, err := media.DecodeImage(thumb); err == nil {
+		rendition.width, rendition.height = img.Bounds().Dx(), img.Bounds().Dy()
+	}
+	return []preparedRendition{rendition}, needTranscode
+}
+
+// storeRendition сохраняет версию как медиафайл и привязывает её к исходному
+func (s *UploadServiceServer) storeRendition(parent *entity.Upload, parentID int, r preparedRendition) error {
+	fileType := "photo"
+	if strings.HasPrefix(r.mime, "video/") {
+		fileType = "video"
+	}
+	renditionID, err := s.uploadRepo.UploadFile(&entity.Upload{
+		RawBytes: bytes.NewReader(r.data),
+		FilePath: renditionPath(parent.FilePath, r.kind, r.ext),
+		FileType: fileType,
+		UserID:   parent.UserID,
+		TeamID:   parent.TeamID,
+		Width:    r.width,
+		Height:   r.height,
+	})
+	if err != nil {
+		return err
+	}
+	return s.uploadRepo.AddRendition(parentID, &entity.UploadRendition{
+		MediaFileID: renditionID,
+		Kind:        r.kind,
+		MimeType:    r.mime,
+		Width:       r.width,
+		Height:      r.height,
+		Size:        int64(len(r.data)),
+	})
+}
+
+// transcodeVideo перекодирует видео в H.264 720p, чтобы его приняли все площадки
+func (s *UploadServiceServer) transcodeVideo(parent *entity.Upload, parentID int, content *spooledUpload) {
+	ctx, cancel := context.WithTimeout(context.Background(), transcodeTimeout)
+	defer cancel()
+	transcoded, err := media.TranscodeVideo(ctx, content.file.Name(), transcodeMaxHeight)
+	if err != nil {
+		if !errors.Is(err, media.ErrFFmpegNotAvailable) {
+			log.Errorf("ошибка при перекодировании видео %s: %v", parent.FilePath, err)
+		}
+		return
+	}
+	rendition := preparedRendition{kind: entity.RenditionMP4720, mime: "video/mp4", ext: "mp4", data: transcoded}
+	if info, err := media.ProbeMP4(transcoded); err == nil {
+		rendition.width, rendition.height = info.Width, info.Height
+	}
+	if err := s.storeRendition(parent, parentID, rendition); err != nil {
+		log.Errorf("ошибка при сохранении перекодированного видео %s: %v", parent.FilePath, err)
+	}
+}
+
+// renditionPath строит имя объекта версии рядом с исходным: <имя>_<вид>.<расширение>
+func renditionPath(filePath, kind, ext string) string {
+	if idx := strings.LastIndex(filePath, "."); idx >= 0 {
+		filePath = filePath[:idx]
+	}
+	return fmt.Sprintf("%s_%s.%s", filePath, kind, ext)
+}
@@ -3,30 +3,42 @@ package uploadservice
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"io"
+	"os"
 	uploadpb "postic-backend/internal/delivery/grpc/upload-service/proto"
 	"postic-backend/internal/entity"
 	"postic-backend/internal/repo"
+
+	"github.com/labstack/gommon/log"
 )
 
 type UploadServiceServer struct {
 	uploadpb.UnimplementedUploadServiceServer
-	uploadRepo repo.Upload
+	uploadRepo        repo.Upload
+	uploadSessionRepo repo.UploadSession
 }
 
-func NewUploadServiceServer(uploadRepo repo.Upload) *UploadServiceServer {
+func NewUploadServiceServer(uploadRepo repo.Upload, uploadSessionRepo repo.UploadSession) *UploadServiceServer {
 	return &UploadServiceServer{
-		uploadRepo: uploadRepo,
+		uploadRepo:        uploadRepo,
+		uploadSessionRepo: uploadSessionRepo,
 	}
 }
 
 func (s *UploadServiceServer) UploadFile(stream uploadpb.UploadService_UploadFileServer) error {
 	var (
-		buf      bytes.Buffer
-		fileType string
-		userID   *int
-		fileName string
+		buf         bytes.Buffer
+		fileType    string
+		userID      *int
+		teamID      *int
+		fileName    string
+		displayName string
+		sessionID   int64
+		offset      int64 = -1
 	)
 	for {
 		chunk, err := stream.Recv()
@@ -43,27 +55,112 @@ func (s *UploadServiceServer) UploadFile(stream uploadpb.UploadService_UploadFil
 			uid := int(chunk.UserId)
 			userID = &uid
 		}
+		if chunk.TeamId != 0 {
+			tid := int(chunk.TeamId)
+			teamID = &tid
+		}
 		if chunk.FileName != "" {
 			fileName = chunk.FileName
 		}
+		if chunk.DisplayName != "" {
+			displayName = chunk.DisplayName
+		}
+		if chunk.Id != 0 {
+			sessionID = chunk.Id
+		}
+		if offset < 0 {
+			offset = chunk.Offset
+		}
 		buf.Write(chunk.Data)
 	}
+	if sessionID != 0 {
+		// Чанки относятся к сессии возобновляемой загрузки: метаданные файла уже хранятся в ней
+		return s.uploadSessionPart(stream, int(sessionID), max(offset, 0), buf.Bytes())
+	}
+	content, err := spoolUpload(&buf)
+	if err != nil {
+		return err
+	}
 	upload := &entity.Upload{
-		RawBytes: bytes.NewReader(buf.Bytes()),
-		FileType: fileType,
-		UserID:   userID,
-		FilePath: fileName,
+		FileType:    fileType,
+		UserID:      userID,
+		TeamID:      teamID,
+		FilePath:    fileName,
+		DisplayName: displayName,
 	}
-	id, err := s.uploadRepo.UploadFile(upload)
+	id, err := s.storeUpload(upload, content)
 	if err != nil {
 		return err
 	}
 	return stream.SendAndClose(&uploadpb.UploadFileResponse{
 		Id:       int64(id),
 		FilePath: fileName,
+		Offset:   content.size,
 	})
 }
 
+// spooledUpload содержимое загрузки во временном файле вместе с размером и SHA-256, посчитанными при копировании
+type spooledUpload struct {
+	file *os.File
+	size int64
+	hash string
+}
+
+// spoolUpload копирует r во временный файл, чтобы большие загрузки не держать в памяти целиком
+func spoolUpload(r io.Reader) (*spooledUpload, error) {
+	file, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании временного файла: %w", err)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), r)
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, fmt.Errorf("ошибка при записи временного файла: %w", err)
+	}
+	return &spooledUpload{file: file, size: size, hash: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// reader возвращает новый независимый поток содержимого с начала
+func (c *spooledUpload) reader() io.ReadSeeker {
+	return io.NewSectionReader(c.file, 0, c.size)
+}
+
+func (c *spooledUpload) remove() {
+	_ = c.file.Close()
+	_ = os.Remove(c.file.Name())
+}
+
+// storeUpload сохраняет файл и его производные версии. Временный файл content удаляется здесь же,
+// а если видео нужно перекодировать — после фонового перекодирования
+func (s *UploadServiceServer) storeUpload(upload *entity.Upload, content *spooledUpload) (int, error) {
+	upload.RawBytes = content.reader()
+	upload.ContentHash = content.hash
+	upload.Size = content.size
+	// Метаданные заполняются в upload до сохранения, поэтому версии готовим заранее
+	renditions, needTranscode := prepareRenditions(upload, content)
+	id, err := s.uploadRepo.UploadFile(upload)
+	if err != nil {
+		content.remove()
+		return 0, err
+	}
+	for _, rendition := range renditions {
+		if err := s.storeRendition(upload, id, rendition); err != nil {
+			log.Errorf("ошибка при сохранении версии %s для %s: %v", rendition.kind, upload.FilePath, err)
+		}
+	}
+	if !needTranscode {
+		content.remove()
+		return id, nil
+	}
+	go func() {
+		defer content.remove()
+		s.transcodeVideo(upload, id, content)
+	}()
+	return id, nil
+}
+
 func (s *UploadServiceServer) DownloadChunk(ctx context.Context, req *uploadpb.DownloadChunkRequest) (*uploadpb.DownloadChunkResponse, error) {
 	upload, err := s.uploadRepo.GetUpload(int(req.Id))
 	if err != nil {
@@ -72,6 +169,10 @@ func (s *UploadServiceServer) DownloadChunk(ctx context.Context, req *uploadpb.D
 	if upload.RawBytes == nil {
 		return nil, io.EOF
 	}
+	if closer, ok := upload.RawBytes.(io.Closer); ok {
+		// каждый запрос открывает объект заново, поэтому закрываем его, иначе для fs копятся открытые файлы
+		defer func() { _ = closer.Close() }()
+	}
 
 	// Получаем размер файла сначала, чтобы не затрагивать seek операции позже
 	size := upload.Size
@@ -130,14 +231,30 @@ func (s *UploadServiceServer) GetUploadInfo(ctx context.Context, req *uploadpb.G
 		return nil, err
 	}
 	resp := &uploadpb.GetUploadInfoResponse{
-		Id:       int64(upload.ID),
-		FilePath: upload.FilePath,
-		FileType: upload.FileType,
-		Size:     upload.Size,
+		Id:         int64(upload.ID),
+		FilePath:   upload.FilePath,
+		FileType:   upload.FileType,
+		Size:       upload.Size,
+		Width:      int32(upload.Width),
+		Height:     int32(upload.Height),
+		DurationMs: int64(upload.DurationMs),
+	}
+	for _, rendition := range upload.Renditions {
+		resp.Renditions = append(resp.Renditions, &uploadpb.Rendition{
+			Id:       int64(rendition.MediaFileID),
+			Kind:     rendition.Kind,
+			MimeType: rendition.MimeType,
+			Width:    int32(rendition.Width),
+			Height:   int32(rendition.Height),
+			Size:     rendition.Size,
+		})
 	}
 	if upload.UserID != nil {
 		resp.UserId = int32(*upload.UserID)
 	}
+	if upload.TeamID != nil {
+		resp.TeamId = int32(*upload.TeamID)
+	}
 	if !upload.CreatedAt.IsZero() {
 		resp.CreatedAt = upload.CreatedAt.Format("2006-01-02T15:04:05Z07:00")
 	}
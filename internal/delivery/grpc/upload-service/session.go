@@ -0,0 +1,175 @@
+package uploadservice
+
+import (
+	"context"
+	"errors"
+	uploadpb "postic-backend/internal/delivery/grpc/upload-service/proto"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"time"
+
+	"github.com/labstack/gommon/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Сессия живёт сутки с момента последней полученной части
+const uploadSessionTTL = 24 * time.Hour
+
+// sessionError переводит ошибки репозитория сессий в gRPC-статусы, чтобы клиент мог их различить
+func sessionError(err error) error {
+	switch {
+	case errors.Is(err, repo.ErrUploadSessionNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, repo.ErrUploadSessionOffsetMismatch):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
+
+func sessionInfo(session *entity.UploadSession) *uploadpb.UploadSessionInfo {
+	info := &uploadpb.UploadSessionInfo{
+		Id:          int64(session.ID),
+		UserId:      int32(session.UserID),
+		TeamId:      int32(session.TeamID),
+		FileType:    session.FileType,
+		DisplayName: session.DisplayName,
+		Size:        session.Size,
+		Offset:      session.Offset,
+		ExpiresAt:   session.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
+		Presigned:   session.Presigned,
+	}
+	if session.MediaFileID != nil {
+		info.MediafileId = int64(*session.MediaFileID)
+	}
+	return info
+}
+
+func (s *UploadServiceServer) CreateUploadSession(ctx context.Context, req *uploadpb.CreateUploadSessionRequest) (*uploadpb.UploadSessionInfo, error) {
+	session := &entity.UploadSession{
+		UserID:      int(req.UserId),
+		TeamID:      int(req.TeamId),
+		FileName:    req.FileName,
+		FileType:    req.FileType,
+		DisplayName: req.DisplayName,
+		Size:        req.Size,
+		ExpiresAt:   time.Now().Add(uploadSessionTTL),
+	}
+	id, err := s.uploadSessionRepo.AddUploadSession(session)
+	if err != nil {
+		return nil, err
+	}
+	session.ID = id
+	return sessionInfo(session), nil
+}
+
+func (s *UploadServiceServer) GetUploadSession(ctx context.Context, req *uploadpb.GetUploadSessionRequest) (*uploadpb.UploadSessionInfo, error) {
+	session, err := s.uploadSessionRepo.GetUploadSession(int(req.Id))
+	if err != nil {
+		return nil, sessionError(err)
+	}
+	return sessionInfo(session), nil
+}
+
+func (s *UploadServiceServer) DeleteUploadSession(ctx context.Context, req *uploadpb.DeleteUploadSessionRequest) (*uploadpb.DeleteUploadSessionResponse, error) {
+	err := s.uploadSessionRepo.DeleteUploadSession(int(req.Id))
+	return &uploadpb.DeleteUploadSessionResponse{Success: err == nil}, err
+}
+
+// uploadSessionPart дописывает полученные чанки в сессию и собирает файл, когда получены все байты
+func (s *UploadServiceServer) uploadSessionPart(stream uploadpb.UploadService_UploadFileServer, sessionID int, offset int64, data []byte) error {
+	session, err := s.uploadSessionRepo.GetUploadSession(sessionID)
+	if err != nil {
+		return sessionError(err)
+	}
+	if session.MediaFileID != nil {
+		// файл уже собран, повторная отправка последней части ничего не меняет
+		return stream.SendAndClose(&uploadpb.UploadFileResponse{
+			Id:       int64(*session.MediaFileID),
+			FilePath: session.FileName,
+			Offset:   session.Offset,
+		})
+	}
+	if session.Presigned {
+		return status.Error(codes.FailedPrecondition, "сессия загружается по временной ссылке")
+	}
+	if offset != session.Offset {
+		return sessionError(repo.ErrUploadSessionOffsetMismatch)
+	}
+	if len(data) > 0 {
+		session.Offset, err = s.uploadSessionRepo.AppendUploadSessionPart(sessionID, offset, data, time.Now().Add(uploadSessionTTL))
+		if err != nil {
+			return sessionError(err)
+		}
+	}
+
+	resp := &uploadpb.UploadFileResponse{
+		FilePath: session.FileName,
+		Offset:   session.Offset,
+	}
+	// Если сборка упала в прошлый раз, пустой чанк с последним offset запускает её снова
+	if session.Offset == session.Size {
+		id, err := s.completeUploadSession(session)
+		if err != nil {
+			return err
+		}
+		resp.Id = int64(id)
+	}
+	return stream.SendAndClose(resp)
+}
+
+// completeUploadSession склеивает части сессии в медиафайл
+func (s *UploadServiceServer) completeUploadSession(session *entity.UploadSession) (int, error) {
+	reader, err := s.uploadSessionRepo.ReadUploadSession(session.ID)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = reader.Close() }()
+	// Части склеиваются во временном файле, а не в памяти: сессиями загружают самые большие файлы
+	content, err := spoolUpload(reader)
+	if err != nil {
+		return 0, err
+	}
+	userID, teamID := session.UserID, session.TeamID
+	id, err := s.storeUpload(&entity.Upload{
+		FilePath:    session.FileName,
+		FileType:    session.FileType,
+		UserID:      &userID,
+		TeamID:      &teamID,
+		DisplayName: session.DisplayName,
+	}, content)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.uploadSessionRepo.CompleteUploadSession(session.ID, id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// CleanupUploadSessions периодически удаляет просроченные сессии вместе с их частями
+func (s *UploadServiceServer) CleanupUploadSessions(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ids, err := s.uploadSessionRepo.GetExpiredUploadSessions(time.Now())
+			if err != nil {
+				log.Errorf("Ошибка при получении просроченных сессий загрузки: %v", err)
+				continue
+			}
+			for _, id := range ids {
+				if err := s.uploadSessionRepo.DeleteUploadSession(id); err != nil {
+					log.Errorf("Ошибка при удалении просроченной сессии загрузки %d: %v", id, err)
+				}
+			}
+			if len(ids) > 0 {
+				log.Infof("Удалено просроченных сессий загрузки: %d", len(ids))
+			}
+		}
+	}
+}
@@ -24,6 +24,7 @@ func NewAnalytics(analyzeUseCase usecase.Analytics, authManager utils.Auth) *Ana
 func (a *Analytics) Configure(server *echo.Group) {
 	server.GET("/stats", a.GetStats)
 	server.GET("/stats/post", a.GetPostUnionStats)
+	server.GET("/sentiment", a.GetSentimentStats)
 	server.GET("/kpi", a.GetUsersKPI)
 }
 
@@ -89,6 +90,41 @@ func (a *Analytics) GetPostUnionStats(c echo.Context) error {
 	return c.JSON(http.StatusOK, stats)
 }
 
+func (a *Analytics) GetSentimentStats(c echo.Context) error {
+	userID, err := a.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return err
+	}
+
+	request := &entity.GetSentimentStatsRequest{}
+	err = utils.ReadQuery(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	stats, err := a.analyzeUseCase.GetSentimentStats(request)
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав для просмотра статистики этой команды",
+		})
+	case errors.Is(err, usecase.ErrPostUnionNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Пост не найден",
+		})
+	case err != nil:
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Ошибка сервера",
+		})
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
+
 func (a *Analytics) GetUsersKPI(c echo.Context) error {
 	userID, err := a.authManager.CheckAuthFromContext(c)
 	if err != nil {
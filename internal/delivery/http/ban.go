@@ -0,0 +1,143 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"postic-backend/internal/delivery/http/utils"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Ban struct {
+	banUseCase  usecase.Ban
+	authManager utils.Auth
+}
+
+func NewBan(banUseCase usecase.Ban, authManager utils.Auth) *Ban {
+	return &Ban{
+		banUseCase:  banUseCase,
+		authManager: authManager,
+	}
+}
+
+func (b *Ban) Configure(server *echo.Group) {
+	server.GET("/list", b.GetBans)
+	server.POST("/add", b.BanCommenter)
+	server.POST("/lift", b.UnbanCommenter)
+}
+
+// banErrorResponse отвечает на общие ошибки банов
+func banErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на управление банами этой команды",
+		})
+	case errors.Is(err, usecase.ErrBanNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Бан не найден",
+		})
+	case errors.Is(err, usecase.ErrBanNotActive):
+		return c.JSON(http.StatusConflict, echo.Map{
+			"error": "Бан уже снят или истёк",
+		})
+	case errors.Is(err, usecase.ErrCommentNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Комментарий не найден",
+		})
+	case errors.Is(err, usecase.ErrCommenterNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Автор комментариев не найден",
+		})
+	}
+	c.Logger().Error(err)
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error": "Ошибка сервера",
+	})
+}
+
+func (b *Ban) GetBans(c echo.Context) error {
+	userID, err := b.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetCommenterBansRequest{}
+	err = utils.ReadQuery(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	bans, err := b.banUseCase.GetBans(request)
+	if err != nil {
+		return banErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"bans": bans,
+	})
+}
+
+func (b *Ban) BanCommenter(c echo.Context) error {
+	userID, err := b.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.BanCommenterRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+	if err := request.IsValid(); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	banID, err := b.banUseCase.BanCommenter(request)
+	if err != nil {
+		return banErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+		"ban_id": banID,
+	})
+}
+
+func (b *Ban) UnbanCommenter(c echo.Context) error {
+	userID, err := b.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.UnbanCommenterRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	err = b.banUseCase.UnbanCommenter(request)
+	if err != nil {
+		return banErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"postic-backend/internal/delivery/http/utils"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CannedReply struct {
+	cannedReplyUseCase usecase.CannedReply
+	authManager        utils.Auth
+}
+
+func NewCannedReply(cannedReplyUseCase usecase.CannedReply, authManager utils.Auth) *CannedReply {
+	return &CannedReply{
+		cannedReplyUseCase: cannedReplyUseCase,
+		authManager:        authManager,
+	}
+}
+
+func (r *CannedReply) Configure(server *echo.Group) {
+	server.GET("/list", r.GetCannedReplies)
+	server.POST("/add", r.AddCannedReply)
+	server.PUT("/edit", r.EditCannedReply)
+	server.DELETE("/delete", r.DeleteCannedReply)
+}
+
+// cannedReplyErrorResponse отвечает на общие ошибки сохранённых ответов
+func cannedReplyErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на работу с ответами этой команды",
+		})
+	case errors.Is(err, usecase.ErrCannedReplyNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Сохранённый ответ не найден",
+		})
+	case errors.Is(err, usecase.ErrMediaFileNotFound):
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Вложение не найдено",
+		})
+	}
+	c.Logger().Error(err)
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error": "Ошибка сервера",
+	})
+}
+
+func (r *CannedReply) GetCannedReplies(c echo.Context) error {
+	userID, err := r.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetCannedRepliesRequest{}
+	err = utils.ReadQuery(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	cannedReplies, err := r.cannedReplyUseCase.GetCannedReplies(request)
+	if err != nil {
+		return cannedReplyErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"canned_replies": cannedReplies,
+	})
+}
+
+// readCannedReplyRequest читает и проверяет ответ из тела запроса
+func (r *CannedReply) readCannedReplyRequest(c echo.Context, userID int) (*entity.CannedReplyRequest, error) {
+	request := &entity.CannedReplyRequest{}
+	if err := utils.ReadJSON(c, request); err != nil {
+		return nil, errors.New("Неверный формат запроса")
+	}
+	request.UserID = userID
+	if err := request.IsValid(); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (r *CannedReply) AddCannedReply(c echo.Context) error {
+	userID, err := r.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request, err := r.readCannedReplyRequest(c, userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	cannedReplyID, err := r.cannedReplyUseCase.AddCannedReply(request)
+	if err != nil {
+		return cannedReplyErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status":          "ok",
+		"canned_reply_id": cannedReplyID,
+	})
+}
+
+func (r *CannedReply) EditCannedReply(c echo.Context) error {
+	userID, err := r.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request, err := r.readCannedReplyRequest(c, userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	err = r.cannedReplyUseCase.EditCannedReply(request)
+	if err != nil {
+		return cannedReplyErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
+
+func (r *CannedReply) DeleteCannedReply(c echo.Context) error {
+	userID, err := r.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.DeleteCannedReplyRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	err = r.cannedReplyUseCase.DeleteCannedReply(request)
+	if err != nil {
+		return cannedReplyErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
@@ -30,13 +30,19 @@ func NewComment(ctx context.Context, commentUseCase usecase.Comment, authManager
 
 func (c *Comment) Configure(server *echo.Group) {
 	server.POST("/reply", c.ReplyToComment)
+	server.PUT("/edit", c.EditComment)
+	server.GET("/edits", c.GetCommentEdits)
+	server.PUT("/reaction", c.ReactComment)
 	server.DELETE("/delete", c.DeleteComment)
 	server.GET("/summarize", c.Summarize)
 	server.GET("/last", c.GetLastComments)
+	server.GET("/feed", c.GetCommentFeed)
+	server.GET("/tree", c.GetCommentTree)
 	server.GET("/get", c.GetComment)
 	server.GET("/subscribe", c.SubscribeToComments)
 	server.GET("/ideas", c.ReplyIdeas)
 	server.POST("/mark", c.MarkAsTicket)
+	server.GET("/export", c.ExportComments)
 }
 
 func (c *Comment) ReplyIdeas(e echo.Context) error {
@@ -98,6 +104,10 @@ func (c *Comment) ReplyToComment(e echo.Context) error {
 		return e.JSON(http.StatusBadRequest, echo.Map{
 			"error": "Невозможно ответить на комментарий. Возможно, он был удален отправителем",
 		})
+	case errors.Is(err, usecase.ErrCannedReplyNotFound):
+		return e.JSON(http.StatusNotFound, echo.Map{
+			"error": "Сохранённый ответ не найден",
+		})
 	case errors.Is(err, usecase.ErrUserForbidden):
 		return e.JSON(http.StatusForbidden, echo.Map{
 			"error": "У вас нет прав на ответ на комментарий",
@@ -152,6 +162,135 @@ func (c *Comment) DeleteComment(e echo.Context) error {
 	})
 }
 
+func (c *Comment) EditComment(e echo.Context) error {
+	userID, err := c.authManager.CheckAuthFromContext(e)
+	if err != nil {
+		return e.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.EditCommentRequest{}
+	err = utils.ReadJSON(e, request)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	err = c.commentUseCase.EditComment(request)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidCommentText):
+		return e.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, usecase.ErrCommentNotEditable):
+		return e.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Изменить можно только ответ от имени команды",
+		})
+	case errors.Is(err, usecase.ErrCommentNotFound):
+		return e.JSON(http.StatusNotFound, echo.Map{
+			"error": "Комментарий не найден",
+		})
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return e.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на изменение комментария",
+		})
+	case err != nil:
+		e.Logger().Error(err)
+		return e.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Ошибка сервера",
+		})
+	}
+	return e.JSON(http.StatusOK, echo.Map{
+		"status":     "ok",
+		"comment_id": request.CommentID,
+	})
+}
+
+func (c *Comment) GetCommentEdits(e echo.Context) error {
+	userID, err := c.authManager.CheckAuthFromContext(e)
+	if err != nil {
+		return e.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetCommentEditsRequest{}
+	err = utils.ReadQuery(e, request)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	edits, err := c.commentUseCase.GetCommentEdits(request)
+	switch {
+	case errors.Is(err, usecase.ErrCommentNotFound):
+		return e.JSON(http.StatusNotFound, echo.Map{
+			"error": "Комментарий не найден",
+		})
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return e.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на получение истории правок",
+		})
+	case err != nil:
+		e.Logger().Error(err)
+		return e.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Ошибка сервера",
+		})
+	}
+	return e.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+		"edits":  edits,
+	})
+}
+
+func (c *Comment) ReactComment(e echo.Context) error {
+	userID, err := c.authManager.CheckAuthFromContext(e)
+	if err != nil {
+		return e.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.ReactCommentRequest{}
+	err = utils.ReadJSON(e, request)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	err = c.commentUseCase.ReactComment(request)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidCommentReaction):
+		return e.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, usecase.ErrCommentNotFound):
+		return e.JSON(http.StatusNotFound, echo.Map{
+			"error": "Комментарий не найден",
+		})
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return e.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на реакции к комментариям",
+		})
+	case err != nil:
+		e.Logger().Error(err)
+		return e.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Ошибка сервера",
+		})
+	}
+	return e.JSON(http.StatusOK, echo.Map{
+		"status":   "ok",
+		"reaction": request.Reaction,
+	})
+}
+
 func (c *Comment) Summarize(e echo.Context) error {
 	userID, err := c.authManager.CheckAuthFromContext(e)
 	if err != nil {
@@ -175,6 +314,10 @@ func (c *Comment) Summarize(e echo.Context) error {
 		return e.JSON(http.StatusForbidden, echo.Map{
 			"error": "У вас нет прав на получение сводки",
 		})
+	case errors.Is(err, usecase.ErrPostUnionNotFound):
+		return e.JSON(http.StatusNotFound, echo.Map{
+			"error": "Пост не найден",
+		})
 	case err != nil:
 		e.Logger().Error(err)
 		return e.JSON(http.StatusInternalServerError, echo.Map{
@@ -222,6 +365,91 @@ func (c *Comment) GetLastComments(e echo.Context) error {
 	})
 }
 
+func (c *Comment) GetCommentFeed(e echo.Context) error {
+	userID, err := c.authManager.CheckAuthFromContext(e)
+	if err != nil {
+		return e.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetCommentFeedRequest{}
+	err = utils.ReadQuery(e, request)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	comments, err := c.commentUseCase.GetCommentFeed(request)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidCommentFeed):
+		return e.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return e.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на получение комментариев",
+		})
+	case err != nil:
+		e.Logger().Error(err)
+		return e.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Ошибка сервера",
+		})
+	}
+	return e.JSON(http.StatusOK, echo.Map{
+		"status":   "ok",
+		"comments": comments,
+	})
+}
+
+func (c *Comment) GetCommentTree(e echo.Context) error {
+	userID, err := c.authManager.CheckAuthFromContext(e)
+	if err != nil {
+		return e.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetCommentTreeRequest{}
+	err = utils.ReadQuery(e, request)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+	if err := request.IsValid(); err != nil {
+		return e.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	tree, err := c.commentUseCase.GetCommentTree(request)
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return e.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на получение комментариев",
+		})
+	case errors.Is(err, usecase.ErrCommentNotFound):
+		return e.JSON(http.StatusNotFound, echo.Map{
+			"error": "Комментарий не найден",
+		})
+	case err != nil:
+		e.Logger().Error(err)
+		return e.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Ошибка сервера",
+		})
+	}
+	return e.JSON(http.StatusOK, echo.Map{
+		"status":      "ok",
+		"parent":      tree.Parent,
+		"comments":    tree.Comments,
+		"next_offset": tree.NextOffset,
+	})
+}
+
 func (c *Comment) GetComment(e echo.Context) error {
 	userID, err := c.authManager.CheckAuthFromContext(e)
 	if err != nil {
@@ -311,6 +539,10 @@ func (c *Comment) SubscribeToComments(e echo.Context) error {
 		})
 	}
 	request.UserID = userID
+	// браузер передаёт id последнего полученного события в заголовке при переподключении
+	if lastEventID := e.Request().Header.Get("Last-Event-ID"); lastEventID != "" {
+		request.LastEventID = lastEventID
+	}
 
 	commentsCh, err := c.commentUseCase.Subscribe(e.Request().Context(), request)
 	switch {
@@ -318,6 +550,8 @@ func (c *Comment) SubscribeToComments(e echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав на получение комментариев")
 	case errors.Is(err, usecase.ErrPostUnionNotFound):
 		return echo.NewHTTPError(http.StatusNotFound, "Пост не найден")
+	case errors.Is(err, usecase.ErrInvalidCommentFeed):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	case err != nil:
 		log.Errorf("Ошибка при подписке на комментарии: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка сервера")
@@ -350,8 +584,9 @@ func (c *Comment) SubscribeToComments(e echo.Context) error {
 				return err
 			}
 
-			// Отправляем ID нового комментария клиенту
+			// Отправляем ID нового комментария клиенту, id события позволяет продолжить поток после обрыва
 			event := sse.Event{
+				ID:    []byte(comment.Cursor),
 				Event: []byte("comment"),
 				Data:  marshaledComment,
 			}
@@ -359,20 +594,11 @@ func (c *Comment) SubscribeToComments(e echo.Context) error {
 				log.Errorf("Ошибка при отправке комментария: %v", err)
 				return err
 			}
-			// Отправляем ping для поддержания соединения
-			ping := sse.Event{
-				Event: []byte("ping"),
-				Data:  []byte(""),
-			}
-			if err := ping.MarshalTo(w); err != nil {
-				log.Errorf("Ошибка при отправке ping: %v", err)
-				return err
-			}
 			w.Flush()
 		case <-pingTicker.C:
+			// комментарий SSE поддерживает соединение и не вызывает обработчиков на клиенте
 			ping := sse.Event{
-				Event: []byte("ping"),
-				Data:  []byte(""),
+				Comment: []byte("ping"),
 			}
 			if err := ping.MarshalTo(w); err != nil {
 				log.Errorf("Ошибка маршалинга пинга: %v", err)
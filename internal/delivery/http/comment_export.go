@@ -0,0 +1,283 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"postic-backend/internal/delivery/http/utils"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/usecase"
+	"postic-backend/pkg/xlsx"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// commentExportFlushRows через сколько строк выгрузка отправляется клиенту
+const commentExportFlushRows = 100
+
+func (c *Comment) ExportComments(e echo.Context) error {
+	userID, err := c.authManager.CheckAuthFromContext(e)
+	if err != nil {
+		return e.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.ExportCommentsRequest{}
+	err = utils.ReadQuery(e, request)
+	if err != nil {
+		return e.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+	if request.Format == "" {
+		request.Format = entity.CommentExportCSV
+	}
+
+	// вложения ведут на выдачу файла через gateway, который перенаправит на временную ссылку хранилища
+	fileURL := fmt.Sprintf("%s://%s/api/upload/get/%%d?redirect=true", e.Scheme(), e.Request().Host)
+
+	// заголовки отправляются с первой строкой, чтобы до неё ошибки доступа вернулись обычным ответом
+	var writer commentExportWriter
+	rows := 0
+	err = c.commentUseCase.ExportComments(request, func(comment *entity.Comment) error {
+		if writer == nil {
+			var err error
+			if writer, err = newCommentExportWriter(e.Response(), request); err != nil {
+				return err
+			}
+		}
+		row := &entity.CommentExportRow{
+			ID:               comment.ID,
+			PostUnionID:      comment.PostUnionID,
+			Platform:         comment.Platform,
+			CreatedAt:        comment.CreatedAt,
+			AuthorPlatformID: comment.UserPlatformID,
+			AuthorName:       comment.FullName,
+			AuthorUsername:   comment.Username,
+			Text:             comment.Text,
+			Attachments:      make([]string, 0, len(comment.Attachments)),
+			ReplyToCommentID: comment.ReplyToCommentID,
+			IsTeamReply:      comment.IsTeamReply,
+			MarkedAsTicket:   comment.MarkedAsTicket,
+			IsDeleted:        comment.IsDeleted,
+		}
+		for _, attachment := range comment.Attachments {
+			row.Attachments = append(row.Attachments, fmt.Sprintf(fileURL, attachment.ID))
+		}
+		if err := writer.WriteRow(row); err != nil {
+			return err
+		}
+		rows++
+		if rows%commentExportFlushRows == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			e.Response().Flush()
+		}
+		return nil
+	})
+	switch {
+	case err != nil && writer != nil:
+		// файл уже передаётся, клиент получит его обрезанным
+		log.Errorf("Ошибка при выгрузке комментариев: %v", err)
+		return nil
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return e.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на получение комментариев",
+		})
+	case errors.Is(err, usecase.ErrInvalidCommentExport):
+		return e.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		e.Logger().Error(err)
+		return e.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Ошибка сервера",
+		})
+	}
+
+	if writer == nil {
+		// под фильтры не подошёл ни один комментарий, отдаём файл с одними заголовками
+		if writer, err = newCommentExportWriter(e.Response(), request); err != nil {
+			log.Errorf("Ошибка при выгрузке комментариев: %v", err)
+			return nil
+		}
+	}
+	if err := writer.Close(); err != nil {
+		log.Errorf("Ошибка при выгрузке комментариев: %v", err)
+	}
+	return nil
+}
+
+// commentExportWriter пишет строки выгрузки комментариев в ответ по мере чтения из базы
+type commentExportWriter interface {
+	WriteRow(row *entity.CommentExportRow) error
+	// Flush отправляет накопленные строки клиенту
+	Flush() error
+	Close() error
+}
+
+var commentExportHeader = []string{
+	"id", "post_union_id", "platform", "created_at", "author_platform_id", "author_name", "author_username",
+	"text", "attachments", "reply_to_comment_id", "is_team_reply", "marked_as_ticket", "is_deleted",
+}
+
+// newCommentExportWriter отправляет заголовки ответа и начинает файл выгрузки
+func newCommentExportWriter(w *echo.Response, request *entity.ExportCommentsRequest) (commentExportWriter, error) {
+	contentType := map[string]string{
+		entity.CommentExportCSV:  "text/csv; charset=utf-8",
+		entity.CommentExportJSON: echo.MIMEApplicationJSONCharsetUTF8,
+		entity.CommentExportXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	}[request.Format]
+	fileName := fmt.Sprintf("comments-%d-%s.%s", request.TeamID, time.Now().Format("2006-01-02"), request.Format)
+	w.Header().Set(echo.HeaderContentType, contentType)
+	w.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	switch request.Format {
+	case entity.CommentExportJSON:
+		if _, err := io.WriteString(w, "["); err != nil {
+			return nil, err
+		}
+		return &jsonCommentExport{w: w, first: true}, nil
+	case entity.CommentExportXLSX:
+		sheet, err := xlsx.NewWriter(w, "Комментарии")
+		if err != nil {
+			return nil, err
+		}
+		header := make([]any, 0, len(commentExportHeader))
+		for _, column := range commentExportHeader {
+			header = append(header, column)
+		}
+		if err := sheet.WriteRow(header...); err != nil {
+			return nil, err
+		}
+		return &xlsxCommentExport{w: sheet}, nil
+	default:
+		// BOM нужен Excel, чтобы открыть CSV с кириллицей в UTF-8
+		if _, err := io.WriteString(w, "\ufeff"); err != nil {
+			return nil, err
+		}
+		records := csv.NewWriter(w)
+		if err := records.Write(commentExportHeader); err != nil {
+			return nil, err
+		}
+		return &csvCommentExport{w: records}, nil
+	}
+}
+
+// escapeFormula экранирует текст, который табличный редактор иначе выполнит как формулу при открытии CSV
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func formatPostUnionID(postUnionID *int) string {
+	if postUnionID == nil {
+		return ""
+	}
+	return strconv.Itoa(*postUnionID)
+}
+
+type csvCommentExport struct {
+	w *csv.Writer
+}
+
+func (e *csvCommentExport) WriteRow(row *entity.CommentExportRow) error {
+	return e.w.Write([]string{
+		strconv.Itoa(row.ID),
+		formatPostUnionID(row.PostUnionID),
+		row.Platform,
+		row.CreatedAt.Format(time.RFC3339),
+		strconv.Itoa(row.AuthorPlatformID),
+		escapeFormula(row.AuthorName),
+		escapeFormula(row.AuthorUsername),
+		escapeFormula(row.Text),
+		strings.Join(row.Attachments, "\n"),
+		strconv.Itoa(row.ReplyToCommentID),
+		strconv.FormatBool(row.IsTeamReply),
+		strconv.FormatBool(row.MarkedAsTicket),
+		strconv.FormatBool(row.IsDeleted),
+	})
+}
+
+func (e *csvCommentExport) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvCommentExport) Close() error {
+	return e.Flush()
+}
+
+type jsonCommentExport struct {
+	w     io.Writer
+	first bool
+}
+
+func (e *jsonCommentExport) WriteRow(row *entity.CommentExportRow) error {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if !e.first {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.first = false
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonCommentExport) Flush() error {
+	return nil
+}
+
+func (e *jsonCommentExport) Close() error {
+	_, err := io.WriteString(e.w, "]")
+	return err
+}
+
+type xlsxCommentExport struct {
+	w *xlsx.Writer
+}
+
+func (e *xlsxCommentExport) WriteRow(row *entity.CommentExportRow) error {
+	return e.w.WriteRow(
+		row.ID,
+		formatPostUnionID(row.PostUnionID),
+		row.Platform,
+		row.CreatedAt.Format(time.RFC3339),
+		row.AuthorPlatformID,
+		// строки пишутся как inlineStr и не вычисляются, поэтому в XLSX текст не экранируется
+		row.AuthorName,
+		row.AuthorUsername,
+		row.Text,
+		strings.Join(row.Attachments, "\n"),
+		row.ReplyToCommentID,
+		row.IsTeamReply,
+		row.MarkedAsTicket,
+		row.IsDeleted,
+	)
+}
+
+func (e *xlsxCommentExport) Flush() error {
+	return nil
+}
+
+func (e *xlsxCommentExport) Close() error {
+	return e.w.Close()
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"postic-backend/internal/delivery/http/utils"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Commenter struct {
+	commenterUseCase usecase.Commenter
+	authManager      utils.Auth
+}
+
+func NewCommenter(commenterUseCase usecase.Commenter, authManager utils.Auth) *Commenter {
+	return &Commenter{
+		commenterUseCase: commenterUseCase,
+		authManager:      authManager,
+	}
+}
+
+func (r *Commenter) Configure(server *echo.Group) {
+	server.GET("/get", r.GetCommenter)
+	server.GET("/comments", r.GetCommenterComments)
+	server.PUT("/tags", r.SetTags)
+	server.POST("/notes/add", r.AddNote)
+}
+
+// commenterErrorResponse отвечает на общие ошибки профилей авторов
+func commenterErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на просмотр авторов комментариев этой команды",
+		})
+	case errors.Is(err, usecase.ErrCommenterNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Автор комментариев не найден",
+		})
+	}
+	c.Logger().Error(err)
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error": "Ошибка сервера",
+	})
+}
+
+func (r *Commenter) GetCommenter(c echo.Context) error {
+	userID, err := r.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetCommenterRequest{}
+	err = utils.ReadQuery(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	commenter, err := r.commenterUseCase.GetCommenter(request)
+	if err != nil {
+		return commenterErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"commenter": commenter,
+	})
+}
+
+func (r *Commenter) GetCommenterComments(c echo.Context) error {
+	userID, err := r.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetCommenterCommentsRequest{}
+	err = utils.ReadQuery(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	comments, err := r.commenterUseCase.GetCommenterComments(request)
+	if err != nil {
+		return commenterErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"comments": comments,
+	})
+}
+
+func (r *Commenter) SetTags(c echo.Context) error {
+	userID, err := r.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.SetCommenterTagsRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+	if err := request.IsValid(); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	err = r.commenterUseCase.SetTags(request)
+	if err != nil {
+		return commenterErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
+
+func (r *Commenter) AddNote(c echo.Context) error {
+	userID, err := r.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.AddCommenterNoteRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+	if err := request.IsValid(); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	noteID, err := r.commenterUseCase.AddNote(request)
+	if err != nil {
+		return commenterErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status":  "ok",
+		"note_id": noteID,
+	})
+}
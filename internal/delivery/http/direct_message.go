@@ -0,0 +1,273 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"postic-backend/internal/delivery/http/utils"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/usecase"
+	"postic-backend/pkg/sse"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+type DirectMessage struct {
+	ctx         context.Context
+	dmUseCase   usecase.DirectMessage
+	authManager utils.Auth
+}
+
+func NewDirectMessage(ctx context.Context, dmUseCase usecase.DirectMessage, authManager utils.Auth) *DirectMessage {
+	return &DirectMessage{
+		ctx:         ctx,
+		dmUseCase:   dmUseCase,
+		authManager: authManager,
+	}
+}
+
+func (d *DirectMessage) Configure(server *echo.Group) {
+	server.GET("/conversations", d.GetConversations)
+	server.GET("/messages", d.GetMessages)
+	server.POST("/send", d.SendMessage)
+	server.POST("/read", d.ReadConversation)
+	server.GET("/tg-link", d.GetTelegramLink)
+	server.GET("/subscribe", d.Subscribe)
+}
+
+// dmErrorResponse отвечает на общие ошибки личных сообщений
+func dmErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на работу с сообщениями этой команды",
+		})
+	case errors.Is(err, usecase.ErrDMConversationNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Переписка не найдена",
+		})
+	case errors.Is(err, usecase.ErrInvalidDirectMessage):
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, usecase.ErrDirectMessageUnavailable):
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Личные сообщения для этой платформы не настроены",
+		})
+	case errors.Is(err, usecase.ErrMediaFileNotFound):
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Вложение не найдено",
+		})
+	}
+	c.Logger().Error(err)
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error": "Ошибка сервера",
+	})
+}
+
+func (d *DirectMessage) GetConversations(c echo.Context) error {
+	userID, err := d.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetDMConversationsRequest{}
+	if err := utils.ReadQuery(c, request); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	conversations, err := d.dmUseCase.GetConversations(request)
+	if err != nil {
+		return dmErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status":        "ok",
+		"conversations": conversations,
+	})
+}
+
+func (d *DirectMessage) GetMessages(c echo.Context) error {
+	userID, err := d.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetDirectMessagesRequest{}
+	if err := utils.ReadQuery(c, request); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	messages, err := d.dmUseCase.GetMessages(request)
+	if err != nil {
+		return dmErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status":   "ok",
+		"messages": messages,
+	})
+}
+
+func (d *DirectMessage) SendMessage(c echo.Context) error {
+	userID, err := d.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.SendDirectMessageRequest{}
+	if err := utils.ReadJSON(c, request); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	messageID, err := d.dmUseCase.SendMessage(request)
+	if err != nil {
+		return dmErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status":     "ok",
+		"message_id": messageID,
+	})
+}
+
+func (d *DirectMessage) ReadConversation(c echo.Context) error {
+	userID, err := d.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.ReadDMConversationRequest{}
+	if err := utils.ReadJSON(c, request); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	if err := d.dmUseCase.ReadConversation(request); err != nil {
+		return dmErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
+
+func (d *DirectMessage) GetTelegramLink(c echo.Context) error {
+	userID, err := d.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetDMLinkRequest{}
+	if err := utils.ReadQuery(c, request); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	link, err := d.dmUseCase.GetTelegramLink(request)
+	if err != nil {
+		return dmErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+		"link":   link,
+	})
+}
+
+func (d *DirectMessage) Subscribe(c echo.Context) error {
+	userID, err := d.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.DMSubscriber{}
+	if err := utils.ReadQuery(c, request); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	eventsCh, err := d.dmUseCase.Subscribe(c.Request().Context(), request)
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав на работу с сообщениями этой команды")
+	case errors.Is(err, usecase.ErrDMConversationNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "Переписка не найдена")
+	case err != nil:
+		log.Errorf("Ошибка при подписке на личные сообщения: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка сервера")
+	}
+
+	// Настраиваем SSE соединение
+	w := c.Response()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	pingTicker := time.NewTicker(20 * time.Second)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return nil
+		case <-c.Request().Context().Done():
+			return nil
+		case dmEvent, ok := <-eventsCh:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(dmEvent)
+			if err != nil {
+				log.Errorf("Ошибка при сериализации события личных сообщений: %v", err)
+				return err
+			}
+			event := sse.Event{
+				Event: []byte("message"),
+				Data:  data,
+			}
+			if err := event.MarshalTo(w); err != nil {
+				log.Errorf("Ошибка при отправке события личных сообщений: %v", err)
+				return err
+			}
+			w.Flush()
+		case <-pingTicker.C:
+			ping := sse.Event{
+				Event: []byte("ping"),
+				Data:  []byte(""),
+			}
+			if err := ping.MarshalTo(w); err != nil {
+				log.Errorf("Ошибка маршалинга пинга: %v", err)
+				return nil
+			}
+			w.Flush()
+		}
+	}
+}
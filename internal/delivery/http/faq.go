@@ -0,0 +1,216 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"postic-backend/internal/delivery/http/utils"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+type FAQ struct {
+	faqUseCase  usecase.FAQ
+	authManager utils.Auth
+}
+
+func NewFAQ(faqUseCase usecase.FAQ, authManager utils.Auth) *FAQ {
+	return &FAQ{
+		faqUseCase:  faqUseCase,
+		authManager: authManager,
+	}
+}
+
+func (f *FAQ) Configure(server *echo.Group) {
+	server.GET("/rules", f.GetRules)
+	server.POST("/rules/add", f.AddRule)
+	server.PUT("/rules/edit", f.EditRule)
+	server.DELETE("/rules/delete", f.DeleteRule)
+	server.GET("/settings", f.GetSettings)
+	server.PUT("/settings", f.SetEnabled)
+}
+
+// faqErrorResponse отвечает на общие ошибки автоответов
+func faqErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на настройку автоответов этой команды",
+		})
+	case errors.Is(err, usecase.ErrFAQRuleNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Правило автоответа не найдено",
+		})
+	}
+	c.Logger().Error(err)
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error": "Ошибка сервера",
+	})
+}
+
+func (f *FAQ) GetRules(c echo.Context) error {
+	userID, err := f.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetFAQRulesRequest{}
+	err = utils.ReadQuery(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	rules, err := f.faqUseCase.GetRules(request)
+	if err != nil {
+		return faqErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"rules": rules,
+	})
+}
+
+// readRuleRequest читает и проверяет правило из тела запроса
+func (f *FAQ) readRuleRequest(c echo.Context, userID int) (*entity.FAQRuleRequest, error) {
+	request := &entity.FAQRuleRequest{}
+	if err := utils.ReadJSON(c, request); err != nil {
+		return nil, errors.New("Неверный формат запроса")
+	}
+	request.UserID = userID
+	if err := request.IsValid(); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (f *FAQ) AddRule(c echo.Context) error {
+	userID, err := f.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request, err := f.readRuleRequest(c, userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ruleID, err := f.faqUseCase.AddRule(request)
+	if err != nil {
+		return faqErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status":  "ok",
+		"rule_id": ruleID,
+	})
+}
+
+func (f *FAQ) EditRule(c echo.Context) error {
+	userID, err := f.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request, err := f.readRuleRequest(c, userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	err = f.faqUseCase.EditRule(request)
+	if err != nil {
+		return faqErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
+
+func (f *FAQ) DeleteRule(c echo.Context) error {
+	userID, err := f.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.DeleteFAQRuleRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	err = f.faqUseCase.DeleteRule(request)
+	if err != nil {
+		return faqErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
+
+func (f *FAQ) GetSettings(c echo.Context) error {
+	userID, err := f.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetFAQSettingsRequest{}
+	err = utils.ReadQuery(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	settings, err := f.faqUseCase.GetSettings(request)
+	if err != nil {
+		return faqErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"settings": settings,
+	})
+}
+
+func (f *FAQ) SetEnabled(c echo.Context) error {
+	userID, err := f.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.SetFAQEnabledRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	err = f.faqUseCase.SetEnabled(request)
+	if err != nil {
+		return faqErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
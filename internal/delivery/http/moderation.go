@@ -0,0 +1,247 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"postic-backend/internal/delivery/http/utils"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Moderation struct {
+	moderationUseCase usecase.Moderation
+	authManager       utils.Auth
+}
+
+func NewModeration(moderationUseCase usecase.Moderation, authManager utils.Auth) *Moderation {
+	return &Moderation{
+		moderationUseCase: moderationUseCase,
+		authManager:       authManager,
+	}
+}
+
+func (m *Moderation) Configure(server *echo.Group) {
+	server.GET("/rules", m.GetRules)
+	server.POST("/rules/add", m.AddRule)
+	server.PUT("/rules/edit", m.EditRule)
+	server.DELETE("/rules/delete", m.DeleteRule)
+	server.GET("/log", m.GetLog)
+	server.GET("/held", m.GetHeldComments)
+	server.POST("/held/approve", m.ApproveHeldComment)
+}
+
+// moderationErrorResponse отвечает на общие ошибки модерации
+func moderationErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на модерацию комментариев этой команды",
+		})
+	case errors.Is(err, usecase.ErrModerationRuleNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Правило не найдено",
+		})
+	case errors.Is(err, usecase.ErrHeldCommentNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Комментарий не найден или уже проверен",
+		})
+	}
+	c.Logger().Error(err)
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error": "Ошибка сервера",
+	})
+}
+
+func (m *Moderation) GetRules(c echo.Context) error {
+	userID, err := m.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetModerationRulesRequest{}
+	err = utils.ReadQuery(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	rules, err := m.moderationUseCase.GetRules(request)
+	if err != nil {
+		return moderationErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"rules": rules,
+	})
+}
+
+// readRuleRequest читает и проверяет правило из тела запроса
+func (m *Moderation) readRuleRequest(c echo.Context, userID int) (*entity.ModerationRuleRequest, error) {
+	request := &entity.ModerationRuleRequest{}
+	if err := utils.ReadJSON(c, request); err != nil {
+		return nil, errors.New("Неверный формат запроса")
+	}
+	request.UserID = userID
+	if err := request.IsValid(); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (m *Moderation) AddRule(c echo.Context) error {
+	userID, err := m.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request, err := m.readRuleRequest(c, userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ruleID, err := m.moderationUseCase.AddRule(request)
+	if err != nil {
+		return moderationErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status":  "ok",
+		"rule_id": ruleID,
+	})
+}
+
+func (m *Moderation) EditRule(c echo.Context) error {
+	userID, err := m.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request, err := m.readRuleRequest(c, userID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	err = m.moderationUseCase.EditRule(request)
+	if err != nil {
+		return moderationErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
+
+func (m *Moderation) DeleteRule(c echo.Context) error {
+	userID, err := m.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.DeleteModerationRuleRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	err = m.moderationUseCase.DeleteRule(request)
+	if err != nil {
+		return moderationErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
+
+func (m *Moderation) GetLog(c echo.Context) error {
+	userID, err := m.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetModerationLogRequest{}
+	err = utils.ReadQuery(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	entries, err := m.moderationUseCase.GetLog(request)
+	if err != nil {
+		return moderationErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"log": entries,
+	})
+}
+
+func (m *Moderation) GetHeldComments(c echo.Context) error {
+	userID, err := m.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetHeldCommentsRequest{}
+	err = utils.ReadQuery(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	comments, err := m.moderationUseCase.GetHeldComments(request)
+	if err != nil {
+		return moderationErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"comments": comments,
+	})
+}
+
+func (m *Moderation) ApproveHeldComment(c echo.Context) error {
+	userID, err := m.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.ApproveHeldCommentRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	err = m.moderationUseCase.ApproveHeldComment(request)
+	if err != nil {
+		return moderationErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
@@ -62,6 +62,10 @@ func (p *Post) AddPost(c echo.Context) error {
 		return c.JSON(http.StatusForbidden, echo.Map{
 			"error": "У вас нет прав на создание постов в этой команде",
 		})
+	case errors.Is(err, usecase.ErrMediaFileNotFound):
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Вложение не найдено",
+		})
 	case err != nil:
 		c.Logger().Errorf("error adding post: %v", err)
 		return c.JSON(http.StatusInternalServerError, echo.Map{
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"postic-backend/internal/delivery/http/utils"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// presignErrorResponse отвечает на ошибки загрузки по временной ссылке
+func presignErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на загрузку файлов в эту команду",
+		})
+	case errors.Is(err, usecase.ErrUploadSessionNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Сессия загрузки не найдена",
+		})
+	case errors.Is(err, usecase.ErrUploadSessionOffsetMismatch):
+		return c.JSON(http.StatusConflict, echo.Map{
+			"error": "Сессия загрузки не может быть завершена",
+		})
+	case errors.Is(err, usecase.ErrUploadTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, usecase.ErrStorageQuotaExceeded):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, usecase.ErrInvalidUpload):
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, usecase.ErrPresignNotSupported):
+		return c.JSON(http.StatusNotImplemented, echo.Map{
+			"error": "Прямая загрузка недоступна, используйте /api/upload/tus",
+		})
+	}
+	c.Logger().Error(err)
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error": "Ошибка сервера",
+	})
+}
+
+func (u *Upload) PresignUpload(c echo.Context) error {
+	userID, err := u.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.PresignUploadRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+	if request.FileType != "photo" && request.FileType != "video" {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный тип файла. Допустимые типы: photo, video",
+		})
+	}
+
+	session, uploadURL, err := u.mediaLibraryUseCase.CreatePresignedUpload(request)
+	if err != nil {
+		return presignErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status":     "ok",
+		"session_id": session.ID,
+		"upload_url": uploadURL,
+	})
+}
+
+func (u *Upload) FinalizeUpload(c echo.Context) error {
+	userID, err := u.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.FinalizeUploadRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	session, err := u.mediaLibraryUseCase.FinalizePresignedUpload(request)
+	if err != nil {
+		return presignErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status":  "ok",
+		"file_id": session.MediaFileID,
+	})
+}
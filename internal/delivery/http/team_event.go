@@ -0,0 +1,118 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"postic-backend/internal/delivery/http/utils"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/usecase"
+	"postic-backend/pkg/sse"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+type TeamEvents struct {
+	ctx               context.Context
+	teamEventsUseCase usecase.TeamEvents
+	authManager       utils.Auth
+}
+
+func NewTeamEvents(ctx context.Context, teamEventsUseCase usecase.TeamEvents, authManager utils.Auth) *TeamEvents {
+	return &TeamEvents{
+		ctx:               ctx,
+		teamEventsUseCase: teamEventsUseCase,
+		authManager:       authManager,
+	}
+}
+
+func (t *TeamEvents) Configure(server *echo.Group) {
+	server.GET("/subscribe", t.Subscribe)
+}
+
+// Subscribe отдаёт общий поток событий команды. Тип события передаётся в поле event, например comment.created.
+// При переподключении с Last-Event-ID приходят события, пропущенные за время обрыва
+func (t *TeamEvents) Subscribe(c echo.Context) error {
+	userID, err := t.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.TeamEventSubscriber{}
+	if err := utils.ReadQuery(c, request); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+	// браузер передаёт id последнего полученного события в заголовке при переподключении
+	if lastEventID := c.Request().Header.Get("Last-Event-ID"); lastEventID != "" {
+		request.LastEventID = lastEventID
+	}
+
+	eventsCh, err := t.teamEventsUseCase.Subscribe(c.Request().Context(), request)
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return echo.NewHTTPError(http.StatusForbidden, "У вас нет прав на получение этих событий команды")
+	case errors.Is(err, usecase.ErrInvalidTeamEventFilter):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case err != nil:
+		log.Errorf("Ошибка при подписке на события команды: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Ошибка сервера")
+	}
+
+	// Настраиваем SSE соединение
+	w := c.Response()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	pingTicker := time.NewTicker(20 * time.Second)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return nil
+		case <-c.Request().Context().Done():
+			return nil
+		case teamEvent, ok := <-eventsCh:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(teamEvent)
+			if err != nil {
+				log.Errorf("Ошибка при сериализации события команды: %v", err)
+				return err
+			}
+			// id события позволяет продолжить поток после обрыва
+			event := sse.Event{
+				ID:    []byte(teamEvent.Cursor),
+				Event: []byte(teamEvent.Type),
+				Data:  data,
+			}
+			if err := event.MarshalTo(w); err != nil {
+				log.Errorf("Ошибка при отправке события команды: %v", err)
+				return err
+			}
+			w.Flush()
+		case <-pingTicker.C:
+			// комментарий SSE поддерживает соединение и не вызывает обработчиков на клиенте
+			ping := sse.Event{
+				Comment: []byte("ping"),
+			}
+			if err := ping.MarshalTo(w); err != nil {
+				log.Errorf("Ошибка маршалинга пинга: %v", err)
+				return nil
+			}
+			w.Flush()
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"postic-backend/internal/delivery/http/utils"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+type Ticket struct {
+	ticketUseCase usecase.Ticket
+	authManager   utils.Auth
+}
+
+func NewTicket(ticketUseCase usecase.Ticket, authManager utils.Auth) *Ticket {
+	return &Ticket{
+		ticketUseCase: ticketUseCase,
+		authManager:   authManager,
+	}
+}
+
+func (t *Ticket) Configure(server *echo.Group) {
+	server.GET("/list", t.GetTickets)
+	server.GET("/get", t.GetTicket)
+	server.POST("/create", t.CreateTicket)
+	server.PUT("/update", t.UpdateTicket)
+	server.POST("/notes/add", t.AddNote)
+	server.POST("/comments/link", t.LinkComment)
+	server.DELETE("/comments/unlink", t.UnlinkComment)
+}
+
+// ticketErrorResponse отвечает на общие ошибки тикетов
+func ticketErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на работу с тикетами этой команды",
+		})
+	case errors.Is(err, usecase.ErrTicketNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Тикет не найден",
+		})
+	case errors.Is(err, usecase.ErrCommentNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Комментарий не найден",
+		})
+	case errors.Is(err, usecase.ErrTicketCommentLinked):
+		return c.JSON(http.StatusConflict, echo.Map{
+			"error": "Комментарий уже привязан к тикету",
+		})
+	case errors.Is(err, usecase.ErrTicketCommentNotLinked):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Комментарий не привязан к этому тикету",
+		})
+	case errors.Is(err, usecase.ErrTicketAssigneeNotInTeam):
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Исполнитель не состоит в команде",
+		})
+	}
+	c.Logger().Error(err)
+	return c.JSON(http.StatusInternalServerError, echo.Map{
+		"error": "Ошибка сервера",
+	})
+}
+
+func (t *Ticket) GetTickets(c echo.Context) error {
+	userID, err := t.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetTicketsRequest{}
+	err = utils.ReadQuery(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	tickets, err := t.ticketUseCase.GetTickets(request)
+	if err != nil {
+		return ticketErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"tickets": tickets,
+	})
+}
+
+func (t *Ticket) GetTicket(c echo.Context) error {
+	userID, err := t.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetTicketRequest{}
+	err = utils.ReadQuery(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	ticket, err := t.ticketUseCase.GetTicket(request)
+	if err != nil {
+		return ticketErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"ticket": ticket,
+	})
+}
+
+func (t *Ticket) CreateTicket(c echo.Context) error {
+	userID, err := t.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.CreateTicketRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+	if err := request.IsValid(); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ticketID, err := t.ticketUseCase.CreateTicket(request)
+	if err != nil {
+		return ticketErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status":    "ok",
+		"ticket_id": ticketID,
+	})
+}
+
+func (t *Ticket) UpdateTicket(c echo.Context) error {
+	userID, err := t.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.UpdateTicketRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+	if err := request.IsValid(); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	err = t.ticketUseCase.UpdateTicket(request)
+	if err != nil {
+		return ticketErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
+
+func (t *Ticket) AddNote(c echo.Context) error {
+	userID, err := t.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.AddTicketNoteRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+	if err := request.IsValid(); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	noteID, err := t.ticketUseCase.AddNote(request)
+	if err != nil {
+		return ticketErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status":  "ok",
+		"note_id": noteID,
+	})
+}
+
+func (t *Ticket) LinkComment(c echo.Context) error {
+	userID, err := t.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.TicketCommentRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	err = t.ticketUseCase.LinkComment(request)
+	if err != nil {
+		return ticketErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
+
+func (t *Ticket) UnlinkComment(c echo.Context) error {
+	userID, err := t.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.TicketCommentRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	err = t.ticketUseCase.UnlinkComment(request)
+	if err != nil {
+		return ticketErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
@@ -0,0 +1,232 @@
+package http
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"path"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/usecase"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Возобновляемая загрузка по протоколу tus 1.0.0 (https://tus.io/protocols/resumable-upload)
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,expiration,termination"
+
+	tusOffsetContentType = "application/offset+octet-stream"
+)
+
+func (u *Upload) configureTus(server *echo.Group) {
+	server.OPTIONS("/tus", u.TusOptions)
+	server.OPTIONS("/tus/:id", u.TusOptions)
+	server.POST("/tus", u.TusCreate)
+	server.HEAD("/tus/:id", u.TusHead)
+	server.PATCH("/tus/:id", u.TusPatch)
+	server.DELETE("/tus/:id", u.TusDelete)
+}
+
+// tusError отвечает ошибкой, сохраняя обязательный заголовок протокола
+func tusError(c echo.Context, code int, message string) error {
+	c.Response().Header().Set("Tus-Resumable", tusVersion)
+	if c.Request().Method == http.MethodHead {
+		return c.NoContent(code)
+	}
+	return c.JSON(code, echo.Map{
+		"error": message,
+	})
+}
+
+// tusPrepare проверяет версию протокола и авторизацию, возвращая id пользователя
+func (u *Upload) tusPrepare(c echo.Context) (int, error) {
+	if c.Request().Header.Get("Tus-Resumable") != tusVersion {
+		c.Response().Header().Set("Tus-Version", tusVersion)
+		return 0, tusError(c, http.StatusPreconditionFailed, "Неподдерживаемая версия протокола tus")
+	}
+	userID, err := u.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return 0, tusError(c, http.StatusUnauthorized, "Пользователь не авторизован")
+	}
+	c.Response().Header().Set("Tus-Resumable", tusVersion)
+	return userID, nil
+}
+
+// tusSessionHeaders выставляет заголовки с состоянием сессии
+func tusSessionHeaders(c echo.Context, session *entity.UploadSession) {
+	header := c.Response().Header()
+	header.Set("Upload-Offset", strconv.FormatInt(session.Offset, 10))
+	header.Set("Upload-Length", strconv.FormatInt(session.Size, 10))
+	header.Set("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+	if session.MediaFileID != nil {
+		// Не входит в протокол: id готового файла для последующего использования в постах
+		header.Set("Upload-File-Id", strconv.Itoa(*session.MediaFileID))
+	}
+}
+
+// tusSessionError переводит ошибки сессии в ответы протокола
+func tusSessionError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrUploadSessionNotFound):
+		return tusError(c, http.StatusNotFound, "Сессия загрузки не найдена")
+	case errors.Is(err, usecase.ErrUploadSessionOffsetMismatch):
+		return tusError(c, http.StatusConflict, "Смещение не совпадает с текущим смещением загрузки")
+	case errors.Is(err, usecase.ErrUploadTooLarge):
+		return tusError(c, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, usecase.ErrStorageQuotaExceeded):
+		return tusError(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return tusError(c, http.StatusForbidden, "У вас нет прав на загрузку файлов в эту команду")
+	case errors.Is(err, usecase.ErrInvalidUpload):
+		return tusError(c, http.StatusBadRequest, err.Error())
+	}
+	c.Logger().Error(err)
+	return tusError(c, http.StatusInternalServerError, "Ошибка сервера")
+}
+
+// parseTusMetadata разбирает заголовок Upload-Metadata вида "key base64value,key2 base64value2"
+func parseTusMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if header == "" {
+		return metadata, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = string(decoded)
+	}
+	return metadata, nil
+}
+
+func (u *Upload) TusOptions(c echo.Context) error {
+	header := c.Response().Header()
+	header.Set("Tus-Resumable", tusVersion)
+	header.Set("Tus-Version", tusVersion)
+	header.Set("Tus-Extension", tusExtensions)
+	header.Set("Tus-Max-Size", strconv.FormatInt(usecase.MaxUploadSessionSize, 10))
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (u *Upload) TusCreate(c echo.Context) error {
+	userID, err := u.tusPrepare(c)
+	if err != nil {
+		return err
+	}
+
+	size, err := strconv.ParseInt(c.Request().Header.Get("Upload-Length"), 10, 64)
+	if err != nil || size <= 0 {
+		return tusError(c, http.StatusBadRequest, "Неверный формат Upload-Length")
+	}
+	if size > usecase.MaxUploadSessionSize {
+		return tusError(c, http.StatusRequestEntityTooLarge, "Файл превышает максимальный размер загрузки")
+	}
+
+	metadata, err := parseTusMetadata(c.Request().Header.Get("Upload-Metadata"))
+	if err != nil {
+		return tusError(c, http.StatusBadRequest, "Неверный формат Upload-Metadata")
+	}
+	teamID, err := strconv.Atoi(metadata["team_id"])
+	if err != nil {
+		return tusError(c, http.StatusBadRequest, "Неверный формат id команды")
+	}
+	if metadata["filename"] == "" {
+		return tusError(c, http.StatusBadRequest, "Не указано имя файла")
+	}
+	// type - наша пометка photo/video, filetype - MIME-тип, который передают стандартные клиенты tus
+	fileType := metadata["type"]
+	if fileType == "" {
+		switch {
+		case strings.HasPrefix(metadata["filetype"], "image/"):
+			fileType = "photo"
+		case strings.HasPrefix(metadata["filetype"], "video/"):
+			fileType = "video"
+		}
+	}
+	if fileType != "photo" && fileType != "video" {
+		return tusError(c, http.StatusBadRequest, "Неверный тип файла. Допустимые типы: photo, video")
+	}
+
+	session, err := u.mediaLibraryUseCase.CreateUploadSession(&entity.UploadSession{
+		UserID:   userID,
+		TeamID:   teamID,
+		FileName: metadata["filename"],
+		FileType: fileType,
+		Size:     size,
+	})
+	if err != nil {
+		return tusSessionError(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderLocation, path.Join(c.Request().URL.Path, strconv.Itoa(session.ID)))
+	c.Response().Header().Set("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+	return c.NoContent(http.StatusCreated)
+}
+
+func (u *Upload) TusHead(c echo.Context) error {
+	userID, err := u.tusPrepare(c)
+	if err != nil {
+		return err
+	}
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return tusError(c, http.StatusNotFound, "Сессия загрузки не найдена")
+	}
+
+	session, err := u.mediaLibraryUseCase.GetUploadSession(userID, sessionID)
+	if err != nil {
+		return tusSessionError(c, err)
+	}
+	tusSessionHeaders(c, session)
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.NoContent(http.StatusOK)
+}
+
+func (u *Upload) TusPatch(c echo.Context) error {
+	userID, err := u.tusPrepare(c)
+	if err != nil {
+		return err
+	}
+	if c.Request().Header.Get(echo.HeaderContentType) != tusOffsetContentType {
+		return tusError(c, http.StatusUnsupportedMediaType, "Content-Type должен быть "+tusOffsetContentType)
+	}
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return tusError(c, http.StatusNotFound, "Сессия загрузки не найдена")
+	}
+	offset, err := strconv.ParseInt(c.Request().Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return tusError(c, http.StatusBadRequest, "Неверный формат Upload-Offset")
+	}
+
+	session, err := u.mediaLibraryUseCase.WriteUploadSession(userID, sessionID, offset, c.Request().Body)
+	if err != nil {
+		return tusSessionError(c, err)
+	}
+	tusSessionHeaders(c, session)
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (u *Upload) TusDelete(c echo.Context) error {
+	userID, err := u.tusPrepare(c)
+	if err != nil {
+		return err
+	}
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return tusError(c, http.StatusNotFound, "Сессия загрузки не найдена")
+	}
+
+	if err := u.mediaLibraryUseCase.DeleteUploadSession(userID, sessionID); err != nil {
+		return tusSessionError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"postic-backend/internal/delivery/http/utils"
@@ -13,20 +14,28 @@ import (
 )
 
 type Upload struct {
-	uploadUseCase usecase.Upload
-	authManager   utils.Auth
+	mediaLibraryUseCase usecase.MediaLibrary
+	authManager         utils.Auth
 }
 
-func NewUpload(uploadUseCase usecase.Upload, authManager utils.Auth) *Upload {
+func NewUpload(mediaLibraryUseCase usecase.MediaLibrary, authManager utils.Auth) *Upload {
 	return &Upload{
-		uploadUseCase: uploadUseCase,
-		authManager:   authManager,
+		mediaLibraryUseCase: mediaLibraryUseCase,
+		authManager:         authManager,
 	}
 }
 
 func (u *Upload) Configure(server *echo.Group) {
 	server.POST("/", u.Upload)
+	server.POST("/url", u.ImportURL)
 	server.GET("/get/:id", u.GetFile)
+	server.GET("/library", u.GetLibrary)
+	server.PUT("/library/edit", u.EditLibraryFile)
+	server.DELETE("/library/delete", u.DeleteLibraryFile)
+	server.GET("/usage", u.GetStorageUsage)
+	server.POST("/presign", u.PresignUpload)
+	server.POST("/presign/finalize", u.FinalizeUpload)
+	u.configureTus(server)
 }
 
 func (u *Upload) Upload(c echo.Context) error {
@@ -37,6 +46,14 @@ func (u *Upload) Upload(c echo.Context) error {
 		})
 	}
 
+	// Команда, в медиатеку которой загружается файл
+	teamID, err := strconv.Atoi(c.FormValue("team_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат id команды",
+		})
+	}
+
 	// Извлекаем файл
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -64,13 +81,27 @@ func (u *Upload) Upload(c echo.Context) error {
 
 	upload := &entity.Upload{
 		UserID:   &userID,
+		TeamID:   &teamID,
 		FilePath: file.Filename,
 		FileType: fileType,
 		RawBytes: fileBytes,
 	}
 
-	fileID, err := u.uploadUseCase.UploadFile(upload)
-	if err != nil {
+	fileID, err := u.mediaLibraryUseCase.UploadFile(upload)
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на загрузку файлов в эту команду",
+		})
+	case errors.Is(err, usecase.ErrStorageQuotaExceeded):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, usecase.ErrUploadTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, echo.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"error": "Ошибка сохранения файла: " + err.Error(),
 		})
@@ -82,8 +113,58 @@ func (u *Upload) Upload(c echo.Context) error {
 	})
 }
 
+func (u *Upload) ImportURL(c echo.Context) error {
+	userID, err := u.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.ImportURLRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil || request.URL == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	fileID, err := u.mediaLibraryUseCase.ImportURL(request)
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на загрузку файлов в эту команду",
+		})
+	case errors.Is(err, usecase.ErrStorageQuotaExceeded):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, usecase.ErrUploadTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, usecase.ErrInvalidImportURL), errors.Is(err, usecase.ErrInvalidUpload):
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, usecase.ErrURLImportFailed):
+		return c.JSON(http.StatusBadGateway, echo.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Ошибка сохранения файла: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"file_id": fileID,
+	})
+}
+
 func (u *Upload) GetFile(c echo.Context) error {
-	_, err := u.authManager.CheckAuthFromContext(c)
+	userID, err := u.authManager.CheckAuthFromContext(c)
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, echo.Map{
 			"error": "Пользователь не авторизован",
@@ -96,8 +177,30 @@ func (u *Upload) GetFile(c echo.Context) error {
 		})
 	}
 
-	file, err := u.uploadUseCase.GetUpload(fileID)
-	if err != nil {
+	// ?redirect=true отдаёт файл напрямую из хранилища, если оно умеет выдавать временные ссылки
+	if c.QueryParam("redirect") == "true" {
+		fileURL, err := u.mediaLibraryUseCase.GetFileURL(userID, fileID)
+		switch {
+		case errors.Is(err, usecase.ErrMediaFileNotFound):
+			return c.JSON(http.StatusNotFound, echo.Map{
+				"error": "Файл не найден",
+			})
+		case err == nil:
+			// ссылка временная, поэтому сам редирект кэшировать нельзя
+			c.Response().Header().Set("Cache-Control", "no-store")
+			return c.Redirect(http.StatusFound, fileURL.URL)
+		case !errors.Is(err, usecase.ErrPresignNotSupported):
+			c.Logger().Error(err)
+		}
+	}
+
+	file, err := u.mediaLibraryUseCase.GetFile(userID, fileID)
+	switch {
+	case errors.Is(err, usecase.ErrMediaFileNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Файл не найден",
+		})
+	case err != nil:
 		return c.JSON(http.StatusInternalServerError, echo.Map{
 			"error": "Ошибка получения файла: " + err.Error(),
 		})
@@ -109,7 +212,8 @@ func (u *Upload) GetFile(c echo.Context) error {
 	// ETag — по id и времени создания
 	etag := fmt.Sprintf("\"%d-%d\"", file.ID, file.CreatedAt.Unix())
 	c.Response().Header().Set("ETag", etag)
-	c.Response().Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	// Файлы доступны только участникам команды, поэтому кэшировать их могут лишь браузеры
+	c.Response().Header().Set("Cache-Control", "private, max-age=31536000, immutable")
 	c.Response().Header().Set("Last-Modified", file.CreatedAt.UTC().Format(http.TimeFormat))
 
 	if match := c.Request().Header.Get("If-None-Match"); match == etag {
@@ -128,3 +232,159 @@ func (u *Upload) GetFile(c echo.Context) error {
 	http.ServeContent(c.Response(), c.Request(), file.FilePath, file.CreatedAt, file.RawBytes)
 	return nil
 }
+
+func (u *Upload) GetLibrary(c echo.Context) error {
+	userID, err := u.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetMediaLibraryRequest{}
+	err = utils.ReadQuery(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	files, err := u.mediaLibraryUseCase.GetMediaFiles(request)
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на просмотр медиатеки этой команды",
+		})
+	case err != nil:
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Ошибка сервера",
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+		"files":  files,
+	})
+}
+
+func (u *Upload) EditLibraryFile(c echo.Context) error {
+	userID, err := u.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.EditMediaFileRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+	if err := request.IsValid(); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	file, err := u.mediaLibraryUseCase.EditMediaFile(request)
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на изменение медиатеки этой команды",
+		})
+	case errors.Is(err, usecase.ErrMediaFileNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Файл не найден",
+		})
+	case err != nil:
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Ошибка сервера",
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+		"file":   file,
+	})
+}
+
+func (u *Upload) DeleteLibraryFile(c echo.Context) error {
+	userID, err := u.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.DeleteMediaFileRequest{}
+	err = utils.ReadJSON(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	err = u.mediaLibraryUseCase.DeleteMediaFile(request)
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на удаление файлов из медиатеки этой команды",
+		})
+	case errors.Is(err, usecase.ErrMediaFileNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{
+			"error": "Файл не найден",
+		})
+	case errors.Is(err, usecase.ErrMediaFileInUse):
+		return c.JSON(http.StatusConflict, echo.Map{
+			"error": "Файл используется в постах и не может быть удалён",
+		})
+	case err != nil:
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Ошибка сервера",
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+	})
+}
+
+func (u *Upload) GetStorageUsage(c echo.Context) error {
+	userID, err := u.authManager.CheckAuthFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, echo.Map{
+			"error": "Пользователь не авторизован",
+		})
+	}
+
+	request := &entity.GetStorageUsageRequest{}
+	err = utils.ReadQuery(c, request)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{
+			"error": "Неверный формат запроса",
+		})
+	}
+	request.UserID = userID
+
+	usage, err := u.mediaLibraryUseCase.GetStorageUsage(request)
+	switch {
+	case errors.Is(err, usecase.ErrUserForbidden):
+		return c.JSON(http.StatusForbidden, echo.Map{
+			"error": "У вас нет прав на просмотр медиатеки этой команды",
+		})
+	case err != nil:
+		c.Logger().Error(err)
+		return c.JSON(http.StatusInternalServerError, echo.Map{
+			"error": "Ошибка сервера",
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"status": "ok",
+		"usage":  usage,
+	})
+}
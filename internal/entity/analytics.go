@@ -21,6 +21,29 @@ type GetPostUnionStatsRequest struct {
 	PostUnionID int `query:"post_union_id"`
 }
 
+// GetSentimentStatsRequest запрашивает динамику тональности комментариев к посту.
+// Если период не указан, берутся последние 30 дней
+type GetSentimentStatsRequest struct {
+	UserID      int       `query:"-"`
+	TeamID      int       `query:"team_id"`
+	PostUnionID int       `query:"post_union_id"`
+	Start       time.Time `query:"start"`
+	End         time.Time `query:"end"`
+}
+
+// SentimentPoint количество классифицированных комментариев каждой тональности за день
+type SentimentPoint struct {
+	Date     time.Time `json:"date" db:"date"`
+	Positive int       `json:"positive" db:"positive"`
+	Neutral  int       `json:"neutral" db:"neutral"`
+	Negative int       `json:"negative" db:"negative"`
+}
+
+type SentimentStatsResponse struct {
+	PostUnionID int               `json:"post_union_id"`
+	Points      []*SentimentPoint `json:"points"`
+}
+
 type GetUsersKPIRequest struct {
 	UserID int       `query:"-"`
 	TeamID int       `query:"team_id"`
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// CommenterBanMaxDuration самый долгий срочный бан. Telegram считает более долгие баны бессрочными
+const CommenterBanMaxDuration = 366 * 24 * time.Hour
+
+// CommenterBan бан автора комментариев в обсуждениях команды
+type CommenterBan struct {
+	ID             int        `json:"id" db:"id"`
+	TeamID         int        `json:"team_id" db:"team_id"`
+	Platform       string     `json:"platform" db:"platform"`
+	UserPlatformID int        `json:"user_platform_id" db:"user_platform_id"`
+	FullName       string     `json:"full_name" db:"full_name"`
+	Username       string     `json:"username" db:"username"`
+	Reason         string     `json:"reason" db:"reason"`
+	CommentID      *int       `json:"comment_id" db:"comment_id"`
+	BannedBy       *int       `json:"banned_by" db:"banned_by"` // nil, если забанило правило модерации
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt      *time.Time `json:"expires_at" db:"expires_at"` // nil, если бан бессрочный
+	LiftedAt       *time.Time `json:"lifted_at" db:"lifted_at"`
+	LiftedBy       *int       `json:"lifted_by" db:"lifted_by"`
+}
+
+// IsActive сообщает, действует ли бан: его не сняли и срок не истёк
+func (b *CommenterBan) IsActive(now time.Time) bool {
+	return b.LiftedAt == nil && (b.ExpiresAt == nil || b.ExpiresAt.After(now))
+}
+
+type GetCommenterBansRequest struct {
+	UserID     int       `query:"-"`
+	TeamID     int       `query:"team_id"`
+	Platform   string    `query:"platform"`
+	ActiveOnly bool      `query:"active_only"`
+	Offset     time.Time `query:"offset"`    // баны, выданные раньше offset
+	OffsetID   int       `query:"offset_id"` // ID последнего полученного бана, чтобы не терять баны со временем offset
+	Limit      int       `query:"limit"`
+}
+
+// BanCommenterRequest банит автора комментария CommentID или автора (Platform, UserPlatformID)
+type BanCommenterRequest struct {
+	UserID          int    `json:"-"`
+	TeamID          int    `json:"team_id"`
+	CommentID       int    `json:"comment_id"`
+	Platform        string `json:"platform"`
+	UserPlatformID  int    `json:"user_platform_id"`
+	Reason          string `json:"reason"`
+	DurationSeconds int    `json:"duration_seconds"` // 0 — бессрочно
+}
+
+func (r *BanCommenterRequest) IsValid() error {
+	if r.CommentID == 0 && (r.Platform == "" || r.UserPlatformID == 0) {
+		return errors.New("comment_id or platform with user_platform_id is required")
+	}
+	if utf8.RuneCountInString(r.Reason) > 1024 {
+		return errors.New("reason must be at most 1024 characters")
+	}
+	duration := time.Duration(r.DurationSeconds) * time.Second
+	if r.DurationSeconds != 0 && (duration < time.Minute || duration > CommenterBanMaxDuration) {
+		return errors.New("duration must be 0 or from 1 minute to 366 days")
+	}
+	return nil
+}
+
+type UnbanCommenterRequest struct {
+	UserID int `json:"-"`
+	TeamID int `json:"team_id"`
+	BanID  int `json:"ban_id"`
+}
@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// replyTemplateVariable переменная шаблона ответа вида {{name}}
+var replyTemplateVariable = regexp.MustCompile(`{{\s*([a-z_]+)\s*}}`)
+
+// CannedReply сохранённый ответ команды на комментарий
+type CannedReply struct {
+	ID          int        `json:"id" db:"id"`
+	TeamID      int        `json:"team_id" db:"team_id"`
+	Title       string     `json:"title" db:"title"`
+	Text        string     `json:"text" db:"text"` // шаблон с переменными {{name}} и {{username}}
+	Attachments []int      `json:"attachments" db:"-"`
+	UsageCount  int        `json:"usage_count" db:"usage_count"`
+	LastUsedAt  *time.Time `json:"last_used_at" db:"last_used_at"`
+	CreatedBy   *int       `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// replyTemplateValue возвращает значение переменной шаблона для комментария
+func replyTemplateValue(name string, comment *Comment) (string, bool) {
+	switch name {
+	case "name":
+		return comment.FullName, true
+	case "username":
+		return comment.Username, true
+	}
+	return "", false
+}
+
+func (r *CannedReply) IsValid() error {
+	if r.Title == "" || utf8.RuneCountInString(r.Title) > 256 {
+		return errors.New("title must be from 1 to 256 characters")
+	}
+	if strings.TrimSpace(r.Text) == "" && len(r.Attachments) == 0 {
+		return errors.New("text and attachments are empty")
+	}
+	if utf8.RuneCountInString(r.Text) > 4096 {
+		return errors.New("text must be at most 4096 characters")
+	}
+	if len(r.Attachments) > 10 {
+		return errors.New("at most 10 attachments are allowed")
+	}
+	return ValidateReplyTemplate(r.Text)
+}
+
+// Render подставляет в шаблон данные автора комментария
+func (r *CannedReply) Render(comment *Comment) string {
+	return RenderReplyTemplate(r.Text, comment)
+}
+
+// ValidateReplyTemplate проверяет, что в шаблоне ответа только известные переменные
+func ValidateReplyTemplate(template string) error {
+	for _, match := range replyTemplateVariable.FindAllStringSubmatch(template, -1) {
+		if _, ok := replyTemplateValue(match[1], &Comment{}); !ok {
+			return errors.New("unknown variable " + match[0] + ", use {{name}} or {{username}}")
+		}
+	}
+	return nil
+}
+
+// RenderReplyTemplate подставляет в шаблон ответа данные автора комментария
+func RenderReplyTemplate(template string, comment *Comment) string {
+	return replyTemplateVariable.ReplaceAllStringFunc(template, func(variable string) string {
+		name := replyTemplateVariable.FindStringSubmatch(variable)[1]
+		if value, ok := replyTemplateValue(name, comment); ok {
+			return value
+		}
+		return variable
+	})
+}
+
+type GetCannedRepliesRequest struct {
+	UserID int `query:"-"`
+	TeamID int `query:"team_id"`
+}
+
+type CannedReplyRequest struct {
+	UserID int `json:"-"`
+	CannedReply
+}
+
+type DeleteCannedReplyRequest struct {
+	UserID        int `json:"-"`
+	TeamID        int `json:"team_id"`
+	CannedReplyID int `json:"canned_reply_id"`
+}
@@ -2,14 +2,26 @@ package entity
 
 import (
 	"errors"
+	"slices"
 	"time"
 	"unicode/utf8"
 )
 
 type JustTextComment struct {
+	ID   int    `json:"id" db:"id"`
 	Text string `json:"text" db:"text"`
 }
 
+// PlatformComment сохранённое состояние комментария для сверки с платформой
+type PlatformComment struct {
+	ID                int    `db:"id"`
+	CommentPlatformID int    `db:"comment_platform_id"`
+	Text              string `db:"text"`
+	IsTeamReply       bool   `db:"is_team_reply"`
+	IsDeleted         bool   `db:"is_deleted"`
+	HeldForReview     bool   `db:"held_for_review"`
+}
+
 type Comment struct {
 	ID                int       `json:"id" db:"id"`
 	TeamID            int       `json:"team_id" db:"team_id"`
@@ -28,6 +40,46 @@ type Comment struct {
 	Attachments       []*Upload `json:"attachments"`
 	MarkedAsTicket    bool      `json:"marked_as_ticket" db:"marked_as_ticket"`
 	IsDeleted         bool      `json:"is_deleted" db:"is_deleted"`
+	HeldForReview     bool      `json:"held_for_review" db:"held_for_review"` // отложен автомодерацией до проверки
+	Sentiment         string    `json:"sentiment,omitempty" db:"sentiment"`   // пусто, пока комментарий не классифицирован
+	Topic             string    `json:"topic,omitempty" db:"topic"`
+	ReplySource       string    `json:"reply_source,omitempty" db:"reply_source"`   // manual или faq у ответов команды
+	FAQRuleID         *int      `json:"faq_rule_id,omitempty" db:"faq_rule_id"`     // правило, по которому отправлен автоответ
+	TeamReaction      string    `json:"team_reaction,omitempty" db:"team_reaction"` // реакция команды, пусто — реакции нет
+}
+
+const (
+	SentimentPositive = "positive"
+	SentimentNeutral  = "neutral"
+	SentimentNegative = "negative"
+)
+
+const (
+	TopicQuestion  = "question"
+	TopicComplaint = "complaint"
+	TopicPraise    = "praise"
+	TopicSpam      = "spam"
+	TopicOther     = "other"
+)
+
+// CommentClassification результат классификации комментария
+type CommentClassification struct {
+	Sentiment string `json:"sentiment"`
+	Topic     string `json:"topic"`
+}
+
+func (c *CommentClassification) IsValid() error {
+	switch c.Sentiment {
+	case SentimentPositive, SentimentNeutral, SentimentNegative:
+	default:
+		return errors.New("unknown sentiment")
+	}
+	switch c.Topic {
+	case TopicQuestion, TopicComplaint, TopicPraise, TopicSpam, TopicOther:
+	default:
+		return errors.New("unknown topic")
+	}
+	return nil
 }
 
 type GetCommentsRequest struct {
@@ -38,6 +90,10 @@ type GetCommentsRequest struct {
 	Before         bool      `query:"before"`
 	Limit          int       `query:"limit"`
 	MarkedAsTicket *bool     `query:"marked_as_ticket"`
+	Sentiment      string    `query:"sentiment"` // если задан sentiment, topic или автор, комментарии возвращаются списком без веток
+	Topic          string    `query:"topic"`
+	Platform       string    `query:"platform"` // вместе с user_platform_id оставляет комментарии одного автора
+	UserPlatformID int       `query:"user_platform_id"`
 }
 
 type DeleteCommentRequest struct {
@@ -48,11 +104,22 @@ type DeleteCommentRequest struct {
 }
 
 type ReplyCommentRequest struct {
-	UserID      int    `json:"-"`
-	TeamID      int    `json:"team_id"`
-	CommentID   int    `json:"comment_id"`
-	Text        string `json:"text"`
-	Attachments []int  `json:"attachments"`
+	UserID        int    `json:"-"`
+	TeamID        int    `json:"team_id"`
+	CommentID     int    `json:"comment_id"`
+	Text          string `json:"text"`
+	Attachments   []int  `json:"attachments"`
+	CannedReplyID int    `json:"canned_reply_id"` // шаблон ответа заменяет Text, его вложения добавляются к Attachments
+	ReplySource   string `json:"-"`               // по умолчанию manual
+	FAQRuleID     *int   `json:"-"`
+}
+
+// Source возвращает источник ответа для сохранения в комментарии
+func (r *ReplyCommentRequest) Source() string {
+	if r.ReplySource == "" {
+		return ReplySourceManual
+	}
+	return r.ReplySource
 }
 
 func (r *ReplyCommentRequest) IsValid(platform string) error {
@@ -77,9 +144,91 @@ func (r *ReplyCommentRequest) IsValid(platform string) error {
 }
 
 type SummarizeCommentRequest struct {
-	UserID      int `query:"-"`
-	TeamID      int `query:"team_id"`
-	PostUnionID int `query:"post_union_id"`
+	UserID      int  `query:"-"`
+	TeamID      int  `query:"team_id"`
+	PostUnionID int  `query:"post_union_id"`
+	Refresh     bool `query:"refresh"` // обновить сводку, не дожидаясь фонового обновления
+}
+
+// EditCommentRequest изменяет текст ответа команды
+type EditCommentRequest struct {
+	UserID    int    `json:"-"`
+	TeamID    int    `json:"team_id"`
+	CommentID int    `json:"comment_id"`
+	Text      string `json:"text"`
+}
+
+// IsValid проверяет новый текст по ограничениям платформы комментария.
+// У ответа с вложениями текст — подпись, её можно убрать
+func (r *EditCommentRequest) IsValid(comment *Comment) error {
+	hasAttachments := len(comment.Attachments) > 0
+	if r.Text == "" && !hasAttachments {
+		return errors.New("text is empty")
+	}
+
+	switch comment.Platform {
+	case "tg":
+		if !hasAttachments && utf8.RuneCountInString(r.Text) > 4096 {
+			return errors.New("text is too long for telegram")
+		}
+		if hasAttachments && utf8.RuneCountInString(r.Text) > 1024 {
+			return errors.New("text is too long for telegram with attachments")
+		}
+	case "vk":
+		if utf8.RuneCountInString(r.Text) > 4096 {
+			return errors.New("text is too long for vkontakte")
+		}
+	}
+	return nil
+}
+
+// CommentReactionLike лайк ВКонтакте: likes.add не различает реакции
+const CommentReactionLike = "❤"
+
+// telegramReactions реакции, которые бот может поставить в Telegram
+var telegramReactions = []string{
+	"👍", "👎", "❤", "🔥", "🥰", "👏", "😁", "🤔", "😢", "🎉", "🙏", "👌", "😍", "💯", "🤝", "⚡",
+}
+
+// ReactCommentRequest ставит реакцию команды на комментарий. Пустая реакция снимает текущую
+type ReactCommentRequest struct {
+	UserID    int    `json:"-"`
+	TeamID    int    `json:"team_id"`
+	CommentID int    `json:"comment_id"`
+	Reaction  string `json:"reaction"`
+}
+
+// IsValid проверяет, что платформа комментария поддерживает реакцию
+func (r *ReactCommentRequest) IsValid(comment *Comment) error {
+	if r.Reaction == "" {
+		return nil
+	}
+	switch comment.Platform {
+	case "tg":
+		if !slices.Contains(telegramReactions, r.Reaction) {
+			return errors.New("reaction is not supported by telegram")
+		}
+	case "vk":
+		if r.Reaction != CommentReactionLike {
+			return errors.New("only like reaction is supported by vkontakte")
+		}
+	}
+	return nil
+}
+
+// CommentEdit предыдущая версия текста комментария
+type CommentEdit struct {
+	ID           int       `json:"id" db:"id"`
+	CommentID    int       `json:"comment_id" db:"comment_id"`
+	PreviousText string    `json:"previous_text" db:"previous_text"`
+	EditedBy     *int      `json:"edited_by" db:"edited_by"`
+	EditedAt     time.Time `json:"edited_at" db:"edited_at"`
+}
+
+type GetCommentEditsRequest struct {
+	UserID    int `query:"-"`
+	TeamID    int `query:"team_id"`
+	CommentID int `query:"comment_id"`
 }
 
 type GetCommentRequest struct {
@@ -88,10 +237,87 @@ type GetCommentRequest struct {
 	CommentID int `query:"comment_id"`
 }
 
+// GetCommentTreeRequest запрашивает дерево обсуждения. Постранично выдаётся один уровень: корневые комментарии поста
+// или ответы на ParentID, а под каждым комментарием — до ChildLimit первых ответов на глубину Depth
+type GetCommentTreeRequest struct {
+	UserID      int       `query:"-"`
+	TeamID      int       `query:"team_id"`
+	PostUnionID int       `query:"post_union_id"`
+	ParentID    int       `query:"parent_id"` // если задан, возвращается ветка ответов на этот комментарий
+	Offset      time.Time `query:"offset"`
+	OffsetID    int       `query:"offset_id"` // ID последнего полученного комментария, чтобы не терять комментарии со временем offset
+	Before      bool      `query:"before"`
+	Limit       int       `query:"limit"`
+	Depth       *int      `query:"depth"` // 0 — только уровень страницы с числом ответов
+	ChildLimit  int       `query:"child_limit"`
+}
+
+func (r *GetCommentTreeRequest) IsValid() error {
+	if r.Limit < 0 || r.ChildLimit < 0 {
+		return errors.New("limit must not be negative")
+	}
+	if r.Depth != nil && *r.Depth < 0 {
+		return errors.New("depth must not be negative")
+	}
+	return nil
+}
+
+// CommentNode комментарий в дереве обсуждения
+type CommentNode struct {
+	*Comment
+	Depth      int            `json:"depth"`       // 0 у комментариев уровня страницы
+	ChildCount int            `json:"child_count"` // число всех прямых ответов, включая не попавшие в дерево
+	Replies    []*CommentNode `json:"replies"`
+}
+
+type CommentTree struct {
+	Parent     *Comment       `json:"parent,omitempty"` // комментарий, ветка ответов на который запрошена
+	Comments   []*CommentNode `json:"comments"`
+	NextOffset *time.Time     `json:"next_offset"` // offset следующей страницы или null, если страница последняя
+}
+
+// Subscriber подписка на события комментариев. PostUnionID 0 — все обсуждения команды
 type Subscriber struct {
-	UserID      int `json:"-"`
-	TeamID      int `json:"team_id" query:"team_id"`
-	PostUnionID int `json:"post_union_id" query:"post_union_id"`
+	UserID      int    `json:"-"`
+	TeamID      int    `json:"team_id" query:"team_id"`
+	PostUnionID int    `json:"post_union_id" query:"post_union_id"`
+	Discussion  string `json:"discussion" query:"discussion"` // при PostUnionID 0: post, free или пусто — все
+	LastEventID string `json:"-" query:"last_event_id"`       // id последнего полученного события для продолжения потока
+}
+
+const (
+	// CommentDiscussionPost комментарии в ветках постов
+	CommentDiscussionPost = "post"
+	// CommentDiscussionFree сообщения группы обсуждений вне веток постов, у них нет post_union_id
+	CommentDiscussionFree = "free"
+)
+
+// GetCommentFeedRequest общая лента обсуждений команды списком от новых к старым
+type GetCommentFeedRequest struct {
+	UserID     int       `query:"-"`
+	TeamID     int       `query:"team_id"`
+	Discussion string    `query:"discussion"` // post, free или пусто — все
+	Platform   string    `query:"platform"`
+	From       time.Time `query:"from"`
+	To         time.Time `query:"to"`
+	Offset     time.Time `query:"offset"`    // комментарии раньше offset
+	OffsetID   int       `query:"offset_id"` // ID последнего полученного комментария, чтобы не терять комментарии со временем offset
+	Limit      int       `query:"limit"`
+}
+
+func (r *GetCommentFeedRequest) IsValid() error {
+	switch r.Discussion {
+	case "", CommentDiscussionPost, CommentDiscussionFree:
+	default:
+		return errors.New("discussion must be post or free")
+	}
+	if r.Limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+	if !r.From.IsZero() && !r.To.IsZero() && !r.From.Before(r.To) {
+		return errors.New("from must be before to")
+	}
+	return nil
 }
 
 type ReplyIdeasRequest struct {
@@ -110,3 +336,70 @@ type MarkAsTicketRequest struct {
 	PostCommentID  int  `json:"comment_id"`
 	MarkedAsTicket bool `json:"marked_as_ticket"`
 }
+
+const (
+	CommentExportCSV  = "csv"
+	CommentExportJSON = "json"
+	CommentExportXLSX = "xlsx"
+)
+
+// ExportCommentsRequest выгрузка комментариев поста или всей команды за период.
+// Период отбирает ветки по дате корневого комментария, ответы выгружаются вместе с веткой
+type ExportCommentsRequest struct {
+	UserID         int       `query:"-"`
+	TeamID         int       `query:"team_id"`
+	PostUnionID    int       `query:"post_union_id"` // 0 — все посты команды
+	From           time.Time `query:"from"`
+	To             time.Time `query:"to"` // пусто — до текущего момента
+	Format         string    `query:"format"`
+	Platform       string    `query:"platform"`
+	MarkedAsTicket *bool     `query:"marked_as_ticket"`
+	IsDeleted      *bool     `query:"is_deleted"`
+	IsTeamReply    *bool     `query:"is_team_reply"`
+}
+
+func (r *ExportCommentsRequest) IsValid() error {
+	switch r.Format {
+	case CommentExportCSV, CommentExportJSON, CommentExportXLSX:
+	default:
+		return errors.New("format must be csv, json or xlsx")
+	}
+	if !r.To.IsZero() && !r.From.Before(r.To) {
+		return errors.New("from must be before to")
+	}
+	return nil
+}
+
+// Matches проверяет комментарий по фильтрам выгрузки, кроме периода
+func (r *ExportCommentsRequest) Matches(comment *Comment) bool {
+	if r.Platform != "" && comment.Platform != r.Platform {
+		return false
+	}
+	if r.MarkedAsTicket != nil && comment.MarkedAsTicket != *r.MarkedAsTicket {
+		return false
+	}
+	if r.IsDeleted != nil && comment.IsDeleted != *r.IsDeleted {
+		return false
+	}
+	if r.IsTeamReply != nil && comment.IsTeamReply != *r.IsTeamReply {
+		return false
+	}
+	return true
+}
+
+// CommentExportRow строка выгрузки комментариев
+type CommentExportRow struct {
+	ID               int       `json:"id"`
+	PostUnionID      *int      `json:"post_union_id"`
+	Platform         string    `json:"platform"`
+	CreatedAt        time.Time `json:"created_at"`
+	AuthorPlatformID int       `json:"author_platform_id"`
+	AuthorName       string    `json:"author_name"`
+	AuthorUsername   string    `json:"author_username"`
+	Text             string    `json:"text"`
+	Attachments      []string  `json:"attachments"` // ссылки на вложения
+	ReplyToCommentID int       `json:"reply_to_comment_id"`
+	IsTeamReply      bool      `json:"is_team_reply"`
+	MarkedAsTicket   bool      `json:"marked_as_ticket"`
+	IsDeleted        bool      `json:"is_deleted"`
+}
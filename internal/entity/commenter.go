@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Commenter профиль автора комментариев. Автор определяется парой (Platform, UserPlatformID) внутри команды
+type Commenter struct {
+	TeamID          int                `json:"team_id"`
+	Platform        string             `json:"platform"`
+	UserPlatformID  int                `json:"user_platform_id"`
+	FullName        string             `json:"full_name"` // имя и аватар из последнего комментария
+	Username        string             `json:"username"`
+	AvatarMediaFile *Upload            `json:"avatar_mediafile"`
+	CommentCount    int                `json:"comment_count"`
+	PostCount       int                `json:"post_count"` // сколько разных постов автор комментировал
+	TicketCount     int                `json:"ticket_count"`
+	Sentiment       CommenterSentiment `json:"sentiment"`
+	FirstSeenAt     time.Time          `json:"first_seen_at"`
+	LastSeenAt      time.Time          `json:"last_seen_at"`
+	Banned          bool               `json:"banned"`
+	Ban             *CommenterBan      `json:"ban"` // действующий бан
+	Tags            []string           `json:"tags"`
+	Notes           []*CommenterNote   `json:"notes"`
+}
+
+// CommenterSentiment число классифицированных комментариев автора по тональности
+type CommenterSentiment struct {
+	Positive int `json:"positive"`
+	Neutral  int `json:"neutral"`
+	Negative int `json:"negative"`
+}
+
+// CommenterNote внутренняя заметка команды об авторе комментариев
+type CommenterNote struct {
+	ID             int       `json:"id" db:"id"`
+	TeamID         int       `json:"team_id" db:"team_id"`
+	Platform       string    `json:"platform" db:"platform"`
+	UserPlatformID int       `json:"user_platform_id" db:"user_platform_id"`
+	UserID         *int      `json:"user_id" db:"user_id"`
+	Text           string    `json:"text" db:"text"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+type GetCommenterRequest struct {
+	UserID         int    `query:"-"`
+	TeamID         int    `query:"team_id"`
+	Platform       string `query:"platform"`
+	UserPlatformID int    `query:"user_platform_id"`
+}
+
+// GetCommenterCommentsRequest история комментариев автора по всем постам, от новых к старым
+type GetCommenterCommentsRequest struct {
+	UserID         int       `query:"-"`
+	TeamID         int       `query:"team_id"`
+	Platform       string    `query:"platform"`
+	UserPlatformID int       `query:"user_platform_id"`
+	Offset         time.Time `query:"offset"` // комментарии, написанные раньше offset
+	Limit          int       `query:"limit"`
+}
+
+// SetCommenterTagsRequest заменяет все метки автора
+type SetCommenterTagsRequest struct {
+	UserID         int      `json:"-"`
+	TeamID         int      `json:"team_id"`
+	Platform       string   `json:"platform"`
+	UserPlatformID int      `json:"user_platform_id"`
+	Tags           []string `json:"tags"`
+}
+
+// IsValid проверяет метки и убирает из них пробелы по краям и повторы
+func (r *SetCommenterTagsRequest) IsValid() error {
+	if len(r.Tags) > 20 {
+		return errors.New("tags must contain at most 20 items")
+	}
+	tags := make([]string, 0, len(r.Tags))
+	seen := make(map[string]struct{}, len(r.Tags))
+	for _, tag := range r.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > 64 {
+			return errors.New("tag must be from 1 to 64 characters")
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	r.Tags = tags
+	return nil
+}
+
+type AddCommenterNoteRequest struct {
+	UserID         int    `json:"-"`
+	TeamID         int    `json:"team_id"`
+	Platform       string `json:"platform"`
+	UserPlatformID int    `json:"user_platform_id"`
+	Text           string `json:"text"`
+}
+
+func (r *AddCommenterNoteRequest) IsValid() error {
+	if r.Text == "" || utf8.RuneCountInString(r.Text) > 4096 {
+		return errors.New("text must be from 1 to 4096 characters")
+	}
+	return nil
+}
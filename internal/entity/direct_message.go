@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// DMConversation личная переписка пользователя с командой
+type DMConversation struct {
+	ID              int            `json:"id" db:"id"`
+	TeamID          int            `json:"team_id" db:"team_id"`
+	Platform        string         `json:"platform" db:"platform"`
+	PeerID          int64          `json:"peer_id" db:"peer_id"` // ID чата в Telegram или ID пользователя ВКонтакте
+	FullName        string         `json:"full_name" db:"full_name"`
+	Username        string         `json:"username" db:"username"`
+	AvatarMediaFile *Upload        `json:"avatar_mediafile" db:"-"`
+	UnreadCount     int            `json:"unread_count" db:"unread_count"`
+	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+	LastMessageAt   time.Time      `json:"last_message_at" db:"last_message_at"`
+	LastMessage     *DirectMessage `json:"last_message,omitempty" db:"-"`
+}
+
+// DirectMessage сообщение в личной переписке
+type DirectMessage struct {
+	ID                int       `json:"id" db:"id"`
+	ConversationID    int       `json:"conversation_id" db:"conversation_id"`
+	MessagePlatformID int64     `json:"message_platform_id" db:"message_platform_id"`
+	Text              string    `json:"text" db:"text"`
+	IsTeamReply       bool      `json:"is_team_reply" db:"is_team_reply"`
+	SentBy            *int      `json:"sent_by,omitempty" db:"sent_by"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	Attachments       []*Upload `json:"attachments" db:"-"`
+}
+
+type GetDMConversationsRequest struct {
+	UserID   int       `query:"-"`
+	TeamID   int       `query:"team_id"`
+	Platform string    `query:"platform"` // пусто — все платформы
+	Offset   time.Time `query:"offset"`   // переписки с последним сообщением раньше offset
+	Limit    int       `query:"limit"`
+}
+
+type GetDirectMessagesRequest struct {
+	UserID         int       `query:"-"`
+	TeamID         int       `query:"team_id"`
+	ConversationID int       `query:"conversation_id"`
+	Offset         time.Time `query:"offset"` // сообщения раньше offset
+	Limit          int       `query:"limit"`
+}
+
+type SendDirectMessageRequest struct {
+	UserID         int    `json:"-"`
+	TeamID         int    `json:"team_id"`
+	ConversationID int    `json:"conversation_id"`
+	Text           string `json:"text"`
+	Attachments    []int  `json:"attachments"`
+}
+
+// IsValid проверяет сообщение по ограничениям платформы переписки
+func (r *SendDirectMessageRequest) IsValid(platform string) error {
+	if r.Text == "" && len(r.Attachments) == 0 {
+		return errors.New("text and attachments are empty")
+	}
+	if len(r.Attachments) > 10 {
+		return errors.New("at most 10 attachments are allowed")
+	}
+	// в Telegram текст сообщения с вложениями становится подписью
+	if platform == "tg" && len(r.Attachments) > 0 && utf8.RuneCountInString(r.Text) > 1024 {
+		return errors.New("text is too long for telegram with attachments")
+	}
+	if utf8.RuneCountInString(r.Text) > 4096 {
+		return errors.New("text must be at most 4096 characters")
+	}
+	return nil
+}
+
+// ReadDMConversationRequest отмечает переписку прочитанной
+type ReadDMConversationRequest struct {
+	UserID         int `json:"-"`
+	TeamID         int `json:"team_id"`
+	ConversationID int `json:"conversation_id"`
+}
+
+// DMSubscriber подписка на новые личные сообщения команды. ConversationID 0 — все переписки
+type DMSubscriber struct {
+	UserID         int `json:"-"`
+	TeamID         int `json:"team_id" query:"team_id"`
+	ConversationID int `json:"conversation_id" query:"conversation_id"`
+}
+
+type GetDMLinkRequest struct {
+	UserID int `query:"-"`
+	TeamID int `query:"team_id"`
+}
@@ -17,4 +17,72 @@ type CommentEvent struct {
 	Type       CommentEventType `json:"type" msgpack:"type"`
 	CommentID  int              `json:"comment_id" msgpack:"comment_id"`
 	OccurredAt time.Time        `json:"-" msgpack:"occurred_at"`
+	// Cursor позиция события в потоке команды, с которой можно продолжить подписку. Заполняется при чтении
+	Cursor string `json:"-" msgpack:"-"`
+}
+
+type DirectMessageEventType string
+
+const (
+	DirectMessageCreated DirectMessageEventType = "created"
+	DirectMessageRead    DirectMessageEventType = "read" // команда прочитала переписку
+)
+
+type DirectMessageEvent struct {
+	EventID        string                 `json:"-" msgpack:"event_id"`
+	TeamID         int                    `json:"-" msgpack:"team_id"`
+	Type           DirectMessageEventType `json:"type" msgpack:"type"`
+	ConversationID int                    `json:"conversation_id" msgpack:"conversation_id"`
+	MessageID      int                    `json:"message_id,omitempty" msgpack:"message_id"`
+	OccurredAt     time.Time              `json:"-" msgpack:"occurred_at"`
+}
+
+// TeamEventType тип события общего потока команды
+type TeamEventType string
+
+const (
+	TeamCommentCreated    TeamEventType = "comment.created"
+	TeamCommentEdited     TeamEventType = "comment.edited"
+	TeamCommentDeleted    TeamEventType = "comment.deleted"
+	TeamTicketCreated     TeamEventType = "ticket.created"
+	TeamTicketUpdated     TeamEventType = "ticket.updated" // изменены поля, заметки или связанные комментарии
+	TeamPostActionUpdated TeamEventType = "post_action.updated"
+	TeamPostPublished     TeamEventType = "post.published" // опубликован запланированный пост
+	TeamStatsUpdated      TeamEventType = "stats.updated"
+)
+
+// TeamEventTypes все типы событий общего потока команды
+var TeamEventTypes = []TeamEventType{
+	TeamCommentCreated,
+	TeamCommentEdited,
+	TeamCommentDeleted,
+	TeamTicketCreated,
+	TeamTicketUpdated,
+	TeamPostActionUpdated,
+	TeamPostPublished,
+	TeamStatsUpdated,
+}
+
+// TeamEvent событие общего потока команды. Заполняются только поля, относящиеся к типу события
+type TeamEvent struct {
+	EventID      string        `json:"-" msgpack:"event_id"`
+	TeamID       int           `json:"-" msgpack:"team_id"`
+	Type         TeamEventType `json:"type" msgpack:"type"`
+	PostUnionID  int           `json:"post_union_id,omitempty" msgpack:"post_union_id"`
+	CommentID    int           `json:"comment_id,omitempty" msgpack:"comment_id"`
+	TicketID     int           `json:"ticket_id,omitempty" msgpack:"ticket_id"`
+	PostActionID int           `json:"post_action_id,omitempty" msgpack:"post_action_id"`
+	Platform     string        `json:"platform,omitempty" msgpack:"platform"`
+	Status       string        `json:"status,omitempty" msgpack:"status"` // статус действия с постом
+	Error        string        `json:"error,omitempty" msgpack:"error"`
+	OccurredAt   time.Time     `json:"occurred_at" msgpack:"occurred_at"`
+	// Cursor позиция события в потоке команды, с которой можно продолжить подписку. Заполняется при чтении
+	Cursor string `json:"-" msgpack:"-"`
+}
+
+type TeamEventSubscriber struct {
+	UserID      int    `json:"-"`
+	TeamID      int    `json:"team_id" query:"team_id"`
+	Types       string `json:"types" query:"types"`     // типы событий через запятую, пусто — все доступные по ролям
+	LastEventID string `json:"-" query:"last_event_id"` // id последнего полученного события для продолжения потока
 }
@@ -0,0 +1,112 @@
+package entity
+
+import (
+	"errors"
+	"regexp"
+	"time"
+	"unicode/utf8"
+)
+
+type FAQTrigger string
+
+const (
+	// FAQKeywords срабатывает, если в тексте есть одно из слов (без учёта регистра)
+	FAQKeywords FAQTrigger = "keywords"
+	// FAQRegex срабатывает на совпадение с регулярным выражением
+	FAQRegex FAQTrigger = "regex"
+	// FAQIntent срабатывает, если ML-сервис определил у комментария намерение Intent
+	FAQIntent FAQTrigger = "intent"
+)
+
+const (
+	ReplySourceManual = "manual"
+	ReplySourceFAQ    = "faq"
+)
+
+// FAQRule правило автоматического ответа на частый вопрос
+type FAQRule struct {
+	ID              int        `json:"id" db:"id"`
+	TeamID          int        `json:"team_id" db:"team_id"`
+	Name            string     `json:"name" db:"name"`
+	Trigger         FAQTrigger `json:"trigger" db:"trigger_type"`
+	Keywords        []string   `json:"keywords" db:"keywords"`
+	Pattern         string     `json:"pattern" db:"pattern"`
+	Intent          string     `json:"intent" db:"intent"`
+	ResponseText    string     `json:"response_text" db:"response_text"` // шаблон с переменными {{name}} и {{username}}
+	CooldownSeconds int        `json:"cooldown_seconds" db:"cooldown_seconds"`
+	Enabled         bool       `json:"enabled" db:"enabled"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+func (r *FAQRule) IsValid() error {
+	if r.Name == "" || utf8.RuneCountInString(r.Name) > 256 {
+		return errors.New("name must be from 1 to 256 characters")
+	}
+	if r.ResponseText == "" || utf8.RuneCountInString(r.ResponseText) > 4096 {
+		return errors.New("response text must be from 1 to 4096 characters")
+	}
+	if err := ValidateReplyTemplate(r.ResponseText); err != nil {
+		return err
+	}
+	if r.CooldownSeconds < 0 || r.CooldownSeconds > 30*24*60*60 {
+		return errors.New("cooldown must be from 0 to 30 days")
+	}
+	switch r.Trigger {
+	case FAQKeywords:
+		if len(r.Keywords) == 0 || len(r.Keywords) > 500 {
+			return errors.New("keywords must contain from 1 to 500 words")
+		}
+		for _, keyword := range r.Keywords {
+			if keyword == "" || utf8.RuneCountInString(keyword) > 128 {
+				return errors.New("keyword must be from 1 to 128 characters")
+			}
+		}
+	case FAQRegex:
+		if r.Pattern == "" || len(r.Pattern) > 1024 {
+			return errors.New("pattern must be from 1 to 1024 bytes")
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return errors.New("pattern is not a valid regular expression")
+		}
+	case FAQIntent:
+		if r.Intent == "" || len(r.Intent) > 64 {
+			return errors.New("intent must be from 1 to 64 bytes")
+		}
+	default:
+		return errors.New("trigger must be one of keywords, regex, intent")
+	}
+	return nil
+}
+
+type GetFAQRulesRequest struct {
+	UserID int `query:"-"`
+	TeamID int `query:"team_id"`
+}
+
+type FAQRuleRequest struct {
+	UserID int `json:"-"`
+	FAQRule
+}
+
+type DeleteFAQRuleRequest struct {
+	UserID int `json:"-"`
+	TeamID int `json:"team_id"`
+	RuleID int `json:"rule_id"`
+}
+
+// FAQSettings общие настройки автоответов команды
+type FAQSettings struct {
+	TeamID  int  `json:"team_id"`
+	Enabled bool `json:"enabled"` // выключатель всех автоответов команды
+}
+
+type GetFAQSettingsRequest struct {
+	UserID int `query:"-"`
+	TeamID int `query:"team_id"`
+}
+
+type SetFAQEnabledRequest struct {
+	UserID  int  `json:"-"`
+	TeamID  int  `json:"team_id"`
+	Enabled bool `json:"enabled"`
+}
@@ -0,0 +1,119 @@
+package entity
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// MediaFile медиафайл в медиатеке команды
+type MediaFile struct {
+	ID               int       `json:"id" db:"id"`
+	TeamID           int       `json:"team_id" db:"team_id"`
+	FilePath         string    `json:"file_path" db:"file_path"`
+	FileType         string    `json:"file_type" db:"file_type"`
+	DisplayName      string    `json:"display_name" db:"display_name"`
+	Tags             []string  `json:"tags" db:"tags"`
+	UploadedByUserID *int      `json:"uploaded_by_user_id" db:"uploaded_by_user_id"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	Width            int       `json:"width" db:"width"`
+	Height           int       `json:"height" db:"height"`
+	DurationMs       int       `json:"duration_ms" db:"duration_ms"`
+	PostsCount       int       `json:"posts_count" db:"posts_count"` // в скольких постах используется файл
+}
+
+type GetMediaLibraryRequest struct {
+	UserID     int       `query:"-"`
+	TeamID     int       `query:"team_id"`
+	FileType   string    `query:"file_type"`
+	From       time.Time `query:"from"`
+	To         time.Time `query:"to"`
+	UploaderID int       `query:"uploader_id"`
+	Used       *bool     `query:"used"`
+	Tag        string    `query:"tag"`
+	Offset     int       `query:"offset"`
+	Limit      int       `query:"limit"`
+}
+
+type EditMediaFileRequest struct {
+	UserID      int      `json:"-"`
+	TeamID      int      `json:"team_id"`
+	MediaFileID int      `json:"mediafile_id"`
+	DisplayName *string  `json:"display_name"`
+	Tags        []string `json:"tags"`
+}
+
+func (r *EditMediaFileRequest) IsValid() error {
+	if r.DisplayName != nil && (*r.DisplayName == "" || utf8.RuneCountInString(*r.DisplayName) > 256) {
+		return errors.New("display name must be from 1 to 256 characters")
+	}
+	if len(r.Tags) > 20 {
+		return errors.New("too many tags")
+	}
+	for _, tag := range r.Tags {
+		if tag == "" || utf8.RuneCountInString(tag) > 64 {
+			return errors.New("tag must be from 1 to 64 characters")
+		}
+	}
+	return nil
+}
+
+type DeleteMediaFileRequest struct {
+	UserID      int `json:"-"`
+	TeamID      int `json:"team_id"`
+	MediaFileID int `json:"mediafile_id"`
+}
+
+type PresignUploadRequest struct {
+	UserID   int    `json:"-"`
+	TeamID   int    `json:"team_id"`
+	FileName string `json:"file_name"`
+	FileType string `json:"file_type"`
+	Size     int64  `json:"size"`
+}
+
+type FinalizeUploadRequest struct {
+	UserID    int `json:"-"`
+	SessionID int `json:"session_id"`
+}
+
+// ImportURLRequest загрузка в медиатеку файла по ссылке
+type ImportURLRequest struct {
+	UserID      int    `json:"-"`
+	TeamID      int    `json:"team_id"`
+	URL         string `json:"url"`
+	DisplayName string `json:"display_name"` // если не указано, берётся из ссылки
+}
+
+// TeamStorageQuota ограничения хранилища команды
+type TeamStorageQuota struct {
+	TeamID          int   `json:"team_id" db:"team_id"`
+	MaxStorageBytes int64 `json:"max_storage_bytes" db:"max_storage_bytes"`
+	MaxFiles        int   `json:"max_files" db:"max_files"`
+}
+
+// StorageUsage занятое место; размер файла включает его производные версии
+type StorageUsage struct {
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"`
+}
+
+// TeamStorageUsage занятое командой место с разбивкой по назначению файлов.
+// Файл, используемый в нескольких местах, учитывается один раз: посты, затем комментарии, затем аватары
+type TeamStorageUsage struct {
+	TeamID   int          `json:"team_id"`
+	Posts    StorageUsage `json:"posts"`
+	Comments StorageUsage `json:"comments"`
+	Avatars  StorageUsage `json:"avatars"`
+	Library  StorageUsage `json:"library"` // файлы медиатеки, которые нигде не используются
+	Total    StorageUsage `json:"total"`
+	// Counted файлы, которые учитываются в квоте: загруженные командой в медиатеку и прикреплённые к её постам.
+	// Вложения комментариев и аватары сохраняются без участия команды и в квоту не входят
+	Counted StorageUsage      `json:"counted"`
+	Quota   *TeamStorageQuota `json:"quota"`
+}
+
+type GetStorageUsageRequest struct {
+	UserID int `query:"-"`
+	TeamID int `query:"team_id"`
+}
@@ -0,0 +1,184 @@
+package entity
+
+import (
+	"errors"
+	"regexp"
+	"time"
+	"unicode/utf8"
+)
+
+type ModerationRuleType string
+
+const (
+	// ModerationKeywords срабатывает, если в тексте есть одно из слов (без учёта регистра)
+	ModerationKeywords ModerationRuleType = "keywords"
+	// ModerationRegex срабатывает на совпадение с регулярным выражением
+	ModerationRegex ModerationRuleType = "regex"
+	// ModerationLink срабатывает на ссылки и упоминания доменов
+	ModerationLink ModerationRuleType = "link"
+	// ModerationPhone срабатывает на номера телефонов
+	ModerationPhone ModerationRuleType = "phone"
+	// ModerationFlood срабатывает, если автор оставил больше Threshold комментариев за WindowSeconds
+	ModerationFlood ModerationRuleType = "flood"
+	// ModerationRepeat срабатывает, если автор повторил тот же текст Threshold раз за WindowSeconds
+	ModerationRepeat ModerationRuleType = "repeat"
+	// ModerationNewAccount срабатывает, если у автора меньше Threshold прошлых комментариев в команде
+	ModerationNewAccount ModerationRuleType = "new_account"
+)
+
+type ModerationAction string
+
+const (
+	ModerationActionNone   ModerationAction = ""
+	ModerationActionTicket ModerationAction = "ticket"
+	ModerationActionHold   ModerationAction = "hold"
+	ModerationActionDelete ModerationAction = "delete"
+	ModerationActionBan    ModerationAction = "ban"
+)
+
+// ChecksText возвращает true для правил, которые смотрят только на текст комментария.
+// Остальные правила оценивают поведение автора в момент появления комментария
+func (t ModerationRuleType) ChecksText() bool {
+	switch t {
+	case ModerationKeywords, ModerationRegex, ModerationLink, ModerationPhone:
+		return true
+	}
+	return false
+}
+
+// Severity возвращает строгость действия: если сработало несколько правил, применяется самое строгое
+func (a ModerationAction) Severity() int {
+	switch a {
+	case ModerationActionTicket:
+		return 1
+	case ModerationActionHold:
+		return 2
+	case ModerationActionDelete:
+		return 3
+	case ModerationActionBan:
+		return 4
+	}
+	return 0
+}
+
+// HidesComment сообщает, что после действия комментарий не показывается в ленте
+func (a ModerationAction) HidesComment() bool {
+	return a == ModerationActionHold || a == ModerationActionDelete || a == ModerationActionBan
+}
+
+// ModerationRule правило автоматической модерации комментариев команды
+type ModerationRule struct {
+	ID            int                `json:"id" db:"id"`
+	TeamID        int                `json:"team_id" db:"team_id"`
+	Name          string             `json:"name" db:"name"`
+	Type          ModerationRuleType `json:"type" db:"type"`
+	Keywords      []string           `json:"keywords" db:"keywords"`
+	Pattern       string             `json:"pattern" db:"pattern"`
+	Threshold     int                `json:"threshold" db:"threshold"`
+	WindowSeconds int                `json:"window_seconds" db:"window_seconds"`
+	Action        ModerationAction   `json:"action" db:"action"`
+	Enabled       bool               `json:"enabled" db:"enabled"`
+	CreatedAt     time.Time          `json:"created_at" db:"created_at"`
+}
+
+func (r *ModerationRule) IsValid() error {
+	if r.Name == "" || utf8.RuneCountInString(r.Name) > 256 {
+		return errors.New("name must be from 1 to 256 characters")
+	}
+	switch r.Action {
+	case ModerationActionTicket, ModerationActionHold, ModerationActionDelete, ModerationActionBan:
+	default:
+		return errors.New("action must be one of ticket, hold, delete, ban")
+	}
+	switch r.Type {
+	case ModerationKeywords:
+		if len(r.Keywords) == 0 || len(r.Keywords) > 500 {
+			return errors.New("keywords must contain from 1 to 500 words")
+		}
+		for _, keyword := range r.Keywords {
+			if keyword == "" || utf8.RuneCountInString(keyword) > 128 {
+				return errors.New("keyword must be from 1 to 128 characters")
+			}
+		}
+	case ModerationRegex:
+		if r.Pattern == "" || len(r.Pattern) > 1024 {
+			return errors.New("pattern must be from 1 to 1024 bytes")
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return errors.New("pattern is not a valid regular expression")
+		}
+	case ModerationLink, ModerationPhone:
+	case ModerationFlood, ModerationRepeat:
+		if r.Threshold < 1 || (r.Type == ModerationRepeat && r.Threshold < 2) {
+			return errors.New("threshold must be positive, at least 2 for repeat")
+		}
+		if r.WindowSeconds < 1 || r.WindowSeconds > 7*24*60*60 {
+			return errors.New("window must be from 1 second to 7 days")
+		}
+	case ModerationNewAccount:
+		if r.Threshold < 1 {
+			return errors.New("threshold must be positive")
+		}
+	default:
+		return errors.New("type must be one of keywords, regex, link, phone, flood, repeat, new_account")
+	}
+	return nil
+}
+
+// ModerationVerdict решение модерации по новому комментарию
+type ModerationVerdict struct {
+	Rule   *ModerationRule
+	Action ModerationAction
+	Reason string
+}
+
+// ModerationLogEntry запись журнала автоматической модерации
+type ModerationLogEntry struct {
+	ID             int              `json:"id" db:"id"`
+	TeamID         int              `json:"team_id" db:"team_id"`
+	RuleID         *int             `json:"rule_id" db:"rule_id"`
+	CommentID      *int             `json:"comment_id" db:"comment_id"`
+	Platform       string           `json:"platform" db:"platform"`
+	UserPlatformID int              `json:"user_platform_id" db:"user_platform_id"`
+	Action         ModerationAction `json:"action" db:"action"`
+	Reason         string           `json:"reason" db:"reason"`
+	Succeeded      bool             `json:"succeeded" db:"succeeded"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+}
+
+type GetModerationRulesRequest struct {
+	UserID int `query:"-"`
+	TeamID int `query:"team_id"`
+}
+
+type ModerationRuleRequest struct {
+	UserID int `json:"-"`
+	ModerationRule
+}
+
+type DeleteModerationRuleRequest struct {
+	UserID int `json:"-"`
+	TeamID int `json:"team_id"`
+	RuleID int `json:"rule_id"`
+}
+
+type GetModerationLogRequest struct {
+	UserID int       `query:"-"`
+	TeamID int       `query:"team_id"`
+	Offset time.Time `query:"offset"`
+	Limit  int       `query:"limit"`
+}
+
+type GetHeldCommentsRequest struct {
+	UserID int `query:"-"`
+	TeamID int `query:"team_id"`
+	Limit  int `query:"limit"`
+}
+
+// ApproveHeldCommentRequest публикует отложенный комментарий. Отклонённый комментарий удаляется
+// обычным DELETE /api/comment/delete
+type ApproveHeldCommentRequest struct {
+	UserID    int `json:"-"`
+	TeamID    int `json:"team_id"`
+	CommentID int `json:"comment_id"`
+}
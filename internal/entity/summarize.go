@@ -1,8 +1,20 @@
 package entity
 
+import "time"
+
 type Summarize struct {
 	// Markdown содержит сводку по комментариям с определенного поста
-	Markdown string `json:"markdown"`
+	Markdown string `json:"markdown" db:"markdown"`
 	// PostUnionID является уникальным идентификатором поста
-	PostUnionID int `json:"post_union_id"`
+	PostUnionID int `json:"post_union_id" db:"post_union_id"`
+	TeamID      int `json:"-" db:"team_id"`
+	// LastCommentID последний комментарий, учтённый в сводке
+	LastCommentID int `json:"last_comment_id" db:"last_comment_id"`
+	// CommentCount сколько комментариев учтено в сводке
+	CommentCount int       `json:"comment_count" db:"comment_count"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	// AgeSeconds сколько секунд прошло с обновления сводки
+	AgeSeconds int `json:"age_seconds" db:"-"`
+	// NewComments сколько комментариев появилось после обновления сводки
+	NewComments int `json:"new_comments" db:"-"`
 }
@@ -0,0 +1,151 @@
+package entity
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+type TicketStatus string
+
+const (
+	TicketOpen       TicketStatus = "open"
+	TicketInProgress TicketStatus = "in_progress"
+	TicketWaiting    TicketStatus = "waiting" // ждём ответа автора комментария
+	TicketResolved   TicketStatus = "resolved"
+)
+
+func (s TicketStatus) IsValid() bool {
+	switch s {
+	case TicketOpen, TicketInProgress, TicketWaiting, TicketResolved:
+		return true
+	}
+	return false
+}
+
+type TicketPriority string
+
+const (
+	TicketPriorityLow    TicketPriority = "low"
+	TicketPriorityNormal TicketPriority = "normal"
+	TicketPriorityHigh   TicketPriority = "high"
+	TicketPriorityUrgent TicketPriority = "urgent"
+)
+
+func (p TicketPriority) IsValid() bool {
+	switch p {
+	case TicketPriorityLow, TicketPriorityNormal, TicketPriorityHigh, TicketPriorityUrgent:
+		return true
+	}
+	return false
+}
+
+// Ticket обращение в поддержку, собранное из одного или нескольких комментариев
+type Ticket struct {
+	ID              int            `json:"id" db:"id"`
+	TeamID          int            `json:"team_id" db:"team_id"`
+	SourceCommentID *int           `json:"source_comment_id" db:"source_comment_id"` // комментарий, с которого открыт тикет
+	Title           string         `json:"title" db:"title"`
+	Status          TicketStatus   `json:"status" db:"status"`
+	Priority        TicketPriority `json:"priority" db:"priority"`
+	AssigneeID      *int           `json:"assignee_id" db:"assignee_id"`
+	CreatedBy       *int           `json:"created_by" db:"created_by"` // nil, если тикет открыт правилом модерации
+	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at" db:"updated_at"`
+	FirstResponseAt *time.Time     `json:"first_response_at" db:"first_response_at"`
+	ResolvedAt      *time.Time     `json:"resolved_at" db:"resolved_at"`
+	CommentIDs      []int          `json:"comment_ids" db:"-"`
+	Comments        []*Comment     `json:"comments,omitempty" db:"-"` // заполняется только при получении одного тикета
+	Notes           []*TicketNote  `json:"notes,omitempty" db:"-"`
+}
+
+// TicketNote внутренняя заметка команды к тикету
+type TicketNote struct {
+	ID        int       `json:"id" db:"id"`
+	TicketID  int       `json:"ticket_id" db:"ticket_id"`
+	UserID    *int      `json:"user_id" db:"user_id"`
+	Text      string    `json:"text" db:"text"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// GetTicketsRequest фильтры списка тикетов. Пустые поля не фильтруют
+type GetTicketsRequest struct {
+	UserID     int            `query:"-"`
+	TeamID     int            `query:"team_id"`
+	Status     TicketStatus   `query:"status"`
+	Priority   TicketPriority `query:"priority"`
+	AssigneeID *int           `query:"assignee_id"` // 0 — только неназначенные
+	Offset     time.Time      `query:"offset"`      // тикеты, созданные раньше offset
+	Limit      int            `query:"limit"`
+}
+
+type GetTicketRequest struct {
+	UserID   int `query:"-"`
+	TeamID   int `query:"team_id"`
+	TicketID int `query:"ticket_id"`
+}
+
+type CreateTicketRequest struct {
+	UserID     int            `json:"-"`
+	TeamID     int            `json:"team_id"`
+	CommentID  int            `json:"comment_id"`
+	Title      string         `json:"title"` // по умолчанию — начало текста комментария
+	Priority   TicketPriority `json:"priority"`
+	AssigneeID *int           `json:"assignee_id"`
+}
+
+func (r *CreateTicketRequest) IsValid() error {
+	if utf8.RuneCountInString(r.Title) > 256 {
+		return errors.New("title must be at most 256 characters")
+	}
+	if r.Priority != "" && !r.Priority.IsValid() {
+		return errors.New("priority must be one of low, normal, high, urgent")
+	}
+	return nil
+}
+
+// UpdateTicketRequest меняет только переданные поля
+type UpdateTicketRequest struct {
+	UserID     int             `json:"-"`
+	TeamID     int             `json:"team_id"`
+	TicketID   int             `json:"ticket_id"`
+	Title      *string         `json:"title"`
+	Status     *TicketStatus   `json:"status"`
+	Priority   *TicketPriority `json:"priority"`
+	AssigneeID *int            `json:"assignee_id"` // 0 снимает назначение
+}
+
+func (r *UpdateTicketRequest) IsValid() error {
+	if r.Title != nil && (*r.Title == "" || utf8.RuneCountInString(*r.Title) > 256) {
+		return errors.New("title must be from 1 to 256 characters")
+	}
+	if r.Status != nil && !r.Status.IsValid() {
+		return errors.New("status must be one of open, in_progress, waiting, resolved")
+	}
+	if r.Priority != nil && !r.Priority.IsValid() {
+		return errors.New("priority must be one of low, normal, high, urgent")
+	}
+	return nil
+}
+
+type AddTicketNoteRequest struct {
+	UserID   int    `json:"-"`
+	TeamID   int    `json:"team_id"`
+	TicketID int    `json:"ticket_id"`
+	Text     string `json:"text"`
+}
+
+func (r *AddTicketNoteRequest) IsValid() error {
+	if r.Text == "" || utf8.RuneCountInString(r.Text) > 4096 {
+		return errors.New("text must be from 1 to 4096 characters")
+	}
+	return nil
+}
+
+// TicketCommentRequest привязывает комментарий к тикету или отвязывает его
+type TicketCommentRequest struct {
+	UserID    int `json:"-"`
+	TeamID    int `json:"team_id"`
+	TicketID  int `json:"ticket_id"`
+	CommentID int `json:"comment_id"`
+}
@@ -6,11 +6,89 @@ import (
 )
 
 type Upload struct {
-	ID        int           `json:"id" db:"id"`
-	RawBytes  io.ReadSeeker `json:"-"`
-	FilePath  string        `json:"file_path" db:"file_path"`
-	FileType  string        `json:"file_type" db:"file_type"`
-	UserID    *int          `json:"uploaded_by_user_id,omitempty" db:"uploaded_by_user_id"`
-	CreatedAt time.Time     `json:"created_at" db:"created_at"`
-	Size      int64         `json:"size" db:"-"`
+	ID          int                `json:"id" db:"id"`
+	RawBytes    io.ReadSeeker      `json:"-"`
+	FilePath    string             `json:"file_path" db:"file_path"`
+	FileType    string             `json:"file_type" db:"file_type"`
+	UserID      *int               `json:"uploaded_by_user_id,omitempty" db:"uploaded_by_user_id"`
+	TeamID      *int               `json:"team_id,omitempty" db:"team_id"`
+	DisplayName string             `json:"display_name,omitempty" db:"display_name"`
+	ContentHash string             `json:"content_hash,omitempty" db:"content_hash"` // SHA-256 содержимого, пустой у старых файлов
+	CreatedAt   time.Time          `json:"created_at" db:"created_at"`
+	Size        int64              `json:"size" db:"-"`
+	Width       int                `json:"width,omitempty" db:"width"`
+	Height      int                `json:"height,omitempty" db:"height"`
+	DurationMs  int                `json:"duration_ms,omitempty" db:"duration_ms"`
+	Renditions  []*UploadRendition `json:"renditions,omitempty" db:"-"`
+}
+
+// UploadRendition производная версия медиафайла, хранящаяся отдельным медиафайлом
+type UploadRendition struct {
+	MediaFileID int    `json:"mediafile_id" db:"rendition_mediafile_id"`
+	Kind        string `json:"kind" db:"kind"`
+	MimeType    string `json:"mime_type" db:"mime_type"`
+	Width       int    `json:"width" db:"width"`
+	Height      int    `json:"height" db:"height"`
+	Size        int64  `json:"size" db:"size"`
+}
+
+const (
+	RenditionJPEG2560  = "jpeg_2560"
+	RenditionJPEG1280  = "jpeg_1280"
+	RenditionThumbnail = "thumbnail"
+	RenditionMP4720    = "mp4_720"
+)
+
+// PickRendition возвращает первую из перечисленных версий, которая укладывается в maxSize байт
+// и в maxDimSum по сумме сторон (0 - без ограничения). nil означает, что подходящей версии нет
+// и нужно использовать оригинал
+func (u *Upload) PickRendition(maxSize int64, maxDimSum int, kinds ...string) *UploadRendition {
+	fits := func(size int64, w, h int) bool {
+		return (maxSize == 0 || size <= maxSize) && (maxDimSum == 0 || w+h <= maxDimSum)
+	}
+	for _, kind := range kinds {
+		for _, r := range u.Renditions {
+			if r.Kind == kind && fits(r.Size, r.Width, r.Height) {
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+// ObjectName возвращает имя объекта в хранилище: одинаковое содержимое хранится одним объектом
+func (u *Upload) ObjectName() string {
+	if u.ContentHash != "" {
+		return "sha256/" + u.ContentHash
+	}
+	return u.FilePath
+}
+
+// MediaGCReport итог одного прохода сборщика неиспользуемых медиафайлов
+type MediaGCReport struct {
+	DeletedFiles   int   `json:"deleted_files"`   // удалено строк mediafile (включая производные версии)
+	DeletedObjects int   `json:"deleted_objects"` // удалено объектов из хранилища
+	ReclaimedBytes int64 `json:"reclaimed_bytes"`
+}
+
+// PresignedURL временная ссылка на объект в хранилище
+type PresignedURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// UploadSession сессия возобновляемой загрузки (tus) или загрузки по временной ссылке
+type UploadSession struct {
+	ID          int       `json:"id" db:"id"`
+	UserID      int       `json:"user_id" db:"user_id"`
+	TeamID      int       `json:"team_id" db:"team_id"`
+	FileName    string    `json:"file_name" db:"file_name"`
+	FileType    string    `json:"file_type" db:"file_type"`
+	DisplayName string    `json:"display_name" db:"display_name"`
+	Size        int64     `json:"size" db:"size"`
+	Offset      int64     `json:"offset" db:"upload_offset"`
+	MediaFileID *int      `json:"mediafile_id" db:"mediafile_id"` // nil, пока файл не собран
+	Presigned   bool      `json:"presigned" db:"presigned"`       // файл загружается по временной ссылке
+	ExpiresAt   time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
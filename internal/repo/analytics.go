@@ -13,6 +13,8 @@ type Analytics interface {
 	GetPostPlatformStatsByDateRange(startDate, endDate time.Time, platform string) ([]*entity.PostPlatformStats, error)
 	// GetCommentsCountByPeriod возвращает количество комментариев к посту за указанный период
 	GetCommentsCountByPeriod(postUnionID int, startDate, endDate time.Time) (int, error)
+	// GetCommentSentimentByDay возвращает количество комментариев к посту каждой тональности по дням
+	GetCommentSentimentByDay(postUnionID int, startDate, endDate time.Time) ([]*entity.SentimentPoint, error)
 	// CommentsCount возвращает количество комментариев к посту
 	CommentsCount(postUnionID int) (int, error)
 	// SavePostPlatformStats сохраняет новую статистику поста
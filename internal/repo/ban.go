@@ -0,0 +1,25 @@
+package repo
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+	"time"
+)
+
+type Ban interface {
+	// AddBan сохраняет бан автора. Не снятый бан этого автора считается заменённым новым
+	AddBan(ban *entity.CommenterBan) (int, error)
+	// GetBan возвращает бан по ID
+	GetBan(banID int) (*entity.CommenterBan, error)
+	// GetBans возвращает баны команды от новых к старым, начиная после пары (offset, offsetID).
+	// platform пустой — по всем платформам
+	GetBans(teamID int, platform string, activeOnly bool, offset time.Time, offsetID int, limit int) ([]*entity.CommenterBan, error)
+	// GetActiveBan возвращает действующий бан автора
+	GetActiveBan(teamID int, platform string, userPlatformID int) (*entity.CommenterBan, error)
+	// LiftBan снимает бан. liftedBy равен nil, если бан снят автоматически
+	LiftBan(banID int, liftedBy *int, liftedAt time.Time) error
+}
+
+var (
+	ErrBanNotFound = errors.New("ban not found")
+)
@@ -0,0 +1,26 @@
+package repo
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+	"time"
+)
+
+type CannedReply interface {
+	// GetCannedReplies возвращает сохранённые ответы команды, начиная с самых используемых
+	GetCannedReplies(teamID int) ([]*entity.CannedReply, error)
+	// GetCannedReply возвращает сохранённый ответ по ID
+	GetCannedReply(cannedReplyID int) (*entity.CannedReply, error)
+	// AddCannedReply добавляет сохранённый ответ вместе с вложениями
+	AddCannedReply(cannedReply *entity.CannedReply) (int, error)
+	// EditCannedReply изменяет название, текст и вложения сохранённого ответа
+	EditCannedReply(cannedReply *entity.CannedReply) error
+	// DeleteCannedReply удаляет сохранённый ответ
+	DeleteCannedReply(cannedReplyID int) error
+	// AddCannedReplyUsage увеличивает счётчик использований ответа
+	AddCannedReplyUsage(cannedReplyID int, usedAt time.Time) error
+}
+
+var (
+	ErrCannedReplyNotFound = errors.New("canned reply not found")
+)
@@ -348,6 +348,35 @@ func (a *Analytics) GetCommentsCountByPeriod(postUnionID int, startDate, endDate
 	return count, nil
 }
 
+func (a *Analytics) GetCommentSentimentByDay(postUnionID int, startDate, endDate time.Time) ([]*entity.SentimentPoint, error) {
+	query, args, err := sq.Select(
+		"date_trunc('day', created_at) AS date",
+		"COUNT(*) FILTER (WHERE sentiment = 'positive') AS positive",
+		"COUNT(*) FILTER (WHERE sentiment = 'neutral') AS neutral",
+		"COUNT(*) FILTER (WHERE sentiment = 'negative') AS negative",
+	).
+		From("post_comment").
+		Where(sq.Eq{"post_union_id": postUnionID}).
+		Where(sq.GtOrEq{"created_at": startDate}).
+		Where(sq.LtOrEq{"created_at": endDate}).
+		Where(sq.NotEq{"sentiment": nil}).
+		Where(sq.Eq{"is_deleted": false}).
+		Where(sq.Eq{"held_for_review": false}).
+		GroupBy("date").
+		OrderBy("date").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения тональности комментариев: %w", err)
+	}
+
+	points := make([]*entity.SentimentPoint, 0)
+	if err := a.db.Select(&points, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении тональности комментариев: %w", err)
+	}
+	return points, nil
+}
+
 func (a *Analytics) GetUserKPI(userID int, startDate, endDate time.Time) (*entity.UserKPI, error) {
 	// Получаем все посты пользователя за указанный период
 	queryPosts := `
@@ -0,0 +1,157 @@
+package cockroach
+
+import (
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+type Ban struct {
+	db *sqlx.DB
+}
+
+func NewBan(db *sqlx.DB) repo.Ban {
+	return &Ban{db: db}
+}
+
+func (b *Ban) selectBans() sq.SelectBuilder {
+	return sq.Select(
+		"id", "team_id", "platform", "user_platform_id", "full_name", "username", "reason",
+		"comment_id", "banned_by", "created_at", "expires_at", "lifted_at", "lifted_by",
+	).
+		From("commenter_ban").
+		PlaceholderFormat(sq.Dollar)
+}
+
+// activeBanCondition бан не снят и его срок не истёк
+var activeBanCondition = sq.And{
+	sq.Expr("lifted_at IS NULL"),
+	sq.Or{sq.Expr("expires_at IS NULL"), sq.Expr("expires_at > NOW()")},
+}
+
+func (b *Ban) AddBan(ban *entity.CommenterBan) (int, error) {
+	tx, err := b.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec(
+		"UPDATE commenter_ban SET lifted_at = $1 WHERE team_id = $2 AND platform = $3 AND user_platform_id = $4 AND lifted_at IS NULL",
+		ban.CreatedAt, ban.TeamID, ban.Platform, ban.UserPlatformID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при замене предыдущего бана: %w", err)
+	}
+
+	query, args, err := sq.Insert("commenter_ban").
+		Columns(
+			"team_id", "platform", "user_platform_id", "full_name", "username", "reason",
+			"comment_id", "banned_by", "created_at", "expires_at",
+		).
+		Values(
+			ban.TeamID,
+			ban.Platform,
+			ban.UserPlatformID,
+			ban.FullName,
+			ban.Username,
+			ban.Reason,
+			ban.CommentID,
+			ban.BannedBy,
+			ban.CreatedAt,
+			ban.ExpiresAt,
+		).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при формировании SQL-запроса для добавления бана: %w", err)
+	}
+	var banID int
+	if err := tx.QueryRow(query, args...).Scan(&banID); err != nil {
+		return 0, fmt.Errorf("ошибка при добавлении бана: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+	return banID, nil
+}
+
+func (b *Ban) GetBan(banID int) (*entity.CommenterBan, error) {
+	query, args, err := b.selectBans().Where(sq.Eq{"id": banID}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения бана: %w", err)
+	}
+	bans := make([]*entity.CommenterBan, 0, 1)
+	if err := b.db.Select(&bans, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении бана: %w", err)
+	}
+	if len(bans) == 0 {
+		return nil, repo.ErrBanNotFound
+	}
+	return bans[0], nil
+}
+
+func (b *Ban) GetBans(teamID int, platform string, activeOnly bool, offset time.Time, offsetID int, limit int) ([]*entity.CommenterBan, error) {
+	builder := b.selectBans().
+		Where(sq.Eq{"team_id": teamID}).
+		Where(sq.Or{
+			sq.Lt{"created_at": offset},
+			sq.And{sq.Eq{"created_at": offset}, sq.Lt{"id": offsetID}},
+		})
+	if platform != "" {
+		builder = builder.Where(sq.Eq{"platform": platform})
+	}
+	if activeOnly {
+		builder = builder.Where(activeBanCondition)
+	}
+	query, args, err := builder.
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения банов: %w", err)
+	}
+	bans := make([]*entity.CommenterBan, 0)
+	if err := b.db.Select(&bans, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении банов: %w", err)
+	}
+	return bans, nil
+}
+
+func (b *Ban) GetActiveBan(teamID int, platform string, userPlatformID int) (*entity.CommenterBan, error) {
+	query, args, err := b.selectBans().
+		Where(sq.Eq{"team_id": teamID, "platform": platform, "user_platform_id": userPlatformID}).
+		Where(activeBanCondition).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения бана: %w", err)
+	}
+	bans := make([]*entity.CommenterBan, 0, 1)
+	if err := b.db.Select(&bans, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении бана: %w", err)
+	}
+	if len(bans) == 0 {
+		return nil, repo.ErrBanNotFound
+	}
+	return bans[0], nil
+}
+
+func (b *Ban) LiftBan(banID int, liftedBy *int, liftedAt time.Time) error {
+	res, err := b.db.Exec(
+		"UPDATE commenter_ban SET lifted_at = $1, lifted_by = $2 WHERE id = $3 AND lifted_at IS NULL",
+		liftedAt, liftedBy, banID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при снятии бана: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrBanNotFound
+	}
+	return nil
+}
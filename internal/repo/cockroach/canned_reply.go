@@ -0,0 +1,214 @@
+package cockroach
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type CannedReply struct {
+	db *sqlx.DB
+}
+
+func NewCannedReply(db *sqlx.DB) repo.CannedReply {
+	return &CannedReply{db: db}
+}
+
+func (c *CannedReply) selectCannedReplies() sq.SelectBuilder {
+	return sq.Select(
+		"id", "team_id", "title", "text", "usage_count", "last_used_at",
+		"created_by", "created_at", "updated_at",
+	).
+		From("canned_reply").
+		PlaceholderFormat(sq.Dollar)
+}
+
+// fillAttachments заполняет ID вложений одним запросом на все ответы
+func (c *CannedReply) fillAttachments(cannedReplies []*entity.CannedReply) error {
+	if len(cannedReplies) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(cannedReplies))
+	byID := make(map[int]*entity.CannedReply, len(cannedReplies))
+	for _, cannedReply := range cannedReplies {
+		cannedReply.Attachments = make([]int, 0)
+		ids = append(ids, cannedReply.ID)
+		byID[cannedReply.ID] = cannedReply
+	}
+
+	rows, err := c.db.Query(
+		"SELECT canned_reply_id, mediafile_id FROM canned_reply_mediafile WHERE canned_reply_id = ANY($1) ORDER BY position",
+		pq.Array(ids),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при получении вложений сохранённых ответов: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var cannedReplyID, mediaFileID int
+		if err := rows.Scan(&cannedReplyID, &mediaFileID); err != nil {
+			return fmt.Errorf("ошибка при сканировании вложения сохранённого ответа: %w", err)
+		}
+		byID[cannedReplyID].Attachments = append(byID[cannedReplyID].Attachments, mediaFileID)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка при получении вложений сохранённых ответов: %w", err)
+	}
+	return nil
+}
+
+// putCannedReplyAttachments заменяет вложения ответа внутри транзакции
+func putCannedReplyAttachments(tx *sqlx.Tx, cannedReplyID int, attachments []int) error {
+	if _, err := tx.Exec("DELETE FROM canned_reply_mediafile WHERE canned_reply_id = $1", cannedReplyID); err != nil {
+		return fmt.Errorf("ошибка при удалении вложений сохранённого ответа: %w", err)
+	}
+	for i, mediaFileID := range attachments {
+		_, err := tx.Exec(
+			"INSERT INTO canned_reply_mediafile (canned_reply_id, mediafile_id, position) VALUES ($1, $2, $3) "+
+				"ON CONFLICT (canned_reply_id, mediafile_id) DO NOTHING",
+			cannedReplyID, mediaFileID, i,
+		)
+		if err != nil {
+			return fmt.Errorf("ошибка при добавлении вложения сохранённого ответа: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *CannedReply) GetCannedReplies(teamID int) ([]*entity.CannedReply, error) {
+	query, args, err := c.selectCannedReplies().
+		Where(sq.Eq{"team_id": teamID}).
+		OrderBy("usage_count DESC", "id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения сохранённых ответов: %w", err)
+	}
+
+	cannedReplies := make([]*entity.CannedReply, 0)
+	if err := c.db.Select(&cannedReplies, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении сохранённых ответов: %w", err)
+	}
+	if err := c.fillAttachments(cannedReplies); err != nil {
+		return nil, err
+	}
+	return cannedReplies, nil
+}
+
+func (c *CannedReply) GetCannedReply(cannedReplyID int) (*entity.CannedReply, error) {
+	query, args, err := c.selectCannedReplies().Where(sq.Eq{"id": cannedReplyID}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения сохранённого ответа: %w", err)
+	}
+	cannedReply := &entity.CannedReply{}
+	err = c.db.Get(cannedReply, query, args...)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, repo.ErrCannedReplyNotFound
+	case err != nil:
+		return nil, fmt.Errorf("ошибка при получении сохранённого ответа: %w", err)
+	}
+	if err := c.fillAttachments([]*entity.CannedReply{cannedReply}); err != nil {
+		return nil, err
+	}
+	return cannedReply, nil
+}
+
+func (c *CannedReply) AddCannedReply(cannedReply *entity.CannedReply) (int, error) {
+	query, args, err := sq.Insert("canned_reply").
+		Columns("team_id", "title", "text", "created_by", "created_at", "updated_at").
+		Values(
+			cannedReply.TeamID,
+			cannedReply.Title,
+			cannedReply.Text,
+			cannedReply.CreatedBy,
+			cannedReply.CreatedAt,
+			cannedReply.UpdatedAt,
+		).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при формировании SQL-запроса для добавления сохранённого ответа: %w", err)
+	}
+
+	tx, err := c.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var cannedReplyID int
+	if err := tx.QueryRow(query, args...).Scan(&cannedReplyID); err != nil {
+		return 0, fmt.Errorf("ошибка при добавлении сохранённого ответа: %w", err)
+	}
+	if err := putCannedReplyAttachments(tx, cannedReplyID, cannedReply.Attachments); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+	return cannedReplyID, nil
+}
+
+func (c *CannedReply) EditCannedReply(cannedReply *entity.CannedReply) error {
+	query, args, err := sq.Update("canned_reply").
+		Set("title", cannedReply.Title).
+		Set("text", cannedReply.Text).
+		Set("updated_at", cannedReply.UpdatedAt).
+		Where(sq.Eq{"id": cannedReply.ID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании SQL-запроса для изменения сохранённого ответа: %w", err)
+	}
+
+	tx, err := c.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении сохранённого ответа: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrCannedReplyNotFound
+	}
+	if err := putCannedReplyAttachments(tx, cannedReply.ID, cannedReply.Attachments); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+	return nil
+}
+
+func (c *CannedReply) DeleteCannedReply(cannedReplyID int) error {
+	res, err := c.db.Exec("DELETE FROM canned_reply WHERE id = $1", cannedReplyID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении сохранённого ответа: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrCannedReplyNotFound
+	}
+	return nil
+}
+
+func (c *CannedReply) AddCannedReplyUsage(cannedReplyID int, usedAt time.Time) error {
+	_, err := c.db.Exec(
+		"UPDATE canned_reply SET usage_count = usage_count + 1, last_used_at = $1 WHERE id = $2",
+		usedAt, cannedReplyID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при учёте использования сохранённого ответа: %w", err)
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type Comment struct {
@@ -50,6 +51,8 @@ func (c *Comment) EditComment(comment *entity.Comment) error {
 		Set("is_team_reply", comment.IsTeamReply).
 		Set("created_at", comment.CreatedAt).
 		Set("marked_as_ticket", comment.MarkedAsTicket).
+		// изменение может только отложить комментарий: снимает отметку проверка модератором
+		Set("held_for_review", sq.Expr("held_for_review OR ?", comment.HeldForReview)).
 		Where(sq.Eq{"id": comment.ID}).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
@@ -71,6 +74,20 @@ func (c *Comment) EditComment(comment *entity.Comment) error {
 }
 
 func (c *Comment) GetCommentByPlatformID(platformID int, platform string) (*entity.Comment, error) {
+	return c.getActiveComment(sq.Eq{"comment_platform_id": platformID, "platform": platform})
+}
+
+func (c *Comment) GetPlatformComment(teamID int, platform string, platformPostID, commentPlatformID int) (*entity.Comment, error) {
+	return c.getActiveComment(sq.Eq{
+		"team_id":             teamID,
+		"platform":            platform,
+		"post_platform_id":    platformPostID,
+		"comment_platform_id": commentPlatformID,
+	})
+}
+
+// getActiveComment возвращает неудалённый комментарий по условию вместе с аватаром и вложениями
+func (c *Comment) getActiveComment(where sq.Eq) (*entity.Comment, error) {
 	query, args, err := sq.Select(
 		"id", "team_id", "post_union_id", "platform", "post_platform_id",
 		"user_platform_id", "comment_platform_id", "full_name", "username",
@@ -78,8 +95,7 @@ func (c *Comment) GetCommentByPlatformID(platformID int, platform string) (*enti
 		"created_at", "marked_as_ticket", "is_deleted",
 	).
 		From("post_comment").
-		Where(sq.Eq{"comment_platform_id": platformID}).
-		Where(sq.Eq{"platform": platform}).
+		Where(where).
 		Where(sq.Eq{"is_deleted": false}).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
@@ -177,6 +193,7 @@ func (c *Comment) GetLastComments(postUnionID int, limit int) ([]*entity.JustTex
 		From("post_comment").
 		Where(sq.Eq{"post_union_id": postUnionID}).
 		Where(sq.Eq{"is_deleted": false}).
+		Where(sq.Eq{"held_for_review": false}).
 		OrderBy("created_at DESC").
 		Limit(uint64(limit)).
 		PlaceholderFormat(sq.Dollar).
@@ -207,6 +224,91 @@ func (c *Comment) GetLastComments(postUnionID int, limit int) ([]*entity.JustTex
 	return comments, nil
 }
 
+func (c *Comment) GetCommentTextsAfter(postUnionID, afterCommentID, limit int) ([]*entity.JustTextComment, error) {
+	query, args, err := sq.Select("id", "COALESCE(text, '') AS text").
+		From("post_comment").
+		Where(sq.Eq{"post_union_id": postUnionID}).
+		Where(sq.Gt{"id": afterCommentID}).
+		Where(sq.Eq{"is_deleted": false}).
+		Where(sq.Eq{"held_for_review": false}).
+		OrderBy("id ASC").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения новых комментариев: %w", err)
+	}
+
+	comments := make([]*entity.JustTextComment, 0, limit)
+	if err := c.db.Select(&comments, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении новых комментариев: %w", err)
+	}
+	return comments, nil
+}
+
+func (c *Comment) PlatformCommentExists(teamID int, platform string, platformPostID, commentPlatformID int) (bool, error) {
+	query, args, err := sq.Select("1").
+		From("post_comment").
+		Where(sq.Eq{"team_id": teamID}).
+		Where(sq.Eq{"platform": platform}).
+		Where(sq.Eq{"post_platform_id": platformPostID}).
+		Where(sq.Eq{"comment_platform_id": commentPlatformID}).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("ошибка при формировании SQL-запроса для проверки комментария: %w", err)
+	}
+	var exists bool
+	if err := c.db.Get(&exists, query, args...); err != nil {
+		return false, fmt.Errorf("ошибка при проверке комментария: %w", err)
+	}
+	return exists, nil
+}
+
+func (c *Comment) GetPlatformPostComments(teamID int, platform string, platformPostID int) ([]*entity.PlatformComment, error) {
+	query, args, err := sq.Select(
+		"id", "comment_platform_id", "COALESCE(text, '') AS text",
+		"is_team_reply", "is_deleted", "held_for_review",
+	).
+		From("post_comment").
+		Where(sq.Eq{"team_id": teamID}).
+		Where(sq.Eq{"platform": platform}).
+		Where(sq.Eq{"post_platform_id": platformPostID}).
+		OrderBy("comment_platform_id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения комментариев поста платформы: %w", err)
+	}
+
+	var comments []*entity.PlatformComment
+	if err := c.db.Select(&comments, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении комментариев поста платформы: %w", err)
+	}
+	return comments, nil
+}
+
+func (c *Comment) CountCommentsAfter(postUnionID, afterCommentID int) (int, error) {
+	query, args, err := sq.Select("COUNT(*)").
+		From("post_comment").
+		Where(sq.Eq{"post_union_id": postUnionID}).
+		Where(sq.Gt{"id": afterCommentID}).
+		Where(sq.Eq{"is_deleted": false}).
+		Where(sq.Eq{"held_for_review": false}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при формировании SQL-запроса для подсчёта новых комментариев: %w", err)
+	}
+	var count int
+	if err := c.db.Get(&count, query, args...); err != nil {
+		return 0, fmt.Errorf("ошибка при подсчёте новых комментариев: %w", err)
+	}
+	return count, nil
+}
+
 func (c *Comment) AddComment(comment *entity.Comment) (int, error) {
 	tx, err := c.db.Begin()
 	if err != nil {
@@ -226,7 +328,7 @@ func (c *Comment) AddComment(comment *entity.Comment) (int, error) {
 			"team_id", "post_union_id", "platform", "post_platform_id",
 			"user_platform_id", "comment_platform_id", "full_name", "username",
 			"avatar_mediafile_id", "text", "reply_to_comment_id", "is_team_reply",
-			"created_at", "marked_as_ticket",
+			"created_at", "marked_as_ticket", "held_for_review", "reply_source", "faq_rule_id",
 		).
 		Values(
 			comment.TeamID,
@@ -243,6 +345,9 @@ func (c *Comment) AddComment(comment *entity.Comment) (int, error) {
 			comment.IsTeamReply,
 			comment.CreatedAt,
 			comment.MarkedAsTicket,
+			comment.HeldForReview,
+			comment.ReplySource,
+			comment.FAQRuleID,
 		).
 		Suffix("RETURNING id").
 		PlaceholderFormat(sq.Dollar).
@@ -288,6 +393,7 @@ func (c *Comment) GetComments(
 	teamID int,
 	postUnionID int,
 	offset time.Time,
+	offsetID int,
 	before bool,
 	limit int,
 ) ([]*entity.Comment, error) {
@@ -329,13 +435,19 @@ WITH RECURSIVE top_level_comments AS (
         is_team_reply,
         created_at,
 		marked_as_ticket,
-		is_deleted
+		is_deleted,
+		sentiment,
+		topic,
+		reply_source,
+		faq_rule_id,
+		team_reaction
     FROM post_comment
     WHERE ($1 = 0 OR team_id = $1)
 	  AND ($2 = 0 OR "post_union_id" = $2)
       AND reply_to_comment_id = 0
-      AND created_at %s $3
-    ORDER BY created_at %s
+      AND NOT held_for_review
+      AND (created_at %[1]s $3 OR ($5 != 0 AND created_at = $3 AND id %[1]s $5))
+    ORDER BY created_at %[2]s, id %[2]s
     LIMIT $4
 ),
 comment_tree AS (
@@ -355,7 +467,12 @@ comment_tree AS (
         is_team_reply,
         created_at,
 		marked_as_ticket,
-		is_deleted
+		is_deleted,
+		sentiment,
+		topic,
+		reply_source,
+		faq_rule_id,
+		team_reaction
     FROM top_level_comments
 
     UNION ALL
@@ -376,9 +493,15 @@ comment_tree AS (
         pc.is_team_reply,
         pc.created_at,
 		pc.marked_as_ticket,
-		pc.is_deleted
+		pc.is_deleted,
+		pc.sentiment,
+		pc.topic,
+		pc.reply_source,
+		pc.faq_rule_id,
+		pc.team_reaction
     FROM post_comment pc
     JOIN comment_tree ct ON pc.reply_to_comment_id = ct.id
+    WHERE NOT pc.held_for_review
 )
 SELECT
     id,
@@ -396,12 +519,17 @@ SELECT
     is_team_reply,
     created_at,
 	marked_as_ticket,
-	is_deleted
+	is_deleted,
+	COALESCE(sentiment, ''),
+	COALESCE(topic, ''),
+	reply_source,
+	faq_rule_id,
+	COALESCE(team_reaction, '')
 FROM comment_tree
-ORDER BY CASE WHEN reply_to_comment_id = 0 THEN 0 ELSE 1 END, created_at DESC
+ORDER BY CASE WHEN reply_to_comment_id = 0 THEN 0 ELSE 1 END, created_at DESC, id DESC
 `, comparator, sortOrder)
 
-	rows, err := c.db.Queryx(query, teamID, postUnionID, offset, limit)
+	rows, err := c.db.Queryx(query, teamID, postUnionID, offset, limit, offsetID)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении комментариев: %w", err)
 	}
@@ -429,6 +557,11 @@ ORDER BY CASE WHEN reply_to_comment_id = 0 THEN 0 ELSE 1 END, created_at DESC
 			&comment.CreatedAt,
 			&comment.MarkedAsTicket,
 			&comment.IsDeleted,
+			&comment.Sentiment,
+			&comment.Topic,
+			&comment.ReplySource,
+			&comment.FAQRuleID,
+			&comment.TeamReaction,
 		); err != nil {
 			return nil, fmt.Errorf("ошибка при сканировании комментария: %w", err)
 		}
@@ -499,7 +632,9 @@ func (c *Comment) GetComment(commentID int) (*entity.Comment, error) {
 		"id", "team_id", "post_union_id", "platform", "post_platform_id",
 		"user_platform_id", "comment_platform_id", "full_name", "username",
 		"avatar_mediafile_id", "text", "reply_to_comment_id", "is_team_reply",
-		"created_at", "marked_as_ticket", "is_deleted",
+		"created_at", "marked_as_ticket", "is_deleted", "held_for_review",
+		"COALESCE(sentiment, '')", "COALESCE(topic, '')", "reply_source", "faq_rule_id",
+		"COALESCE(team_reaction, '')",
 	).
 		From("post_comment").
 		Where(sq.Eq{"id": commentID}).
@@ -530,6 +665,12 @@ func (c *Comment) GetComment(commentID int) (*entity.Comment, error) {
 		&comment.CreatedAt,
 		&comment.MarkedAsTicket,
 		&comment.IsDeleted,
+		&comment.HeldForReview,
+		&comment.Sentiment,
+		&comment.Topic,
+		&comment.ReplySource,
+		&comment.FAQRuleID,
+		&comment.TeamReaction,
 	)
 	switch {
 	case errors.Is(err, sql.ErrNoRows):
@@ -595,10 +736,21 @@ func (c *Comment) GetComment(commentID int) (*entity.Comment, error) {
 }
 
 func (c *Comment) GetTicketComments(teamID int, offset time.Time, before bool, limit int) ([]*entity.Comment, error) {
+	markedAsTicket := true
+	return c.GetFilteredComments(&entity.GetCommentsRequest{
+		TeamID:         teamID,
+		Offset:         offset,
+		Before:         before,
+		Limit:          limit,
+		MarkedAsTicket: &markedAsTicket,
+	})
+}
+
+func (c *Comment) GetFilteredComments(request *entity.GetCommentsRequest) ([]*entity.Comment, error) {
 	var comparator string
 	var sortOrder string
 
-	if before {
+	if request.Before {
 		// Получить комментарии ДО offset
 		comparator = "<"
 		sortOrder = "DESC" // Сначала новые
@@ -613,27 +765,45 @@ func (c *Comment) GetTicketComments(teamID int, offset time.Time, before bool, l
 		"user_platform_id", "comment_platform_id", "full_name", "username",
 		"avatar_mediafile_id", "text", "reply_to_comment_id", "is_team_reply",
 		"created_at", "marked_as_ticket", "is_deleted",
+		"COALESCE(sentiment, '')", "COALESCE(topic, '')", "reply_source", "faq_rule_id",
+		"COALESCE(team_reaction, '')",
 	}
 
 	// Создаем запрос с использованием squirrel, добавляя фильтр is_deleted = false
-	query, args, err := sq.Select(columns...).
+	builder := sq.Select(columns...).
 		From("post_comment").
-		Where(sq.Eq{"team_id": teamID}).
-		Where(fmt.Sprintf("created_at %s ?", comparator), offset).
-		Where(sq.Eq{"marked_as_ticket": true}).
+		Where(sq.Eq{"team_id": request.TeamID}).
+		Where(fmt.Sprintf("created_at %s ?", comparator), request.Offset).
 		Where(sq.Eq{"is_deleted": false}). // Добавляем фильтр, чтобы не возвращать удаленные комментарии
+		Where(sq.Eq{"held_for_review": false})
+	if request.PostUnionID != 0 {
+		builder = builder.Where(sq.Eq{"post_union_id": request.PostUnionID})
+	}
+	if request.MarkedAsTicket != nil && *request.MarkedAsTicket {
+		builder = builder.Where(sq.Eq{"marked_as_ticket": true})
+	}
+	if request.Sentiment != "" {
+		builder = builder.Where(sq.Eq{"sentiment": request.Sentiment})
+	}
+	if request.Topic != "" {
+		builder = builder.Where(sq.Eq{"topic": request.Topic})
+	}
+	if request.UserPlatformID != 0 {
+		builder = builder.Where(sq.Eq{"platform": request.Platform, "user_platform_id": request.UserPlatformID})
+	}
+	query, args, err := builder.
 		OrderBy(fmt.Sprintf("created_at %s", sortOrder)).
-		Limit(uint64(limit)).
+		Limit(uint64(request.Limit)).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения тикетов: %w", err)
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения комментариев: %w", err)
 	}
 
 	rows, err := c.db.Queryx(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении тикетов: %w", err)
+		return nil, fmt.Errorf("ошибка при получении комментариев: %w", err)
 	}
 	defer func() { _ = rows.Close() }()
 
@@ -659,8 +829,13 @@ func (c *Comment) GetTicketComments(teamID int, offset time.Time, before bool, l
 			&comment.CreatedAt,
 			&comment.MarkedAsTicket,
 			&comment.IsDeleted,
+			&comment.Sentiment,
+			&comment.Topic,
+			&comment.ReplySource,
+			&comment.FAQRuleID,
+			&comment.TeamReaction,
 		); err != nil {
-			return nil, fmt.Errorf("ошибка при сканировании тикета: %w", err)
+			return nil, fmt.Errorf("ошибка при сканировании комментария: %w", err)
 		}
 
 		// Загружаем аватар, если он есть
@@ -723,6 +898,98 @@ func (c *Comment) GetTicketComments(teamID int, offset time.Time, before bool, l
 	return comments, nil
 }
 
+func (c *Comment) GetCommentFeed(request *entity.GetCommentFeedRequest) ([]*entity.Comment, error) {
+	builder := sq.Select(
+		"id", "team_id", "post_union_id", "platform", "post_platform_id",
+		"user_platform_id", "comment_platform_id", "full_name", "username",
+		"avatar_mediafile_id", "text", "reply_to_comment_id", "is_team_reply",
+		"created_at", "marked_as_ticket", "is_deleted",
+		"COALESCE(sentiment, '')", "COALESCE(topic, '')", "reply_source", "faq_rule_id",
+		"COALESCE(team_reaction, '')",
+	).
+		From("post_comment").
+		Where(sq.Eq{"team_id": request.TeamID}).
+		Where(sq.Or{
+			sq.Lt{"created_at": request.Offset},
+			sq.And{sq.Eq{"created_at": request.Offset}, sq.Lt{"id": request.OffsetID}},
+		}).
+		Where(sq.Eq{"is_deleted": false}).
+		Where(sq.Eq{"held_for_review": false})
+	switch request.Discussion {
+	case entity.CommentDiscussionPost:
+		builder = builder.Where(sq.NotEq{"post_union_id": nil})
+	case entity.CommentDiscussionFree:
+		builder = builder.Where(sq.Eq{"post_union_id": nil})
+	}
+	if request.Platform != "" {
+		builder = builder.Where(sq.Eq{"platform": request.Platform})
+	}
+	if !request.From.IsZero() {
+		builder = builder.Where(sq.GtOrEq{"created_at": request.From})
+	}
+	if !request.To.IsZero() {
+		builder = builder.Where(sq.Lt{"created_at": request.To})
+	}
+	query, args, err := builder.
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(request.Limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения ленты комментариев: %w", err)
+	}
+
+	rows, err := c.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении ленты комментариев: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	comments := make([]*entity.Comment, 0)
+	avatarIDs := make(map[int]int)
+	for rows.Next() {
+		comment := &entity.Comment{}
+		var avatarMediafileID *int
+		if err := rows.Scan(
+			&comment.ID,
+			&comment.TeamID,
+			&comment.PostUnionID,
+			&comment.Platform,
+			&comment.PostPlatformID,
+			&comment.UserPlatformID,
+			&comment.CommentPlatformID,
+			&comment.FullName,
+			&comment.Username,
+			&avatarMediafileID,
+			&comment.Text,
+			&comment.ReplyToCommentID,
+			&comment.IsTeamReply,
+			&comment.CreatedAt,
+			&comment.MarkedAsTicket,
+			&comment.IsDeleted,
+			&comment.Sentiment,
+			&comment.Topic,
+			&comment.ReplySource,
+			&comment.FAQRuleID,
+			&comment.TeamReaction,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании комментария: %w", err)
+		}
+		if avatarMediafileID != nil {
+			avatarIDs[comment.ID] = *avatarMediafileID
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов запроса: %w", err)
+	}
+
+	if err := c.fillCommentMedia(comments, avatarIDs); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
 func (c *Comment) DeleteComment(commentID int) error {
 	// Вместо удаления комментария, помечаем его как удаленный
 	query, args, err := sq.Update("post_comment").
@@ -742,3 +1009,341 @@ func (c *Comment) DeleteComment(commentID int) error {
 
 	return nil
 }
+
+func (c *Comment) SetCommentClassification(commentID int, classification *entity.CommentClassification) error {
+	query, args, err := sq.Update("post_comment").
+		Set("sentiment", classification.Sentiment).
+		Set("topic", classification.Topic).
+		Set("classified_at", time.Now()).
+		Where(sq.Eq{"id": commentID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании SQL-запроса для сохранения классификации комментария: %w", err)
+	}
+	if _, err := c.db.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при сохранении классификации комментария: %w", err)
+	}
+	return nil
+}
+
+func (c *Comment) GetUnclassifiedCommentIDs(since, before time.Time, limit int) ([]int, error) {
+	query, args, err := sq.Select("id").
+		From("post_comment").
+		Where("classified_at IS NULL").
+		Where(sq.GtOrEq{"created_at": since}).
+		Where(sq.Lt{"created_at": before}).
+		Where(sq.Eq{"is_team_reply": false}).
+		Where(sq.Eq{"is_deleted": false}).
+		OrderBy("created_at").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения неклассифицированных комментариев: %w", err)
+	}
+	ids := make([]int, 0)
+	if err := c.db.Select(&ids, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении неклассифицированных комментариев: %w", err)
+	}
+	return ids, nil
+}
+
+func (c *Comment) GetCommentTree(request *entity.GetCommentTreeRequest) ([]*entity.CommentNode, error) {
+	depth := 0
+	if request.Depth != nil {
+		depth = *request.Depth
+	}
+	var comparator string
+	var sortOrder string
+
+	if request.Before {
+		comparator = "<"
+		sortOrder = "DESC"
+	} else {
+		comparator = ">"
+		sortOrder = "ASC"
+	}
+
+	/*
+		Этот запрос:
+		Выбирает страницу комментариев одного уровня: корневые комментарии поста или ответы на parent_id
+		Рекурсивно добавляет под каждый комментарий не больше child_limit первых ответов, пока не достигнута глубина
+		Считает для каждого узла все прямые ответы, чтобы клиент знал, есть ли что догружать
+		Уровень страницы сортируется по направлению пагинации, ответы — от старых к новым
+		Страница продолжается после пары (offset, offset_id); без offset_id — строго после времени offset
+	*/
+	query := fmt.Sprintf(`
+WITH RECURSIVE page AS (
+    SELECT id, created_at
+    FROM post_comment
+    WHERE team_id = $1
+      AND ($2 = 0 OR "post_union_id" = $2)
+      AND reply_to_comment_id = $3
+      AND NOT held_for_review
+      AND (created_at %s $4 OR (created_at = $4 AND $8 != 0 AND id %s $8))
+    ORDER BY created_at %s, id %s
+    LIMIT $5
+),
+comment_tree AS (
+    SELECT id, 0 AS depth
+    FROM page
+
+    UNION ALL
+
+    SELECT r.id, ct.depth + 1
+    FROM comment_tree ct
+    JOIN LATERAL (
+        SELECT pc.id
+        FROM post_comment pc
+        WHERE pc.reply_to_comment_id = ct.id
+          AND NOT pc.held_for_review
+        ORDER BY pc.created_at, pc.id
+        LIMIT $7
+    ) r ON true
+    WHERE ct.depth < $6
+),
+child_counts AS (
+    SELECT reply_to_comment_id, COUNT(*) AS child_count
+    FROM post_comment
+    WHERE reply_to_comment_id IN (SELECT id FROM comment_tree)
+      AND NOT held_for_review
+    GROUP BY reply_to_comment_id
+)
+SELECT
+    pc.id,
+    pc.team_id,
+    pc."post_union_id",
+    pc.platform,
+    pc.post_platform_id,
+    pc.user_platform_id,
+    pc.comment_platform_id,
+    pc.full_name,
+    pc.username,
+    pc.avatar_mediafile_id,
+    pc.text,
+    pc.reply_to_comment_id,
+    pc.is_team_reply,
+    pc.created_at,
+    pc.marked_as_ticket,
+    pc.is_deleted,
+    COALESCE(pc.sentiment, ''),
+    COALESCE(pc.topic, ''),
+    pc.reply_source,
+    pc.faq_rule_id,
+    COALESCE(pc.team_reaction, ''),
+    ct.depth,
+    COALESCE(cc.child_count, 0)
+FROM comment_tree ct
+JOIN post_comment pc ON pc.id = ct.id
+LEFT JOIN child_counts cc ON cc.reply_to_comment_id = ct.id
+ORDER BY ct.depth, CASE WHEN ct.depth = 0 THEN pc.created_at END %s, pc.created_at, pc.id
+`, comparator, comparator, sortOrder, sortOrder, sortOrder)
+
+	rows, err := c.db.Query(
+		query,
+		request.TeamID,
+		request.PostUnionID,
+		request.ParentID,
+		request.Offset,
+		request.Limit,
+		depth,
+		request.ChildLimit,
+		request.OffsetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении дерева комментариев: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	nodes := make([]*entity.CommentNode, 0)
+	avatarIDs := make(map[int]int)
+	for rows.Next() {
+		node := &entity.CommentNode{Comment: &entity.Comment{}}
+		var avatarMediafileID *int
+		if err := rows.Scan(
+			&node.ID,
+			&node.TeamID,
+			&node.PostUnionID,
+			&node.Platform,
+			&node.PostPlatformID,
+			&node.UserPlatformID,
+			&node.CommentPlatformID,
+			&node.FullName,
+			&node.Username,
+			&avatarMediafileID,
+			&node.Text,
+			&node.ReplyToCommentID,
+			&node.IsTeamReply,
+			&node.CreatedAt,
+			&node.MarkedAsTicket,
+			&node.IsDeleted,
+			&node.Sentiment,
+			&node.Topic,
+			&node.ReplySource,
+			&node.FAQRuleID,
+			&node.TeamReaction,
+			&node.Depth,
+			&node.ChildCount,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании комментария: %w", err)
+		}
+		if avatarMediafileID != nil {
+			avatarIDs[node.ID] = *avatarMediafileID
+		}
+		nodes = append(nodes, node)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при обработке результатов запроса: %w", err)
+	}
+
+	comments := make([]*entity.Comment, len(nodes))
+	for i, node := range nodes {
+		comments[i] = node.Comment
+	}
+	if err := c.fillCommentMedia(comments, avatarIDs); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// fillCommentMedia заполняет аватары и вложения комментариев двумя запросами на все комментарии.
+// avatarIDs сопоставляет ID комментария с ID файла аватара
+func (c *Comment) fillCommentMedia(comments []*entity.Comment, avatarIDs map[int]int) error {
+	if len(comments) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(comments))
+	byID := make(map[int]*entity.Comment, len(comments))
+	for _, comment := range comments {
+		comment.Attachments = make([]*entity.Upload, 0)
+		ids = append(ids, comment.ID)
+		byID[comment.ID] = comment
+	}
+
+	if len(avatarIDs) > 0 {
+		mediaFileIDs := make([]int, 0, len(avatarIDs))
+		for _, mediaFileID := range avatarIDs {
+			mediaFileIDs = append(mediaFileIDs, mediaFileID)
+		}
+		avatars := make([]*entity.Upload, 0, len(mediaFileIDs))
+		err := c.db.Select(
+			&avatars,
+			"SELECT id, file_path, file_type, uploaded_by_user_id, created_at FROM mediafile WHERE id = ANY($1)",
+			pq.Array(mediaFileIDs),
+		)
+		if err != nil {
+			return fmt.Errorf("ошибка при получении аватаров: %w", err)
+		}
+		avatarByID := make(map[int]*entity.Upload, len(avatars))
+		for _, avatar := range avatars {
+			avatarByID[avatar.ID] = avatar
+		}
+		for commentID, mediaFileID := range avatarIDs {
+			byID[commentID].AvatarMediaFile = avatarByID[mediaFileID]
+		}
+	}
+
+	rows, err := c.db.Queryx(`
+SELECT pca.comment_id, m.id, m.file_path, m.file_type, m.uploaded_by_user_id, m.created_at
+FROM post_comment_attachment pca
+JOIN mediafile m ON pca.mediafile_id = m.id
+WHERE pca.comment_id = ANY($1)`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при получении вложений: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var commentID int
+		upload := &entity.Upload{}
+		if err := rows.Scan(
+			&commentID,
+			&upload.ID,
+			&upload.FilePath,
+			&upload.FileType,
+			&upload.UserID,
+			&upload.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("ошибка при сканировании вложения: %w", err)
+		}
+		byID[commentID].Attachments = append(byID[commentID].Attachments, upload)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка при обработке результатов запроса: %w", err)
+	}
+	return nil
+}
+
+func (c *Comment) EditCommentText(commentID int, text string, editedBy *int, editedAt time.Time) error {
+	tx, err := c.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(
+		"INSERT INTO post_comment_edit (comment_id, previous_text, edited_by, edited_at) "+
+			"SELECT id, text, $2, $3 FROM post_comment WHERE id = $1",
+		commentID, editedBy, editedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении предыдущего текста комментария: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrCommentNotFound
+	}
+
+	if _, err := tx.Exec("UPDATE post_comment SET text = $1 WHERE id = $2", text, commentID); err != nil {
+		return fmt.Errorf("ошибка при обновлении текста комментария: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+	return nil
+}
+
+func (c *Comment) GetCommentEdits(commentID int) ([]*entity.CommentEdit, error) {
+	edits := make([]*entity.CommentEdit, 0)
+	err := c.db.Select(
+		&edits,
+		"SELECT id, comment_id, previous_text, edited_by, edited_at FROM post_comment_edit WHERE comment_id = $1 ORDER BY edited_at, id",
+		commentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении истории правок комментария: %w", err)
+	}
+	return edits, nil
+}
+
+func (c *Comment) SetCommentReaction(commentID int, reaction string, reactedBy *int, reactedAt time.Time) error {
+	builder := sq.Update("post_comment").
+		Where(sq.Eq{"id": commentID}).
+		PlaceholderFormat(sq.Dollar)
+	if reaction == "" {
+		builder = builder.
+			Set("team_reaction", nil).
+			Set("team_reacted_by", nil).
+			Set("team_reacted_at", nil)
+	} else {
+		builder = builder.
+			Set("team_reaction", reaction).
+			Set("team_reacted_by", reactedBy).
+			Set("team_reacted_at", reactedAt)
+	}
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании SQL-запроса для сохранения реакции: %w", err)
+	}
+
+	res, err := c.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении реакции на комментарий: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrCommentNotFound
+	}
+	return nil
+}
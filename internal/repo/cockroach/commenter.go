@@ -0,0 +1,178 @@
+package cockroach
+
+import (
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+type Commenter struct {
+	db *sqlx.DB
+}
+
+func NewCommenter(db *sqlx.DB) repo.Commenter {
+	return &Commenter{db: db}
+}
+
+func (c *Commenter) GetCommenter(teamID int, platform string, userPlatformID int) (*entity.Commenter, error) {
+	commenter := &entity.Commenter{
+		TeamID:         teamID,
+		Platform:       platform,
+		UserPlatformID: userPlatformID,
+	}
+
+	// Ответы команды не относятся к автору, даже если платформа подставила его ID
+	query, args, err := sq.Select(
+		"COUNT(*)",
+		"COUNT(DISTINCT post_union_id)",
+		"MIN(created_at)",
+		"MAX(created_at)",
+		"COUNT(*) FILTER (WHERE sentiment = 'positive')",
+		"COUNT(*) FILTER (WHERE sentiment = 'neutral')",
+		"COUNT(*) FILTER (WHERE sentiment = 'negative')",
+	).
+		From("post_comment").
+		Where(sq.Eq{"team_id": teamID, "platform": platform, "user_platform_id": userPlatformID}).
+		Where(sq.Eq{"is_team_reply": false}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения профиля автора: %w", err)
+	}
+	var firstSeenAt, lastSeenAt *time.Time
+	err = c.db.QueryRow(query, args...).Scan(
+		&commenter.CommentCount,
+		&commenter.PostCount,
+		&firstSeenAt,
+		&lastSeenAt,
+		&commenter.Sentiment.Positive,
+		&commenter.Sentiment.Neutral,
+		&commenter.Sentiment.Negative,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении профиля автора: %w", err)
+	}
+	if commenter.CommentCount == 0 {
+		return nil, repo.ErrCommenterNotFound
+	}
+	commenter.FirstSeenAt = *firstSeenAt
+	commenter.LastSeenAt = *lastSeenAt
+
+	// Имя и аватар берём из последнего комментария: автор мог их сменить
+	var avatarMediafileID *int
+	err = c.db.QueryRow(`
+SELECT full_name, username, avatar_mediafile_id
+FROM post_comment
+WHERE team_id = $1 AND platform = $2 AND user_platform_id = $3 AND NOT is_team_reply
+ORDER BY created_at DESC
+LIMIT 1`,
+		teamID, platform, userPlatformID,
+	).Scan(&commenter.FullName, &commenter.Username, &avatarMediafileID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении имени автора: %w", err)
+	}
+	if avatarMediafileID != nil {
+		commenter.AvatarMediaFile = &entity.Upload{}
+		err := c.db.Get(
+			commenter.AvatarMediaFile,
+			"SELECT id, file_path, file_type, uploaded_by_user_id, created_at FROM mediafile WHERE id = $1",
+			*avatarMediafileID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при получении аватара: %w", err)
+		}
+	}
+
+	err = c.db.Get(&commenter.TicketCount, `
+SELECT COUNT(DISTINCT tc.ticket_id)
+FROM ticket_comment tc
+JOIN post_comment pc ON pc.id = tc.comment_id
+WHERE pc.team_id = $1 AND pc.platform = $2 AND pc.user_platform_id = $3`,
+		teamID, platform, userPlatformID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подсчёте тикетов автора: %w", err)
+	}
+
+	return commenter, nil
+}
+
+func (c *Commenter) GetCommenterTags(teamID int, platform string, userPlatformID int) ([]string, error) {
+	tags := make([]string, 0)
+	err := c.db.Select(
+		&tags,
+		"SELECT tag FROM commenter_tag WHERE team_id = $1 AND platform = $2 AND user_platform_id = $3 ORDER BY tag",
+		teamID, platform, userPlatformID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении меток автора: %w", err)
+	}
+	return tags, nil
+}
+
+func (c *Commenter) SetCommenterTags(teamID int, platform string, userPlatformID int, tags []string) error {
+	tx, err := c.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec(
+		"DELETE FROM commenter_tag WHERE team_id = $1 AND platform = $2 AND user_platform_id = $3",
+		teamID, platform, userPlatformID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении меток автора: %w", err)
+	}
+	for _, tag := range tags {
+		_, err := tx.Exec(
+			"INSERT INTO commenter_tag (team_id, platform, user_platform_id, tag) VALUES ($1, $2, $3, $4) "+
+				"ON CONFLICT (team_id, platform, user_platform_id, tag) DO NOTHING",
+			teamID, platform, userPlatformID, tag,
+		)
+		if err != nil {
+			return fmt.Errorf("ошибка при добавлении метки автора: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+	return nil
+}
+
+func (c *Commenter) AddCommenterNote(note *entity.CommenterNote) (int, error) {
+	query, args, err := sq.Insert("commenter_note").
+		Columns("team_id", "platform", "user_platform_id", "user_id", "text", "created_at").
+		Values(note.TeamID, note.Platform, note.UserPlatformID, note.UserID, note.Text, note.CreatedAt).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при формировании SQL-запроса для добавления заметки об авторе: %w", err)
+	}
+	var noteID int
+	if err := c.db.QueryRow(query, args...).Scan(&noteID); err != nil {
+		return 0, fmt.Errorf("ошибка при добавлении заметки об авторе: %w", err)
+	}
+	return noteID, nil
+}
+
+func (c *Commenter) GetCommenterNotes(teamID int, platform string, userPlatformID int) ([]*entity.CommenterNote, error) {
+	notes := make([]*entity.CommenterNote, 0)
+	err := c.db.Select(&notes, `
+SELECT id, team_id, platform, user_platform_id, user_id, text, created_at
+FROM commenter_note
+WHERE team_id = $1 AND platform = $2 AND user_platform_id = $3
+ORDER BY created_at, id`,
+		teamID, platform, userPlatformID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении заметок об авторе: %w", err)
+	}
+	return notes, nil
+}
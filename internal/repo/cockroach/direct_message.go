@@ -0,0 +1,349 @@
+package cockroach
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type DirectMessage struct {
+	db *sqlx.DB
+}
+
+func NewDirectMessage(db *sqlx.DB) repo.DirectMessage {
+	return &DirectMessage{db: db}
+}
+
+// conversationRow переписка вместе с ID аватара собеседника
+type conversationRow struct {
+	entity.DMConversation
+	AvatarMediaFileID *int `db:"avatar_mediafile_id"`
+}
+
+func (d *DirectMessage) selectConversations() sq.SelectBuilder {
+	return sq.Select(
+		"id", "team_id", "platform", "peer_id", "full_name", "username", "avatar_mediafile_id",
+		"unread_count", "created_at", "last_message_at",
+	).
+		From("dm_conversation").
+		PlaceholderFormat(sq.Dollar)
+}
+
+func (d *DirectMessage) PutConversation(conversation *entity.DMConversation) (int, error) {
+	var avatarMediaFileID *int
+	if conversation.AvatarMediaFile != nil {
+		avatarMediaFileID = &conversation.AvatarMediaFile.ID
+	}
+	// имя собеседника могло измениться, аватар обновляется, только если его удалось загрузить.
+	// last_message_at не трогаем: пустая переписка не должна подниматься в списке
+	var conversationID int
+	err := d.db.QueryRow(`
+INSERT INTO dm_conversation (team_id, platform, peer_id, full_name, username, avatar_mediafile_id, created_at, opened_at, last_message_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7, $7)
+ON CONFLICT (team_id, platform, peer_id) DO UPDATE SET
+    opened_at = excluded.opened_at,
+    full_name = excluded.full_name,
+    username = excluded.username,
+    avatar_mediafile_id = COALESCE(excluded.avatar_mediafile_id, dm_conversation.avatar_mediafile_id)
+RETURNING id`,
+		conversation.TeamID,
+		conversation.Platform,
+		conversation.PeerID,
+		conversation.FullName,
+		conversation.Username,
+		avatarMediaFileID,
+		conversation.CreatedAt,
+	).Scan(&conversationID)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при сохранении переписки: %w", err)
+	}
+	return conversationID, nil
+}
+
+func (d *DirectMessage) getConversation(builder sq.SelectBuilder) (*entity.DMConversation, error) {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения переписки: %w", err)
+	}
+	row := &conversationRow{}
+	err = d.db.Get(row, query, args...)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, repo.ErrDMConversationNotFound
+	case err != nil:
+		return nil, fmt.Errorf("ошибка при получении переписки: %w", err)
+	}
+	conversations := []*entity.DMConversation{&row.DMConversation}
+	if err := d.fillAvatars(conversations, []*conversationRow{row}); err != nil {
+		return nil, err
+	}
+	return conversations[0], nil
+}
+
+func (d *DirectMessage) GetConversation(conversationID int) (*entity.DMConversation, error) {
+	return d.getConversation(d.selectConversations().Where(sq.Eq{"id": conversationID}))
+}
+
+func (d *DirectMessage) GetLastConversationByPeer(platform string, peerID int64) (*entity.DMConversation, error) {
+	return d.getConversation(
+		d.selectConversations().
+			Where(sq.Eq{"platform": platform, "peer_id": peerID}).
+			OrderBy("opened_at DESC", "id DESC").
+			Limit(1),
+	)
+}
+
+func (d *DirectMessage) GetConversations(request *entity.GetDMConversationsRequest) ([]*entity.DMConversation, error) {
+	builder := d.selectConversations().
+		Where(sq.Eq{"team_id": request.TeamID}).
+		Where(sq.Lt{"last_message_at": request.Offset})
+	if request.Platform != "" {
+		builder = builder.Where(sq.Eq{"platform": request.Platform})
+	}
+	query, args, err := builder.
+		OrderBy("last_message_at DESC", "id DESC").
+		Limit(uint64(request.Limit)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения переписок: %w", err)
+	}
+
+	rows := make([]*conversationRow, 0)
+	if err := d.db.Select(&rows, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении переписок: %w", err)
+	}
+	conversations := make([]*entity.DMConversation, 0, len(rows))
+	for _, row := range rows {
+		conversations = append(conversations, &row.DMConversation)
+	}
+	if err := d.fillAvatars(conversations, rows); err != nil {
+		return nil, err
+	}
+	if err := d.fillLastMessages(conversations); err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+// fillAvatars загружает аватары собеседников одним запросом
+func (d *DirectMessage) fillAvatars(conversations []*entity.DMConversation, rows []*conversationRow) error {
+	mediaFileIDs := make([]int, 0, len(rows))
+	for _, row := range rows {
+		if row.AvatarMediaFileID != nil {
+			mediaFileIDs = append(mediaFileIDs, *row.AvatarMediaFileID)
+		}
+	}
+	if len(mediaFileIDs) == 0 {
+		return nil
+	}
+	avatars := make([]*entity.Upload, 0, len(mediaFileIDs))
+	err := d.db.Select(
+		&avatars,
+		"SELECT id, file_path, file_type, uploaded_by_user_id, created_at FROM mediafile WHERE id = ANY($1)",
+		pq.Array(mediaFileIDs),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при получении аватаров: %w", err)
+	}
+	avatarByID := make(map[int]*entity.Upload, len(avatars))
+	for _, avatar := range avatars {
+		avatarByID[avatar.ID] = avatar
+	}
+	for i, row := range rows {
+		if row.AvatarMediaFileID != nil {
+			conversations[i].AvatarMediaFile = avatarByID[*row.AvatarMediaFileID]
+		}
+	}
+	return nil
+}
+
+// fillLastMessages заполняет последнее сообщение каждой переписки
+func (d *DirectMessage) fillLastMessages(conversations []*entity.DMConversation) error {
+	if len(conversations) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(conversations))
+	byID := make(map[int]*entity.DMConversation, len(conversations))
+	for _, conversation := range conversations {
+		ids = append(ids, conversation.ID)
+		byID[conversation.ID] = conversation
+	}
+	messages := make([]*entity.DirectMessage, 0, len(conversations))
+	err := d.db.Select(&messages, `
+SELECT DISTINCT ON (conversation_id)
+    id, conversation_id, message_platform_id, text, is_team_reply, sent_by, created_at
+FROM dm_message
+WHERE conversation_id = ANY($1)
+ORDER BY conversation_id, created_at DESC, id DESC`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при получении последних сообщений: %w", err)
+	}
+	if err := d.fillAttachments(messages); err != nil {
+		return err
+	}
+	for _, message := range messages {
+		byID[message.ConversationID].LastMessage = message
+	}
+	return nil
+}
+
+func (d *DirectMessage) AddMessage(message *entity.DirectMessage) (int, bool, error) {
+	query, args, err := sq.Insert("dm_message").
+		Columns("conversation_id", "message_platform_id", "text", "is_team_reply", "sent_by", "created_at").
+		Values(
+			message.ConversationID,
+			message.MessagePlatformID,
+			message.Text,
+			message.IsTeamReply,
+			message.SentBy,
+			message.CreatedAt,
+		).
+		Suffix("ON CONFLICT DO NOTHING RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, false, fmt.Errorf("ошибка при формировании SQL-запроса для сохранения сообщения: %w", err)
+	}
+
+	tx, err := d.db.Beginx()
+	if err != nil {
+		return 0, false, fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var messageID int
+	err = tx.QueryRow(query, args...).Scan(&messageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// событие пришло повторно: сообщение, вложения и счётчик непрочитанных уже сохранены
+		err = tx.QueryRow(
+			"SELECT id FROM dm_message WHERE conversation_id = $1 AND message_platform_id = $2",
+			message.ConversationID, message.MessagePlatformID,
+		).Scan(&messageID)
+		if err != nil {
+			return 0, false, fmt.Errorf("ошибка при получении сохранённого сообщения: %w", err)
+		}
+		return messageID, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("ошибка при сохранении сообщения: %w", err)
+	}
+	for _, attachment := range message.Attachments {
+		_, err := tx.Exec(
+			"INSERT INTO dm_message_attachment (message_id, mediafile_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+			messageID, attachment.ID,
+		)
+		if err != nil {
+			return 0, false, fmt.Errorf("ошибка при сохранении вложения сообщения: %w", err)
+		}
+	}
+
+	// ответ команды означает, что переписку прочитали
+	unread := "unread_count + 1"
+	if message.IsTeamReply {
+		unread = "0"
+	}
+	res, err := tx.Exec(
+		"UPDATE dm_conversation SET last_message_at = GREATEST(last_message_at, $1), unread_count = "+unread+" WHERE id = $2",
+		message.CreatedAt, message.ConversationID,
+	)
+	if err != nil {
+		return 0, false, fmt.Errorf("ошибка при обновлении переписки: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return 0, false, repo.ErrDMConversationNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, false, fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+	return messageID, true, nil
+}
+
+func (d *DirectMessage) GetMessages(request *entity.GetDirectMessagesRequest) ([]*entity.DirectMessage, error) {
+	query, args, err := sq.Select(
+		"id", "conversation_id", "message_platform_id", "text", "is_team_reply", "sent_by", "created_at",
+	).
+		From("dm_message").
+		Where(sq.Eq{"conversation_id": request.ConversationID}).
+		Where(sq.Lt{"created_at": request.Offset}).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(request.Limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения сообщений: %w", err)
+	}
+
+	messages := make([]*entity.DirectMessage, 0)
+	if err := d.db.Select(&messages, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении сообщений: %w", err)
+	}
+	if err := d.fillAttachments(messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// fillAttachments загружает вложения сообщений одним запросом
+func (d *DirectMessage) fillAttachments(messages []*entity.DirectMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(messages))
+	byID := make(map[int]*entity.DirectMessage, len(messages))
+	for _, message := range messages {
+		message.Attachments = make([]*entity.Upload, 0)
+		ids = append(ids, message.ID)
+		byID[message.ID] = message
+	}
+
+	rows, err := d.db.Queryx(`
+SELECT dma.message_id, m.id, m.file_path, m.file_type, m.uploaded_by_user_id, m.created_at
+FROM dm_message_attachment dma
+JOIN mediafile m ON dma.mediafile_id = m.id
+WHERE dma.message_id = ANY($1)`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при получении вложений сообщений: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var messageID int
+		upload := &entity.Upload{}
+		if err := rows.Scan(
+			&messageID,
+			&upload.ID,
+			&upload.FilePath,
+			&upload.FileType,
+			&upload.UserID,
+			&upload.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("ошибка при сканировании вложения сообщения: %w", err)
+		}
+		byID[messageID].Attachments = append(byID[messageID].Attachments, upload)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка при получении вложений сообщений: %w", err)
+	}
+	return nil
+}
+
+func (d *DirectMessage) ResetUnread(conversationID int) error {
+	res, err := d.db.Exec("UPDATE dm_conversation SET unread_count = 0 WHERE id = $1", conversationID)
+	if err != nil {
+		return fmt.Errorf("ошибка при сбросе непрочитанных сообщений: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrDMConversationNotFound
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package cockroach
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type FAQ struct {
+	db *sqlx.DB
+}
+
+func NewFAQ(db *sqlx.DB) repo.FAQ {
+	return &FAQ{db: db}
+}
+
+func (f *FAQ) selectFAQRules() sq.SelectBuilder {
+	return sq.Select(
+		"id", "team_id", "name", "trigger_type", "keywords", "pattern", "intent",
+		"response_text", "cooldown_seconds", "enabled", "created_at",
+	).
+		From("faq_rule").
+		PlaceholderFormat(sq.Dollar)
+}
+
+func scanFAQRule(row interface{ Scan(...any) error }) (*entity.FAQRule, error) {
+	rule := &entity.FAQRule{}
+	err := row.Scan(
+		&rule.ID,
+		&rule.TeamID,
+		&rule.Name,
+		&rule.Trigger,
+		pq.Array(&rule.Keywords),
+		&rule.Pattern,
+		&rule.Intent,
+		&rule.ResponseText,
+		&rule.CooldownSeconds,
+		&rule.Enabled,
+		&rule.CreatedAt,
+	)
+	return rule, err
+}
+
+func (f *FAQ) GetFAQRules(teamID int) ([]*entity.FAQRule, error) {
+	query, args, err := f.selectFAQRules().
+		Where(sq.Eq{"team_id": teamID}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения правил автоответов: %w", err)
+	}
+
+	rows, err := f.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении правил автоответов: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	rules := make([]*entity.FAQRule, 0)
+	for rows.Next() {
+		rule, err := scanFAQRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании правила автоответа: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при получении правил автоответов: %w", err)
+	}
+	return rules, nil
+}
+
+func (f *FAQ) GetFAQRule(ruleID int) (*entity.FAQRule, error) {
+	query, args, err := f.selectFAQRules().Where(sq.Eq{"id": ruleID}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения правила автоответа: %w", err)
+	}
+	rule, err := scanFAQRule(f.db.QueryRow(query, args...))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, repo.ErrFAQRuleNotFound
+	case err != nil:
+		return nil, fmt.Errorf("ошибка при получении правила автоответа: %w", err)
+	}
+	return rule, nil
+}
+
+func (f *FAQ) AddFAQRule(rule *entity.FAQRule) (int, error) {
+	query, args, err := sq.Insert("faq_rule").
+		Columns(
+			"team_id", "name", "trigger_type", "keywords", "pattern", "intent",
+			"response_text", "cooldown_seconds", "enabled",
+		).
+		Values(
+			rule.TeamID,
+			rule.Name,
+			rule.Trigger,
+			pq.Array(rule.Keywords),
+			rule.Pattern,
+			rule.Intent,
+			rule.ResponseText,
+			rule.CooldownSeconds,
+			rule.Enabled,
+		).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при формировании SQL-запроса для добавления правила автоответа: %w", err)
+	}
+	var ruleID int
+	if err := f.db.QueryRow(query, args...).Scan(&ruleID); err != nil {
+		return 0, fmt.Errorf("ошибка при добавлении правила автоответа: %w", err)
+	}
+	return ruleID, nil
+}
+
+func (f *FAQ) EditFAQRule(rule *entity.FAQRule) error {
+	query, args, err := sq.Update("faq_rule").
+		Set("name", rule.Name).
+		Set("trigger_type", rule.Trigger).
+		Set("keywords", pq.Array(rule.Keywords)).
+		Set("pattern", rule.Pattern).
+		Set("intent", rule.Intent).
+		Set("response_text", rule.ResponseText).
+		Set("cooldown_seconds", rule.CooldownSeconds).
+		Set("enabled", rule.Enabled).
+		Where(sq.Eq{"id": rule.ID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании SQL-запроса для изменения правила автоответа: %w", err)
+	}
+	res, err := f.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении правила автоответа: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrFAQRuleNotFound
+	}
+	return nil
+}
+
+func (f *FAQ) DeleteFAQRule(ruleID int) error {
+	res, err := f.db.Exec("DELETE FROM faq_rule WHERE id = $1", ruleID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении правила автоответа: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrFAQRuleNotFound
+	}
+	return nil
+}
+
+func (f *FAQ) GetFAQEnabled(teamID int) (bool, error) {
+	var enabled bool
+	err := f.db.Get(&enabled, "SELECT faq_enabled FROM team WHERE id = $1", teamID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return false, repo.ErrTeamNotFound
+	case err != nil:
+		return false, fmt.Errorf("ошибка при получении настроек автоответов: %w", err)
+	}
+	return enabled, nil
+}
+
+func (f *FAQ) SetFAQEnabled(teamID int, enabled bool) error {
+	res, err := f.db.Exec("UPDATE team SET faq_enabled = $1 WHERE id = $2", enabled, teamID)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении настроек автоответов: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrTeamNotFound
+	}
+	return nil
+}
+
+func (f *FAQ) HasRecentFAQReply(ruleID int, postUnionID *int, since time.Time) (bool, error) {
+	query, args, err := sq.Select("1").
+		From("post_comment").
+		Where(sq.Eq{"faq_rule_id": ruleID}).
+		Where(sq.Eq{"post_union_id": postUnionID}).
+		Where(sq.GtOrEq{"created_at": since}).
+		Limit(1).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("ошибка при формировании SQL-запроса для проверки автоответов: %w", err)
+	}
+	var exists bool
+	if err := f.db.Get(&exists, query, args...); err != nil {
+		return false, fmt.Errorf("ошибка при проверке автоответов: %w", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,193 @@
+package cockroach
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type MediaLibrary struct {
+	db *sqlx.DB
+}
+
+func NewMediaLibrary(db *sqlx.DB) repo.MediaLibrary {
+	return &MediaLibrary{db: db}
+}
+
+func (m *MediaLibrary) selectMediaFiles() sq.SelectBuilder {
+	return sq.Select(
+		"m.id", "COALESCE(m.team_id, 0)", "m.file_path", "m.file_type", "m.display_name", "m.tags",
+		"m.uploaded_by_user_id", "m.created_at", "m.width", "m.height", "m.duration_ms",
+		"(SELECT COUNT(*) FROM post_union_mediafile pum WHERE pum.mediafile_id = m.id)",
+	).
+		From("mediafile m").
+		PlaceholderFormat(sq.Dollar)
+}
+
+func scanMediaFile(row interface{ Scan(...any) error }) (*entity.MediaFile, error) {
+	file := &entity.MediaFile{}
+	err := row.Scan(
+		&file.ID,
+		&file.TeamID,
+		&file.FilePath,
+		&file.FileType,
+		&file.DisplayName,
+		pq.Array(&file.Tags),
+		&file.UploadedByUserID,
+		&file.CreatedAt,
+		&file.Width,
+		&file.Height,
+		&file.DurationMs,
+		&file.PostsCount,
+	)
+	return file, err
+}
+
+func (m *MediaLibrary) GetMediaFiles(request *entity.GetMediaLibraryRequest) ([]*entity.MediaFile, error) {
+	builder := m.selectMediaFiles().
+		Where(sq.Eq{"m.team_id": request.TeamID}).
+		// производные версии показываются вместе с исходным файлом, а не отдельно
+		Where("NOT EXISTS (SELECT 1 FROM mediafile_rendition r WHERE r.rendition_mediafile_id = m.id)")
+
+	if request.FileType != "" {
+		builder = builder.Where(sq.Eq{"m.file_type": request.FileType})
+	}
+	if !request.From.IsZero() {
+		builder = builder.Where(sq.GtOrEq{"m.created_at": request.From})
+	}
+	if !request.To.IsZero() {
+		builder = builder.Where(sq.Lt{"m.created_at": request.To})
+	}
+	if request.UploaderID != 0 {
+		builder = builder.Where(sq.Eq{"m.uploaded_by_user_id": request.UploaderID})
+	}
+	if request.Used != nil {
+		used := "EXISTS (SELECT 1 FROM post_union_mediafile pum WHERE pum.mediafile_id = m.id)"
+		if *request.Used {
+			builder = builder.Where(used)
+		} else {
+			builder = builder.Where("NOT " + used)
+		}
+	}
+	if request.Tag != "" {
+		builder = builder.Where("? = ANY(m.tags)", request.Tag)
+	}
+
+	query, args, err := builder.
+		OrderBy("m.created_at DESC", "m.id DESC").
+		Limit(uint64(request.Limit)).
+		Offset(uint64(request.Offset)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения медиатеки: %w", err)
+	}
+
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении медиатеки: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	files := make([]*entity.MediaFile, 0)
+	for rows.Next() {
+		file, err := scanMediaFile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании медиафайла: %w", err)
+		}
+		files = append(files, file)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при получении медиатеки: %w", err)
+	}
+	return files, nil
+}
+
+func (m *MediaLibrary) GetMediaFile(id int) (*entity.MediaFile, error) {
+	query, args, err := m.selectMediaFiles().Where(sq.Eq{"m.id": id}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения медиафайла: %w", err)
+	}
+	file, err := scanMediaFile(m.db.QueryRow(query, args...))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, repo.ErrMediaFileNotFound
+	case err != nil:
+		return nil, fmt.Errorf("ошибка при получении медиафайла: %w", err)
+	}
+	return file, nil
+}
+
+func (m *MediaLibrary) EditMediaFile(mediaFile *entity.MediaFile) error {
+	query, args, err := sq.Update("mediafile").
+		Set("display_name", mediaFile.DisplayName).
+		Set("tags", pq.Array(mediaFile.Tags)).
+		Where(sq.Eq{"id": mediaFile.ID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании SQL-запроса для изменения медиафайла: %w", err)
+	}
+	res, err := m.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении медиафайла: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrMediaFileNotFound
+	}
+	return nil
+}
+
+func (m *MediaLibrary) CanUserAccessMediaFile(userID, mediaFileID int) (bool, error) {
+	// Для производной версии права определяются исходным файлом
+	query := `
+		WITH file AS (
+			SELECT m.id, m.uploaded_by_user_id, m.team_id
+			FROM mediafile m
+			WHERE m.id = COALESCE(
+				(SELECT r.mediafile_id FROM mediafile_rendition r WHERE r.rendition_mediafile_id = $1),
+				$1
+			)
+		), user_teams AS (
+			SELECT team_id FROM team_user_role WHERE user_id = $2
+		)
+		SELECT EXISTS (
+			SELECT 1 FROM file
+			WHERE file.uploaded_by_user_id = $2
+				OR file.team_id IN (SELECT team_id FROM user_teams)
+				OR EXISTS (
+					SELECT 1 FROM post_union_mediafile pum
+					JOIN post_union pu ON pu.id = pum.post_union_id
+					WHERE pum.mediafile_id = file.id AND pu.team_id IN (SELECT team_id FROM user_teams)
+				)
+				OR EXISTS (
+					SELECT 1 FROM post_comment_attachment pca
+					JOIN post_comment pc ON pc.id = pca.comment_id
+					WHERE pca.mediafile_id = file.id AND pc.team_id IN (SELECT team_id FROM user_teams)
+				)
+				OR EXISTS (
+					SELECT 1 FROM post_comment pc
+					WHERE pc.avatar_mediafile_id = file.id AND pc.team_id IN (SELECT team_id FROM user_teams)
+				)
+				OR EXISTS (
+					SELECT 1 FROM dm_message_attachment dma
+					JOIN dm_message dm ON dm.id = dma.message_id
+					JOIN dm_conversation dc ON dc.id = dm.conversation_id
+					WHERE dma.mediafile_id = file.id AND dc.team_id IN (SELECT team_id FROM user_teams)
+				)
+				OR EXISTS (
+					SELECT 1 FROM dm_conversation dc
+					WHERE dc.avatar_mediafile_id = file.id AND dc.team_id IN (SELECT team_id FROM user_teams)
+				)
+		)`
+	var allowed bool
+	if err := m.db.QueryRow(query, mediaFileID, userID).Scan(&allowed); err != nil {
+		return false, fmt.Errorf("ошибка при проверке доступа к медиафайлу: %w", err)
+	}
+	return allowed, nil
+}
@@ -0,0 +1,290 @@
+package cockroach
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Moderation struct {
+	db *sqlx.DB
+}
+
+func NewModeration(db *sqlx.DB) repo.Moderation {
+	return &Moderation{db: db}
+}
+
+func (m *Moderation) selectModerationRules() sq.SelectBuilder {
+	return sq.Select(
+		"id", "team_id", "name", "type", "keywords", "pattern",
+		"threshold", "window_seconds", "action", "enabled", "created_at",
+	).
+		From("moderation_rule").
+		PlaceholderFormat(sq.Dollar)
+}
+
+func scanModerationRule(row interface{ Scan(...any) error }) (*entity.ModerationRule, error) {
+	rule := &entity.ModerationRule{}
+	err := row.Scan(
+		&rule.ID,
+		&rule.TeamID,
+		&rule.Name,
+		&rule.Type,
+		pq.Array(&rule.Keywords),
+		&rule.Pattern,
+		&rule.Threshold,
+		&rule.WindowSeconds,
+		&rule.Action,
+		&rule.Enabled,
+		&rule.CreatedAt,
+	)
+	return rule, err
+}
+
+func (m *Moderation) GetModerationRules(teamID int) ([]*entity.ModerationRule, error) {
+	query, args, err := m.selectModerationRules().
+		Where(sq.Eq{"team_id": teamID}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения правил модерации: %w", err)
+	}
+
+	rows, err := m.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении правил модерации: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	rules := make([]*entity.ModerationRule, 0)
+	for rows.Next() {
+		rule, err := scanModerationRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании правила модерации: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при получении правил модерации: %w", err)
+	}
+	return rules, nil
+}
+
+func (m *Moderation) GetModerationRule(ruleID int) (*entity.ModerationRule, error) {
+	query, args, err := m.selectModerationRules().Where(sq.Eq{"id": ruleID}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения правила модерации: %w", err)
+	}
+	rule, err := scanModerationRule(m.db.QueryRow(query, args...))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, repo.ErrModerationRuleNotFound
+	case err != nil:
+		return nil, fmt.Errorf("ошибка при получении правила модерации: %w", err)
+	}
+	return rule, nil
+}
+
+func (m *Moderation) AddModerationRule(rule *entity.ModerationRule) (int, error) {
+	query, args, err := sq.Insert("moderation_rule").
+		Columns(
+			"team_id", "name", "type", "keywords", "pattern",
+			"threshold", "window_seconds", "action", "enabled",
+		).
+		Values(
+			rule.TeamID,
+			rule.Name,
+			rule.Type,
+			pq.Array(rule.Keywords),
+			rule.Pattern,
+			rule.Threshold,
+			rule.WindowSeconds,
+			rule.Action,
+			rule.Enabled,
+		).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при формировании SQL-запроса для добавления правила модерации: %w", err)
+	}
+	var ruleID int
+	if err := m.db.QueryRow(query, args...).Scan(&ruleID); err != nil {
+		return 0, fmt.Errorf("ошибка при добавлении правила модерации: %w", err)
+	}
+	return ruleID, nil
+}
+
+func (m *Moderation) EditModerationRule(rule *entity.ModerationRule) error {
+	query, args, err := sq.Update("moderation_rule").
+		Set("name", rule.Name).
+		Set("type", rule.Type).
+		Set("keywords", pq.Array(rule.Keywords)).
+		Set("pattern", rule.Pattern).
+		Set("threshold", rule.Threshold).
+		Set("window_seconds", rule.WindowSeconds).
+		Set("action", rule.Action).
+		Set("enabled", rule.Enabled).
+		Where(sq.Eq{"id": rule.ID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании SQL-запроса для изменения правила модерации: %w", err)
+	}
+	res, err := m.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении правила модерации: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrModerationRuleNotFound
+	}
+	return nil
+}
+
+func (m *Moderation) DeleteModerationRule(ruleID int) error {
+	query, args, err := sq.Delete("moderation_rule").
+		Where(sq.Eq{"id": ruleID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании SQL-запроса для удаления правила модерации: %w", err)
+	}
+	res, err := m.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении правила модерации: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrModerationRuleNotFound
+	}
+	return nil
+}
+
+func (m *Moderation) AddModerationLog(entry *entity.ModerationLogEntry) error {
+	query, args, err := sq.Insert("moderation_log").
+		Columns(
+			"team_id", "rule_id", "comment_id", "platform", "user_platform_id",
+			"action", "reason", "succeeded", "created_at",
+		).
+		Values(
+			entry.TeamID,
+			entry.RuleID,
+			entry.CommentID,
+			entry.Platform,
+			entry.UserPlatformID,
+			entry.Action,
+			entry.Reason,
+			entry.Succeeded,
+			entry.CreatedAt,
+		).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании SQL-запроса для записи в журнал модерации: %w", err)
+	}
+	if _, err := m.db.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при записи в журнал модерации: %w", err)
+	}
+	return nil
+}
+
+func (m *Moderation) GetModerationLog(teamID int, offset time.Time, limit int) ([]*entity.ModerationLogEntry, error) {
+	query, args, err := sq.Select(
+		"id", "team_id", "rule_id", "comment_id", "platform", "user_platform_id",
+		"action", "reason", "succeeded", "created_at",
+	).
+		From("moderation_log").
+		Where(sq.Eq{"team_id": teamID}).
+		Where(sq.Lt{"created_at": offset}).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения журнала модерации: %w", err)
+	}
+
+	entries := make([]*entity.ModerationLogEntry, 0)
+	if err := m.db.Select(&entries, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении журнала модерации: %w", err)
+	}
+	return entries, nil
+}
+
+func (m *Moderation) countComments(builder sq.SelectBuilder) (int, error) {
+	query, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при формировании SQL-запроса для подсчёта комментариев: %w", err)
+	}
+	var count int
+	if err := m.db.QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка при подсчёте комментариев: %w", err)
+	}
+	return count, nil
+}
+
+func (m *Moderation) selectUserComments(teamID int, platform string, userPlatformID int, since time.Time) sq.SelectBuilder {
+	// удалённые комментарии тоже учитываются: иначе удаление спама сбрасывало бы счётчик флуда
+	return sq.Select("COUNT(*)").
+		From("post_comment").
+		Where(sq.Eq{"team_id": teamID}).
+		Where(sq.Eq{"platform": platform}).
+		Where(sq.Eq{"user_platform_id": userPlatformID}).
+		Where(sq.Eq{"is_team_reply": false}).
+		Where(sq.GtOrEq{"created_at": since})
+}
+
+func (m *Moderation) CountUserComments(teamID int, platform string, userPlatformID int, since time.Time) (int, error) {
+	return m.countComments(m.selectUserComments(teamID, platform, userPlatformID, since))
+}
+
+func (m *Moderation) CountUserRepeats(teamID int, platform string, userPlatformID int, text string, since time.Time) (int, error) {
+	return m.countComments(m.selectUserComments(teamID, platform, userPlatformID, since).Where(sq.Eq{"text": text}))
+}
+
+func (m *Moderation) GetHeldCommentIDs(teamID int, limit int) ([]int, error) {
+	query, args, err := sq.Select("id").
+		From("post_comment").
+		Where(sq.Eq{"team_id": teamID}).
+		Where(sq.Eq{"held_for_review": true}).
+		Where(sq.Eq{"is_deleted": false}).
+		OrderBy("created_at", "id").
+		Limit(uint64(limit)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения отложенных комментариев: %w", err)
+	}
+	ids := make([]int, 0)
+	if err := m.db.Select(&ids, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении отложенных комментариев: %w", err)
+	}
+	return ids, nil
+}
+
+func (m *Moderation) ReleaseHeldComment(commentID int) error {
+	query, args, err := sq.Update("post_comment").
+		Set("held_for_review", false).
+		Where(sq.Eq{"id": commentID}).
+		Where(sq.Eq{"held_for_review": true}).
+		Where(sq.Eq{"is_deleted": false}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании SQL-запроса для публикации комментария: %w", err)
+	}
+	res, err := m.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при публикации комментария: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrHeldCommentNotFound
+	}
+	return nil
+}
@@ -436,6 +436,33 @@ func (p *PostDB) GetPostPlatformByPost(platformID int, channelID int, platform s
 	return &postPlatform, nil
 }
 
+func (p *PostDB) GetRecentPostPlatforms(channelID int, platform string, since time.Time) ([]*entity.PostPlatform, error) {
+	var channelColumn string
+	switch platform {
+	case "tg":
+		channelColumn = "tg_channel_id"
+	case "vk":
+		channelColumn = "vk_channel_id"
+	default:
+		return nil, errors.New("unsupported platform")
+	}
+
+	// запланированные посты публикуются в pub_datetime, остальные сразу после создания
+	query := fmt.Sprintf(`
+		SELECT pp.id, pp.post_union_id, pp.post_id, pp.platform, pp.tg_channel_id, pp.vk_channel_id
+		FROM post_platform pp
+		JOIN post_union pu ON pu.id = pp.post_union_id
+		WHERE pp.%s = $1 AND pp.platform = $2 AND COALESCE(pu.pub_datetime, pu.created_at) >= $3
+		ORDER BY pp.id
+	`, channelColumn)
+	var postPlatforms []*entity.PostPlatform
+	err := p.db.Select(&postPlatforms, query, channelID, platform, since)
+	if err != nil {
+		return nil, err
+	}
+	return postPlatforms, nil
+}
+
 func (p *PostDB) AddPostPlatform(postPlatform *entity.PostPlatform) (int, error) {
 	var query string
 	var postPlatformID int
@@ -0,0 +1,114 @@
+package cockroach
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+type StorageQuota struct {
+	db *sqlx.DB
+}
+
+func NewStorageQuota(db *sqlx.DB) repo.StorageQuota {
+	return &StorageQuota{db: db}
+}
+
+func (s *StorageQuota) GetTeamStorageQuota(teamID int) (*entity.TeamStorageQuota, error) {
+	query, args, err := sq.Select("team_id", "max_storage_bytes", "max_files").
+		From("team_storage_quota").
+		Where(sq.Eq{"team_id": teamID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения квоты команды: %w", err)
+	}
+	quota := &entity.TeamStorageQuota{}
+	err = s.db.Get(quota, query, args...)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, repo.ErrStorageQuotaNotFound
+	case err != nil:
+		return nil, fmt.Errorf("ошибка при получении квоты команды: %w", err)
+	}
+	return quota, nil
+}
+
+func (s *StorageQuota) GetTeamStorageUsage(teamID int) (*entity.TeamStorageUsage, error) {
+	// Каждый файл относится к одной категории с наименьшим приоритетом:
+	// 0 - посты, 1 - комментарии, 2 - аватары, 3 - медиатека.
+	// В квоту входят файлы постов и медиатеки, даже если они попали в другую категорию
+	query := `
+		WITH team_files AS (
+			SELECT pum.mediafile_id AS id, 0 AS priority
+			FROM post_union_mediafile pum
+			JOIN post_union pu ON pu.id = pum.post_union_id
+			WHERE pu.team_id = $1
+			UNION ALL
+			SELECT pca.mediafile_id, 1
+			FROM post_comment_attachment pca
+			JOIN post_comment pc ON pc.id = pca.comment_id
+			WHERE pc.team_id = $1
+			UNION ALL
+			SELECT pc.avatar_mediafile_id, 2
+			FROM post_comment pc
+			WHERE pc.team_id = $1 AND pc.avatar_mediafile_id IS NOT NULL
+			UNION ALL
+			SELECT m.id, 3
+			FROM mediafile m
+			WHERE m.team_id = $1
+				AND NOT EXISTS (SELECT 1 FROM mediafile_rendition r WHERE r.rendition_mediafile_id = m.id)
+		), categorized AS (
+			SELECT id, MIN(priority) AS priority, BOOL_OR(priority IN (0, 3)) AS counted
+			FROM team_files
+			GROUP BY id
+		)
+		SELECT
+			c.priority,
+			c.counted,
+			COUNT(*),
+			COALESCE(SUM(m.size + COALESCE(
+				(SELECT SUM(r.size) FROM mediafile_rendition r WHERE r.mediafile_id = m.id), 0
+			)), 0)
+		FROM categorized c
+		JOIN mediafile m ON m.id = c.id
+		GROUP BY c.priority, c.counted`
+	rows, err := s.db.Query(query, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подсчёте занятого командой места: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	usage := &entity.TeamStorageUsage{TeamID: teamID}
+	categories := []*entity.StorageUsage{&usage.Posts, &usage.Comments, &usage.Avatars, &usage.Library}
+	for rows.Next() {
+		var (
+			priority int
+			counted  bool
+			category entity.StorageUsage
+		)
+		if err := rows.Scan(&priority, &counted, &category.Files, &category.Bytes); err != nil {
+			return nil, fmt.Errorf("ошибка при подсчёте занятого командой места: %w", err)
+		}
+		if priority < 0 || priority >= len(categories) {
+			continue
+		}
+		categories[priority].Files += category.Files
+		categories[priority].Bytes += category.Bytes
+		usage.Total.Files += category.Files
+		usage.Total.Bytes += category.Bytes
+		if counted {
+			usage.Counted.Files += category.Files
+			usage.Counted.Bytes += category.Bytes
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при подсчёте занятого командой места: %w", err)
+	}
+	return usage, nil
+}
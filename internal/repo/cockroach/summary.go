@@ -0,0 +1,59 @@
+package cockroach
+
+import (
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+type CommentSummary struct {
+	db *sqlx.DB
+}
+
+func NewCommentSummary(db *sqlx.DB) repo.CommentSummary {
+	return &CommentSummary{db: db}
+}
+
+func (s *CommentSummary) GetSummary(postUnionID int) (*entity.Summarize, error) {
+	query, args, err := sq.Select(
+		"post_union_id", "team_id", "markdown", "last_comment_id", "comment_count", "updated_at",
+	).
+		From("post_comment_summary").
+		Where(sq.Eq{"post_union_id": postUnionID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения сводки: %w", err)
+	}
+	summaries := make([]*entity.Summarize, 0, 1)
+	if err := s.db.Select(&summaries, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении сводки: %w", err)
+	}
+	if len(summaries) == 0 {
+		return nil, repo.ErrCommentSummaryNotFound
+	}
+	return summaries[0], nil
+}
+
+func (s *CommentSummary) PutSummary(summary *entity.Summarize) error {
+	query, args, err := sq.Insert("post_comment_summary").
+		Columns("post_union_id", "team_id", "markdown", "last_comment_id", "comment_count", "updated_at").
+		Values(summary.PostUnionID, summary.TeamID, summary.Markdown, summary.LastCommentID, summary.CommentCount, summary.UpdatedAt).
+		Suffix(`ON CONFLICT (post_union_id) DO UPDATE SET
+    markdown = excluded.markdown,
+    last_comment_id = excluded.last_comment_id,
+    comment_count = excluded.comment_count,
+    updated_at = excluded.updated_at`).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании SQL-запроса для сохранения сводки: %w", err)
+	}
+	if _, err := s.db.Exec(query, args...); err != nil {
+		return fmt.Errorf("ошибка при сохранении сводки: %w", err)
+	}
+	return nil
+}
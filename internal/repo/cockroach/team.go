@@ -76,6 +76,15 @@ func (t *Team) GetTeam(teamId int) (*entity.Team, error) {
 	return team, nil
 }
 
+func (t *Team) GetTeamIDs() ([]int, error) {
+	var teamIDs []int
+	err := t.db.Select(&teamIDs, "SELECT id FROM team ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	return teamIDs, nil
+}
+
 func (t *Team) GetUserTeams(userID int) ([]int, error) {
 	var teamIDs []int
 	err := t.db.Select(&teamIDs, "SELECT team_id FROM team_user_role WHERE user_id = $1", userID)
@@ -0,0 +1,315 @@
+package cockroach
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Ticket struct {
+	db *sqlx.DB
+}
+
+func NewTicket(db *sqlx.DB) repo.Ticket {
+	return &Ticket{db: db}
+}
+
+func (t *Ticket) selectTickets() sq.SelectBuilder {
+	return sq.Select(
+		"id", "team_id", "source_comment_id", "title", "status", "priority", "assignee_id",
+		"created_by", "created_at", "updated_at", "first_response_at", "resolved_at",
+	).
+		From("ticket").
+		PlaceholderFormat(sq.Dollar)
+}
+
+// linkComment привязывает комментарий к тикету внутри транзакции
+func linkComment(tx *sqlx.Tx, ticketID, commentID int, linkedAt time.Time) error {
+	res, err := tx.Exec(
+		"INSERT INTO ticket_comment (ticket_id, comment_id, linked_at) VALUES ($1, $2, $3) ON CONFLICT (comment_id) DO NOTHING",
+		ticketID, commentID, linkedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при привязке комментария к тикету: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrTicketCommentLinked
+	}
+	_, err = tx.Exec("UPDATE post_comment SET marked_as_ticket = TRUE WHERE id = $1", commentID)
+	if err != nil {
+		return fmt.Errorf("ошибка при пометке комментария как тикета: %w", err)
+	}
+	return nil
+}
+
+func (t *Ticket) AddTicket(ticket *entity.Ticket) (int, error) {
+	query, args, err := sq.Insert("ticket").
+		Columns(
+			"team_id", "source_comment_id", "title", "status", "priority", "assignee_id",
+			"created_by", "created_at", "updated_at",
+		).
+		Values(
+			ticket.TeamID,
+			ticket.SourceCommentID,
+			ticket.Title,
+			ticket.Status,
+			ticket.Priority,
+			ticket.AssigneeID,
+			ticket.CreatedBy,
+			ticket.CreatedAt,
+			ticket.UpdatedAt,
+		).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при формировании SQL-запроса для создания тикета: %w", err)
+	}
+
+	tx, err := t.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var ticketID int
+	if err := tx.QueryRow(query, args...).Scan(&ticketID); err != nil {
+		return 0, fmt.Errorf("ошибка при создании тикета: %w", err)
+	}
+	if ticket.SourceCommentID != nil {
+		if err := linkComment(tx, ticketID, *ticket.SourceCommentID, ticket.CreatedAt); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+	return ticketID, nil
+}
+
+// fillCommentIDs заполняет ID привязанных комментариев одним запросом на все тикеты
+func (t *Ticket) fillCommentIDs(tickets []*entity.Ticket) error {
+	if len(tickets) == 0 {
+		return nil
+	}
+	ticketIDs := make([]int, 0, len(tickets))
+	byID := make(map[int]*entity.Ticket, len(tickets))
+	for _, ticket := range tickets {
+		ticket.CommentIDs = make([]int, 0)
+		ticketIDs = append(ticketIDs, ticket.ID)
+		byID[ticket.ID] = ticket
+	}
+
+	rows, err := t.db.Query(
+		"SELECT ticket_id, comment_id FROM ticket_comment WHERE ticket_id = ANY($1) ORDER BY linked_at, comment_id",
+		pq.Array(ticketIDs),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при получении комментариев тикетов: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var ticketID, commentID int
+		if err := rows.Scan(&ticketID, &commentID); err != nil {
+			return fmt.Errorf("ошибка при сканировании комментария тикета: %w", err)
+		}
+		byID[ticketID].CommentIDs = append(byID[ticketID].CommentIDs, commentID)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка при получении комментариев тикетов: %w", err)
+	}
+	return nil
+}
+
+func (t *Ticket) GetTicket(ticketID int) (*entity.Ticket, error) {
+	query, args, err := t.selectTickets().Where(sq.Eq{"id": ticketID}).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения тикета: %w", err)
+	}
+	ticket := &entity.Ticket{}
+	err = t.db.Get(ticket, query, args...)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, repo.ErrTicketNotFound
+	case err != nil:
+		return nil, fmt.Errorf("ошибка при получении тикета: %w", err)
+	}
+	if err := t.fillCommentIDs([]*entity.Ticket{ticket}); err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+func (t *Ticket) GetTickets(request *entity.GetTicketsRequest) ([]*entity.Ticket, error) {
+	builder := t.selectTickets().
+		Where(sq.Eq{"team_id": request.TeamID}).
+		Where(sq.Lt{"created_at": request.Offset})
+	if request.Status != "" {
+		builder = builder.Where(sq.Eq{"status": request.Status})
+	}
+	if request.Priority != "" {
+		builder = builder.Where(sq.Eq{"priority": request.Priority})
+	}
+	if request.AssigneeID != nil {
+		if *request.AssigneeID == 0 {
+			builder = builder.Where(sq.Eq{"assignee_id": nil})
+		} else {
+			builder = builder.Where(sq.Eq{"assignee_id": *request.AssigneeID})
+		}
+	}
+	query, args, err := builder.
+		OrderBy("created_at DESC", "id DESC").
+		Limit(uint64(request.Limit)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения тикетов: %w", err)
+	}
+
+	tickets := make([]*entity.Ticket, 0)
+	if err := t.db.Select(&tickets, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении тикетов: %w", err)
+	}
+	if err := t.fillCommentIDs(tickets); err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+func (t *Ticket) EditTicket(ticket *entity.Ticket) error {
+	query, args, err := sq.Update("ticket").
+		Set("title", ticket.Title).
+		Set("status", ticket.Status).
+		Set("priority", ticket.Priority).
+		Set("assignee_id", ticket.AssigneeID).
+		Set("updated_at", ticket.UpdatedAt).
+		Set("first_response_at", ticket.FirstResponseAt).
+		Set("resolved_at", ticket.ResolvedAt).
+		Where(sq.Eq{"id": ticket.ID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании SQL-запроса для изменения тикета: %w", err)
+	}
+	res, err := t.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении тикета: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrTicketNotFound
+	}
+	return nil
+}
+
+func (t *Ticket) GetTicketIDByComment(commentID int) (int, error) {
+	var ticketID int
+	err := t.db.Get(&ticketID, "SELECT ticket_id FROM ticket_comment WHERE comment_id = $1", commentID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, repo.ErrTicketNotFound
+	case err != nil:
+		return 0, fmt.Errorf("ошибка при получении тикета комментария: %w", err)
+	}
+	return ticketID, nil
+}
+
+func (t *Ticket) LinkComment(ticketID, commentID int) error {
+	tx, err := t.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	if err := linkComment(tx, ticketID, commentID, now); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE ticket SET updated_at = $1 WHERE id = $2", now, ticketID); err != nil {
+		return fmt.Errorf("ошибка при изменении тикета: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+	return nil
+}
+
+func (t *Ticket) UnlinkComment(ticketID, commentID int) error {
+	tx, err := t.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec("DELETE FROM ticket_comment WHERE ticket_id = $1 AND comment_id = $2", ticketID, commentID)
+	if err != nil {
+		return fmt.Errorf("ошибка при отвязке комментария от тикета: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repo.ErrTicketCommentNotLinked
+	}
+	if _, err := tx.Exec("UPDATE post_comment SET marked_as_ticket = FALSE WHERE id = $1", commentID); err != nil {
+		return fmt.Errorf("ошибка при снятии пометки тикета с комментария: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE ticket SET updated_at = $1 WHERE id = $2", time.Now(), ticketID); err != nil {
+		return fmt.Errorf("ошибка при изменении тикета: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+	return nil
+}
+
+func (t *Ticket) SetFirstResponse(commentID int, respondedAt time.Time) error {
+	_, err := t.db.Exec(
+		"UPDATE ticket SET first_response_at = $1, updated_at = $1 "+
+			"WHERE id = (SELECT ticket_id FROM ticket_comment WHERE comment_id = $2) AND first_response_at IS NULL",
+		respondedAt, commentID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при отметке первого ответа по тикету: %w", err)
+	}
+	return nil
+}
+
+func (t *Ticket) AddTicketNote(note *entity.TicketNote) (int, error) {
+	tx, err := t.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var noteID int
+	err = tx.QueryRow(
+		"INSERT INTO ticket_note (ticket_id, user_id, text, created_at) VALUES ($1, $2, $3, $4) RETURNING id",
+		note.TicketID, note.UserID, note.Text, note.CreatedAt,
+	).Scan(&noteID)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при добавлении заметки к тикету: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE ticket SET updated_at = $1 WHERE id = $2", note.CreatedAt, note.TicketID); err != nil {
+		return 0, fmt.Errorf("ошибка при изменении тикета: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+	return noteID, nil
+}
+
+func (t *Ticket) GetTicketNotes(ticketID int) ([]*entity.TicketNote, error) {
+	notes := make([]*entity.TicketNote, 0)
+	err := t.db.Select(
+		&notes,
+		"SELECT id, ticket_id, user_id, text, created_at FROM ticket_note WHERE ticket_id = $1 ORDER BY created_at, id",
+		ticketID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении заметок тикета: %w", err)
+	}
+	return notes, nil
+}
@@ -1,47 +1,58 @@
 package cockroach
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"postic-backend/internal/entity"
 	"postic-backend/internal/repo"
+	"postic-backend/pkg/storage"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
-	"github.com/minio/minio-go/v7"
 )
 
+var mediafileColumns = []string{
+	"id", "file_path", "file_type", "uploaded_by_user_id", "team_id", "display_name",
+	"content_hash", "created_at", "width", "height", "duration_ms",
+}
+
+// orphanMediafileCondition условие для строки mediafile, на которую ничего не ссылается.
+// Файлы медиатеки команды хранятся, пока их не удалят вручную, а производные версии удаляются вместе с исходным файлом
+const orphanMediafileCondition = `mediafile.team_id IS NULL
+	AND NOT EXISTS (SELECT 1 FROM mediafile_rendition r WHERE r.rendition_mediafile_id = mediafile.id)
+	AND NOT EXISTS (SELECT 1 FROM post_union_mediafile pum WHERE pum.mediafile_id = mediafile.id)
+	AND NOT EXISTS (SELECT 1 FROM post_comment_attachment pca WHERE pca.mediafile_id = mediafile.id)
+	AND NOT EXISTS (SELECT 1 FROM post_comment pc WHERE pc.avatar_mediafile_id = mediafile.id)
+	AND NOT EXISTS (SELECT 1 FROM canned_reply_mediafile crm WHERE crm.mediafile_id = mediafile.id)
+	AND NOT EXISTS (SELECT 1 FROM dm_message_attachment dma WHERE dma.mediafile_id = mediafile.id)
+	AND NOT EXISTS (SELECT 1 FROM dm_conversation dc WHERE dc.avatar_mediafile_id = mediafile.id)`
+
+const contentHashPrefix = "sha256/"
+
 type Upload struct {
-	db          *sqlx.DB
-	minioClient *minio.Client
+	db      *sqlx.DB
+	storage storage.Storage
 }
 
-func NewUpload(db *sqlx.DB, minioClient *minio.Client) (repo.Upload, error) {
-	// Создаем бакет для user uploads, предварительно проверив, что его нет
-	ctx := context.Background()
-	exists, err := minioClient.BucketExists(ctx, "mediafiles")
-	if err != nil {
-		return nil, err
-	}
-	if !exists {
-		err = minioClient.MakeBucket(ctx, "mediafiles", minio.MakeBucketOptions{
-			Region:        "eu-central-1", // Предположим, что мы центральные европейцы
-			ObjectLocking: true,
-		})
-		if err != nil {
-			return nil, err
-		}
-	}
+func NewUpload(db *sqlx.DB, storage storage.Storage) repo.Upload {
 	return &Upload{
-		db:          db,
-		minioClient: minioClient,
-	}, nil
+		db:      db,
+		storage: storage,
+	}
 }
 
 func (u *Upload) GetUpload(id int) (*entity.Upload, error) {
 	upload := &entity.Upload{}
-	query, args, err := sq.Select("*").From("mediafile").Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
+	query, args, err := sq.Select(mediafileColumns...).From("mediafile").Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return nil, err
 	}
@@ -49,8 +60,7 @@ func (u *Upload) GetUpload(id int) (*entity.Upload, error) {
 	if err != nil {
 		return nil, err
 	}
-	ctx := context.TODO()
-	object, err := u.minioClient.GetObject(ctx, "mediafiles", upload.FilePath, minio.GetObjectOptions{Checksum: true})
+	object, err := u.storage.Get(context.TODO(), upload.ObjectName())
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +70,7 @@ func (u *Upload) GetUpload(id int) (*entity.Upload, error) {
 
 func (u *Upload) GetUploadInfo(id int) (*entity.Upload, error) {
 	upload := &entity.Upload{}
-	query, args, err := sq.Select("*").From("mediafile").Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
+	query, args, err := sq.Select(mediafileColumns...).From("mediafile").Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return nil, err
 	}
@@ -68,74 +78,366 @@ func (u *Upload) GetUploadInfo(id int) (*entity.Upload, error) {
 	if err != nil {
 		return nil, err
 	}
-	// Получаем размер файла из MinIO
-	ctx := context.TODO()
-	stat, err := u.minioClient.StatObject(ctx, "mediafiles", upload.FilePath, minio.StatObjectOptions{})
+	// Получаем размер файла из хранилища
+	stat, err := u.storage.Stat(context.TODO(), upload.ObjectName())
 	if err == nil {
 		upload.Size = stat.Size
 	} else {
 		upload.Size = 0 // Если не удалось получить размер, ставим 0
+		return upload, err
 	}
+	upload.Renditions, err = u.GetRenditions(id)
 	return upload, err
 }
 
 func (u *Upload) UploadFile(upload *entity.Upload) (int, error) {
 	ctx := context.Background()
-	rawBytes, err := io.ReadAll(upload.RawBytes)
-	if err != nil {
-		return 0, err
+	// Хэш и размер может посчитать вызывающий, пока копирует содержимое, тогда файл не читается в память
+	if upload.ContentHash == "" {
+		rawBytes, err := io.ReadAll(upload.RawBytes)
+		if err != nil {
+			return 0, err
+		}
+		hash := sha256.Sum256(rawBytes)
+		upload.ContentHash = hex.EncodeToString(hash[:])
+		upload.Size = int64(len(rawBytes))
+		upload.RawBytes = bytes.NewReader(rawBytes)
 	}
-	_, err = upload.RawBytes.Seek(0, io.SeekStart) // Сбросим указатель на начало, чтобы MinIO мог прочитать файл
+
+	tx, err := u.db.Beginx()
 	if err != nil {
+		return 0, fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	// Сборщик мусора не удалит объект, пока строка mediafile не добавлена в этой же транзакции
+	if err := lockObject(tx, upload.ObjectName()); err != nil {
 		return 0, err
 	}
-	mediaType := http.DetectContentType(rawBytes)
-	_, err = u.minioClient.PutObject(
-		ctx,
-		"mediafiles",
-		upload.FilePath,
-		upload.RawBytes,
-		int64(len(rawBytes)),
-		minio.PutObjectOptions{
-			ContentType: mediaType,
-		},
-	)
+
+	// Одинаковое содержимое (например, один и тот же аватар из каждого комментария) хранится одним объектом
+	_, err = u.storage.Stat(ctx, upload.ObjectName())
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		err = u.putObject(ctx, upload)
+	}
 	if err != nil {
 		return 0, err
 	}
-	builder := sq.Insert("mediafile").Columns("file_path", "file_type")
-	if upload.UserID == nil {
-		builder = builder.Values(upload.FilePath, upload.FileType)
-	} else {
-		builder = builder.Columns("uploaded_by_user_id").Values(upload.FilePath, upload.FileType, upload.UserID)
+	values := map[string]any{
+		"file_path":    upload.FilePath,
+		"file_type":    upload.FileType,
+		"display_name": upload.DisplayName,
+		"content_hash": upload.ContentHash,
+		"size":         upload.Size,
+		"width":        upload.Width,
+		"height":       upload.Height,
+		"duration_ms":  upload.DurationMs,
+	}
+	if upload.UserID != nil {
+		values["uploaded_by_user_id"] = upload.UserID
+	}
+	if upload.TeamID != nil {
+		values["team_id"] = upload.TeamID
 	}
-	builder = builder.Suffix("RETURNING id").PlaceholderFormat(sq.Dollar)
+	builder := sq.Insert("mediafile").SetMap(values).Suffix("RETURNING id").PlaceholderFormat(sq.Dollar)
 	query, qargs, err := builder.ToSql()
 	if err != nil {
 		return 0, err
 	}
 	var uploadID int
-	err = u.db.QueryRow(query, qargs...).Scan(&uploadID)
+	err = tx.QueryRow(query, qargs...).Scan(&uploadID)
 	if err != nil {
 		return 0, err
 	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
 	return uploadID, nil
 }
 
+// putObject записывает содержимое upload в хранилище. Тип содержимого определяется по первым байтам
+func (u *Upload) putObject(ctx context.Context, upload *entity.Upload) error {
+	header := make([]byte, 512)
+	n, err := io.ReadFull(upload.RawBytes, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("ошибка при чтении файла: %w", err)
+	}
+	if _, err := upload.RawBytes.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("ошибка при чтении файла: %w", err)
+	}
+	return u.storage.Put(ctx, upload.ObjectName(), upload.RawBytes, upload.Size, http.DetectContentType(header[:n]))
+}
+
+// lockObject блокирует имя объекта хранилища до конца транзакции
+func lockObject(tx *sqlx.Tx, objectName string) error {
+	_, err := tx.Exec(
+		`INSERT INTO storage_object_lock (object_name) VALUES ($1)
+		ON CONFLICT (object_name) DO UPDATE SET object_name = excluded.object_name`,
+		objectName,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при блокировке объекта: %w", err)
+	}
+	return nil
+}
+
 func (u *Upload) DeleteUpload(id int) error {
-	upload, err := u.GetUploadInfo(id)
+	upload := &entity.Upload{}
+	query, args, err := sq.Select(mediafileColumns...).From("mediafile").Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return err
 	}
-	ctx := context.Background()
-	err = u.minioClient.RemoveObject(ctx, "mediafiles", upload.FilePath, minio.RemoveObjectOptions{})
+	err = u.db.Get(upload, query, args...)
+	if err != nil {
+		return err
+	}
+	renditions, err := u.GetRenditions(id)
 	if err != nil {
 		return err
 	}
-	query, args, err := sq.Delete("mediafile").Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
+	// Сначала удаляем производные версии, иначе их объекты останутся в хранилище после каскадного удаления строк
+	for _, rendition := range renditions {
+		if err := u.DeleteUpload(rendition.MediaFileID); err != nil {
+			return err
+		}
+	}
+	query, args, err = sq.Delete("mediafile").Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return err
 	}
 	_, err = u.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	// Объект удаляется, только если на то же содержимое не ссылаются другие файлы.
+	// Отсутствующий объект не мешает удалению строки: releaseObject считает его уже удалённым
+	_, _, err = u.releaseObject(upload.ObjectName())
 	return err
 }
+
+func (u *Upload) AddRendition(mediaFileID int, rendition *entity.UploadRendition) error {
+	query, args, err := sq.Insert("mediafile_rendition").
+		Columns("mediafile_id", "rendition_mediafile_id", "kind", "mime_type", "width", "height", "size").
+		Values(mediaFileID, rendition.MediaFileID, rendition.Kind, rendition.MimeType, rendition.Width, rendition.Height, rendition.Size).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка при формировании SQL-запроса для добавления версии медиафайла: %w", err)
+	}
+	_, err = u.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при добавлении версии медиафайла: %w", err)
+	}
+	return nil
+}
+
+func (u *Upload) GetRenditions(mediaFileID int) ([]*entity.UploadRendition, error) {
+	query, args, err := sq.Select("rendition_mediafile_id", "kind", "mime_type", "width", "height", "size").
+		From("mediafile_rendition").
+		Where(sq.Eq{"mediafile_id": mediaFileID}).
+		OrderBy("size DESC").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения версий медиафайла: %w", err)
+	}
+	var renditions []*entity.UploadRendition
+	if err := u.db.Select(&renditions, query, args...); err != nil {
+		return nil, fmt.Errorf("ошибка при получении версий медиафайла: %w", err)
+	}
+	return renditions, nil
+}
+
+func (u *Upload) GetOrphanUploads(before time.Time) ([]int, error) {
+	var ids []int
+	err := u.db.Select(
+		&ids,
+		"SELECT id FROM mediafile WHERE created_at < $1 AND "+orphanMediafileCondition,
+		before,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении неиспользуемых медиафайлов: %w", err)
+	}
+	return ids, nil
+}
+
+func (u *Upload) DeleteOrphanUpload(id int) (*entity.MediaGCReport, error) {
+	renditions, err := u.GetRenditions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := u.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Условие проверяется повторно при удалении: файл могли прикрепить после выборки
+	var objectNames []string
+	rows, err := tx.Query(
+		"DELETE FROM mediafile WHERE id = $1 AND "+orphanMediafileCondition+" RETURNING file_path, content_hash",
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при удалении неиспользуемого медиафайла: %w", err)
+	}
+	for rows.Next() {
+		upload := &entity.Upload{}
+		if err := rows.Scan(&upload.FilePath, &upload.ContentHash); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("ошибка при удалении неиспользуемого медиафайла: %w", err)
+		}
+		objectNames = append(objectNames, upload.ObjectName())
+	}
+	_ = rows.Close()
+	report := &entity.MediaGCReport{}
+	if len(objectNames) == 0 {
+		return report, nil
+	}
+	for _, rendition := range renditions {
+		upload := &entity.Upload{}
+		err := tx.QueryRow(
+			"DELETE FROM mediafile WHERE id = $1 RETURNING file_path, content_hash",
+			rendition.MediaFileID,
+		).Scan(&upload.FilePath, &upload.ContentHash)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("ошибка при удалении версии медиафайла: %w", err)
+		}
+		objectNames = append(objectNames, upload.ObjectName())
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+	report.DeletedFiles = len(objectNames)
+
+	for _, objectName := range objectNames {
+		size, removed, err := u.releaseObject(objectName)
+		if err != nil {
+			return report, err
+		}
+		if removed {
+			report.DeletedObjects++
+			report.ReclaimedBytes += size
+		}
+	}
+	return report, nil
+}
+
+func (u *Upload) DeleteOrphanObjects(before time.Time) (*entity.MediaGCReport, error) {
+	ctx := context.Background()
+	report := &entity.MediaGCReport{}
+	for object, err := range u.storage.List(ctx, "") {
+		if err != nil {
+			return report, fmt.Errorf("ошибка при получении списка объектов: %w", err)
+		}
+		// Части сессий загрузки удаляются вместе с сессией, а свежие объекты могут ещё не успеть получить строку
+		if strings.HasPrefix(object.Name, sessionPartsRoot) || !object.LastModified.Before(before) {
+			continue
+		}
+		size, removed, err := u.releaseObject(object.Name)
+		if err != nil {
+			return report, err
+		}
+		if removed {
+			report.DeletedObjects++
+			report.ReclaimedBytes += size
+		}
+	}
+	return report, nil
+}
+
+// releaseObject удаляет объект со всеми версиями, если на него не ссылается ни одна строка mediafile.
+// Возвращает освобождённый объём и признак удаления
+func (u *Upload) releaseObject(objectName string) (int64, bool, error) {
+	tx, err := u.db.Beginx()
+	if err != nil {
+		return 0, false, fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	// Пока блокировка удерживается, загрузка того же содержимого ждёт и после коммита заново запишет объект
+	if err := lockObject(tx, objectName); err != nil {
+		return 0, false, err
+	}
+
+	var referenced bool
+	if hash, ok := strings.CutPrefix(objectName, contentHashPrefix); ok {
+		err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM mediafile WHERE content_hash = $1)", hash).Scan(&referenced)
+	} else {
+		err = tx.QueryRow(
+			"SELECT EXISTS (SELECT 1 FROM mediafile WHERE file_path = $1 AND content_hash = '')",
+			objectName,
+		).Scan(&referenced)
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("ошибка при проверке использования объекта: %w", err)
+	}
+	if referenced {
+		return 0, false, nil
+	}
+
+	// Уже удалённый объект ничего не освобождает, но строка блокировки всё равно больше не нужна
+	size, err := u.storage.Remove(context.Background(), objectName)
+	removed := true
+	switch {
+	case errors.Is(err, storage.ErrObjectNotFound):
+		size, removed = 0, false
+	case err != nil:
+		return 0, false, fmt.Errorf("ошибка при удалении объекта: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM storage_object_lock WHERE object_name = $1", objectName); err != nil {
+		return 0, false, fmt.Errorf("ошибка при снятии блокировки объекта: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, false, fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+	return size, removed, nil
+}
+
+func (u *Upload) PresignDownload(id int, expires time.Duration) (string, error) {
+	presigner, ok := u.storage.(storage.Presigner)
+	if !ok {
+		return "", repo.ErrPresignNotSupported
+	}
+	upload := &entity.Upload{}
+	query, args, err := sq.Select(mediafileColumns...).From("mediafile").Where(sq.Eq{"id": id}).PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return "", err
+	}
+	err = u.db.Get(upload, query, args...)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "", repo.ErrMediaFileNotFound
+	case err != nil:
+		return "", err
+	}
+	link, err := presigner.PresignGet(context.Background(), upload.ObjectName(), expires)
+	if err != nil {
+		return "", fmt.Errorf("ошибка при создании ссылки на скачивание: %w", err)
+	}
+	return link.String(), nil
+}
+
+func (u *Upload) FillMissingSizes() (int, error) {
+	var uploads []*entity.Upload
+	err := u.db.Select(&uploads, "SELECT id, file_path, content_hash FROM mediafile WHERE size = 0")
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении файлов без размера: %w", err)
+	}
+	filled := 0
+	for _, upload := range uploads {
+		stat, err := u.storage.Stat(context.Background(), upload.ObjectName())
+		if err != nil || stat.Size == 0 {
+			// объекта нет или он пустой - такие файлы остаются с нулевым размером
+			continue
+		}
+		_, err = u.db.Exec("UPDATE mediafile SET size = $1 WHERE id = $2", stat.Size, upload.ID)
+		if err != nil {
+			return filled, fmt.Errorf("ошибка при обновлении размера файла: %w", err)
+		}
+		filled++
+	}
+	return filled, nil
+}
@@ -0,0 +1,290 @@
+package cockroach
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"postic-backend/pkg/storage"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type UploadSession struct {
+	db      *sqlx.DB
+	storage storage.Storage
+}
+
+// NewUploadSession хранит части в том же хранилище, что и медиафайлы, под префиксом sessions/
+func NewUploadSession(db *sqlx.DB, storage storage.Storage) repo.UploadSession {
+	return &UploadSession{
+		db:      db,
+		storage: storage,
+	}
+}
+
+// sessionPartsRoot общий префикс частей всех сессий в бакете mediafiles
+const sessionPartsRoot = "sessions/"
+
+func sessionPartsPrefix(id int) string {
+	return fmt.Sprintf("%s%d/", sessionPartsRoot, id)
+}
+
+func (u *UploadSession) AddUploadSession(session *entity.UploadSession) (int, error) {
+	query, args, err := sq.Insert("upload_session").
+		Columns("user_id", "team_id", "file_name", "file_type", "display_name", "size", "presigned", "expires_at").
+		Values(
+			session.UserID, session.TeamID, session.FileName, session.FileType, session.DisplayName,
+			session.Size, session.Presigned, session.ExpiresAt,
+		).
+		Suffix("RETURNING id").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при формировании SQL-запроса для создания сессии загрузки: %w", err)
+	}
+	var id int
+	if err := u.db.QueryRow(query, args...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("ошибка при создании сессии загрузки: %w", err)
+	}
+	return id, nil
+}
+
+func (u *UploadSession) GetUploadSession(id int) (*entity.UploadSession, error) {
+	query, args, err := sq.Select(
+		"id", "user_id", "team_id", "file_name", "file_type", "display_name",
+		"size", "upload_offset", "mediafile_id", "presigned", "expires_at", "created_at",
+	).
+		From("upload_session").
+		Where(sq.Eq{"id": id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при формировании SQL-запроса для получения сессии загрузки: %w", err)
+	}
+	session := &entity.UploadSession{}
+	err = u.db.Get(session, query, args...)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, repo.ErrUploadSessionNotFound
+	case err != nil:
+		return nil, fmt.Errorf("ошибка при получении сессии загрузки: %w", err)
+	}
+	return session, nil
+}
+
+func (u *UploadSession) AppendUploadSessionPart(id int, offset int64, data []byte, expiresAt time.Time) (int64, error) {
+	// Имя части уникально: при гонке двух запросов с одним offset в сессию попадёт только та часть,
+	// чья транзакция прошла, а вторая останется сиротой до удаления сессии
+	objectName := fmt.Sprintf("%s%020d-%s", sessionPartsPrefix(id), offset, uuid.New().String())
+	err := u.storage.Put(context.Background(), objectName, bytes.NewReader(data), int64(len(data)), "application/octet-stream")
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при сохранении части загрузки: %w", err)
+	}
+
+	tx, err := u.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	newOffset := offset + int64(len(data))
+	res, err := tx.Exec(
+		"UPDATE upload_session SET upload_offset = $1, expires_at = $2 WHERE id = $3 AND upload_offset = $4 AND size >= $1",
+		newOffset, expiresAt, id, offset,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при обновлении смещения сессии загрузки: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return 0, repo.ErrUploadSessionOffsetMismatch
+	}
+	_, err = tx.Exec(
+		"INSERT INTO upload_session_part (session_id, part_offset, object_name, size) VALUES ($1, $2, $3, $4)",
+		id, offset, objectName, len(data),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при добавлении части загрузки: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+	return newOffset, nil
+}
+
+func (u *UploadSession) ReadUploadSession(id int) (io.ReadCloser, error) {
+	var objectNames []string
+	err := u.db.Select(
+		&objectNames,
+		"SELECT object_name FROM upload_session_part WHERE session_id = $1 ORDER BY part_offset",
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении частей загрузки: %w", err)
+	}
+	return &partsReader{storage: u.storage, objectNames: objectNames}, nil
+}
+
+func (u *UploadSession) CompleteUploadSession(id, mediaFileID int) error {
+	_, err := u.db.Exec("UPDATE upload_session SET mediafile_id = $1 WHERE id = $2", mediaFileID, id)
+	if err != nil {
+		return fmt.Errorf("ошибка при завершении сессии загрузки: %w", err)
+	}
+	// Сама сессия остаётся до истечения срока, чтобы клиент мог узнать ID файла через HEAD
+	if err := u.removeParts(id); err != nil {
+		return err
+	}
+	_, err = u.db.Exec("DELETE FROM upload_session_part WHERE session_id = $1", id)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении частей загрузки: %w", err)
+	}
+	return nil
+}
+
+func (u *UploadSession) DeleteUploadSession(id int) error {
+	if err := u.removeParts(id); err != nil {
+		return err
+	}
+	_, err := u.db.Exec("DELETE FROM upload_session WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении сессии загрузки: %w", err)
+	}
+	return nil
+}
+
+func (u *UploadSession) GetExpiredUploadSessions(before time.Time) ([]int, error) {
+	var ids []int
+	err := u.db.Select(&ids, "SELECT id FROM upload_session WHERE expires_at < $1", before)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении просроченных сессий загрузки: %w", err)
+	}
+	return ids, nil
+}
+
+// presignedObjectName объект, который клиент загружает по ссылке. Лежит среди частей сессии,
+// поэтому удаляется вместе с ней и не считается сиротой сборщиком медиафайлов
+func presignedObjectName(id int) string {
+	return sessionPartsPrefix(id) + "presigned"
+}
+
+func (u *UploadSession) PresignUploadSession(id int, size int64, expires time.Duration) (string, error) {
+	presigner, ok := u.storage.(storage.Presigner)
+	if !ok {
+		return "", repo.ErrPresignNotSupported
+	}
+	link, err := presigner.PresignPut(context.Background(), presignedObjectName(id), size, expires)
+	if err != nil {
+		return "", fmt.Errorf("ошибка при создании ссылки для загрузки: %w", err)
+	}
+	return link.String(), nil
+}
+
+func (u *UploadSession) CheckPresignedObject(id int, size int64) error {
+	info, err := u.storage.Stat(context.Background(), presignedObjectName(id))
+	switch {
+	case errors.Is(err, storage.ErrObjectNotFound):
+		return fmt.Errorf("%w: файл не загружен по ссылке", repo.ErrInvalidUploadContent)
+	case err != nil:
+		return fmt.Errorf("ошибка при получении загруженного файла: %w", err)
+	case info.Size != size:
+		return fmt.Errorf("%w: загружено %d байт вместо %d", repo.ErrInvalidUploadContent, info.Size, size)
+	}
+	return nil
+}
+
+func (u *UploadSession) AttachPresignedObject(id int, size int64) error {
+	objectName := presignedObjectName(id)
+	if err := u.CheckPresignedObject(id, size); err != nil {
+		return err
+	}
+
+	tx, err := u.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(
+		"UPDATE upload_session SET upload_offset = size WHERE id = $1 AND upload_offset = 0 AND size = $2",
+		id, size,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении смещения сессии загрузки: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return repo.ErrUploadSessionOffsetMismatch
+	}
+	_, err = tx.Exec(
+		"INSERT INTO upload_session_part (session_id, part_offset, object_name, size) VALUES ($1, 0, $2, $3)",
+		id, objectName, size,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при добавлении части загрузки: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при коммите транзакции: %w", err)
+	}
+	return nil
+}
+
+// removeParts удаляет все объекты сессии, включая части, не попавшие в таблицу
+func (u *UploadSession) removeParts(id int) error {
+	ctx := context.Background()
+	for object, err := range u.storage.List(ctx, sessionPartsPrefix(id)) {
+		if err != nil {
+			return fmt.Errorf("ошибка при получении списка частей загрузки: %w", err)
+		}
+		_, err := u.storage.Remove(ctx, object.Name)
+		if err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			return fmt.Errorf("ошибка при удалении части загрузки: %w", err)
+		}
+	}
+	return nil
+}
+
+// partsReader последовательно читает объекты частей, открывая следующий только после окончания предыдущего
+type partsReader struct {
+	storage     storage.Storage
+	objectNames []string
+	current     io.ReadCloser
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.objectNames) == 0 {
+				return 0, io.EOF
+			}
+			object, err := r.storage.Get(context.Background(), r.objectNames[0])
+			if err != nil {
+				return 0, err
+			}
+			r.current = object
+			r.objectNames = r.objectNames[1:]
+		}
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			_ = r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *partsReader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+	return nil
+}
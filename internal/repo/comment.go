@@ -9,20 +9,50 @@ import (
 type Comment interface {
 	// GetLastComments возвращает последние комментарии к посту со всех платформ
 	GetLastComments(postUnionID int, limit int) ([]*entity.JustTextComment, error)
+	// GetCommentTextsAfter возвращает до limit первых комментариев поста с ID больше afterCommentID, от старых к новым.
+	// ID — водяной знак без гарантий: комментарий, закоммиченный позже комментария с большим ID, или отложенный
+	// модерацией и одобренный после того, как знак его обогнал, в выборку уже не попадёт
+	GetCommentTextsAfter(postUnionID, afterCommentID, limit int) ([]*entity.JustTextComment, error)
+	// CountCommentsAfter считает комментарии поста с ID больше afterCommentID
+	CountCommentsAfter(postUnionID, afterCommentID int) (int, error)
 	// AddComment добавляет комментарий к посту
 	AddComment(comment *entity.Comment) (int, error)
 	// EditComment редактирует комментарий
 	EditComment(comment *entity.Comment) error
-	// GetComments возвращает комментарии к посту
-	GetComments(teamID, postUnionID int, offset time.Time, before bool, limit int) ([]*entity.Comment, error)
+	// GetComments возвращает комментарии к посту. Ветки отбираются по времени корня относительно offset;
+	// при ненулевом offsetID корни с тем же временем сравниваются по ID, чтобы страницы не теряли их
+	GetComments(teamID, postUnionID int, offset time.Time, offsetID int, before bool, limit int) ([]*entity.Comment, error)
 	// GetTicketComments возвращает комментарии, помеченные как тикет
 	GetTicketComments(teamID int, offset time.Time, before bool, limit int) ([]*entity.Comment, error)
+	// GetFilteredComments возвращает комментарии списком без веток с фильтрами по тикетам и классификации
+	GetFilteredComments(request *entity.GetCommentsRequest) ([]*entity.Comment, error)
+	// GetCommentFeed возвращает комментарии команды списком, включая сообщения вне веток постов
+	GetCommentFeed(request *entity.GetCommentFeedRequest) ([]*entity.Comment, error)
+	// GetCommentTree возвращает узлы дерева обсуждения без вложенности, по уровням от страницы вглубь
+	GetCommentTree(request *entity.GetCommentTreeRequest) ([]*entity.CommentNode, error)
 	// GetComment возвращает информацию о комментарии
 	GetComment(commentID int) (*entity.Comment, error)
 	// GetCommentByPlatformID возвращает информацию о комментарии по ID платформы
 	GetCommentByPlatformID(platformID int, platform string) (*entity.Comment, error)
+	// GetPlatformComment возвращает комментарий команды к посту платформы. Нужен там, где ID комментария
+	// уникален только в пределах поста или стены, как во ВКонтакте
+	GetPlatformComment(teamID int, platform string, platformPostID, commentPlatformID int) (*entity.Comment, error)
+	// PlatformCommentExists проверяет, сохранён ли комментарий команды к посту платформы, включая удалённые
+	PlatformCommentExists(teamID int, platform string, platformPostID, commentPlatformID int) (bool, error)
+	// GetPlatformPostComments возвращает все сохранённые комментарии команды к посту платформы, включая удалённые
+	GetPlatformPostComments(teamID int, platform string, platformPostID int) ([]*entity.PlatformComment, error)
+	// EditCommentText меняет текст комментария и сохраняет предыдущий в истории правок
+	EditCommentText(commentID int, text string, editedBy *int, editedAt time.Time) error
+	// SetCommentReaction сохраняет реакцию команды на комментарий, пустая строка снимает её
+	SetCommentReaction(commentID int, reaction string, reactedBy *int, reactedAt time.Time) error
+	// GetCommentEdits возвращает историю правок комментария от старых к новым
+	GetCommentEdits(commentID int) ([]*entity.CommentEdit, error)
 	// DeleteComment удаляет комментарий
 	DeleteComment(commentID int) error
+	// SetCommentClassification сохраняет тональность и тему комментария
+	SetCommentClassification(commentID int, classification *entity.CommentClassification) error
+	// GetUnclassifiedCommentIDs возвращает ID неклассифицированных комментариев пользователей, созданных в [since, before)
+	GetUnclassifiedCommentIDs(since, before time.Time, limit int) ([]int, error)
 }
 
 var (
@@ -7,5 +7,10 @@ import (
 
 type CommentEventRepository interface {
 	PublishCommentEvent(ctx context.Context, event *entity.CommentEvent) error
-	SubscribeCommentEvents(ctx context.Context, teamID int, postID int) (<-chan *entity.CommentEvent, error)
+	// SubscribeCommentEvents читает события команды, начиная с позиции cursor из CommentEvent.Cursor.
+	// С пустым курсором приходят только новые события
+	SubscribeCommentEvents(ctx context.Context, teamID int, postID int, cursor string) (<-chan *entity.CommentEvent, error)
+	// ConsumeCommentEvents читает события команды в группе потребителей groupID: каждое событие получает только
+	// один участник группы, а после перезапуска чтение продолжается с последнего прочитанного события
+	ConsumeCommentEvents(ctx context.Context, groupID string, teamID int) (<-chan *entity.CommentEvent, error)
 }
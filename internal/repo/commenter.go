@@ -0,0 +1,23 @@
+package repo
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+)
+
+type Commenter interface {
+	// GetCommenter собирает профиль автора по его комментариям в команде без меток, заметок и бана
+	GetCommenter(teamID int, platform string, userPlatformID int) (*entity.Commenter, error)
+	// GetCommenterTags возвращает метки автора
+	GetCommenterTags(teamID int, platform string, userPlatformID int) ([]string, error)
+	// SetCommenterTags заменяет метки автора
+	SetCommenterTags(teamID int, platform string, userPlatformID int, tags []string) error
+	// AddCommenterNote добавляет заметку об авторе
+	AddCommenterNote(note *entity.CommenterNote) (int, error)
+	// GetCommenterNotes возвращает заметки об авторе от старых к новым
+	GetCommenterNotes(teamID int, platform string, userPlatformID int) ([]*entity.CommenterNote, error)
+}
+
+var (
+	ErrCommenterNotFound = errors.New("commenter not found")
+)
@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"postic-backend/internal/entity"
+)
+
+type DirectMessage interface {
+	// PutConversation создаёт переписку или обновляет имя и аватар собеседника и время открытия, возвращает ID переписки
+	PutConversation(conversation *entity.DMConversation) (int, error)
+	// GetConversation возвращает переписку по ID
+	GetConversation(conversationID int) (*entity.DMConversation, error)
+	// GetLastConversationByPeer возвращает переписку собеседника, которую он открыл последней среди всех команд
+	GetLastConversationByPeer(platform string, peerID int64) (*entity.DMConversation, error)
+	// GetConversations возвращает переписки команды с последним сообщением, от новых к старым
+	GetConversations(request *entity.GetDMConversationsRequest) ([]*entity.DMConversation, error)
+	// AddMessage сохраняет сообщение с вложениями и сдвигает время последнего сообщения переписки.
+	// Если сообщение платформы уже сохранено, возвращает его ID и created = false, ничего не меняя
+	AddMessage(message *entity.DirectMessage) (id int, created bool, err error)
+	// GetMessages возвращает сообщения переписки, от новых к старым
+	GetMessages(request *entity.GetDirectMessagesRequest) ([]*entity.DirectMessage, error)
+	// ResetUnread обнуляет счётчик непрочитанных сообщений переписки
+	ResetUnread(conversationID int) error
+}
+
+type DirectMessageEventRepository interface {
+	PublishDirectMessageEvent(ctx context.Context, event *entity.DirectMessageEvent) error
+	// SubscribeDirectMessageEvents читает новые события команды. conversationID 0 — события всех переписок
+	SubscribeDirectMessageEvents(ctx context.Context, teamID int, conversationID int) (<-chan *entity.DirectMessageEvent, error)
+}
+
+var (
+	ErrDMConversationNotFound = errors.New("dm conversation not found")
+)
@@ -0,0 +1,33 @@
+package repo
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+	"time"
+)
+
+type FAQ interface {
+	// GetFAQRules возвращает правила автоответов команды
+	GetFAQRules(teamID int) ([]*entity.FAQRule, error)
+	// GetFAQRule возвращает правило автоответа по ID
+	GetFAQRule(ruleID int) (*entity.FAQRule, error)
+	// AddFAQRule добавляет правило автоответа
+	AddFAQRule(rule *entity.FAQRule) (int, error)
+	// EditFAQRule изменяет правило автоответа
+	EditFAQRule(rule *entity.FAQRule) error
+	// DeleteFAQRule удаляет правило автоответа
+	DeleteFAQRule(ruleID int) error
+
+	// GetFAQEnabled сообщает, включены ли автоответы команды
+	GetFAQEnabled(teamID int) (bool, error)
+	// SetFAQEnabled включает или выключает все автоответы команды
+	SetFAQEnabled(teamID int, enabled bool) error
+
+	// HasRecentFAQReply проверяет, отвечало ли правило в обсуждении поста после since.
+	// postUnionID равен nil для общего обсуждения
+	HasRecentFAQReply(ruleID int, postUnionID *int, since time.Time) (bool, error)
+}
+
+var (
+	ErrFAQRuleNotFound = errors.New("faq rule not found")
+)
@@ -25,10 +25,9 @@ type TopicConfig struct {
 }
 
 type CommentEventKafkaRepository struct {
-	writer        *kafka.Writer
-	readerFactory func(teamID int, postID int) *kafka.Reader
-	brokers       []string
-	topicConfig   TopicConfig
+	writer      *kafka.Writer
+	brokers     []string
+	topicConfig TopicConfig
 }
 
 // createTopicIfNotExists создает топик, если он не существует
@@ -163,28 +162,8 @@ func NewCommentEventKafkaRepository(brokers []string) (repo.CommentEventReposito
 	return &CommentEventKafkaRepository{
 		writer: &kafka.Writer{
 			Addr:     kafka.TCP(brokers...),
-			Topic:    baseTopicName, // по умолчанию, можно менять по команде
 			Balancer: &kafka.LeastBytes{},
 		},
-		readerFactory: func(teamID int, postID int) *kafka.Reader {
-			topic := fmt.Sprintf("comment-events-team-%d", teamID)
-
-			// Всегда читаем только новые сообщения
-			startOffset := kafka.LastOffset
-
-			// Создаем GroupID с добавлением уникального идентификатора на основе времени
-			// Это гарантирует, что каждое новое подключение будет получать только новые сообщения
-			groupID := fmt.Sprintf("comment-listener-%d-%d-%d", teamID, postID, time.Now().UnixNano())
-
-			return kafka.NewReader(kafka.ReaderConfig{
-				Brokers:     brokers,
-				Topic:       topic,
-				GroupID:     groupID,
-				MinBytes:    1,
-				MaxBytes:    10e6,
-				StartOffset: startOffset,
-			})
-		},
 		brokers:     brokers,
 		topicConfig: topicConfig,
 	}, nil
@@ -205,16 +184,15 @@ func (r *CommentEventKafkaRepository) PublishCommentEvent(ctx context.Context, e
 		return err
 	}
 
-	// устанавливаем топик для записи
-	r.writer.Topic = topic
-
+	// топик задаётся в сообщении: writer общий, и события разных команд публикуются параллельно
 	return r.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
 		Key:   []byte(fmt.Sprintf("%d", event.PostID)),
 		Value: b,
 	})
 }
 
-func (r *CommentEventKafkaRepository) SubscribeCommentEvents(ctx context.Context, teamID int, postID int) (<-chan *entity.CommentEvent, error) {
+func (r *CommentEventKafkaRepository) SubscribeCommentEvents(ctx context.Context, teamID int, postID int, cursor string) (<-chan *entity.CommentEvent, error) {
 	// Определяем топик
 	topic := fmt.Sprintf("comment-events-team-%d", teamID)
 
@@ -223,20 +201,68 @@ func (r *CommentEventKafkaRepository) SubscribeCommentEvents(ctx context.Context
 		return nil, fmt.Errorf("ошибка при создании топика для команды %d: %w", teamID, err)
 	}
 
-	reader := r.readerFactory(teamID, postID)
+	messages, err := readPartitions(ctx, r.brokers, topic, cursor)
+	if err != nil {
+		return nil, err
+	}
+
 	ch := make(chan *entity.CommentEvent)
 	go func() {
 		defer close(ch)
+		for m := range messages {
+			var event entity.CommentEvent
+			if err := msgpack.Unmarshal(m.message.Value, &event); err != nil {
+				continue
+			}
+			// postID 0 — все обсуждения команды, включая сообщения вне веток постов
+			if postID != 0 && event.PostID != postID {
+				continue
+			}
+			event.Cursor = m.cursor
+			select {
+			case ch <- &event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (r *CommentEventKafkaRepository) ConsumeCommentEvents(ctx context.Context, groupID string, teamID int) (<-chan *entity.CommentEvent, error) {
+	topic := fmt.Sprintf("comment-events-team-%d", teamID)
+
+	if err := createTopicIfNotExists(ctx, r.brokers, topic, r.topicConfig); err != nil {
+		return nil, fmt.Errorf("ошибка при создании топика для команды %d: %w", teamID, err)
+	}
+
+	// Группа постоянная, поэтому смещение сохраняется между перезапусками.
+	// Новая группа начинает с новых сообщений, а не с начала топика
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     r.brokers,
+		Topic:       topic,
+		GroupID:     groupID,
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		StartOffset: kafka.LastOffset,
+	})
+	ch := make(chan *entity.CommentEvent)
+	go func() {
+		defer close(ch)
+		defer func() { _ = reader.Close() }()
 		for {
 			m, err := reader.ReadMessage(ctx)
 			if err != nil {
 				return
 			}
 			var event entity.CommentEvent
-			if err := msgpack.Unmarshal(m.Value, &event); err == nil {
-				if postID == 0 || event.PostID == postID {
-					ch <- &event
-				}
+			if err := msgpack.Unmarshal(m.Value, &event); err != nil {
+				continue
+			}
+			select {
+			case ch <- &event:
+			case <-ctx.Done():
+				return
 			}
 		}
 	}()
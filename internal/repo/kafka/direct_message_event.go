@@ -0,0 +1,109 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type DirectMessageEventKafkaRepository struct {
+	writer      *kafka.Writer
+	brokers     []string
+	topicConfig TopicConfig
+}
+
+func directMessageTopic(teamID int) string {
+	return fmt.Sprintf("dm-events-team-%d", teamID)
+}
+
+func NewDirectMessageEventKafkaRepository(brokers []string) (repo.DirectMessageEventRepository, error) {
+	if len(brokers) == 0 {
+		return nil, errors.New("не предоставлены брокеры Kafka")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	actualReplicationFactor, err := getMaxReplicationFactor(ctx, brokers, 3)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при определении фактора репликации: %w", err)
+	}
+
+	return &DirectMessageEventKafkaRepository{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Balancer: &kafka.LeastBytes{},
+		},
+		brokers: brokers,
+		topicConfig: TopicConfig{
+			NumPartitions:     NumPartitions,
+			ReplicationFactor: actualReplicationFactor,
+		},
+	}, nil
+}
+
+func (r *DirectMessageEventKafkaRepository) PublishDirectMessageEvent(ctx context.Context, event *entity.DirectMessageEvent) error {
+	topic := directMessageTopic(event.TeamID)
+	if err := createTopicIfNotExists(ctx, r.brokers, topic, r.topicConfig); err != nil {
+		return fmt.Errorf("ошибка при создании топика личных сообщений команды %d: %w", event.TeamID, err)
+	}
+
+	b, err := msgpack.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	// топик задаётся в сообщении, чтобы один writer обслуживал все команды
+	return r.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key:   []byte(fmt.Sprintf("%d", event.ConversationID)),
+		Value: b,
+	})
+}
+
+func (r *DirectMessageEventKafkaRepository) SubscribeDirectMessageEvents(ctx context.Context, teamID int, conversationID int) (<-chan *entity.DirectMessageEvent, error) {
+	topic := directMessageTopic(teamID)
+	if err := createTopicIfNotExists(ctx, r.brokers, topic, r.topicConfig); err != nil {
+		return nil, fmt.Errorf("ошибка при создании топика личных сообщений команды %d: %w", teamID, err)
+	}
+
+	// Уникальная группа на каждое подключение: подписчик получает только новые сообщения
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     r.brokers,
+		Topic:       topic,
+		GroupID:     fmt.Sprintf("dm-listener-%d-%d-%d", teamID, conversationID, time.Now().UnixNano()),
+		MinBytes:    1,
+		MaxBytes:    10e6,
+		StartOffset: kafka.LastOffset,
+	})
+	ch := make(chan *entity.DirectMessageEvent)
+	go func() {
+		defer close(ch)
+		defer func() { _ = reader.Close() }()
+		for {
+			m, err := reader.ReadMessage(ctx)
+			if err != nil {
+				return
+			}
+			var event entity.DirectMessageEvent
+			if err := msgpack.Unmarshal(m.Value, &event); err != nil {
+				continue
+			}
+			if conversationID != 0 && event.ConversationID != conversationID {
+				continue
+			}
+			select {
+			case ch <- &event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
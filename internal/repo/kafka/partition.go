@@ -0,0 +1,169 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// EventReplayWindow за какое время подписка по курсору повторяет пропущенные клиентом события
+const EventReplayWindow = 24 * time.Hour
+
+// cursorMessage сообщение топика вместе с курсором, с которого чтение продолжится после него
+type cursorMessage struct {
+	message kafka.Message
+	cursor  string
+}
+
+// readPartitions читает все партиции топика напрямую, без группы потребителей, чтобы начать с позиции из курсора.
+// Без курсора (или для партиций, которых в нём нет) читаются только новые сообщения. Канал закрывается
+// с отменой ctx
+func readPartitions(ctx context.Context, brokers []string, topic string, cursor string) (<-chan cursorMessage, error) {
+	offsets, err := startOffsets(ctx, brokers, topic, parseEventCursor(cursor))
+	if err != nil {
+		return nil, err
+	}
+
+	type partitionMessage struct {
+		partition int
+		message   kafka.Message
+	}
+	messages := make(chan partitionMessage)
+	readCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for partition, offset := range offsets {
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:   brokers,
+			Topic:     topic,
+			Partition: partition,
+			MinBytes:  1,
+			MaxBytes:  10e6,
+		})
+		if err := reader.SetOffset(offset); err != nil {
+			_ = reader.Close()
+			cancel()
+			wg.Wait()
+			return nil, fmt.Errorf("ошибка при установке смещения партиции %d: %w", partition, err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { _ = reader.Close() }()
+			for {
+				m, err := reader.ReadMessage(readCtx)
+				if err != nil {
+					return
+				}
+				select {
+				case messages <- partitionMessage{partition: partition, message: m}:
+				case <-readCtx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	ch := make(chan cursorMessage)
+	go func() {
+		defer close(ch)
+		defer wg.Wait()
+		defer cancel()
+		// события старше окна повтора клиенту уже не нужны, но позиция в курсоре по ним сдвигается
+		replaySince := time.Now().Add(-EventReplayWindow)
+		for {
+			var pm partitionMessage
+			select {
+			case pm = <-messages:
+			case <-ctx.Done():
+				return
+			}
+			offsets[pm.partition] = pm.message.Offset + 1
+			if pm.message.Time.Before(replaySince) {
+				continue
+			}
+			select {
+			case ch <- cursorMessage{message: pm.message, cursor: formatEventCursor(offsets)}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// startOffsets возвращает смещения, с которых читается каждая партиция топика. Смещения из курсора
+// ограничиваются сообщениями, которые ещё хранит Kafka
+func startOffsets(ctx context.Context, brokers []string, topic string, cursor map[int]int64) (map[int]int64, error) {
+	conn, err := kafka.DialContext(ctx, "tcp", brokers[0])
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при подключении к Kafka: %w", err)
+	}
+	partitions, err := conn.ReadPartitions(topic)
+	_ = conn.Close()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении партиций топика %s: %w", topic, err)
+	}
+
+	offsets := make(map[int]int64, len(partitions))
+	for _, partition := range partitions {
+		leader, err := kafka.DialLeader(ctx, "tcp", brokers[0], topic, partition.ID)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при подключении к партиции %d: %w", partition.ID, err)
+		}
+		first, last, err := leader.ReadOffsets()
+		_ = leader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при получении смещений партиции %d: %w", partition.ID, err)
+		}
+		offset, ok := cursor[partition.ID]
+		if !ok {
+			offset = last
+		}
+		offsets[partition.ID] = max(first, min(offset, last))
+	}
+	return offsets, nil
+}
+
+// formatEventCursor записывает следующие смещения всех партиций как "партиция:смещение" через точку
+func formatEventCursor(offsets map[int]int64) string {
+	partitions := make([]int, 0, len(offsets))
+	for partition := range offsets {
+		partitions = append(partitions, partition)
+	}
+	slices.Sort(partitions)
+	parts := make([]string, 0, len(partitions))
+	for _, partition := range partitions {
+		parts = append(parts, fmt.Sprintf("%d:%d", partition, offsets[partition]))
+	}
+	return strings.Join(parts, ".")
+}
+
+// parseEventCursor разбирает курсор formatEventCursor. Некорректный курсор считается пустым
+func parseEventCursor(cursor string) map[int]int64 {
+	offsets := make(map[int]int64)
+	if cursor == "" {
+		return offsets
+	}
+	for _, part := range strings.Split(cursor, ".") {
+		partitionStr, offsetStr, ok := strings.Cut(part, ":")
+		if !ok {
+			return map[int]int64{}
+		}
+		partition, err := strconv.Atoi(partitionStr)
+		if err != nil {
+			return map[int]int64{}
+		}
+		offset, err := strconv.ParseInt(offsetStr, 10, 64)
+		if err != nil || offset < 0 {
+			return map[int]int64{}
+		}
+		offsets[partition] = offset
+	}
+	return offsets
+}
@@ -0,0 +1,104 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type TeamEventKafkaRepository struct {
+	writer      *kafka.Writer
+	brokers     []string
+	topicConfig TopicConfig
+}
+
+func teamEventTopic(teamID int) string {
+	return fmt.Sprintf("team-events-team-%d", teamID)
+}
+
+func NewTeamEventKafkaRepository(brokers []string) (repo.TeamEventRepository, error) {
+	if len(brokers) == 0 {
+		return nil, errors.New("не предоставлены брокеры Kafka")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	actualReplicationFactor, err := getMaxReplicationFactor(ctx, brokers, 3)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при определении фактора репликации: %w", err)
+	}
+
+	return &TeamEventKafkaRepository{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Balancer: &kafka.LeastBytes{},
+		},
+		brokers: brokers,
+		topicConfig: TopicConfig{
+			NumPartitions:     NumPartitions,
+			ReplicationFactor: actualReplicationFactor,
+		},
+	}, nil
+}
+
+func (r *TeamEventKafkaRepository) PublishTeamEvent(ctx context.Context, event *entity.TeamEvent) error {
+	topic := teamEventTopic(event.TeamID)
+	if err := createTopicIfNotExists(ctx, r.brokers, topic, r.topicConfig); err != nil {
+		return fmt.Errorf("ошибка при создании топика событий команды %d: %w", event.TeamID, err)
+	}
+
+	if event.EventID == "" {
+		event.EventID = uuid.NewString()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	b, err := msgpack.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	// топик задаётся в сообщении, чтобы один writer обслуживал все команды
+	return r.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key:   []byte(event.Type),
+		Value: b,
+	})
+}
+
+func (r *TeamEventKafkaRepository) SubscribeTeamEvents(ctx context.Context, teamID int, cursor string) (<-chan *entity.TeamEvent, error) {
+	topic := teamEventTopic(teamID)
+	if err := createTopicIfNotExists(ctx, r.brokers, topic, r.topicConfig); err != nil {
+		return nil, fmt.Errorf("ошибка при создании топика событий команды %d: %w", teamID, err)
+	}
+
+	messages, err := readPartitions(ctx, r.brokers, topic, cursor)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan *entity.TeamEvent)
+	go func() {
+		defer close(ch)
+		for m := range messages {
+			var event entity.TeamEvent
+			if err := msgpack.Unmarshal(m.message.Value, &event); err != nil {
+				continue
+			}
+			event.Cursor = m.cursor
+			select {
+			case ch <- &event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
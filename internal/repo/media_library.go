@@ -0,0 +1,22 @@
+package repo
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+)
+
+type MediaLibrary interface {
+	// GetMediaFiles возвращает медиафайлы команды по фильтрам запроса (без производных версий)
+	GetMediaFiles(request *entity.GetMediaLibraryRequest) ([]*entity.MediaFile, error)
+	// GetMediaFile возвращает медиафайл медиатеки по ID
+	GetMediaFile(id int) (*entity.MediaFile, error)
+	// EditMediaFile обновляет название и теги медиафайла
+	EditMediaFile(mediaFile *entity.MediaFile) error
+	// CanUserAccessMediaFile проверяет, может ли пользователь получить файл: он загрузил его сам,
+	// файл принадлежит его команде или используется в постах/комментариях его команды
+	CanUserAccessMediaFile(userID, mediaFileID int) (bool, error)
+}
+
+var (
+	ErrMediaFileNotFound = errors.New("media file not found")
+)
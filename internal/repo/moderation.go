@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+	"time"
+)
+
+type Moderation interface {
+	// GetModerationRules возвращает правила модерации команды
+	GetModerationRules(teamID int) ([]*entity.ModerationRule, error)
+	// GetModerationRule возвращает правило по ID
+	GetModerationRule(ruleID int) (*entity.ModerationRule, error)
+	// AddModerationRule добавляет правило и возвращает его ID
+	AddModerationRule(rule *entity.ModerationRule) (int, error)
+	// EditModerationRule обновляет правило
+	EditModerationRule(rule *entity.ModerationRule) error
+	// DeleteModerationRule удаляет правило
+	DeleteModerationRule(ruleID int) error
+	// AddModerationLog записывает автоматическое действие в журнал
+	AddModerationLog(entry *entity.ModerationLogEntry) error
+	// GetModerationLog возвращает записи журнала команды, созданные раньше offset, от новых к старым
+	GetModerationLog(teamID int, offset time.Time, limit int) ([]*entity.ModerationLogEntry, error)
+	// CountUserComments возвращает число комментариев автора в команде, оставленных после since
+	CountUserComments(teamID int, platform string, userPlatformID int, since time.Time) (int, error)
+	// CountUserRepeats возвращает число комментариев автора в команде с тем же текстом, оставленных после since
+	CountUserRepeats(teamID int, platform string, userPlatformID int, text string, since time.Time) (int, error)
+	// GetHeldCommentIDs возвращает ID отложенных до проверки комментариев команды, от старых к новым
+	GetHeldCommentIDs(teamID int, limit int) ([]int, error)
+	// ReleaseHeldComment снимает с комментария отметку об отложенной проверке.
+	// Возвращает ErrHeldCommentNotFound, если комментарий не был отложен
+	ReleaseHeldComment(commentID int) error
+}
+
+var (
+	ErrModerationRuleNotFound = errors.New("moderation rule not found")
+	ErrHeldCommentNotFound    = errors.New("held comment not found")
+)
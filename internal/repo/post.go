@@ -43,6 +43,8 @@ type Post interface {
 	GetPostPlatform(postUnionID int, platform string) (*entity.PostPlatform, error)
 	// GetPostPlatformByPost возвращает пост с платформы по ID поста и каналу поста
 	GetPostPlatformByPost(platformID int, channelID int, platform string) (*entity.PostPlatform, error)
+	// GetRecentPostPlatforms возвращает посты канала на платформе, опубликованные не раньше since
+	GetRecentPostPlatforms(channelID int, platform string, since time.Time) ([]*entity.PostPlatform, error)
 	// AddPostPlatform добавляет связанную с PostUnion запись про пост, опубликованный на платформе
 	AddPostPlatform(postPlatform *entity.PostPlatform) (int, error)
 	// DeletePostPlatform удаляет записи о постах для конкретной платформы из базы данных
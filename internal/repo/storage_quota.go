@@ -0,0 +1,17 @@
+package repo
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+)
+
+type StorageQuota interface {
+	// GetTeamStorageQuota возвращает индивидуальную квоту команды
+	GetTeamStorageQuota(teamID int) (*entity.TeamStorageQuota, error)
+	// GetTeamStorageUsage считает место, занятое файлами команды
+	GetTeamStorageUsage(teamID int) (*entity.TeamStorageUsage, error)
+}
+
+var (
+	ErrStorageQuotaNotFound = errors.New("storage quota not found")
+)
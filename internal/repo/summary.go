@@ -0,0 +1,17 @@
+package repo
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+)
+
+type CommentSummary interface {
+	// GetSummary возвращает сохранённую сводку комментариев поста
+	GetSummary(postUnionID int) (*entity.Summarize, error)
+	// PutSummary сохраняет сводку поста, заменяя предыдущую
+	PutSummary(summary *entity.Summarize) error
+}
+
+var (
+	ErrCommentSummaryNotFound = errors.New("comment summary not found")
+)
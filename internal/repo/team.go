@@ -12,6 +12,8 @@ type Team interface {
 	EditTeam(team *entity.Team) error
 	// GetTeam возвращает команду по ID
 	GetTeam(teamId int) (*entity.Team, error)
+	// GetTeamIDs возвращает ID всех команд
+	GetTeamIDs() ([]int, error)
 	// GetTeamUsers возвращает ID пользователей команды по ID команды
 	GetTeamUsers(teamId int) ([]int, error)
 	// GetTeamIDBySecret возвращает ID команды по секретному ключу
@@ -0,0 +1,14 @@
+package repo
+
+import (
+	"context"
+	"postic-backend/internal/entity"
+)
+
+type TeamEventRepository interface {
+	// PublishTeamEvent публикует событие в общий поток команды
+	PublishTeamEvent(ctx context.Context, event *entity.TeamEvent) error
+	// SubscribeTeamEvents читает события общего потока команды, начиная с позиции cursor из TeamEvent.Cursor.
+	// С пустым курсором приходят только новые события
+	SubscribeTeamEvents(ctx context.Context, teamID int, cursor string) (<-chan *entity.TeamEvent, error)
+}
@@ -0,0 +1,36 @@
+package repo
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+	"time"
+)
+
+type Ticket interface {
+	// AddTicket создаёт тикет и привязывает к нему исходный комментарий
+	AddTicket(ticket *entity.Ticket) (int, error)
+	// GetTicket возвращает тикет с ID привязанных комментариев
+	GetTicket(ticketID int) (*entity.Ticket, error)
+	// GetTickets возвращает тикеты команды по фильтрам, от новых к старым
+	GetTickets(request *entity.GetTicketsRequest) ([]*entity.Ticket, error)
+	// EditTicket сохраняет название, статус, приоритет, исполнителя и отметки времени тикета
+	EditTicket(ticket *entity.Ticket) error
+	// GetTicketIDByComment возвращает ID тикета, к которому привязан комментарий
+	GetTicketIDByComment(commentID int) (int, error)
+	// LinkComment привязывает комментарий к тикету и помечает его как тикет
+	LinkComment(ticketID, commentID int) error
+	// UnlinkComment отвязывает комментарий от тикета и снимает с него пометку
+	UnlinkComment(ticketID, commentID int) error
+	// SetFirstResponse отмечает время первого ответа у тикета комментария, если ответа ещё не было
+	SetFirstResponse(commentID int, respondedAt time.Time) error
+	// AddTicketNote добавляет внутреннюю заметку к тикету
+	AddTicketNote(note *entity.TicketNote) (int, error)
+	// GetTicketNotes возвращает заметки тикета в порядке добавления
+	GetTicketNotes(ticketID int) ([]*entity.TicketNote, error)
+}
+
+var (
+	ErrTicketNotFound         = errors.New("ticket not found")
+	ErrTicketCommentLinked    = errors.New("comment already linked to a ticket")
+	ErrTicketCommentNotLinked = errors.New("comment not linked to the ticket")
+)
@@ -1,6 +1,9 @@
 package repo
 
-import "postic-backend/internal/entity"
+import (
+	"postic-backend/internal/entity"
+	"time"
+)
 
 type Upload interface {
 	// GetUpload возвращает загрузку по ID, включая файл
@@ -9,6 +12,21 @@ type Upload interface {
 	GetUploadInfo(id int) (*entity.Upload, error)
 	// UploadFile загружает файл
 	UploadFile(upload *entity.Upload) (int, error)
-	// DeleteUpload удаляет файл по ID
+	// DeleteUpload удаляет файл по ID вместе с его производными версиями
 	DeleteUpload(id int) error
+	// AddRendition привязывает уже загруженный медиафайл как производную версию другого
+	AddRendition(mediaFileID int, rendition *entity.UploadRendition) error
+	// GetRenditions возвращает производные версии медиафайла
+	GetRenditions(mediaFileID int) ([]*entity.UploadRendition, error)
+	// GetOrphanUploads возвращает ID файлов, созданных до before, которые не используются в постах, комментариях,
+	// аватарах и не принадлежат медиатеке команды
+	GetOrphanUploads(before time.Time) ([]int, error)
+	// DeleteOrphanUpload удаляет файл вместе с производными версиями, если он всё ещё не используется
+	DeleteOrphanUpload(id int) (*entity.MediaGCReport, error)
+	// DeleteOrphanObjects удаляет объекты хранилища старше before, на которые не ссылается ни один файл
+	DeleteOrphanObjects(before time.Time) (*entity.MediaGCReport, error)
+	// PresignDownload возвращает временную ссылку на скачивание файла напрямую из хранилища
+	PresignDownload(id int, expires time.Duration) (string, error)
+	// FillMissingSizes заполняет размер файлов, загруженных до его учёта в базе, и возвращает их количество
+	FillMissingSizes() (int, error)
 }
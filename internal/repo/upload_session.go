@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"errors"
+	"io"
+	"postic-backend/internal/entity"
+	"time"
+)
+
+type UploadSession interface {
+	// AddUploadSession создаёт сессию возобновляемой загрузки и возвращает её ID
+	AddUploadSession(session *entity.UploadSession) (int, error)
+	// GetUploadSession возвращает сессию по ID
+	GetUploadSession(id int) (*entity.UploadSession, error)
+	// AppendUploadSessionPart сохраняет часть данных, начинающуюся с offset, продлевает сессию до expiresAt
+	// и возвращает новое смещение. Если offset не совпадает с текущим, возвращается ErrUploadSessionOffsetMismatch
+	AppendUploadSessionPart(id int, offset int64, data []byte, expiresAt time.Time) (int64, error)
+	// ReadUploadSession возвращает склеенные части сессии
+	ReadUploadSession(id int) (io.ReadCloser, error)
+	// CompleteUploadSession привязывает собранный медиафайл к сессии и удаляет её части
+	CompleteUploadSession(id, mediaFileID int) error
+	// DeleteUploadSession удаляет сессию вместе с частями
+	DeleteUploadSession(id int) error
+	// GetExpiredUploadSessions возвращает ID сессий, срок которых истёк до before
+	GetExpiredUploadSessions(before time.Time) ([]int, error)
+	// PresignUploadSession возвращает ссылку для загрузки содержимого сессии размером size одним PUT-запросом
+	PresignUploadSession(id int, size int64, expires time.Duration) (string, error)
+	// CheckPresignedObject проверяет, что по ссылке загружен файл ожидаемого размера
+	CheckPresignedObject(id int, size int64) error
+	// AttachPresignedObject проверяет, что по ссылке загружен файл ожидаемого размера, и делает его единственной частью сессии
+	AttachPresignedObject(id int, size int64) error
+}
+
+var (
+	ErrUploadSessionNotFound       = errors.New("upload session not found")
+	ErrUploadSessionOffsetMismatch = errors.New("upload session offset mismatch")
+	ErrInvalidUploadContent        = errors.New("invalid upload content")
+	ErrPresignNotSupported         = errors.New("storage does not support presigned urls")
+)
@@ -12,6 +12,8 @@ type Analytics interface {
 	GetStats(request *entity.GetStatsRequest) (*entity.StatsResponse, error)
 	// GetPostUnionStats возвращает статистику по посту
 	GetPostUnionStats(request *entity.GetPostUnionStatsRequest) ([]*entity.PostPlatformStats, error)
+	// GetSentimentStats возвращает тональность комментариев к посту по дням
+	GetSentimentStats(request *entity.GetSentimentStatsRequest) (*entity.SentimentStatsResponse, error)
 	// GetUsersKPI возвращает KPI по постам для нескольких пользователей
 	GetUsersKPI(request *entity.GetUsersKPIRequest) (*entity.UsersKPIResponse, error)
 	// ProcessStatsUpdateTasks обрабатывает задачи обновления статистики
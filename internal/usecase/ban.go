@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+)
+
+type Ban interface {
+	// GetBans возвращает список банов команды
+	GetBans(request *entity.GetCommenterBansRequest) ([]*entity.CommenterBan, error)
+	// BanCommenter банит автора комментариев на платформе и возвращает ID бана
+	BanCommenter(request *entity.BanCommenterRequest) (int, error)
+	// UnbanCommenter снимает бан на платформе
+	UnbanCommenter(request *entity.UnbanCommenterRequest) error
+}
+
+var (
+	ErrBanNotFound  = errors.New("бан не найден")
+	ErrBanNotActive = errors.New("бан уже снят или истёк")
+)
@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+)
+
+type CannedReply interface {
+	// GetCannedReplies возвращает сохранённые ответы команды
+	GetCannedReplies(request *entity.GetCannedRepliesRequest) ([]*entity.CannedReply, error)
+	// AddCannedReply добавляет сохранённый ответ и возвращает его ID
+	AddCannedReply(request *entity.CannedReplyRequest) (int, error)
+	// EditCannedReply изменяет сохранённый ответ
+	EditCannedReply(request *entity.CannedReplyRequest) error
+	// DeleteCannedReply удаляет сохранённый ответ
+	DeleteCannedReply(request *entity.DeleteCannedReplyRequest) error
+}
+
+var (
+	ErrCannedReplyNotFound = errors.New("сохранённый ответ не найден")
+)
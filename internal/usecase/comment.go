@@ -23,6 +23,14 @@ type CommentActionPlatform interface {
 	ReplyComment(request *entity.ReplyCommentRequest) (int, error)
 	// DeleteComment удаляет комментарий
 	DeleteComment(request *entity.DeleteCommentRequest) error
+	// EditComment меняет текст ответа команды
+	EditComment(request *entity.EditCommentRequest) error
+	// ReactComment ставит или снимает реакцию команды на комментарий
+	ReactComment(request *entity.ReactCommentRequest) error
+	// BanUser банит автора комментариев в обсуждениях команды до ban.ExpiresAt или бессрочно
+	BanUser(ban *entity.CommenterBan) error
+	// UnbanUser снимает бан с автора комментариев
+	UnbanUser(ban *entity.CommenterBan) error
 }
 
 type Comment interface {
@@ -30,6 +38,10 @@ type Comment interface {
 	GetComment(request *entity.GetCommentRequest) (*entity.Comment, error)
 	// GetLastComments возвращает последние комментарии к посту
 	GetLastComments(request *entity.GetCommentsRequest) ([]*entity.Comment, error)
+	// GetCommentFeed возвращает общую ленту обсуждений команды, включая сообщения вне веток постов
+	GetCommentFeed(request *entity.GetCommentFeedRequest) ([]*entity.Comment, error)
+	// GetCommentTree возвращает дерево обсуждения поста или ветку ответов на комментарий
+	GetCommentTree(request *entity.GetCommentTreeRequest) (*entity.CommentTree, error)
 	// GetSummarize возвращает сводку по посту
 	GetSummarize(request *entity.SummarizeCommentRequest) (*entity.Summarize, error)
 	// Subscribe подписывается на получение новых комментариев. Теперь принимает context
@@ -38,14 +50,28 @@ type Comment interface {
 	ReplyComment(request *entity.ReplyCommentRequest) (int, error)
 	// DeleteComment удаляет комментарий
 	DeleteComment(request *entity.DeleteCommentRequest) error
+	// EditComment меняет текст ответа команды и сохраняет предыдущий в истории правок
+	EditComment(request *entity.EditCommentRequest) error
+	// GetCommentEdits возвращает историю правок комментария
+	GetCommentEdits(request *entity.GetCommentEditsRequest) ([]*entity.CommentEdit, error)
+	// ReactComment ставит или снимает реакцию команды на комментарий
+	ReactComment(request *entity.ReactCommentRequest) error
 	// ReplyIdeas предлагает варианты быстрого ответа на комментарий
 	ReplyIdeas(request *entity.ReplyIdeasRequest) (*entity.ReplyIdeasResponse, error)
 	// MarkAsTicket помечает комментарий как тикет
 	MarkAsTicket(request *entity.MarkAsTicketRequest) error
+	// ExportComments постранично читает комментарии для выгрузки и передаёт подходящие под фильтры в write
+	// от старых веток к новым. Ошибка write прерывает выгрузку
+	ExportComments(request *entity.ExportCommentsRequest, write func(comment *entity.Comment) error) error
 }
 
 var (
 	ErrCommentNotFound          = errors.New("comment not found")
 	ErrReplyCommentUnavailable  = errors.New("reply comment unavailable")
 	ErrCannotGenerateReplyIdeas = errors.New("cannot generate reply ideas")
+	ErrCommentNotEditable       = errors.New("only team replies can be edited")
+	ErrInvalidCommentText       = errors.New("invalid comment text")
+	ErrInvalidCommentReaction   = errors.New("invalid comment reaction")
+	ErrInvalidCommentExport     = errors.New("invalid comment export")
+	ErrInvalidCommentFeed       = errors.New("invalid comment feed request")
 )
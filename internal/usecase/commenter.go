@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+)
+
+type Commenter interface {
+	// GetCommenter возвращает профиль автора комментариев с метками и заметками команды
+	GetCommenter(request *entity.GetCommenterRequest) (*entity.Commenter, error)
+	// GetCommenterComments возвращает комментарии автора по всем постам команды
+	GetCommenterComments(request *entity.GetCommenterCommentsRequest) ([]*entity.Comment, error)
+	// SetTags заменяет метки автора
+	SetTags(request *entity.SetCommenterTagsRequest) error
+	// AddNote добавляет заметку об авторе и возвращает её ID
+	AddNote(request *entity.AddCommenterNoteRequest) (int, error)
+}
+
+var (
+	ErrCommenterNotFound = errors.New("автор комментариев не найден")
+)
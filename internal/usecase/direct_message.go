@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"postic-backend/internal/entity"
+)
+
+type DirectMessage interface {
+	// GetConversations возвращает личные переписки команды с последним сообщением
+	GetConversations(request *entity.GetDMConversationsRequest) ([]*entity.DMConversation, error)
+	// GetMessages возвращает сообщения переписки
+	GetMessages(request *entity.GetDirectMessagesRequest) ([]*entity.DirectMessage, error)
+	// SendMessage отправляет ответ команды в переписку и возвращает ID сообщения
+	SendMessage(request *entity.SendDirectMessageRequest) (int, error)
+	// ReadConversation отмечает переписку прочитанной
+	ReadConversation(request *entity.ReadDMConversationRequest) error
+	// GetTelegramLink возвращает ссылку, по которой подписчики начинают переписку с командой через бота
+	GetTelegramLink(request *entity.GetDMLinkRequest) (string, error)
+	// Subscribe подписывается на новые сообщения команды
+	Subscribe(ctx context.Context, request *entity.DMSubscriber) (<-chan *entity.DirectMessageEvent, error)
+}
+
+type DirectMessagePlatform interface {
+	// SendDirectMessage отправляет сообщение собеседнику и возвращает его ID на платформе
+	SendDirectMessage(conversation *entity.DMConversation, request *entity.SendDirectMessageRequest) (int64, error)
+}
+
+var (
+	ErrDMConversationNotFound   = errors.New("переписка не найдена")
+	ErrDirectMessageUnavailable = errors.New("личные сообщения недоступны")
+	ErrInvalidDirectMessage     = errors.New("некорректное сообщение")
+)
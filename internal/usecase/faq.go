@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+)
+
+// CommentAutoReplier отвечает на новые комментарии по правилам FAQ команды.
+// Используется слушателями платформ после сохранения комментария
+type CommentAutoReplier interface {
+	// AutoReply отправляет автоответ, если на комментарий сработало правило и не действует задержка
+	AutoReply(comment *entity.Comment)
+}
+
+type FAQ interface {
+	// GetRules возвращает правила автоответов команды
+	GetRules(request *entity.GetFAQRulesRequest) ([]*entity.FAQRule, error)
+	// AddRule добавляет правило автоответа и возвращает его ID
+	AddRule(request *entity.FAQRuleRequest) (int, error)
+	// EditRule изменяет правило автоответа
+	EditRule(request *entity.FAQRuleRequest) error
+	// DeleteRule удаляет правило автоответа
+	DeleteRule(request *entity.DeleteFAQRuleRequest) error
+	// GetSettings возвращает настройки автоответов команды
+	GetSettings(request *entity.GetFAQSettingsRequest) (*entity.FAQSettings, error)
+	// SetEnabled включает или выключает все автоответы команды
+	SetEnabled(request *entity.SetFAQEnabledRequest) error
+}
+
+var (
+	ErrFAQRuleNotFound = errors.New("правило автоответа не найдено")
+)
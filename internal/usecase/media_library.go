@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"errors"
+	"io"
+	"postic-backend/internal/entity"
+)
+
+type MediaLibrary interface {
+	// UploadFile загружает файл в медиатеку команды, если пользователь состоит в ней
+	UploadFile(upload *entity.Upload) (int, error)
+	// ImportURL скачивает файл по ссылке и загружает его в медиатеку команды
+	ImportURL(request *entity.ImportURLRequest) (int, error)
+	// GetFile возвращает файл, если у пользователя есть к нему доступ
+	GetFile(userID, mediaFileID int) (*entity.Upload, error)
+	// GetMediaFiles возвращает медиатеку команды с фильтрами
+	GetMediaFiles(request *entity.GetMediaLibraryRequest) ([]*entity.MediaFile, error)
+	// EditMediaFile переименовывает медиафайл и/или меняет его теги
+	EditMediaFile(request *entity.EditMediaFileRequest) (*entity.MediaFile, error)
+	// DeleteMediaFile удаляет медиафайл, если он не используется в постах
+	DeleteMediaFile(request *entity.DeleteMediaFileRequest) error
+	// CreateUploadSession создаёт сессию возобновляемой загрузки в медиатеку команды
+	CreateUploadSession(session *entity.UploadSession) (*entity.UploadSession, error)
+	// GetUploadSession возвращает сессию загрузки, если её создал пользователь
+	GetUploadSession(userID, sessionID int) (*entity.UploadSession, error)
+	// WriteUploadSession дописывает данные в сессию загрузки пользователя
+	WriteUploadSession(userID, sessionID int, offset int64, r io.Reader) (*entity.UploadSession, error)
+	// DeleteUploadSession прерывает загрузку пользователя
+	DeleteUploadSession(userID, sessionID int) error
+	// CreatePresignedUpload выдаёт ссылку для загрузки файла в медиатеку команды напрямую в хранилище
+	CreatePresignedUpload(request *entity.PresignUploadRequest) (*entity.UploadSession, *entity.PresignedURL, error)
+	// FinalizePresignedUpload завершает загрузку пользователя по ссылке и возвращает сессию с ID медиафайла
+	FinalizePresignedUpload(request *entity.FinalizeUploadRequest) (*entity.UploadSession, error)
+	// GetFileURL возвращает временную ссылку на файл, если у пользователя есть к нему доступ
+	GetFileURL(userID, mediaFileID int) (*entity.PresignedURL, error)
+	// GetStorageUsage возвращает занятое командой место, если пользователь состоит в ней
+	GetStorageUsage(request *entity.GetStorageUsageRequest) (*entity.TeamStorageUsage, error)
+}
+
+var (
+	ErrMediaFileNotFound = errors.New("медиафайл не найден")
+	ErrMediaFileInUse    = errors.New("медиафайл используется в постах")
+	ErrInvalidImportURL  = errors.New("недопустимая ссылка")
+	ErrURLImportFailed   = errors.New("не удалось скачать файл по ссылке")
+)
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+)
+
+// CommentModerator проверяет новые комментарии правилами модерации команды.
+// Используется слушателями платформ до публикации события о комментарии
+type CommentModerator interface {
+	// CheckComment возвращает самое строгое действие из сработавших правил или nil, если ни одно не сработало
+	CheckComment(comment *entity.Comment) (*entity.ModerationVerdict, error)
+	// CheckEditedComment проверяет изменённый текст сохранённого комментария правилами по тексту
+	CheckEditedComment(comment *entity.Comment) (*entity.ModerationVerdict, error)
+	// LogAction записывает в журнал автоматическое действие над сохранённым комментарием
+	LogAction(comment *entity.Comment, verdict *entity.ModerationVerdict, succeeded bool) error
+	// OpenTicket открывает тикет по сохранённому комментарию, на который сработало правило с действием ticket
+	OpenTicket(comment *entity.Comment, verdict *entity.ModerationVerdict) error
+	// RecordBan добавляет в список банов команды автора, забаненного правилом с действием ban
+	RecordBan(comment *entity.Comment, verdict *entity.ModerationVerdict) error
+}
+
+type Moderation interface {
+	// GetRules возвращает правила модерации команды
+	GetRules(request *entity.GetModerationRulesRequest) ([]*entity.ModerationRule, error)
+	// AddRule добавляет правило модерации и возвращает его ID
+	AddRule(request *entity.ModerationRuleRequest) (int, error)
+	// EditRule изменяет правило модерации
+	EditRule(request *entity.ModerationRuleRequest) error
+	// DeleteRule удаляет правило модерации
+	DeleteRule(request *entity.DeleteModerationRuleRequest) error
+	// GetLog возвращает журнал автоматических действий модерации
+	GetLog(request *entity.GetModerationLogRequest) ([]*entity.ModerationLogEntry, error)
+	// GetHeldComments возвращает комментарии, отложенные до проверки
+	GetHeldComments(request *entity.GetHeldCommentsRequest) ([]*entity.Comment, error)
+	// ApproveHeldComment публикует отложенный комментарий
+	ApproveHeldComment(request *entity.ApproveHeldCommentRequest) error
+}
+
+var (
+	ErrModerationRuleNotFound = errors.New("правило модерации не найдено")
+	ErrHeldCommentNotFound    = errors.New("отложенный комментарий не найден")
+)
@@ -19,6 +19,7 @@ type Analytics struct {
 
 	telegramAnalytics  usecase.AnalyticsPlatform
 	vkontakteAnalytics usecase.AnalyticsPlatform
+	teamEventRepo      repo.TeamEventRepository // nil, если Kafka не настроена
 }
 
 func NewAnalytics(
@@ -27,6 +28,7 @@ func NewAnalytics(
 	postRepo repo.Post,
 	telegramAnalytics usecase.AnalyticsPlatform,
 	vkontakteAnalytics usecase.AnalyticsPlatform,
+	teamEventRepo repo.TeamEventRepository,
 ) usecase.Analytics {
 	return &Analytics{
 		analyticsRepo:      analyticsRepo,
@@ -34,6 +36,7 @@ func NewAnalytics(
 		postRepo:           postRepo,
 		telegramAnalytics:  telegramAnalytics,
 		vkontakteAnalytics: vkontakteAnalytics,
+		teamEventRepo:      teamEventRepo,
 	}
 }
 
@@ -181,6 +184,43 @@ func (a *Analytics) GetPostUnionStats(request *entity.GetPostUnionStatsRequest)
 	return allStats, nil
 }
 
+func (a *Analytics) GetSentimentStats(request *entity.GetSentimentStatsRequest) (*entity.SentimentStatsResponse, error) {
+	roles, err := a.teamRepo.GetTeamUserRoles(request.TeamID, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(roles, repo.AdminRole) && !slices.Contains(roles, repo.AnalyticsRole) {
+		return nil, usecase.ErrUserForbidden
+	}
+
+	postUnion, err := a.postRepo.GetPostUnion(request.PostUnionID)
+	if errors.Is(err, repo.ErrPostUnionNotFound) {
+		return nil, usecase.ErrPostUnionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if postUnion.TeamID != request.TeamID {
+		return nil, usecase.ErrPostUnionNotFound
+	}
+
+	if request.End.IsZero() {
+		request.End = time.Now()
+	}
+	if request.Start.IsZero() {
+		request.Start = request.End.AddDate(0, 0, -30)
+	}
+
+	points, err := a.analyticsRepo.GetCommentSentimentByDay(request.PostUnionID, request.Start, request.End)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.SentimentStatsResponse{
+		PostUnionID: request.PostUnionID,
+		Points:      points,
+	}, nil
+}
+
 func (a *Analytics) GetUsersKPI(request *entity.GetUsersKPIRequest) (*entity.UsersKPIResponse, error) {
 	// Проверяем права пользователя
 	roles, err := a.teamRepo.GetTeamUserRoles(request.TeamID, request.UserID)
@@ -274,7 +314,17 @@ func (a *Analytics) processStatsUpdateTask(task *entity.StatsUpdateTask, workerI
 	nextUpdateAt := time.Now().Add(time.Duration(nextInterval) * time.Minute)
 
 	// Обновляем задачу
-	return a.analyticsRepo.UpdateStatsUpdateTask(task.ID, nextUpdateAt, nextInterval)
+	if err := a.analyticsRepo.UpdateStatsUpdateTask(task.ID, nextUpdateAt, nextInterval); err != nil {
+		return err
+	}
+	publishTeamEvent(a.teamEventRepo, &entity.TeamEvent{
+		TeamID:      postUnion.TeamID,
+		Type:        entity.TeamStatsUpdated,
+		PostUnionID: task.PostUnionID,
+		Platform:    task.Platform,
+		OccurredAt:  time.Now(),
+	})
+	return nil
 }
 
 func (a *Analytics) calculateNextInterval(currentInterval int, postAge time.Duration) int {
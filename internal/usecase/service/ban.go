@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"postic-backend/internal/usecase"
+	"slices"
+	"time"
+)
+
+// banCommenter банит автора на платформе и сохраняет бан в списке команды
+func banCommenter(banRepo repo.Ban, action usecase.CommentActionPlatform, ban *entity.CommenterBan) (int, error) {
+	if err := action.BanUser(ban); err != nil {
+		return 0, fmt.Errorf("ошибка при бане автора %d на платформе %s: %w", ban.UserPlatformID, ban.Platform, err)
+	}
+	return banRepo.AddBan(ban)
+}
+
+type Ban struct {
+	banRepo         repo.Ban
+	commentRepo     repo.Comment
+	commenterRepo   repo.Commenter
+	teamRepo        repo.Team
+	telegramAction  usecase.CommentActionPlatform
+	vkontakteAction usecase.CommentActionPlatform
+}
+
+func NewBan(
+	banRepo repo.Ban,
+	commentRepo repo.Comment,
+	commenterRepo repo.Commenter,
+	teamRepo repo.Team,
+	telegramAction usecase.CommentActionPlatform,
+	vkontakteAction usecase.CommentActionPlatform,
+) usecase.Ban {
+	return &Ban{
+		banRepo:         banRepo,
+		commentRepo:     commentRepo,
+		commenterRepo:   commenterRepo,
+		teamRepo:        teamRepo,
+		telegramAction:  telegramAction,
+		vkontakteAction: vkontakteAction,
+	}
+}
+
+// checkRoles проверяет, что пользователь может работать с комментариями команды
+func (b *Ban) checkRoles(teamID, userID int) error {
+	roles, err := b.teamRepo.GetTeamUserRoles(teamID, userID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(roles, repo.AdminRole) && !slices.Contains(roles, repo.CommentsRole) {
+		return usecase.ErrUserForbidden
+	}
+	return nil
+}
+
+// platformAction возвращает действия платформы, на которой написан комментарий
+func (b *Ban) platformAction(platform string) (usecase.CommentActionPlatform, error) {
+	switch platform {
+	case "tg":
+		return b.telegramAction, nil
+	case "vk":
+		return b.vkontakteAction, nil
+	}
+	return nil, usecase.ErrCommenterNotFound
+}
+
+// newBan заполняет бан автором из комментария или профиля автора
+func (b *Ban) newBan(request *entity.BanCommenterRequest) (*entity.CommenterBan, error) {
+	ban := &entity.CommenterBan{
+		TeamID:         request.TeamID,
+		Platform:       request.Platform,
+		UserPlatformID: request.UserPlatformID,
+		Reason:         request.Reason,
+		BannedBy:       &request.UserID,
+		CreatedAt:      time.Now(),
+	}
+	if request.DurationSeconds != 0 {
+		expiresAt := ban.CreatedAt.Add(time.Duration(request.DurationSeconds) * time.Second)
+		ban.ExpiresAt = &expiresAt
+	}
+
+	if request.CommentID != 0 {
+		comment, err := b.commentRepo.GetComment(request.CommentID)
+		switch {
+		case errors.Is(err, repo.ErrCommentNotFound):
+			return nil, usecase.ErrCommentNotFound
+		case err != nil:
+			return nil, err
+		}
+		if comment.TeamID != request.TeamID || comment.IsTeamReply {
+			return nil, usecase.ErrCommentNotFound
+		}
+		ban.Platform = comment.Platform
+		ban.UserPlatformID = comment.UserPlatformID
+		ban.FullName = comment.FullName
+		ban.Username = comment.Username
+		ban.CommentID = &comment.ID
+		return ban, nil
+	}
+
+	commenter, err := b.commenterRepo.GetCommenter(request.TeamID, request.Platform, request.UserPlatformID)
+	switch {
+	case errors.Is(err, repo.ErrCommenterNotFound):
+		return nil, usecase.ErrCommenterNotFound
+	case err != nil:
+		return nil, err
+	}
+	ban.FullName = commenter.FullName
+	ban.Username = commenter.Username
+	return ban, nil
+}
+
+func (b *Ban) GetBans(request *entity.GetCommenterBansRequest) ([]*entity.CommenterBan, error) {
+	if err := b.checkRoles(request.TeamID, request.UserID); err != nil {
+		return nil, err
+	}
+	if request.Offset.IsZero() {
+		request.Offset = time.Now()
+	}
+	if request.Limit <= 0 || request.Limit > 100 {
+		request.Limit = 100
+	}
+	return b.banRepo.GetBans(request.TeamID, request.Platform, request.ActiveOnly, request.Offset, request.OffsetID, request.Limit)
+}
+
+func (b *Ban) BanCommenter(request *entity.BanCommenterRequest) (int, error) {
+	if err := b.checkRoles(request.TeamID, request.UserID); err != nil {
+		return 0, err
+	}
+	ban, err := b.newBan(request)
+	if err != nil {
+		return 0, err
+	}
+	// сообщества и каналы пишут с отрицательным ID, их не забанить
+	if ban.UserPlatformID <= 0 {
+		return 0, usecase.ErrCommenterNotFound
+	}
+	action, err := b.platformAction(ban.Platform)
+	if err != nil {
+		return 0, err
+	}
+	return banCommenter(b.banRepo, action, ban)
+}
+
+func (b *Ban) UnbanCommenter(request *entity.UnbanCommenterRequest) error {
+	if err := b.checkRoles(request.TeamID, request.UserID); err != nil {
+		return err
+	}
+	ban, err := b.banRepo.GetBan(request.BanID)
+	switch {
+	case errors.Is(err, repo.ErrBanNotFound):
+		return usecase.ErrBanNotFound
+	case err != nil:
+		return err
+	}
+	if ban.TeamID != request.TeamID {
+		return usecase.ErrBanNotFound
+	}
+	now := time.Now()
+	if !ban.IsActive(now) {
+		return usecase.ErrBanNotActive
+	}
+
+	action, err := b.platformAction(ban.Platform)
+	if err != nil {
+		return err
+	}
+	if err := action.UnbanUser(ban); err != nil {
+		return fmt.Errorf("ошибка при снятии бана с автора %d на платформе %s: %w", ban.UserPlatformID, ban.Platform, err)
+	}
+	err = b.banRepo.LiftBan(ban.ID, &request.UserID, now)
+	if errors.Is(err, repo.ErrBanNotFound) {
+		return usecase.ErrBanNotActive
+	}
+	return err
+}
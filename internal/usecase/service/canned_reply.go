@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"postic-backend/internal/usecase"
+	"slices"
+	"time"
+)
+
+type CannedReply struct {
+	cannedReplyRepo  repo.CannedReply
+	teamRepo         repo.Team
+	mediaLibraryRepo repo.MediaLibrary
+}
+
+func NewCannedReply(cannedReplyRepo repo.CannedReply, teamRepo repo.Team, mediaLibraryRepo repo.MediaLibrary) usecase.CannedReply {
+	return &CannedReply{
+		cannedReplyRepo:  cannedReplyRepo,
+		teamRepo:         teamRepo,
+		mediaLibraryRepo: mediaLibraryRepo,
+	}
+}
+
+// checkRoles проверяет, что пользователь может отвечать на комментарии команды
+func (c *CannedReply) checkRoles(teamID, userID int) error {
+	roles, err := c.teamRepo.GetTeamUserRoles(teamID, userID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(roles, repo.AdminRole) && !slices.Contains(roles, repo.CommentsRole) {
+		return usecase.ErrUserForbidden
+	}
+	return nil
+}
+
+// checkAttachments проверяет, что пользователь имеет доступ ко всем вложениям ответа
+func (c *CannedReply) checkAttachments(userID int, attachments []int) error {
+	for _, mediaFileID := range attachments {
+		allowed, err := c.mediaLibraryRepo.CanUserAccessMediaFile(userID, mediaFileID)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return usecase.ErrMediaFileNotFound
+		}
+	}
+	return nil
+}
+
+// getTeamCannedReply возвращает ответ, только если он принадлежит команде
+func (c *CannedReply) getTeamCannedReply(teamID, cannedReplyID int) (*entity.CannedReply, error) {
+	cannedReply, err := c.cannedReplyRepo.GetCannedReply(cannedReplyID)
+	switch {
+	case errors.Is(err, repo.ErrCannedReplyNotFound):
+		return nil, usecase.ErrCannedReplyNotFound
+	case err != nil:
+		return nil, err
+	}
+	if cannedReply.TeamID != teamID {
+		return nil, usecase.ErrCannedReplyNotFound
+	}
+	return cannedReply, nil
+}
+
+func (c *CannedReply) GetCannedReplies(request *entity.GetCannedRepliesRequest) ([]*entity.CannedReply, error) {
+	if err := c.checkRoles(request.TeamID, request.UserID); err != nil {
+		return nil, err
+	}
+	return c.cannedReplyRepo.GetCannedReplies(request.TeamID)
+}
+
+func (c *CannedReply) AddCannedReply(request *entity.CannedReplyRequest) (int, error) {
+	if err := c.checkRoles(request.TeamID, request.UserID); err != nil {
+		return 0, err
+	}
+	if err := c.checkAttachments(request.UserID, request.Attachments); err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	return c.cannedReplyRepo.AddCannedReply(&entity.CannedReply{
+		TeamID:      request.TeamID,
+		Title:       request.Title,
+		Text:        request.Text,
+		Attachments: request.Attachments,
+		CreatedBy:   &request.UserID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+func (c *CannedReply) EditCannedReply(request *entity.CannedReplyRequest) error {
+	if err := c.checkRoles(request.TeamID, request.UserID); err != nil {
+		return err
+	}
+	cannedReply, err := c.getTeamCannedReply(request.TeamID, request.ID)
+	if err != nil {
+		return err
+	}
+	if err := c.checkAttachments(request.UserID, request.Attachments); err != nil {
+		return err
+	}
+
+	cannedReply.Title = request.Title
+	cannedReply.Text = request.Text
+	cannedReply.Attachments = request.Attachments
+	cannedReply.UpdatedAt = time.Now()
+	err = c.cannedReplyRepo.EditCannedReply(cannedReply)
+	if errors.Is(err, repo.ErrCannedReplyNotFound) {
+		return usecase.ErrCannedReplyNotFound
+	}
+	return err
+}
+
+func (c *CannedReply) DeleteCannedReply(request *entity.DeleteCannedReplyRequest) error {
+	if err := c.checkRoles(request.TeamID, request.UserID); err != nil {
+		return err
+	}
+	if _, err := c.getTeamCannedReply(request.TeamID, request.CannedReplyID); err != nil {
+		return err
+	}
+	err := c.cannedReplyRepo.DeleteCannedReply(request.CannedReplyID)
+	if errors.Is(err, repo.ErrCannedReplyNotFound) {
+		return usecase.ErrCannedReplyNotFound
+	}
+	return err
+}
@@ -5,12 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"postic-backend/internal/entity"
 	"postic-backend/internal/repo"
 	"postic-backend/internal/usecase"
 	"slices"
-	"strings"
+	"time"
 
 	"github.com/labstack/gommon/log"
 )
@@ -21,9 +22,13 @@ type Comment struct {
 	teamRepo        repo.Team
 	telegramAction  usecase.CommentActionPlatform
 	vkontakteAction usecase.CommentActionPlatform
-	summarizeURL    string
+	summarizer      *CommentSummarizer
 	replyIdeasURL   string
 	eventRepo       repo.CommentEventRepository // Kafka-репозиторий событий комментариев
+	ticketRepo      repo.Ticket
+	cannedReplyRepo repo.CannedReply
+	banRepo         repo.Ban
+	teamEventRepo   repo.TeamEventRepository // общий поток событий команды, сюда попадают изменения тикетов
 }
 
 func NewComment(
@@ -32,9 +37,13 @@ func NewComment(
 	teamRepo repo.Team,
 	telegramAction usecase.CommentActionPlatform,
 	vkontakteAction usecase.CommentActionPlatform,
-	summarizeURL string,
+	summarizer *CommentSummarizer,
 	replyIdeasURL string,
 	eventRepo repo.CommentEventRepository,
+	ticketRepo repo.Ticket,
+	cannedReplyRepo repo.CannedReply,
+	banRepo repo.Ban,
+	teamEventRepo repo.TeamEventRepository,
 ) usecase.Comment {
 	return &Comment{
 		commentRepo:     commentRepo,
@@ -42,9 +51,13 @@ func NewComment(
 		teamRepo:        teamRepo,
 		telegramAction:  telegramAction,
 		vkontakteAction: vkontakteAction,
-		summarizeURL:    summarizeURL,
+		summarizer:      summarizer,
 		replyIdeasURL:   replyIdeasURL,
 		eventRepo:       eventRepo,
+		ticketRepo:      ticketRepo,
+		cannedReplyRepo: cannedReplyRepo,
+		banRepo:         banRepo,
+		teamEventRepo:   teamEventRepo,
 	}
 }
 
@@ -168,9 +181,13 @@ func (c *Comment) GetLastComments(request *entity.GetCommentsRequest) ([]*entity
 	// Получаем комментарии из репозитория, используя текущее время как верхнюю границу
 	// для получения самых последних комментариев
 	var comments []*entity.Comment
-	if request.MarkedAsTicket == nil || !*request.MarkedAsTicket {
-		comments, err = c.commentRepo.GetComments(request.TeamID, request.PostUnionID, request.Offset, request.Before, request.Limit)
-	} else {
+	switch {
+	case request.Sentiment != "" || request.Topic != "" || request.UserPlatformID != 0:
+		// отфильтрованные комментарии не складываются в ветки: родитель может не подойти под фильтр
+		comments, err = c.commentRepo.GetFilteredComments(request)
+	case request.MarkedAsTicket == nil || !*request.MarkedAsTicket:
+		comments, err = c.commentRepo.GetComments(request.TeamID, request.PostUnionID, request.Offset, 0, request.Before, request.Limit)
+	default:
 		comments, err = c.commentRepo.GetTicketComments(request.TeamID, request.Offset, request.Before, request.Limit)
 	}
 	if err != nil {
@@ -180,7 +197,7 @@ func (c *Comment) GetLastComments(request *entity.GetCommentsRequest) ([]*entity
 	return comments, nil
 }
 
-func (c *Comment) GetSummarize(request *entity.SummarizeCommentRequest) (*entity.Summarize, error) {
+func (c *Comment) GetCommentFeed(request *entity.GetCommentFeedRequest) ([]*entity.Comment, error) {
 	// проверяем права пользователя
 	roles, err := c.teamRepo.GetTeamUserRoles(request.TeamID, request.UserID)
 	if err != nil {
@@ -189,55 +206,135 @@ func (c *Comment) GetSummarize(request *entity.SummarizeCommentRequest) (*entity
 	if !slices.Contains(roles, repo.AdminRole) && !slices.Contains(roles, repo.CommentsRole) {
 		return nil, usecase.ErrUserForbidden
 	}
+	if err := request.IsValid(); err != nil {
+		return nil, fmt.Errorf("%w: %v", usecase.ErrInvalidCommentFeed, err)
+	}
 
-	comments, err := c.commentRepo.GetLastComments(request.PostUnionID, 100)
+	if request.Offset.IsZero() {
+		request.Offset = time.Now()
+	}
+	if request.Limit == 0 || request.Limit > 100 {
+		request.Limit = 100
+	}
+	return c.commentRepo.GetCommentFeed(request)
+}
+
+const (
+	// commentTreeDepth сколько уровней ответов возвращается под комментариями страницы по умолчанию
+	commentTreeDepth    = 3
+	commentTreeMaxDepth = 10
+	// commentTreeChildLimit сколько первых ответов возвращается под каждым комментарием по умолчанию
+	commentTreeChildLimit    = 5
+	commentTreeMaxChildLimit = 50
+	commentTreeLimit         = 20
+)
+
+func (c *Comment) GetCommentTree(request *entity.GetCommentTreeRequest) (*entity.CommentTree, error) {
+	// проверяем права пользователя
+	roles, err := c.teamRepo.GetTeamUserRoles(request.TeamID, request.UserID)
 	if err != nil {
 		return nil, err
 	}
-	var payload struct {
-		Comments string `json:"comments"`
+	if !slices.Contains(roles, repo.AdminRole) && !slices.Contains(roles, repo.CommentsRole) {
+		return nil, usecase.ErrUserForbidden
 	}
-	if len(comments) == 0 {
-		payload.Comments = "Нет комментариев для суммаризации"
-	} else {
-		var builder strings.Builder
-		for _, comment := range comments {
-			builder.WriteString(comment.Text)
-			builder.WriteString("\n\n")
+
+	tree := &entity.CommentTree{}
+	switch {
+	case request.ParentID != 0:
+		parent, err := c.commentRepo.GetComment(request.ParentID)
+		switch {
+		case errors.Is(err, repo.ErrCommentNotFound):
+			return nil, usecase.ErrCommentNotFound
+		case err != nil:
+			return nil, err
+		}
+		if parent.TeamID != request.TeamID || parent.HeldForReview {
+			return nil, usecase.ErrCommentNotFound
+		}
+		// ответы лежат в том же обсуждении, что и родитель, у общего обсуждения post_union_id пустой
+		request.PostUnionID = 0
+		tree.Parent = parent
+	case request.PostUnionID != 0:
+		// проверяем, что postUnion принадлежит этой команде
+		postUnion, err := c.postRepo.GetPostUnion(request.PostUnionID)
+		if err != nil {
+			return nil, err
+		}
+		if postUnion.TeamID != request.TeamID {
+			return nil, usecase.ErrUserForbidden
 		}
-		payload.Comments = builder.String()
 	}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return nil, err
+	if request.Offset.IsZero() && request.Before {
+		request.Offset = time.Now()
 	}
-	req, err := http.NewRequest("POST", c.summarizeURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, err
+	if request.Limit == 0 || request.Limit > 100 {
+		request.Limit = commentTreeLimit
+	}
+	if request.Depth == nil {
+		depth := commentTreeDepth
+		request.Depth = &depth
+	} else if *request.Depth > commentTreeMaxDepth {
+		depth := commentTreeMaxDepth
+		request.Depth = &depth
+	}
+	if request.ChildLimit == 0 {
+		request.ChildLimit = commentTreeChildLimit
+	} else if request.ChildLimit > commentTreeMaxChildLimit {
+		request.ChildLimit = commentTreeMaxChildLimit
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	nodes, err := c.commentRepo.GetCommentTree(request)
 	if err != nil {
 		return nil, err
 	}
 
-	type ServerAnswer struct {
-		Response string `json:"response"`
+	// узлы приходят по уровням, поэтому родитель всегда обработан раньше своих ответов
+	tree.Comments = make([]*entity.CommentNode, 0, request.Limit)
+	byID := make(map[int]*entity.CommentNode, len(nodes))
+	for _, node := range nodes {
+		node.Replies = make([]*entity.CommentNode, 0)
+		byID[node.ID] = node
+		if node.Depth == 0 {
+			tree.Comments = append(tree.Comments, node)
+			continue
+		}
+		if parent, ok := byID[node.ReplyToCommentID]; ok {
+			parent.Replies = append(parent.Replies, node)
+		}
+	}
+	if len(tree.Comments) == request.Limit {
+		nextOffset := tree.Comments[len(tree.Comments)-1].CreatedAt
+		tree.NextOffset = &nextOffset
 	}
+	return tree, nil
+}
 
-	var serverAnswer ServerAnswer
-	err = json.NewDecoder(resp.Body).Decode(&serverAnswer)
+func (c *Comment) GetSummarize(request *entity.SummarizeCommentRequest) (*entity.Summarize, error) {
+	// проверяем права пользователя
+	roles, err := c.teamRepo.GetTeamUserRoles(request.TeamID, request.UserID)
 	if err != nil {
 		return nil, err
 	}
-	log.Infof("response: %v", serverAnswer)
-	return &entity.Summarize{
-		Markdown:    serverAnswer.Response,
-		PostUnionID: request.PostUnionID,
-	}, nil
+	if !slices.Contains(roles, repo.AdminRole) && !slices.Contains(roles, repo.CommentsRole) {
+		return nil, usecase.ErrUserForbidden
+	}
+
+	// проверяем, что postUnion принадлежит этой команде
+	postUnion, err := c.postRepo.GetPostUnion(request.PostUnionID)
+	switch {
+	case errors.Is(err, repo.ErrPostUnionNotFound):
+		return nil, usecase.ErrPostUnionNotFound
+	case err != nil:
+		return nil, err
+	}
+	if postUnion.TeamID != request.TeamID {
+		return nil, usecase.ErrUserForbidden
+	}
+
+	// сводка хранится и обновляется в фоне, без refresh возвращается сохранённая
+	return c.summarizer.GetSummary(context.Background(), request.TeamID, request.PostUnionID, request.Refresh)
 }
 
 // Subscribe подписывается на получение новых комментариев через Kafka
@@ -267,17 +364,43 @@ func (c *Comment) Subscribe(ctx context.Context, request *entity.Subscriber) (<-
 		}
 	}
 
+	switch request.Discussion {
+	case "", entity.CommentDiscussionPost, entity.CommentDiscussionFree:
+	default:
+		return nil, fmt.Errorf("%w: discussion must be post or free", usecase.ErrInvalidCommentFeed)
+	}
+
 	// Подписываемся на события комментариев через Kafka
 	ch, err := c.eventRepo.SubscribeCommentEvents(
 		ctx,
 		request.TeamID,
 		request.PostUnionID,
+		request.LastEventID,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if request.PostUnionID != 0 || request.Discussion == "" {
+		return ch, nil
+	}
 
-	return ch, nil
+	// у событий по сообщениям вне веток постов PostID равен 0
+	free := request.Discussion == entity.CommentDiscussionFree
+	filtered := make(chan *entity.CommentEvent)
+	go func() {
+		defer close(filtered)
+		for event := range ch {
+			if (event.PostID == 0) != free {
+				continue
+			}
+			select {
+			case filtered <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return filtered, nil
 }
 
 func (c *Comment) ReplyComment(request *entity.ReplyCommentRequest) (int, error) {
@@ -301,19 +424,48 @@ func (c *Comment) ReplyComment(request *entity.ReplyCommentRequest) (int, error)
 		return 0, usecase.ErrUserForbidden
 	}
 
+	// сохранённый ответ подставляется до проверки длины: после подстановки имени текст может стать длиннее
+	if request.CannedReplyID != 0 {
+		cannedReply, err := c.cannedReplyRepo.GetCannedReply(request.CannedReplyID)
+		switch {
+		case errors.Is(err, repo.ErrCannedReplyNotFound):
+			return 0, usecase.ErrCannedReplyNotFound
+		case err != nil:
+			return 0, err
+		}
+		if cannedReply.TeamID != request.TeamID {
+			return 0, usecase.ErrCannedReplyNotFound
+		}
+		request.Text = cannedReply.Render(comment)
+		request.Attachments = append(request.Attachments, cannedReply.Attachments...)
+	}
+
 	// валидация длины текста для платформы
 	if err := request.IsValid(comment.Platform); err != nil {
 		return 0, err
 	}
 
 	// делегируем отправку комментария
+	var replyID int
 	switch comment.Platform {
 	case "vk":
-		return c.vkontakteAction.ReplyComment(request)
+		replyID, err = c.vkontakteAction.ReplyComment(request)
 	case "tg":
-		return c.telegramAction.ReplyComment(request)
+		replyID, err = c.telegramAction.ReplyComment(request)
+	}
+	if err != nil {
+		return 0, err
+	}
+	// ответ на комментарий тикета считается первым ответом по тикету
+	if err := c.ticketRepo.SetFirstResponse(comment.ID, time.Now()); err != nil {
+		log.Errorf("Failed to set ticket first response: %v", err)
 	}
-	return 0, nil
+	if request.CannedReplyID != 0 {
+		if err := c.cannedReplyRepo.AddCannedReplyUsage(request.CannedReplyID, time.Now()); err != nil {
+			log.Errorf("Failed to count canned reply usage: %v", err)
+		}
+	}
+	return replyID, nil
 }
 
 func (c *Comment) DeleteComment(request *entity.DeleteCommentRequest) error {
@@ -336,15 +488,130 @@ func (c *Comment) DeleteComment(request *entity.DeleteCommentRequest) error {
 	if comment.TeamID != request.TeamID {
 		return usecase.ErrUserForbidden
 	}
+	var action usecase.CommentActionPlatform
 	switch comment.Platform {
 	case "vk":
-		return c.vkontakteAction.DeleteComment(request)
+		action = c.vkontakteAction
 	case "tg":
-		return c.telegramAction.DeleteComment(request)
+		action = c.telegramAction
+	default:
+		return nil
+	}
+	if err := action.DeleteComment(request); err != nil {
+		return err
+	}
+
+	// Бан автора — дополнение к удалению: комментарий уже удалён, поэтому ошибку только логируем
+	if request.BanUser && !comment.IsTeamReply && comment.UserPlatformID > 0 {
+		_, err := banCommenter(c.banRepo, action, &entity.CommenterBan{
+			TeamID:         comment.TeamID,
+			Platform:       comment.Platform,
+			UserPlatformID: comment.UserPlatformID,
+			FullName:       comment.FullName,
+			Username:       comment.Username,
+			CommentID:      &comment.ID,
+			BannedBy:       &request.UserID,
+			CreatedAt:      time.Now(),
+		})
+		if err != nil {
+			log.Errorf("Не удалось забанить автора комментария %d: %v", comment.ID, err)
+		}
 	}
 	return nil
 }
 
+func (c *Comment) EditComment(request *entity.EditCommentRequest) error {
+	// проверяем права пользователя
+	roles, err := c.teamRepo.GetTeamUserRoles(request.TeamID, request.UserID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(roles, repo.AdminRole) && !slices.Contains(roles, repo.CommentsRole) {
+		return usecase.ErrUserForbidden
+	}
+	comment, err := c.commentRepo.GetComment(request.CommentID)
+	switch {
+	case errors.Is(err, repo.ErrCommentNotFound):
+		return usecase.ErrCommentNotFound
+	case err != nil:
+		return err
+	}
+	if comment.TeamID != request.TeamID || comment.IsDeleted {
+		return usecase.ErrCommentNotFound
+	}
+	// комментарии подписчиков Postic изменить не может
+	if !comment.IsTeamReply {
+		return usecase.ErrCommentNotEditable
+	}
+	// ограничения на текст зависят от платформы и вложений, поэтому проверяются только здесь
+	if err := request.IsValid(comment); err != nil {
+		return fmt.Errorf("%w: %v", usecase.ErrInvalidCommentText, err)
+	}
+	switch comment.Platform {
+	case "vk":
+		return c.vkontakteAction.EditComment(request)
+	case "tg":
+		return c.telegramAction.EditComment(request)
+	}
+	return usecase.ErrCommentNotEditable
+}
+
+func (c *Comment) ReactComment(request *entity.ReactCommentRequest) error {
+	// проверяем права пользователя
+	roles, err := c.teamRepo.GetTeamUserRoles(request.TeamID, request.UserID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(roles, repo.AdminRole) && !slices.Contains(roles, repo.CommentsRole) {
+		return usecase.ErrUserForbidden
+	}
+	comment, err := c.commentRepo.GetComment(request.CommentID)
+	switch {
+	case errors.Is(err, repo.ErrCommentNotFound):
+		return usecase.ErrCommentNotFound
+	case err != nil:
+		return err
+	}
+	if comment.TeamID != request.TeamID || comment.IsDeleted {
+		return usecase.ErrCommentNotFound
+	}
+	if err := request.IsValid(comment); err != nil {
+		return fmt.Errorf("%w: %v", usecase.ErrInvalidCommentReaction, err)
+	}
+	if comment.TeamReaction == request.Reaction {
+		return nil
+	}
+	switch comment.Platform {
+	case "vk":
+		return c.vkontakteAction.ReactComment(request)
+	case "tg":
+		return c.telegramAction.ReactComment(request)
+	}
+	return nil
+}
+
+func (c *Comment) GetCommentEdits(request *entity.GetCommentEditsRequest) ([]*entity.CommentEdit, error) {
+	// проверяем права пользователя
+	roles, err := c.teamRepo.GetTeamUserRoles(request.TeamID, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(roles, repo.AdminRole) && !slices.Contains(roles, repo.CommentsRole) {
+		return nil, usecase.ErrUserForbidden
+	}
+	comment, err := c.commentRepo.GetComment(request.CommentID)
+	switch {
+	case errors.Is(err, repo.ErrCommentNotFound):
+		return nil, usecase.ErrCommentNotFound
+	case err != nil:
+		return nil, err
+	}
+	if comment.TeamID != request.TeamID {
+		return nil, usecase.ErrCommentNotFound
+	}
+	return c.commentRepo.GetCommentEdits(comment.ID)
+}
+
 func (c *Comment) MarkAsTicket(request *entity.MarkAsTicketRequest) error {
 	// проверяем права пользователя
 	roles, err := c.teamRepo.GetTeamUserRoles(request.TeamID, request.UserID)
@@ -365,11 +632,109 @@ func (c *Comment) MarkAsTicket(request *entity.MarkAsTicketRequest) error {
 	if comment.TeamID != request.TeamID {
 		return usecase.ErrUserForbidden
 	}
-	// обновляем комментарий, помечая (или наоборот, убирая) его как тикет
-	comment.MarkedAsTicket = request.MarkedAsTicket
-	err = c.commentRepo.EditComment(comment)
+	// пометка открывает тикет по комментарию, снятие пометки отвязывает комментарий от его тикета
+	if !request.MarkedAsTicket {
+		ticketID, err := c.ticketRepo.GetTicketIDByComment(comment.ID)
+		if errors.Is(err, repo.ErrTicketNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = c.ticketRepo.UnlinkComment(ticketID, comment.ID)
+		if errors.Is(err, repo.ErrTicketCommentNotLinked) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		publishTicketUpdated(c.teamEventRepo, comment.TeamID, ticketID)
+		return nil
+	}
+	_, err = newCommentTicket(c.ticketRepo, c.teamEventRepo, comment, &request.UserID, "", "", nil)
+	if errors.Is(err, repo.ErrTicketCommentLinked) {
+		// комментарий уже в тикете
+		return nil
+	}
+	return err
+}
+
+// commentExportPageSize сколько веток читается из базы за один запрос при выгрузке
+const commentExportPageSize = 100
+
+func (c *Comment) ExportComments(request *entity.ExportCommentsRequest, write func(comment *entity.Comment) error) error {
+	// проверяем права пользователя
+	roles, err := c.teamRepo.GetTeamUserRoles(request.TeamID, request.UserID)
 	if err != nil {
 		return err
 	}
-	return nil
+	if !slices.Contains(roles, repo.AdminRole) && !slices.Contains(roles, repo.CommentsRole) {
+		return usecase.ErrUserForbidden
+	}
+	if err := request.IsValid(); err != nil {
+		return fmt.Errorf("%w: %v", usecase.ErrInvalidCommentExport, err)
+	}
+	if request.PostUnionID != 0 {
+		// проверяем, что postUnion принадлежит этой команде
+		postUnion, err := c.postRepo.GetPostUnion(request.PostUnionID)
+		if err != nil {
+			return err
+		}
+		if postUnion.TeamID != request.TeamID {
+			return usecase.ErrUserForbidden
+		}
+	}
+
+	to := request.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	// GetComments отбирает ветки строго после offset, поэтому сдвигаем начало периода, чтобы включить его.
+	// Дальше страницы продолжаются с последнего корня по (created_at, id): корни с одинаковым временем не теряются
+	offset := request.From
+	if !offset.IsZero() {
+		offset = offset.Add(-time.Nanosecond)
+	}
+	offsetID := 0
+
+	for {
+		comments, err := c.commentRepo.GetComments(request.TeamID, request.PostUnionID, offset, offsetID, false, commentExportPageSize)
+		if err != nil {
+			return err
+		}
+
+		byID := make(map[int]*entity.Comment, len(comments))
+		roots := 0
+		for _, comment := range comments {
+			byID[comment.ID] = comment
+			if comment.ReplyToCommentID == 0 {
+				roots++
+				if comment.CreatedAt.After(offset) || (comment.CreatedAt.Equal(offset) && comment.ID > offsetID) {
+					offset, offsetID = comment.CreatedAt, comment.ID
+				}
+			}
+		}
+
+		// страница приходит корнями от новых к старым, а затем ответами; в файл пишем по времени создания
+		slices.SortStableFunc(comments, func(a, b *entity.Comment) int {
+			return a.CreatedAt.Compare(b.CreatedAt)
+		})
+		for _, comment := range comments {
+			// ветка попадает в выгрузку целиком, если её корень создан до конца периода
+			root := comment
+			for root.ReplyToCommentID != 0 && byID[root.ReplyToCommentID] != nil {
+				root = byID[root.ReplyToCommentID]
+			}
+			if !root.CreatedAt.Before(to) || !request.Matches(comment) {
+				continue
+			}
+			if err := write(comment); err != nil {
+				return err
+			}
+		}
+
+		if roots < commentExportPageSize || !offset.Before(to) {
+			return nil
+		}
+	}
 }
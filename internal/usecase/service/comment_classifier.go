@@ -0,0 +1,206 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"sync"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	// classifierGroupID группа потребителей Kafka: при нескольких воркерах каждое событие классифицирует один из них
+	classifierGroupID = "comment-classifier"
+	// classifierTeamsInterval как часто проверяется появление новых команд
+	classifierTeamsInterval = time.Minute
+	// classifierSweepInterval как часто ищутся комментарии, пропущенные обработчиком событий
+	classifierSweepInterval = 5 * time.Minute
+	// classifierSweepDelay сколько времени обработчик событий получает до того, как комментарий считается пропущенным
+	classifierSweepDelay = 2 * time.Minute
+	// classifierSweepWindow за какой период досылаются пропущенные комментарии
+	classifierSweepWindow = 24 * time.Hour
+	classifierSweepLimit  = 200
+)
+
+// CommentClassifier определяет тональность и тему новых комментариев через ML-сервис.
+// Работает асинхронно по событиям о комментариях из Kafka
+type CommentClassifier struct {
+	commentRepo repo.Comment
+	teamRepo    repo.Team
+	eventRepo   repo.CommentEventRepository
+	classifyURL string
+	client      *http.Client
+
+	mu        sync.Mutex
+	consumers map[int]struct{} // команды, события которых уже читаются
+}
+
+func NewCommentClassifier(
+	commentRepo repo.Comment,
+	teamRepo repo.Team,
+	eventRepo repo.CommentEventRepository,
+	classifyURL string,
+) *CommentClassifier {
+	return &CommentClassifier{
+		commentRepo: commentRepo,
+		teamRepo:    teamRepo,
+		eventRepo:   eventRepo,
+		classifyURL: classifyURL,
+		client:      &http.Client{Timeout: 30 * time.Second},
+		consumers:   make(map[int]struct{}),
+	}
+}
+
+func (w *CommentClassifier) Start(ctx context.Context) {
+	teamsTicker := time.NewTicker(classifierTeamsInterval)
+	defer teamsTicker.Stop()
+	sweepTicker := time.NewTicker(classifierSweepInterval)
+	defer sweepTicker.Stop()
+
+	log.Info("Запущен классификатор комментариев")
+	w.watchTeams(ctx)
+	w.sweep(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Остановка классификатора комментариев")
+			return
+		case <-teamsTicker.C:
+			w.watchTeams(ctx)
+		case <-sweepTicker.C:
+			w.sweep(ctx)
+		}
+	}
+}
+
+// watchTeams начинает читать события команд, которые ещё не читаются
+func (w *CommentClassifier) watchTeams(ctx context.Context) {
+	teamIDs, err := w.teamRepo.GetTeamIDs()
+	if err != nil {
+		log.Errorf("Ошибка получения списка команд для классификации: %v", err)
+		return
+	}
+	for _, teamID := range teamIDs {
+		w.mu.Lock()
+		_, ok := w.consumers[teamID]
+		if !ok {
+			w.consumers[teamID] = struct{}{}
+		}
+		w.mu.Unlock()
+		if !ok {
+			go w.consume(ctx, teamID)
+		}
+	}
+}
+
+// consume классифицирует новые и изменённые комментарии команды, пока не закроется поток событий
+func (w *CommentClassifier) consume(ctx context.Context, teamID int) {
+	// после ошибки чтение команды будет перезапущено при следующей проверке команд
+	defer func() {
+		w.mu.Lock()
+		delete(w.consumers, teamID)
+		w.mu.Unlock()
+	}()
+
+	events, err := w.eventRepo.ConsumeCommentEvents(ctx, classifierGroupID, teamID)
+	if err != nil {
+		log.Errorf("Ошибка подписки на события команды %d: %v", teamID, err)
+		return
+	}
+	for event := range events {
+		if event.Type != entity.CommentCreated && event.Type != entity.CommentEdited {
+			continue
+		}
+		if err := w.classifyComment(ctx, event.CommentID); err != nil {
+			// комментарий останется неклассифицированным и будет обработан при следующем обходе
+			log.Errorf("Ошибка классификации комментария %d: %v", event.CommentID, err)
+		}
+	}
+}
+
+// sweep классифицирует недавние комментарии, события о которых были пропущены
+func (w *CommentClassifier) sweep(ctx context.Context) {
+	now := time.Now()
+	ids, err := w.commentRepo.GetUnclassifiedCommentIDs(now.Add(-classifierSweepWindow), now.Add(-classifierSweepDelay), classifierSweepLimit)
+	if err != nil {
+		log.Errorf("Ошибка получения неклассифицированных комментариев: %v", err)
+		return
+	}
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := w.classifyComment(ctx, id); err != nil {
+			log.Errorf("Ошибка классификации комментария %d: %v", id, err)
+		}
+	}
+}
+
+func (w *CommentClassifier) classifyComment(ctx context.Context, commentID int) error {
+	comment, err := w.commentRepo.GetComment(commentID)
+	switch {
+	case errors.Is(err, repo.ErrCommentNotFound):
+		return nil
+	case err != nil:
+		return err
+	}
+	if comment.IsTeamReply || comment.IsDeleted {
+		return nil
+	}
+
+	classification := &entity.CommentClassification{
+		Sentiment: entity.SentimentNeutral,
+		Topic:     entity.TopicOther,
+	}
+	// комментарий из одних вложений классифицировать не по чему
+	if comment.Text != "" {
+		classification, err = w.classify(ctx, comment.Text)
+		if err != nil {
+			return err
+		}
+	}
+	return w.commentRepo.SetCommentClassification(commentID, classification)
+}
+
+// classify отправляет текст комментария в ML-сервис
+func (w *CommentClassifier) classify(ctx context.Context, text string) (*entity.CommentClassification, error) {
+	type MLRequest struct {
+		Comment string `json:"comment"`
+	}
+
+	jsonData, err := json.Marshal(MLRequest{Comment: text})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", w.classifyURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ML-сервис ответил %s", resp.Status)
+	}
+
+	var classification entity.CommentClassification
+	if err := json.NewDecoder(resp.Body).Decode(&classification); err != nil {
+		return nil, err
+	}
+	if err := classification.IsValid(); err != nil {
+		return nil, fmt.Errorf("некорректный ответ ML-сервиса: %w", err)
+	}
+	return &classification, nil
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/gommon/log"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// summarizerGroupID группа потребителей Kafka: при нескольких воркерах каждое событие обрабатывает один из них
+	summarizerGroupID = "comment-summarizer"
+	// summarizerTeamsInterval как часто проверяется появление новых команд
+	summarizerTeamsInterval = time.Minute
+	// summaryCommentsLimit сколько новых комментариев отправляется в ML-сервис за один запрос
+	summaryCommentsLimit = 100
+	// DefaultSummaryRefreshComments после скольких новых комментариев сводка обновляется в фоне
+	DefaultSummaryRefreshComments = 20
+)
+
+// CommentSummarizer хранит сводки комментариев постов и обновляет их по новым комментариям.
+// В ML-сервис отправляются только комментарии после последнего учтённого вместе с предыдущей сводкой
+type CommentSummarizer struct {
+	summaryRepo      repo.CommentSummary
+	commentRepo      repo.Comment
+	teamRepo         repo.Team
+	eventRepo        repo.CommentEventRepository
+	summarizeURL     string
+	refreshThreshold int
+	client           *http.Client
+
+	// refreshes объединяет одновременные обновления сводки одного поста
+	refreshes singleflight.Group
+
+	mu        sync.Mutex
+	consumers map[int]struct{} // команды, события которых уже читаются
+}
+
+func NewCommentSummarizer(
+	summaryRepo repo.CommentSummary,
+	commentRepo repo.Comment,
+	teamRepo repo.Team,
+	eventRepo repo.CommentEventRepository,
+	summarizeURL string,
+	refreshThreshold int,
+) *CommentSummarizer {
+	if refreshThreshold <= 0 {
+		refreshThreshold = DefaultSummaryRefreshComments
+	}
+	return &CommentSummarizer{
+		summaryRepo:      summaryRepo,
+		commentRepo:      commentRepo,
+		teamRepo:         teamRepo,
+		eventRepo:        eventRepo,
+		summarizeURL:     summarizeURL,
+		refreshThreshold: refreshThreshold,
+		client:           &http.Client{Timeout: 2 * time.Minute},
+		consumers:        make(map[int]struct{}),
+	}
+}
+
+// GetSummary возвращает сохранённую сводку поста. Сводка создаётся, если её ещё нет, и обновляется, если задан refresh
+func (s *CommentSummarizer) GetSummary(ctx context.Context, teamID, postUnionID int, refresh bool) (*entity.Summarize, error) {
+	summary, err := s.summaryRepo.GetSummary(postUnionID)
+	switch {
+	case errors.Is(err, repo.ErrCommentSummaryNotFound):
+		refresh = true
+	case err != nil:
+		return nil, err
+	}
+	if refresh {
+		summary, err = s.refresh(ctx, teamID, postUnionID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	summary.NewComments, err = s.commentRepo.CountCommentsAfter(postUnionID, summary.LastCommentID)
+	if err != nil {
+		return nil, err
+	}
+	if !summary.UpdatedAt.IsZero() {
+		summary.AgeSeconds = int(time.Since(summary.UpdatedAt).Seconds())
+	}
+	return summary, nil
+}
+
+// refresh дополняет сводку комментариями, появившимися после её последнего обновления
+func (s *CommentSummarizer) refresh(ctx context.Context, teamID, postUnionID int) (*entity.Summarize, error) {
+	result, err, _ := s.refreshes.Do(strconv.Itoa(postUnionID), func() (any, error) {
+		summary, err := s.summaryRepo.GetSummary(postUnionID)
+		switch {
+		case errors.Is(err, repo.ErrCommentSummaryNotFound):
+			summary = &entity.Summarize{PostUnionID: postUnionID, TeamID: teamID}
+		case err != nil:
+			return nil, err
+		}
+
+		// комментарии учитываются частями от старых к новым, пока сводка не догонит обсуждение.
+		// Каждая часть сохраняется сразу, поэтому после ошибки обновление продолжится с того же места.
+		// Позиция — ID последнего учтённого комментария, поэтому редкие комментарии, сохранённые позже комментария
+		// с большим ID (параллельные вставки, одобрение отложенного), в сводку не попадут. Для сводки это допустимо:
+		// время создания знак не улучшит — у комментариев из сверки и из одобрения оно тоже в прошлом
+		for {
+			comments, err := s.commentRepo.GetCommentTextsAfter(postUnionID, summary.LastCommentID, summaryCommentsLimit)
+			if err != nil {
+				return nil, err
+			}
+			if len(comments) == 0 {
+				// новых комментариев нет, сводка актуальна
+				return summary, nil
+			}
+
+			// обновление общее для всех ожидающих, поэтому не прерывается вместе с запросом, который его начал
+			markdown, err := s.summarize(context.WithoutCancel(ctx), summary.Markdown, comments)
+			if err != nil {
+				return nil, err
+			}
+			summary.Markdown = markdown
+			summary.LastCommentID = comments[len(comments)-1].ID
+			summary.CommentCount += len(comments)
+			summary.UpdatedAt = time.Now()
+			if err := s.summaryRepo.PutSummary(summary); err != nil {
+				return nil, err
+			}
+			if len(comments) < summaryCommentsLimit {
+				return summary, nil
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	// копия, чтобы ожидавшие одного обновления не меняли общую сводку
+	summary := *result.(*entity.Summarize)
+	return &summary, nil
+}
+
+// summarize отправляет в ML-сервис новые комментарии вместе с предыдущей сводкой
+func (s *CommentSummarizer) summarize(ctx context.Context, previous string, comments []*entity.JustTextComment) (string, error) {
+	var builder strings.Builder
+	if previous != "" {
+		builder.WriteString("Предыдущая сводка обсуждения:\n")
+		builder.WriteString(previous)
+		builder.WriteString("\n\nНовые комментарии:\n\n")
+	}
+	for _, comment := range comments {
+		builder.WriteString(comment.Text)
+		builder.WriteString("\n\n")
+	}
+	payload := struct {
+		Comments string `json:"comments"`
+	}{
+		Comments: builder.String(),
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", s.summarizeURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ML-сервис ответил %s", resp.Status)
+	}
+
+	var serverAnswer struct {
+		Response string `json:"response"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&serverAnswer); err != nil {
+		return "", err
+	}
+	return serverAnswer.Response, nil
+}
+
+// Start обновляет сводки в фоне по событиям о новых комментариях из Kafka
+func (s *CommentSummarizer) Start(ctx context.Context) {
+	teamsTicker := time.NewTicker(summarizerTeamsInterval)
+	defer teamsTicker.Stop()
+
+	log.Info("Запущено фоновое обновление сводок комментариев")
+	s.watchTeams(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Остановка фонового обновления сводок комментариев")
+			return
+		case <-teamsTicker.C:
+			s.watchTeams(ctx)
+		}
+	}
+}
+
+// watchTeams начинает читать события команд, которые ещё не читаются
+func (s *CommentSummarizer) watchTeams(ctx context.Context) {
+	teamIDs, err := s.teamRepo.GetTeamIDs()
+	if err != nil {
+		log.Errorf("Ошибка получения списка команд для обновления сводок: %v", err)
+		return
+	}
+	for _, teamID := range teamIDs {
+		s.mu.Lock()
+		_, ok := s.consumers[teamID]
+		if !ok {
+			s.consumers[teamID] = struct{}{}
+		}
+		s.mu.Unlock()
+		if !ok {
+			go s.consume(ctx, teamID)
+		}
+	}
+}
+
+// consume обновляет сводки постов команды, пока не закроется поток событий
+func (s *CommentSummarizer) consume(ctx context.Context, teamID int) {
+	// после ошибки чтение команды будет перезапущено при следующей проверке команд
+	defer func() {
+		s.mu.Lock()
+		delete(s.consumers, teamID)
+		s.mu.Unlock()
+	}()
+
+	events, err := s.eventRepo.ConsumeCommentEvents(ctx, summarizerGroupID, teamID)
+	if err != nil {
+		log.Errorf("Ошибка подписки на события команды %d: %v", teamID, err)
+		return
+	}
+	for event := range events {
+		// у сообщений вне веток постов сводки нет
+		if event.Type != entity.CommentCreated || event.PostID == 0 {
+			continue
+		}
+		if err := s.refreshIfStale(ctx, teamID, event.PostID); err != nil {
+			log.Errorf("Ошибка обновления сводки поста %d: %v", event.PostID, err)
+		}
+	}
+}
+
+// refreshIfStale обновляет сводку, если после неё накопилось достаточно новых комментариев
+func (s *CommentSummarizer) refreshIfStale(ctx context.Context, teamID, postUnionID int) error {
+	lastCommentID := 0
+	summary, err := s.summaryRepo.GetSummary(postUnionID)
+	switch {
+	case err == nil:
+		lastCommentID = summary.LastCommentID
+	case !errors.Is(err, repo.ErrCommentSummaryNotFound):
+		return err
+	}
+	count, err := s.commentRepo.CountCommentsAfter(postUnionID, lastCommentID)
+	if err != nil {
+		return err
+	}
+	if count < s.refreshThreshold {
+		return nil
+	}
+	_, err = s.refresh(ctx, teamID, postUnionID)
+	return err
+}
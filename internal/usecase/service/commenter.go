@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"postic-backend/internal/usecase"
+	"slices"
+	"time"
+)
+
+type Commenter struct {
+	commenterRepo repo.Commenter
+	commentRepo   repo.Comment
+	teamRepo      repo.Team
+	banRepo       repo.Ban
+}
+
+func NewCommenter(commenterRepo repo.Commenter, commentRepo repo.Comment, teamRepo repo.Team, banRepo repo.Ban) usecase.Commenter {
+	return &Commenter{
+		commenterRepo: commenterRepo,
+		commentRepo:   commentRepo,
+		teamRepo:      teamRepo,
+		banRepo:       banRepo,
+	}
+}
+
+// checkRoles проверяет, что пользователь может работать с комментариями команды
+func (c *Commenter) checkRoles(teamID, userID int) error {
+	roles, err := c.teamRepo.GetTeamUserRoles(teamID, userID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(roles, repo.AdminRole) && !slices.Contains(roles, repo.CommentsRole) {
+		return usecase.ErrUserForbidden
+	}
+	return nil
+}
+
+// getTeamCommenter возвращает профиль автора, только если он писал в команде
+func (c *Commenter) getTeamCommenter(teamID int, platform string, userPlatformID int) (*entity.Commenter, error) {
+	commenter, err := c.commenterRepo.GetCommenter(teamID, platform, userPlatformID)
+	if errors.Is(err, repo.ErrCommenterNotFound) {
+		return nil, usecase.ErrCommenterNotFound
+	}
+	return commenter, err
+}
+
+func (c *Commenter) GetCommenter(request *entity.GetCommenterRequest) (*entity.Commenter, error) {
+	if err := c.checkRoles(request.TeamID, request.UserID); err != nil {
+		return nil, err
+	}
+	commenter, err := c.getTeamCommenter(request.TeamID, request.Platform, request.UserPlatformID)
+	if err != nil {
+		return nil, err
+	}
+	commenter.Tags, err = c.commenterRepo.GetCommenterTags(request.TeamID, request.Platform, request.UserPlatformID)
+	if err != nil {
+		return nil, err
+	}
+	commenter.Notes, err = c.commenterRepo.GetCommenterNotes(request.TeamID, request.Platform, request.UserPlatformID)
+	if err != nil {
+		return nil, err
+	}
+	commenter.Ban, err = c.banRepo.GetActiveBan(request.TeamID, request.Platform, request.UserPlatformID)
+	switch {
+	case errors.Is(err, repo.ErrBanNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		commenter.Banned = true
+	}
+	return commenter, nil
+}
+
+func (c *Commenter) GetCommenterComments(request *entity.GetCommenterCommentsRequest) ([]*entity.Comment, error) {
+	if err := c.checkRoles(request.TeamID, request.UserID); err != nil {
+		return nil, err
+	}
+	if request.Offset.IsZero() {
+		request.Offset = time.Now()
+	}
+	if request.Limit <= 0 || request.Limit > 100 {
+		request.Limit = 100
+	}
+	comments, err := c.commentRepo.GetFilteredComments(&entity.GetCommentsRequest{
+		TeamID:         request.TeamID,
+		Offset:         request.Offset,
+		Before:         true,
+		Limit:          request.Limit,
+		Platform:       request.Platform,
+		UserPlatformID: request.UserPlatformID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if comments == nil {
+		comments = make([]*entity.Comment, 0)
+	}
+	return comments, nil
+}
+
+func (c *Commenter) SetTags(request *entity.SetCommenterTagsRequest) error {
+	if err := c.checkRoles(request.TeamID, request.UserID); err != nil {
+		return err
+	}
+	if _, err := c.getTeamCommenter(request.TeamID, request.Platform, request.UserPlatformID); err != nil {
+		return err
+	}
+	return c.commenterRepo.SetCommenterTags(request.TeamID, request.Platform, request.UserPlatformID, request.Tags)
+}
+
+func (c *Commenter) AddNote(request *entity.AddCommenterNoteRequest) (int, error) {
+	if err := c.checkRoles(request.TeamID, request.UserID); err != nil {
+		return 0, err
+	}
+	if _, err := c.getTeamCommenter(request.TeamID, request.Platform, request.UserPlatformID); err != nil {
+		return 0, err
+	}
+	return c.commenterRepo.AddCommenterNote(&entity.CommenterNote{
+		TeamID:         request.TeamID,
+		Platform:       request.Platform,
+		UserPlatformID: request.UserPlatformID,
+		UserID:         &request.UserID,
+		Text:           request.Text,
+		CreatedAt:      time.Now(),
+	})
+}
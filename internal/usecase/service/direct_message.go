@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"postic-backend/internal/usecase"
+	"slices"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+type DirectMessage struct {
+	dmRepo          repo.DirectMessage
+	teamRepo        repo.Team
+	mediaRepo       repo.MediaLibrary
+	eventRepo       repo.DirectMessageEventRepository
+	telegramAction  usecase.DirectMessagePlatform
+	vkontakteAction usecase.DirectMessagePlatform
+	tgBotUsername   string
+}
+
+func NewDirectMessage(
+	dmRepo repo.DirectMessage,
+	teamRepo repo.Team,
+	mediaRepo repo.MediaLibrary,
+	eventRepo repo.DirectMessageEventRepository,
+	telegramAction usecase.DirectMessagePlatform,
+	vkontakteAction usecase.DirectMessagePlatform,
+	tgBotUsername string,
+) usecase.DirectMessage {
+	return &DirectMessage{
+		dmRepo:          dmRepo,
+		teamRepo:        teamRepo,
+		mediaRepo:       mediaRepo,
+		eventRepo:       eventRepo,
+		telegramAction:  telegramAction,
+		vkontakteAction: vkontakteAction,
+		tgBotUsername:   tgBotUsername,
+	}
+}
+
+// checkRoles проверяет, что пользователь может работать с сообщениями команды
+func (d *DirectMessage) checkRoles(teamID, userID int) error {
+	roles, err := d.teamRepo.GetTeamUserRoles(teamID, userID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(roles, repo.AdminRole) && !slices.Contains(roles, repo.CommentsRole) {
+		return usecase.ErrUserForbidden
+	}
+	return nil
+}
+
+// checkAttachments проверяет, что пользователь имеет доступ ко всем вложениям сообщения
+func (d *DirectMessage) checkAttachments(userID int, attachments []int) error {
+	for _, mediaFileID := range attachments {
+		allowed, err := d.mediaRepo.CanUserAccessMediaFile(userID, mediaFileID)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return usecase.ErrMediaFileNotFound
+		}
+	}
+	return nil
+}
+
+// getTeamConversation возвращает переписку, только если она принадлежит команде
+func (d *DirectMessage) getTeamConversation(teamID, conversationID int) (*entity.DMConversation, error) {
+	conversation, err := d.dmRepo.GetConversation(conversationID)
+	switch {
+	case errors.Is(err, repo.ErrDMConversationNotFound):
+		return nil, usecase.ErrDMConversationNotFound
+	case err != nil:
+		return nil, err
+	}
+	if conversation.TeamID != teamID {
+		return nil, usecase.ErrDMConversationNotFound
+	}
+	return conversation, nil
+}
+
+func (d *DirectMessage) GetConversations(request *entity.GetDMConversationsRequest) ([]*entity.DMConversation, error) {
+	if err := d.checkRoles(request.TeamID, request.UserID); err != nil {
+		return nil, err
+	}
+	if request.Offset.IsZero() {
+		request.Offset = time.Now()
+	}
+	if request.Limit <= 0 || request.Limit > 100 {
+		request.Limit = 100
+	}
+	return d.dmRepo.GetConversations(request)
+}
+
+func (d *DirectMessage) GetMessages(request *entity.GetDirectMessagesRequest) ([]*entity.DirectMessage, error) {
+	if err := d.checkRoles(request.TeamID, request.UserID); err != nil {
+		return nil, err
+	}
+	if _, err := d.getTeamConversation(request.TeamID, request.ConversationID); err != nil {
+		return nil, err
+	}
+	if request.Offset.IsZero() {
+		request.Offset = time.Now()
+	}
+	if request.Limit <= 0 || request.Limit > 100 {
+		request.Limit = 100
+	}
+	return d.dmRepo.GetMessages(request)
+}
+
+func (d *DirectMessage) SendMessage(request *entity.SendDirectMessageRequest) (int, error) {
+	if err := d.checkRoles(request.TeamID, request.UserID); err != nil {
+		return 0, err
+	}
+	conversation, err := d.getTeamConversation(request.TeamID, request.ConversationID)
+	if err != nil {
+		return 0, err
+	}
+	if err := request.IsValid(conversation.Platform); err != nil {
+		return 0, fmt.Errorf("%w: %v", usecase.ErrInvalidDirectMessage, err)
+	}
+	// вложения загружаются на платформу от имени команды, поэтому чужие файлы отправить нельзя
+	if err := d.checkAttachments(request.UserID, request.Attachments); err != nil {
+		return 0, err
+	}
+
+	var action usecase.DirectMessagePlatform
+	switch conversation.Platform {
+	case "tg":
+		action = d.telegramAction
+	case "vk":
+		action = d.vkontakteAction
+	default:
+		return 0, usecase.ErrDirectMessageUnavailable
+	}
+	messagePlatformID, err := action.SendDirectMessage(conversation, request)
+	if err != nil {
+		return 0, err
+	}
+
+	message := &entity.DirectMessage{
+		ConversationID:    conversation.ID,
+		MessagePlatformID: messagePlatformID,
+		Text:              request.Text,
+		IsTeamReply:       true,
+		SentBy:            &request.UserID,
+		CreatedAt:         time.Now(),
+		Attachments:       make([]*entity.Upload, 0, len(request.Attachments)),
+	}
+	for _, attachmentID := range request.Attachments {
+		message.Attachments = append(message.Attachments, &entity.Upload{ID: attachmentID})
+	}
+	messageID, created, err := d.dmRepo.AddMessage(message)
+	if err != nil {
+		return 0, err
+	}
+	if !created {
+		// слушатель платформы успел сохранить отправленное сообщение раньше
+		return messageID, nil
+	}
+
+	d.publish(&entity.DirectMessageEvent{
+		EventID:        fmt.Sprintf("dm-%d-%d", conversation.TeamID, messageID),
+		TeamID:         conversation.TeamID,
+		Type:           entity.DirectMessageCreated,
+		ConversationID: conversation.ID,
+		MessageID:      messageID,
+		OccurredAt:     message.CreatedAt,
+	})
+	return messageID, nil
+}
+
+func (d *DirectMessage) ReadConversation(request *entity.ReadDMConversationRequest) error {
+	if err := d.checkRoles(request.TeamID, request.UserID); err != nil {
+		return err
+	}
+	conversation, err := d.getTeamConversation(request.TeamID, request.ConversationID)
+	if err != nil {
+		return err
+	}
+	if conversation.UnreadCount == 0 {
+		return nil
+	}
+	if err := d.dmRepo.ResetUnread(conversation.ID); err != nil {
+		return err
+	}
+
+	// другие участники команды убирают счётчик непрочитанных у себя
+	now := time.Now()
+	d.publish(&entity.DirectMessageEvent{
+		EventID:        fmt.Sprintf("dm-read-%d-%d-%d", conversation.TeamID, conversation.ID, now.UnixNano()),
+		TeamID:         conversation.TeamID,
+		Type:           entity.DirectMessageRead,
+		ConversationID: conversation.ID,
+		OccurredAt:     now,
+	})
+	return nil
+}
+
+func (d *DirectMessage) GetTelegramLink(request *entity.GetDMLinkRequest) (string, error) {
+	if err := d.checkRoles(request.TeamID, request.UserID); err != nil {
+		return "", err
+	}
+	if d.tgBotUsername == "" {
+		return "", usecase.ErrDirectMessageUnavailable
+	}
+	// бот общий для всех команд, поэтому команда передаётся параметром /start
+	return fmt.Sprintf("https://t.me/%s?start=dm%d", d.tgBotUsername, request.TeamID), nil
+}
+
+func (d *DirectMessage) Subscribe(ctx context.Context, request *entity.DMSubscriber) (<-chan *entity.DirectMessageEvent, error) {
+	if err := d.checkRoles(request.TeamID, request.UserID); err != nil {
+		return nil, err
+	}
+	if request.ConversationID != 0 {
+		if _, err := d.getTeamConversation(request.TeamID, request.ConversationID); err != nil {
+			return nil, err
+		}
+	}
+	return d.eventRepo.SubscribeDirectMessageEvents(ctx, request.TeamID, request.ConversationID)
+}
+
+// publish рассылает событие подписчикам. Сообщение уже сохранено, поэтому ошибку только логируем
+func (d *DirectMessage) publish(event *entity.DirectMessageEvent) {
+	if err := d.eventRepo.PublishDirectMessageEvent(context.Background(), event); err != nil {
+		log.Errorf("Не удалось опубликовать событие о личном сообщении в Kafka: %v", err)
+	}
+}
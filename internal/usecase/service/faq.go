@@ -0,0 +1,388 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"postic-backend/internal/usecase"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// faqIntentTimeout сколько ждать ответа ML-сервиса при определении намерения
+const faqIntentTimeout = 10 * time.Second
+
+// compiledFAQRule правило автоответа с заранее скомпилированным выражением
+type compiledFAQRule struct {
+	*entity.FAQRule
+	pattern *regexp.Regexp
+}
+
+type teamFAQ struct {
+	enabled  bool
+	rules    []*compiledFAQRule
+	loadedAt time.Time
+}
+
+// faqThread обсуждение, в котором действует задержка правила: пост или общее обсуждение команды
+type faqThread struct {
+	ruleID      int
+	teamID      int
+	postUnionID int
+}
+
+type FAQ struct {
+	faqRepo     repo.FAQ
+	teamRepo    repo.Team
+	replyAction usecase.CommentActionPlatform
+	intentURL   string
+	client      *http.Client
+
+	mu        sync.Mutex
+	cache     map[int]*teamFAQ
+	lastReply map[faqThread]time.Time
+}
+
+// NewFAQ создаёт сервис автоответов. Он же реализует usecase.CommentAutoReplier для слушателей платформ:
+// replyAction отправляет ответ на платформу, intentURL — адрес ML-сервиса для правил с намерением.
+// Для управления правилами replyAction и intentURL не нужны
+func NewFAQ(
+	faqRepo repo.FAQ,
+	teamRepo repo.Team,
+	replyAction usecase.CommentActionPlatform,
+	intentURL string,
+) *FAQ {
+	return &FAQ{
+		faqRepo:     faqRepo,
+		teamRepo:    teamRepo,
+		replyAction: replyAction,
+		intentURL:   intentURL,
+		client:      &http.Client{Timeout: faqIntentTimeout},
+		cache:       make(map[int]*teamFAQ),
+		lastReply:   make(map[faqThread]time.Time),
+	}
+}
+
+// teamFAQ возвращает включённые правила и выключатель команды из кэша или базы
+func (f *FAQ) teamFAQ(teamID int) (*teamFAQ, error) {
+	f.mu.Lock()
+	cached, ok := f.cache[teamID]
+	f.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < moderationRulesTTL {
+		return cached, nil
+	}
+
+	enabled, err := f.faqRepo.GetFAQEnabled(teamID)
+	if err != nil {
+		return nil, err
+	}
+	loaded := &teamFAQ{enabled: enabled, loadedAt: time.Now()}
+	if enabled {
+		rules, err := f.faqRepo.GetFAQRules(teamID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range rules {
+			if !rule.Enabled {
+				continue
+			}
+			c := &compiledFAQRule{FAQRule: rule}
+			if rule.Trigger == entity.FAQRegex {
+				c.pattern, err = regexp.Compile(rule.Pattern)
+				if err != nil {
+					log.Errorf("Правило автоответа %d содержит некорректное выражение: %v", rule.ID, err)
+					continue
+				}
+			}
+			loaded.rules = append(loaded.rules, c)
+		}
+	}
+
+	f.mu.Lock()
+	f.cache[teamID] = loaded
+	f.mu.Unlock()
+	return loaded, nil
+}
+
+// invalidate сбрасывает кэш правил команды после изменения
+func (f *FAQ) invalidate(teamID int) {
+	f.mu.Lock()
+	delete(f.cache, teamID)
+	f.mu.Unlock()
+}
+
+// detectIntent определяет намерение комментария через ML-сервис
+func (f *FAQ) detectIntent(text string) (string, error) {
+	type MLRequest struct {
+		Comment string `json:"comment"`
+	}
+	type MLResponse struct {
+		Intent string `json:"intent"`
+	}
+
+	jsonData, err := json.Marshal(MLRequest{Comment: text})
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), faqIntentTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "POST", f.intentURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("ML-сервис ответил %s", resp.Status)
+	}
+
+	var response MLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", err
+	}
+	return response.Intent, nil
+}
+
+// matchRule возвращает первое сработавшее на комментарий правило или nil.
+// Намерение запрашивается у ML-сервиса только при первом правиле с намерением
+func (f *FAQ) matchRule(rules []*compiledFAQRule, comment *entity.Comment) *compiledFAQRule {
+	words := textWords(comment.Text)
+	intent, intentLoaded := "", false
+	for _, rule := range rules {
+		switch rule.Trigger {
+		case entity.FAQKeywords:
+			if matchKeywords(comment.Text, words, rule.Keywords) != "" {
+				return rule
+			}
+		case entity.FAQRegex:
+			if rule.pattern.MatchString(comment.Text) {
+				return rule
+			}
+		case entity.FAQIntent:
+			if f.intentURL == "" {
+				continue
+			}
+			if !intentLoaded {
+				var err error
+				intent, err = f.detectIntent(comment.Text)
+				if err != nil {
+					log.Errorf("Ошибка при определении намерения комментария %d: %v", comment.ID, err)
+				}
+				intentLoaded = true
+			}
+			if intent != "" && intent == rule.Intent {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// reserveReply атомарно проверяет задержку правила в обсуждении и сразу отмечает ответ, чтобы параллельные
+// комментарии не получили автоответ дважды. Память процесса защищает от повторов до сохранения ответа,
+// база — между перезапусками и слушателями. Если ответ отправить не удалось, отметку снимает возвращённая функция
+func (f *FAQ) reserveReply(rule *compiledFAQRule, comment *entity.Comment) (bool, func(), error) {
+	if rule.CooldownSeconds == 0 {
+		return true, func() {}, nil
+	}
+	cooldown := time.Duration(rule.CooldownSeconds) * time.Second
+	thread := faqThread{ruleID: rule.ID, teamID: comment.TeamID}
+	if comment.PostUnionID != nil {
+		thread.postUnionID = *comment.PostUnionID
+	}
+
+	now := time.Now()
+	f.mu.Lock()
+	last, ok := f.lastReply[thread]
+	if ok && now.Sub(last) < cooldown {
+		f.mu.Unlock()
+		return false, nil, nil
+	}
+	f.lastReply[thread] = now
+	f.mu.Unlock()
+
+	release := func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		// отметку могли уже заменить более поздним ответом
+		if !f.lastReply[thread].Equal(now) {
+			return
+		}
+		if ok {
+			f.lastReply[thread] = last
+		} else {
+			delete(f.lastReply, thread)
+		}
+	}
+	recent, err := f.faqRepo.HasRecentFAQReply(rule.ID, comment.PostUnionID, now.Add(-cooldown))
+	if err != nil || recent {
+		release()
+		return false, nil, err
+	}
+	return true, release, nil
+}
+
+func (f *FAQ) AutoReply(comment *entity.Comment) {
+	// не отвечаем команде, самим себе и на скрытые комментарии
+	if f.replyAction == nil || comment.IsTeamReply || comment.UserPlatformID <= 0 ||
+		comment.IsDeleted || comment.HeldForReview || comment.Text == "" {
+		return
+	}
+	settings, err := f.teamFAQ(comment.TeamID)
+	if err != nil {
+		log.Errorf("Ошибка при получении правил автоответов команды %d: %v", comment.TeamID, err)
+		return
+	}
+	if !settings.enabled || len(settings.rules) == 0 {
+		return
+	}
+
+	rule := f.matchRule(settings.rules, comment)
+	if rule == nil {
+		return
+	}
+	reserved, release, err := f.reserveReply(rule, comment)
+	if err != nil {
+		log.Errorf("Ошибка при проверке задержки автоответа %d: %v", rule.ID, err)
+		return
+	}
+	if !reserved {
+		return
+	}
+
+	_, err = f.replyAction.ReplyComment(&entity.ReplyCommentRequest{
+		TeamID:      comment.TeamID,
+		CommentID:   comment.ID,
+		Text:        entity.RenderReplyTemplate(rule.ResponseText, comment),
+		ReplySource: entity.ReplySourceFAQ,
+		FAQRuleID:   &rule.ID,
+	})
+	if err != nil {
+		release()
+		log.Errorf("Ошибка при отправке автоответа %d на комментарий %d: %v", rule.ID, comment.ID, err)
+		return
+	}
+	log.Infof("Автоответ: команда %d, комментарий %d, правило %d", comment.TeamID, comment.ID, rule.ID)
+}
+
+// checkRoles проверяет, что у пользователя есть хотя бы одна из ролей в команде
+func (f *FAQ) checkRoles(teamID, userID int, allowed ...string) error {
+	roles, err := f.teamRepo.GetTeamUserRoles(teamID, userID)
+	if err != nil {
+		return err
+	}
+	for _, role := range allowed {
+		if slices.Contains(roles, role) {
+			return nil
+		}
+	}
+	return usecase.ErrUserForbidden
+}
+
+// getTeamRule возвращает правило, только если оно принадлежит команде
+func (f *FAQ) getTeamRule(teamID, ruleID int) (*entity.FAQRule, error) {
+	rule, err := f.faqRepo.GetFAQRule(ruleID)
+	switch {
+	case errors.Is(err, repo.ErrFAQRuleNotFound):
+		return nil, usecase.ErrFAQRuleNotFound
+	case err != nil:
+		return nil, err
+	}
+	if rule.TeamID != teamID {
+		return nil, usecase.ErrFAQRuleNotFound
+	}
+	return rule, nil
+}
+
+func (f *FAQ) GetRules(request *entity.GetFAQRulesRequest) ([]*entity.FAQRule, error) {
+	if err := f.checkRoles(request.TeamID, request.UserID, repo.AdminRole, repo.CommentsRole); err != nil {
+		return nil, err
+	}
+	return f.faqRepo.GetFAQRules(request.TeamID)
+}
+
+func (f *FAQ) AddRule(request *entity.FAQRuleRequest) (int, error) {
+	if err := f.checkRoles(request.TeamID, request.UserID, repo.AdminRole); err != nil {
+		return 0, err
+	}
+	request.CreatedAt = time.Now()
+	ruleID, err := f.faqRepo.AddFAQRule(&request.FAQRule)
+	if err != nil {
+		return 0, err
+	}
+	f.invalidate(request.TeamID)
+	return ruleID, nil
+}
+
+func (f *FAQ) EditRule(request *entity.FAQRuleRequest) error {
+	if err := f.checkRoles(request.TeamID, request.UserID, repo.AdminRole); err != nil {
+		return err
+	}
+	if _, err := f.getTeamRule(request.TeamID, request.ID); err != nil {
+		return err
+	}
+	err := f.faqRepo.EditFAQRule(&request.FAQRule)
+	if errors.Is(err, repo.ErrFAQRuleNotFound) {
+		return usecase.ErrFAQRuleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	f.invalidate(request.TeamID)
+	return nil
+}
+
+func (f *FAQ) DeleteRule(request *entity.DeleteFAQRuleRequest) error {
+	if err := f.checkRoles(request.TeamID, request.UserID, repo.AdminRole); err != nil {
+		return err
+	}
+	if _, err := f.getTeamRule(request.TeamID, request.RuleID); err != nil {
+		return err
+	}
+	err := f.faqRepo.DeleteFAQRule(request.RuleID)
+	if errors.Is(err, repo.ErrFAQRuleNotFound) {
+		return usecase.ErrFAQRuleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	f.invalidate(request.TeamID)
+	return nil
+}
+
+func (f *FAQ) GetSettings(request *entity.GetFAQSettingsRequest) (*entity.FAQSettings, error) {
+	if err := f.checkRoles(request.TeamID, request.UserID, repo.AdminRole, repo.CommentsRole); err != nil {
+		return nil, err
+	}
+	enabled, err := f.faqRepo.GetFAQEnabled(request.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.FAQSettings{TeamID: request.TeamID, Enabled: enabled}, nil
+}
+
+func (f *FAQ) SetEnabled(request *entity.SetFAQEnabledRequest) error {
+	// выключить автоответы при ошибке в правиле должен успеть любой модератор
+	if err := f.checkRoles(request.TeamID, request.UserID, repo.AdminRole, repo.CommentsRole); err != nil {
+		return err
+	}
+	if err := f.faqRepo.SetFAQEnabled(request.TeamID, request.Enabled); err != nil {
+		return err
+	}
+	f.invalidate(request.TeamID)
+	return nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/usecase"
+	"postic-backend/pkg/fetch"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+const (
+	// importMaxSize ограничивает файл, скачиваемый по ссылке: он целиком держится в памяти.
+	// Лимиты по типу файла и квота команды проверяются дальше, при загрузке
+	importMaxSize      = 100 * 1024 * 1024
+	importTimeout      = time.Minute
+	importMaxRedirects = 3
+)
+
+func newImportFetcher() *fetch.Client {
+	return fetch.NewClient(importTimeout, importMaxRedirects, importMaxSize)
+}
+
+// importFileType определяет тип файла и расширение по содержимому, а не по ссылке или заголовкам сервера
+func importFileType(data []byte) (string, string, error) {
+	mime := mimetype.Detect(data)
+	switch {
+	case mime.Is("image/jpeg"), mime.Is("image/png"):
+		return "photo", strings.TrimPrefix(mime.Extension(), "."), nil
+	case mime.Is("video/mp4"):
+		return "video", "mp4", nil
+	}
+	return "", "", fmt.Errorf("%w: неподдерживаемый формат %s, допустимы jpg, png и mp4", usecase.ErrInvalidUpload, mime.String())
+}
+
+// importFileName возвращает имя файла из ссылки без расширения
+func importFileName(u *url.URL) string {
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		return "file"
+	}
+	if ext := path.Ext(name); ext != "" && ext != name {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// importError переводит ошибки скачивания в ошибки usecase
+func importError(err error) error {
+	switch {
+	case errors.Is(err, fetch.ErrForbiddenAddress), errors.Is(err, fetch.ErrUnsupportedScheme):
+		return fmt.Errorf("%w: %v", usecase.ErrInvalidImportURL, err)
+	case errors.Is(err, fetch.ErrTooLarge):
+		return fmt.Errorf("%w: не более %d МБ по ссылке", usecase.ErrUploadTooLarge, importMaxSize/(1024*1024))
+	}
+	return fmt.Errorf("%w: %v", usecase.ErrURLImportFailed, err)
+}
+
+func (m *MediaLibrary) ImportURL(request *entity.ImportURLRequest) (int, error) {
+	member, err := m.isTeamMember(request.UserID, request.TeamID)
+	if err != nil {
+		return 0, err
+	}
+	if !member {
+		return 0, usecase.ErrUserForbidden
+	}
+
+	u, err := url.Parse(request.URL)
+	if err != nil || u.Host == "" {
+		return 0, usecase.ErrInvalidImportURL
+	}
+	resp, err := m.fetcher.Get(context.Background(), u.String())
+	if err != nil {
+		return 0, importError(err)
+	}
+
+	fileType, ext, err := importFileType(resp.Data)
+	if err != nil {
+		return 0, err
+	}
+	fileName := importFileName(resp.URL) + "." + ext
+	displayName := request.DisplayName
+	if displayName == "" {
+		displayName = fileName
+	}
+
+	// Дальше файл проходит те же проверки, что и обычная загрузка: тип, размер и квота команды
+	return m.UploadFile(&entity.Upload{
+		UserID:      &request.UserID,
+		TeamID:      &request.TeamID,
+		FilePath:    fileName,
+		FileType:    fileType,
+		DisplayName: displayName,
+		RawBytes:    bytes.NewReader(resp.Data),
+	})
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"postic-backend/internal/usecase"
+	"postic-backend/pkg/fetch"
+	"slices"
+)
+
+type MediaLibrary struct {
+	mediaRepo     repo.MediaLibrary
+	teamRepo      repo.Team
+	uploadUseCase usecase.Upload
+	fetcher       *fetch.Client
+}
+
+func NewMediaLibrary(mediaRepo repo.MediaLibrary, teamRepo repo.Team, uploadUseCase usecase.Upload) usecase.MediaLibrary {
+	return &MediaLibrary{
+		mediaRepo:     mediaRepo,
+		teamRepo:      teamRepo,
+		uploadUseCase: uploadUseCase,
+		fetcher:       newImportFetcher(),
+	}
+}
+
+// isTeamMember проверяет, что пользователь состоит в команде (с любыми ролями)
+func (m *MediaLibrary) isTeamMember(userID, teamID int) (bool, error) {
+	teams, err := m.teamRepo.GetUserTeams(userID)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(teams, teamID), nil
+}
+
+// canManage проверяет, может ли пользователь изменять и удалять файлы медиатеки
+func (m *MediaLibrary) canManage(userID, teamID int) (bool, error) {
+	roles, err := m.teamRepo.GetTeamUserRoles(teamID, userID)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(roles, repo.AdminRole) || slices.Contains(roles, repo.PostsRole), nil
+}
+
+// getTeamMediaFile возвращает медиафайл, только если он принадлежит команде
+func (m *MediaLibrary) getTeamMediaFile(teamID, mediaFileID int) (*entity.MediaFile, error) {
+	file, err := m.mediaRepo.GetMediaFile(mediaFileID)
+	switch {
+	case errors.Is(err, repo.ErrMediaFileNotFound):
+		return nil, usecase.ErrMediaFileNotFound
+	case err != nil:
+		return nil, err
+	}
+	if file.TeamID != teamID {
+		return nil, usecase.ErrMediaFileNotFound
+	}
+	return file, nil
+}
+
+func (m *MediaLibrary) UploadFile(upload *entity.Upload) (int, error) {
+	if upload.UserID == nil || upload.TeamID == nil {
+		return 0, usecase.ErrUserForbidden
+	}
+	member, err := m.isTeamMember(*upload.UserID, *upload.TeamID)
+	if err != nil {
+		return 0, err
+	}
+	if !member {
+		return 0, usecase.ErrUserForbidden
+	}
+	return m.uploadUseCase.UploadFile(upload)
+}
+
+func (m *MediaLibrary) GetFile(userID, mediaFileID int) (*entity.Upload, error) {
+	allowed, err := m.mediaRepo.CanUserAccessMediaFile(userID, mediaFileID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		// не раскрываем, существует ли файл
+		return nil, usecase.ErrMediaFileNotFound
+	}
+	return m.uploadUseCase.GetUpload(mediaFileID)
+}
+
+func (m *MediaLibrary) GetMediaFiles(request *entity.GetMediaLibraryRequest) ([]*entity.MediaFile, error) {
+	member, err := m.isTeamMember(request.UserID, request.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, usecase.ErrUserForbidden
+	}
+	if request.Limit <= 0 || request.Limit > 100 {
+		request.Limit = 100
+	}
+	if request.Offset < 0 {
+		request.Offset = 0
+	}
+	return m.mediaRepo.GetMediaFiles(request)
+}
+
+func (m *MediaLibrary) EditMediaFile(request *entity.EditMediaFileRequest) (*entity.MediaFile, error) {
+	allowed, err := m.canManage(request.UserID, request.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, usecase.ErrUserForbidden
+	}
+	file, err := m.getTeamMediaFile(request.TeamID, request.MediaFileID)
+	if err != nil {
+		return nil, err
+	}
+	if request.DisplayName != nil {
+		file.DisplayName = *request.DisplayName
+	}
+	if request.Tags != nil {
+		file.Tags = request.Tags
+	}
+	err = m.mediaRepo.EditMediaFile(file)
+	if errors.Is(err, repo.ErrMediaFileNotFound) {
+		return nil, usecase.ErrMediaFileNotFound
+	}
+	return file, err
+}
+
+func (m *MediaLibrary) DeleteMediaFile(request *entity.DeleteMediaFileRequest) error {
+	allowed, err := m.canManage(request.UserID, request.TeamID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return usecase.ErrUserForbidden
+	}
+	file, err := m.getTeamMediaFile(request.TeamID, request.MediaFileID)
+	if err != nil {
+		return err
+	}
+	if file.PostsCount > 0 {
+		return usecase.ErrMediaFileInUse
+	}
+	return m.uploadUseCase.DeleteUpload(file.ID)
+}
+
+func (m *MediaLibrary) CreateUploadSession(session *entity.UploadSession) (*entity.UploadSession, error) {
+	member, err := m.isTeamMember(session.UserID, session.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, usecase.ErrUserForbidden
+	}
+	return m.uploadUseCase.CreateUploadSession(session)
+}
+
+// getUserUploadSession возвращает сессию, только если её создал пользователь
+func (m *MediaLibrary) getUserUploadSession(userID, sessionID int) (*entity.UploadSession, error) {
+	session, err := m.uploadUseCase.GetUploadSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != userID {
+		// чужие сессии не раскрываем
+		return nil, usecase.ErrUploadSessionNotFound
+	}
+	return session, nil
+}
+
+func (m *MediaLibrary) GetUploadSession(userID, sessionID int) (*entity.UploadSession, error) {
+	return m.getUserUploadSession(userID, sessionID)
+}
+
+func (m *MediaLibrary) WriteUploadSession(userID, sessionID int, offset int64, r io.Reader) (*entity.UploadSession, error) {
+	if _, err := m.getUserUploadSession(userID, sessionID); err != nil {
+		return nil, err
+	}
+	return m.uploadUseCase.WriteUploadSession(sessionID, offset, r)
+}
+
+func (m *MediaLibrary) DeleteUploadSession(userID, sessionID int) error {
+	if _, err := m.getUserUploadSession(userID, sessionID); err != nil {
+		return err
+	}
+	return m.uploadUseCase.DeleteUploadSession(sessionID)
+}
+
+func (m *MediaLibrary) CreatePresignedUpload(request *entity.PresignUploadRequest) (*entity.UploadSession, *entity.PresignedURL, error) {
+	member, err := m.isTeamMember(request.UserID, request.TeamID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !member {
+		return nil, nil, usecase.ErrUserForbidden
+	}
+	return m.uploadUseCase.CreatePresignedUpload(&entity.UploadSession{
+		UserID:   request.UserID,
+		TeamID:   request.TeamID,
+		FileName: request.FileName,
+		FileType: request.FileType,
+		Size:     request.Size,
+	})
+}
+
+func (m *MediaLibrary) FinalizePresignedUpload(request *entity.FinalizeUploadRequest) (*entity.UploadSession, error) {
+	if _, err := m.getUserUploadSession(request.UserID, request.SessionID); err != nil {
+		return nil, err
+	}
+	return m.uploadUseCase.FinalizePresignedUpload(request.SessionID)
+}
+
+func (m *MediaLibrary) GetFileURL(userID, mediaFileID int) (*entity.PresignedURL, error) {
+	allowed, err := m.mediaRepo.CanUserAccessMediaFile(userID, mediaFileID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, usecase.ErrMediaFileNotFound
+	}
+	return m.uploadUseCase.PresignDownload(mediaFileID)
+}
+
+func (m *MediaLibrary) GetStorageUsage(request *entity.GetStorageUsageRequest) (*entity.TeamStorageUsage, error) {
+	member, err := m.isTeamMember(request.UserID, request.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, usecase.ErrUserForbidden
+	}
+	return m.uploadUseCase.GetTeamStorageUsage(request.TeamID)
+}
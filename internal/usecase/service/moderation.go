@@ -0,0 +1,465 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"postic-backend/internal/usecase"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/labstack/gommon/log"
+)
+
+// moderationRulesTTL сколько слушатели используют загруженные правила, прежде чем перечитать их из базы
+const moderationRulesTTL = 30 * time.Second
+
+var (
+	linkPattern = regexp.MustCompile(
+		`(?i)(https?://|www\.|t\.me/|@[a-z0-9_]{5,}bot\b)|\b[a-z0-9-]+(\.[a-z0-9-]+)*\.(ru|com|net|org|su|io|me|info|biz|xyz|online|site|shop|pro|by|kz|ua|cc|ly|gg)\b`,
+	)
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s\-().]{8,}\d`)
+)
+
+// compiledRule правило с заранее скомпилированным выражением
+type compiledRule struct {
+	*entity.ModerationRule
+	pattern *regexp.Regexp
+}
+
+type teamRules struct {
+	rules    []*compiledRule
+	loadedAt time.Time
+}
+
+type Moderation struct {
+	moderationRepo repo.Moderation
+	commentRepo    repo.Comment
+	teamRepo       repo.Team
+	eventRepo      repo.CommentEventRepository
+	ticketRepo     repo.Ticket
+	banRepo        repo.Ban
+	teamEventRepo  repo.TeamEventRepository
+
+	mu    sync.Mutex
+	cache map[int]*teamRules
+}
+
+// NewModeration создаёт сервис модерации. Он же реализует usecase.CommentModerator для слушателей платформ
+func NewModeration(
+	moderationRepo repo.Moderation,
+	commentRepo repo.Comment,
+	teamRepo repo.Team,
+	eventRepo repo.CommentEventRepository,
+	ticketRepo repo.Ticket,
+	banRepo repo.Ban,
+	teamEventRepo repo.TeamEventRepository,
+) *Moderation {
+	return &Moderation{
+		moderationRepo: moderationRepo,
+		commentRepo:    commentRepo,
+		teamRepo:       teamRepo,
+		eventRepo:      eventRepo,
+		ticketRepo:     ticketRepo,
+		banRepo:        banRepo,
+		teamEventRepo:  teamEventRepo,
+		cache:          make(map[int]*teamRules),
+	}
+}
+
+// teamRules возвращает включённые правила команды из кэша или базы
+func (m *Moderation) teamRules(teamID int) ([]*compiledRule, error) {
+	m.mu.Lock()
+	cached, ok := m.cache[teamID]
+	m.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < moderationRulesTTL {
+		return cached.rules, nil
+	}
+
+	rules, err := m.moderationRepo.GetModerationRules(teamID)
+	if err != nil {
+		return nil, err
+	}
+	compiled := make([]*compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		c := &compiledRule{ModerationRule: rule}
+		if rule.Type == entity.ModerationRegex {
+			c.pattern, err = regexp.Compile(rule.Pattern)
+			if err != nil {
+				log.Errorf("Правило модерации %d содержит некорректное выражение: %v", rule.ID, err)
+				continue
+			}
+		}
+		compiled = append(compiled, c)
+	}
+
+	m.mu.Lock()
+	m.cache[teamID] = &teamRules{rules: compiled, loadedAt: time.Now()}
+	m.mu.Unlock()
+	return compiled, nil
+}
+
+// invalidateRules сбрасывает кэш правил команды после изменения
+func (m *Moderation) invalidateRules(teamID int) {
+	m.mu.Lock()
+	delete(m.cache, teamID)
+	m.mu.Unlock()
+}
+
+// textWords разбивает текст на слова в нижнем регистре
+func textWords(text string) map[string]struct{} {
+	words := make(map[string]struct{})
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[word] = struct{}{}
+	}
+	return words
+}
+
+// matchKeywords возвращает первое найденное в тексте ключевое слово или пустую строку.
+// Фразы ищутся как подстрока, отдельные слова — целиком, чтобы не срабатывать на части слов
+func matchKeywords(text string, words map[string]struct{}, keywords []string) string {
+	lower := strings.ToLower(text)
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(keyword)
+		if strings.ContainsFunc(keyword, unicode.IsSpace) {
+			if strings.Contains(lower, keyword) {
+				return keyword
+			}
+		} else if _, ok := words[keyword]; ok {
+			return keyword
+		}
+	}
+	return ""
+}
+
+// hasPhone ищет в тексте последовательность, похожую на номер телефона (от 10 до 15 цифр)
+func hasPhone(text string) bool {
+	for _, match := range phonePattern.FindAllString(text, -1) {
+		digits := 0
+		for _, r := range match {
+			if unicode.IsDigit(r) {
+				digits++
+			}
+		}
+		if digits >= 10 && digits <= 15 {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRule проверяет комментарий одним правилом и возвращает причину срабатывания или пустую строку
+func (m *Moderation) matchRule(rule *compiledRule, comment *entity.Comment, words map[string]struct{}) (string, error) {
+	switch rule.Type {
+	case entity.ModerationKeywords:
+		if keyword := matchKeywords(comment.Text, words, rule.Keywords); keyword != "" {
+			if strings.ContainsFunc(keyword, unicode.IsSpace) {
+				return fmt.Sprintf("стоп-фраза «%s»", keyword), nil
+			}
+			return fmt.Sprintf("стоп-слово «%s»", keyword), nil
+		}
+	case entity.ModerationRegex:
+		if rule.pattern.MatchString(comment.Text) {
+			return "совпадение с регулярным выражением", nil
+		}
+	case entity.ModerationLink:
+		if linkPattern.MatchString(comment.Text) {
+			return "ссылка в тексте", nil
+		}
+	case entity.ModerationPhone:
+		if hasPhone(comment.Text) {
+			return "номер телефона в тексте", nil
+		}
+	case entity.ModerationFlood:
+		since := comment.CreatedAt.Add(-time.Duration(rule.WindowSeconds) * time.Second)
+		count, err := m.moderationRepo.CountUserComments(comment.TeamID, comment.Platform, comment.UserPlatformID, since)
+		if err != nil {
+			return "", err
+		}
+		// текущий комментарий ещё не сохранён
+		if count+1 > rule.Threshold {
+			return fmt.Sprintf("%d комментариев за %d с", count+1, rule.WindowSeconds), nil
+		}
+	case entity.ModerationRepeat:
+		if comment.Text == "" {
+			return "", nil
+		}
+		since := comment.CreatedAt.Add(-time.Duration(rule.WindowSeconds) * time.Second)
+		count, err := m.moderationRepo.CountUserRepeats(comment.TeamID, comment.Platform, comment.UserPlatformID, comment.Text, since)
+		if err != nil {
+			return "", err
+		}
+		if count+1 >= rule.Threshold {
+			return fmt.Sprintf("текст повторён %d раз за %d с", count+1, rule.WindowSeconds), nil
+		}
+	case entity.ModerationNewAccount:
+		count, err := m.moderationRepo.CountUserComments(comment.TeamID, comment.Platform, comment.UserPlatformID, time.Time{})
+		if err != nil {
+			return "", err
+		}
+		if count < rule.Threshold {
+			return fmt.Sprintf("новый автор: %d прошлых комментариев", count), nil
+		}
+	}
+	return "", nil
+}
+
+func (m *Moderation) CheckComment(comment *entity.Comment) (*entity.ModerationVerdict, error) {
+	return m.checkComment(comment, false)
+}
+
+func (m *Moderation) CheckEditedComment(comment *entity.Comment) (*entity.ModerationVerdict, error) {
+	// флуд, повторы и новизна автора уже проверены при появлении комментария, а сам он теперь учтён в подсчётах
+	return m.checkComment(comment, true)
+}
+
+func (m *Moderation) checkComment(comment *entity.Comment, textOnly bool) (*entity.ModerationVerdict, error) {
+	if comment.IsTeamReply {
+		return nil, nil
+	}
+	rules, err := m.teamRules(comment.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	words := textWords(comment.Text)
+	var verdict *entity.ModerationVerdict
+	for _, rule := range rules {
+		// правила, которые не строже уже выбранного действия, не проверяются: они не изменят вердикт
+		if verdict != nil && rule.Action.Severity() <= verdict.Action.Severity() {
+			continue
+		}
+		if textOnly && !rule.Type.ChecksText() {
+			continue
+		}
+		reason, err := m.matchRule(rule, comment, words)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			continue
+		}
+		verdict = &entity.ModerationVerdict{
+			Rule:   rule.ModerationRule,
+			Action: rule.Action,
+			Reason: fmt.Sprintf("%s: %s", rule.Name, reason),
+		}
+	}
+	return verdict, nil
+}
+
+func (m *Moderation) LogAction(comment *entity.Comment, verdict *entity.ModerationVerdict, succeeded bool) error {
+	entry := &entity.ModerationLogEntry{
+		TeamID:         comment.TeamID,
+		Platform:       comment.Platform,
+		UserPlatformID: comment.UserPlatformID,
+		Action:         verdict.Action,
+		Reason:         verdict.Reason,
+		Succeeded:      succeeded,
+		CreatedAt:      time.Now(),
+	}
+	if verdict.Rule != nil {
+		entry.RuleID = &verdict.Rule.ID
+	}
+	if comment.ID != 0 {
+		entry.CommentID = &comment.ID
+	}
+	log.Infof("Автомодерация: команда %d, комментарий %d, действие %s (%s)", comment.TeamID, comment.ID, verdict.Action, verdict.Reason)
+	return m.moderationRepo.AddModerationLog(entry)
+}
+
+func (m *Moderation) OpenTicket(comment *entity.Comment, verdict *entity.ModerationVerdict) error {
+	_, err := newCommentTicket(m.ticketRepo, m.teamEventRepo, comment, nil, "", "", nil)
+	if errors.Is(err, repo.ErrTicketCommentLinked) {
+		return nil
+	}
+	return err
+}
+
+func (m *Moderation) RecordBan(comment *entity.Comment, verdict *entity.ModerationVerdict) error {
+	ban := &entity.CommenterBan{
+		TeamID:         comment.TeamID,
+		Platform:       comment.Platform,
+		UserPlatformID: comment.UserPlatformID,
+		FullName:       comment.FullName,
+		Username:       comment.Username,
+		Reason:         verdict.Reason,
+		CreatedAt:      time.Now(),
+	}
+	if comment.ID != 0 {
+		ban.CommentID = &comment.ID
+	}
+	_, err := m.banRepo.AddBan(ban)
+	return err
+}
+
+// checkRoles проверяет, что у пользователя есть хотя бы одна из ролей в команде
+func (m *Moderation) checkRoles(teamID, userID int, allowed ...string) error {
+	roles, err := m.teamRepo.GetTeamUserRoles(teamID, userID)
+	if err != nil {
+		return err
+	}
+	for _, role := range allowed {
+		if slices.Contains(roles, role) {
+			return nil
+		}
+	}
+	return usecase.ErrUserForbidden
+}
+
+// getTeamRule возвращает правило, только если оно принадлежит команде
+func (m *Moderation) getTeamRule(teamID, ruleID int) (*entity.ModerationRule, error) {
+	rule, err := m.moderationRepo.GetModerationRule(ruleID)
+	switch {
+	case errors.Is(err, repo.ErrModerationRuleNotFound):
+		return nil, usecase.ErrModerationRuleNotFound
+	case err != nil:
+		return nil, err
+	}
+	if rule.TeamID != teamID {
+		return nil, usecase.ErrModerationRuleNotFound
+	}
+	return rule, nil
+}
+
+func (m *Moderation) GetRules(request *entity.GetModerationRulesRequest) ([]*entity.ModerationRule, error) {
+	if err := m.checkRoles(request.TeamID, request.UserID, repo.AdminRole, repo.CommentsRole); err != nil {
+		return nil, err
+	}
+	return m.moderationRepo.GetModerationRules(request.TeamID)
+}
+
+func (m *Moderation) AddRule(request *entity.ModerationRuleRequest) (int, error) {
+	if err := m.checkRoles(request.TeamID, request.UserID, repo.AdminRole); err != nil {
+		return 0, err
+	}
+	ruleID, err := m.moderationRepo.AddModerationRule(&request.ModerationRule)
+	if err != nil {
+		return 0, err
+	}
+	m.invalidateRules(request.TeamID)
+	return ruleID, nil
+}
+
+func (m *Moderation) EditRule(request *entity.ModerationRuleRequest) error {
+	if err := m.checkRoles(request.TeamID, request.UserID, repo.AdminRole); err != nil {
+		return err
+	}
+	if _, err := m.getTeamRule(request.TeamID, request.ID); err != nil {
+		return err
+	}
+	err := m.moderationRepo.EditModerationRule(&request.ModerationRule)
+	if errors.Is(err, repo.ErrModerationRuleNotFound) {
+		return usecase.ErrModerationRuleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	m.invalidateRules(request.TeamID)
+	return nil
+}
+
+func (m *Moderation) DeleteRule(request *entity.DeleteModerationRuleRequest) error {
+	if err := m.checkRoles(request.TeamID, request.UserID, repo.AdminRole); err != nil {
+		return err
+	}
+	if _, err := m.getTeamRule(request.TeamID, request.RuleID); err != nil {
+		return err
+	}
+	err := m.moderationRepo.DeleteModerationRule(request.RuleID)
+	if errors.Is(err, repo.ErrModerationRuleNotFound) {
+		return usecase.ErrModerationRuleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	m.invalidateRules(request.TeamID)
+	return nil
+}
+
+func (m *Moderation) GetLog(request *entity.GetModerationLogRequest) ([]*entity.ModerationLogEntry, error) {
+	if err := m.checkRoles(request.TeamID, request.UserID, repo.AdminRole, repo.CommentsRole); err != nil {
+		return nil, err
+	}
+	if request.Offset.IsZero() {
+		request.Offset = time.Now()
+	}
+	if request.Limit <= 0 || request.Limit > 100 {
+		request.Limit = 100
+	}
+	return m.moderationRepo.GetModerationLog(request.TeamID, request.Offset, request.Limit)
+}
+
+func (m *Moderation) GetHeldComments(request *entity.GetHeldCommentsRequest) ([]*entity.Comment, error) {
+	if err := m.checkRoles(request.TeamID, request.UserID, repo.AdminRole, repo.CommentsRole); err != nil {
+		return nil, err
+	}
+	if request.Limit <= 0 || request.Limit > 100 {
+		request.Limit = 100
+	}
+	ids, err := m.moderationRepo.GetHeldCommentIDs(request.TeamID, request.Limit)
+	if err != nil {
+		return nil, err
+	}
+	comments := make([]*entity.Comment, 0, len(ids))
+	for _, id := range ids {
+		comment, err := m.commentRepo.GetComment(id)
+		if errors.Is(err, repo.ErrCommentNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+func (m *Moderation) ApproveHeldComment(request *entity.ApproveHeldCommentRequest) error {
+	if err := m.checkRoles(request.TeamID, request.UserID, repo.AdminRole, repo.CommentsRole); err != nil {
+		return err
+	}
+	comment, err := m.commentRepo.GetComment(request.CommentID)
+	switch {
+	case errors.Is(err, repo.ErrCommentNotFound):
+		return usecase.ErrHeldCommentNotFound
+	case err != nil:
+		return err
+	}
+	if comment.TeamID != request.TeamID {
+		return usecase.ErrHeldCommentNotFound
+	}
+
+	err = m.moderationRepo.ReleaseHeldComment(comment.ID)
+	if errors.Is(err, repo.ErrHeldCommentNotFound) {
+		return usecase.ErrHeldCommentNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// Подписчики узнают о комментарии только сейчас, как если бы он только что пришёл
+	event := &entity.CommentEvent{
+		EventID:    fmt.Sprintf("%s-%d-%d", comment.Platform, comment.TeamID, comment.ID),
+		TeamID:     comment.TeamID,
+		PostID:     derefInt(comment.PostUnionID),
+		Type:       entity.CommentCreated,
+		CommentID:  comment.ID,
+		OccurredAt: time.Now(),
+	}
+	if err := m.eventRepo.PublishCommentEvent(context.Background(), event); err != nil {
+		log.Errorf("Не удалось опубликовать событие об одобренном комментарии в Kafka: %v", err)
+	}
+	return nil
+}
@@ -21,9 +21,11 @@ type PostUnion struct {
 	postRepo        repo.Post
 	teamRepo        repo.Team
 	uploadUseCase   usecase.Upload
+	mediaRepo       repo.MediaLibrary
 	analyticsRepo   repo.Analytics
 	telegram        usecase.PostPlatform
 	vkontakte       usecase.PostPlatform
+	teamEventRepo   repo.TeamEventRepository
 	generatePostURL string
 	fixPostTextURL  string
 }
@@ -32,9 +34,11 @@ func NewPostUnion(
 	postRepo repo.Post,
 	teamRepo repo.Team,
 	uploadUseCase usecase.Upload,
+	mediaRepo repo.MediaLibrary,
 	analyticsRepo repo.Analytics,
 	telegram usecase.PostPlatform,
 	vkontakte usecase.PostPlatform,
+	teamEventRepo repo.TeamEventRepository,
 	generatePostURL string,
 	fixPostTextURL string,
 ) usecase.PostUnion {
@@ -42,9 +46,11 @@ func NewPostUnion(
 		postRepo:        postRepo,
 		teamRepo:        teamRepo,
 		uploadUseCase:   uploadUseCase,
+		mediaRepo:       mediaRepo,
 		analyticsRepo:   analyticsRepo,
 		telegram:        telegram,
 		vkontakte:       vkontakte,
+		teamEventRepo:   teamEventRepo,
 		generatePostURL: generatePostURL,
 		fixPostTextURL:  fixPostTextURL,
 	}
@@ -105,6 +111,13 @@ func (p *PostUnion) scheduleListen() {
 					log.Errorf("error updating scheduled post: %v", err)
 					continue
 				}
+				// статус публикации на каждой платформе придёт отдельными событиями действий с постом
+				publishTeamEvent(p.teamEventRepo, &entity.TeamEvent{
+					TeamID:      postUnion.TeamID,
+					Type:        entity.TeamPostPublished,
+					PostUnionID: postUnion.ID,
+					OccurredAt:  time.Now(),
+				})
 			}
 		}
 	}
@@ -130,6 +143,14 @@ func (p *PostUnion) AddPostUnion(request *entity.AddPostRequest) (int, []int, er
 	attachments := make([]*entity.Upload, len(request.Attachments))
 	if len(request.Attachments) > 0 {
 		for i, attachment := range request.Attachments {
+			// к посту можно прикрепить только файл, доступный пользователю через его команды
+			allowed, err := p.mediaRepo.CanUserAccessMediaFile(request.UserID, attachment)
+			if err != nil {
+				return 0, nil, err
+			}
+			if !allowed {
+				return 0, nil, usecase.ErrMediaFileNotFound
+			}
 			upload, err := p.uploadUseCase.GetUpload(attachment)
 			if err != nil {
 				return 0, nil, err
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"postic-backend/internal/usecase"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	// teamEventRolesInterval как часто роли подписчика перечитываются, чтобы отозванные права действовали в открытом потоке
+	teamEventRolesInterval = 30 * time.Second
+	// teamEventCursorSeparator разделяет в курсоре позиции общего потока команды и потока комментариев
+	teamEventCursorSeparator = "/"
+)
+
+// teamEventRoles роль, кроме администратора, которая нужна для получения события
+var teamEventRoles = map[entity.TeamEventType]string{
+	entity.TeamCommentCreated:    repo.CommentsRole,
+	entity.TeamCommentEdited:     repo.CommentsRole,
+	entity.TeamCommentDeleted:    repo.CommentsRole,
+	entity.TeamTicketCreated:     repo.CommentsRole,
+	entity.TeamTicketUpdated:     repo.CommentsRole,
+	entity.TeamPostActionUpdated: repo.PostsRole,
+	entity.TeamPostPublished:     repo.PostsRole,
+	entity.TeamStatsUpdated:      repo.AnalyticsRole,
+}
+
+// teamCommentEventTypes соответствие событий комментариев типам общего потока
+var teamCommentEventTypes = map[entity.CommentEventType]entity.TeamEventType{
+	entity.CommentCreated: entity.TeamCommentCreated,
+	entity.CommentEdited:  entity.TeamCommentEdited,
+	entity.CommentDeleted: entity.TeamCommentDeleted,
+}
+
+type TeamEvents struct {
+	teamRepo         repo.Team
+	teamEventRepo    repo.TeamEventRepository
+	commentEventRepo repo.CommentEventRepository
+}
+
+func NewTeamEvents(
+	teamRepo repo.Team,
+	teamEventRepo repo.TeamEventRepository,
+	commentEventRepo repo.CommentEventRepository,
+) usecase.TeamEvents {
+	return &TeamEvents{
+		teamRepo:         teamRepo,
+		teamEventRepo:    teamEventRepo,
+		commentEventRepo: commentEventRepo,
+	}
+}
+
+func canReceiveTeamEvent(roles []string, eventType entity.TeamEventType) bool {
+	return slices.Contains(roles, repo.AdminRole) || slices.Contains(roles, teamEventRoles[eventType])
+}
+
+// parseTeamEventTypes разбирает типы событий через запятую. Пустая строка — все типы
+func parseTeamEventTypes(types string) ([]entity.TeamEventType, error) {
+	if strings.TrimSpace(types) == "" {
+		return entity.TeamEventTypes, nil
+	}
+	var result []entity.TeamEventType
+	for _, name := range strings.Split(types, ",") {
+		eventType := entity.TeamEventType(strings.TrimSpace(name))
+		if !slices.Contains(entity.TeamEventTypes, eventType) {
+			return nil, fmt.Errorf("%w: unknown event type %q", usecase.ErrInvalidTeamEventFilter, eventType)
+		}
+		if !slices.Contains(result, eventType) {
+			result = append(result, eventType)
+		}
+	}
+	return result, nil
+}
+
+func (t *TeamEvents) Subscribe(ctx context.Context, request *entity.TeamEventSubscriber) (<-chan *entity.TeamEvent, error) {
+	roles, err := t.teamRepo.GetTeamUserRoles(request.TeamID, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, usecase.ErrUserForbidden
+	}
+
+	types, err := parseTeamEventTypes(request.Types)
+	if err != nil {
+		return nil, err
+	}
+	// явно запрошенные типы без нужной роли — ошибка, а из полного списка они просто отбрасываются
+	wanted := make(map[entity.TeamEventType]struct{}, len(types))
+	for _, eventType := range types {
+		if canReceiveTeamEvent(roles, eventType) {
+			wanted[eventType] = struct{}{}
+		} else if request.Types != "" {
+			return nil, usecase.ErrUserForbidden
+		}
+	}
+	if len(wanted) == 0 {
+		return nil, usecase.ErrUserForbidden
+	}
+
+	// события читаются из двух топиков, поэтому курсор хранит позицию в каждом из них
+	teamCursor, commentCursor, _ := strings.Cut(request.LastEventID, teamEventCursorSeparator)
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	teamEvents, err := t.teamEventRepo.SubscribeTeamEvents(streamCtx, request.TeamID, teamCursor)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// события комментариев уже публикуются слушателями платформ в свой топик, поэтому читаются оттуда
+	var commentEvents <-chan *entity.CommentEvent
+	needComments := false
+	for _, eventType := range teamCommentEventTypes {
+		_, ok := wanted[eventType]
+		needComments = needComments || ok
+	}
+	if needComments {
+		commentEvents, err = t.commentEventRepo.SubscribeCommentEvents(streamCtx, request.TeamID, 0, commentCursor)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	ch := make(chan *entity.TeamEvent)
+	go func() {
+		defer close(ch)
+		defer cancel()
+		rolesCheckedAt := time.Now()
+		for {
+			var event *entity.TeamEvent
+			select {
+			case <-streamCtx.Done():
+				return
+			case teamEvent, ok := <-teamEvents:
+				if !ok {
+					return
+				}
+				event = teamEvent
+				teamCursor = teamEvent.Cursor
+			case commentEvent, ok := <-commentEvents:
+				if !ok {
+					return
+				}
+				commentCursor = commentEvent.Cursor
+				event = &entity.TeamEvent{
+					EventID:     commentEvent.EventID,
+					TeamID:      commentEvent.TeamID,
+					Type:        teamCommentEventTypes[commentEvent.Type],
+					PostUnionID: commentEvent.PostID,
+					CommentID:   commentEvent.CommentID,
+					OccurredAt:  commentEvent.OccurredAt,
+				}
+			}
+
+			if time.Since(rolesCheckedAt) > teamEventRolesInterval {
+				roles, err = t.teamRepo.GetTeamUserRoles(request.TeamID, request.UserID)
+				if err != nil {
+					log.Errorf("Ошибка при проверке ролей подписчика событий команды %d: %v", request.TeamID, err)
+					return
+				}
+				// пользователя исключили из команды
+				if len(roles) == 0 {
+					return
+				}
+				rolesCheckedAt = time.Now()
+			}
+			if _, ok := wanted[event.Type]; !ok || !canReceiveTeamEvent(roles, event.Type) {
+				continue
+			}
+			event.Cursor = teamCursor + teamEventCursorSeparator + commentCursor
+
+			select {
+			case ch <- event:
+			case <-streamCtx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// publishTeamEvent публикует событие в общий поток команды. Ошибка публикации не прерывает операцию,
+// без Kafka (teamEventRepo nil) события не публикуются
+func publishTeamEvent(teamEventRepo repo.TeamEventRepository, event *entity.TeamEvent) {
+	if teamEventRepo == nil {
+		return
+	}
+	if err := teamEventRepo.PublishTeamEvent(context.Background(), event); err != nil {
+		log.Errorf("Ошибка публикации события %s команды %d: %v", event.Type, event.TeamID, err)
+	}
+}
+
+// PublishPostActionStatus сообщает команде о смене статуса действия с постом. Общая для площадок публикации:
+// команда определяется по посту действия
+func PublishPostActionStatus(postRepo repo.Post, teamEventRepo repo.TeamEventRepository, action *entity.PostAction, status, errMsg string) {
+	// у действия с уже удалённым постом команду не определить
+	if teamEventRepo == nil || action.PostUnionID == nil {
+		return
+	}
+	postUnion, err := postRepo.GetPostUnion(*action.PostUnionID)
+	if err != nil {
+		log.Errorf("Ошибка при получении поста действия %d: %v", action.ID, err)
+		return
+	}
+	publishTeamEvent(teamEventRepo, &entity.TeamEvent{
+		TeamID:       postUnion.TeamID,
+		Type:         entity.TeamPostActionUpdated,
+		PostUnionID:  postUnion.ID,
+		PostActionID: action.ID,
+		Platform:     action.Platform,
+		Status:       status,
+		Error:        errMsg,
+		OccurredAt:   time.Now(),
+	})
+}
@@ -7,7 +7,6 @@ import (
 	"postic-backend/internal/repo"
 	"postic-backend/internal/usecase"
 	"postic-backend/pkg/retry"
-	"slices"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -40,15 +39,7 @@ func NewTelegramComment(
 
 // ReplyComment отправляет комментарий в ответ на другой комментарий от имени группы
 func (t *Comment) ReplyComment(request *entity.ReplyCommentRequest) (int, error) {
-	// Проверяем, что пользователь является членом команды и имеет права админа или comments
-	roles, err := t.teamRepo.GetTeamUserRoles(request.TeamID, request.UserID)
-	if err != nil {
-		return 0, err
-	}
-	if !slices.Contains(roles, repo.AdminRole) && !slices.Contains(roles, repo.CommentsRole) {
-		return 0, usecase.ErrUserForbidden
-	}
-
+	// Права пользователя проверяет usecase Comment, автоответы FAQ отправляются без пользователя.
 	// Получаем информацию о канале дискуссий
 	teamID := request.TeamID
 	tgChannel, err := t.teamRepo.GetTGChannelByTeamID(teamID)
@@ -193,6 +184,8 @@ func (t *Comment) ReplyComment(request *entity.ReplyCommentRequest) (int, error)
 		Username:          "",
 		Text:              request.Text,
 		IsTeamReply:       true,
+		ReplySource:       request.Source(),
+		FAQRuleID:         request.FAQRuleID,
 		ReplyToCommentID:  request.CommentID,
 		CreatedAt:         time.Now(),
 		Attachments:       make([]*entity.Upload, 0),
@@ -284,31 +277,147 @@ func (t *Comment) DeleteComment(request *entity.DeleteCommentRequest) error {
 		}
 	}
 
-	// Если нужно забанить пользователя в telegram, то баним его
-	if request.BanUser {
-		// Создаем запрос на бан пользователя
-		banConfig := tgbotapi.BanChatMemberConfig{
-			ChatMemberConfig: tgbotapi.ChatMemberConfig{
-				ChatID: int64(*tgChannel.DiscussionID),
-				UserID: int64(request.UserID),
-			},
-			UntilDate:      0, // 0 означает бан навсегда
-			RevokeMessages: false,
-		}
-		// Пробуем забанить пользователя с повторами в случае ошибки
-		err = retry.Retry(func() error {
-			_, err := t.bot.Request(banConfig)
-			return err
-		})
+	return nil
+}
 
-		if err != nil {
-			log.Errorf("Не удалось забанить пользователя в Post: %v", err)
-		}
+// EditComment меняет текст ответа команды в группе обсуждений.
+// У сообщения с вложениями текст хранится в подписи
+func (t *Comment) EditComment(request *entity.EditCommentRequest) error {
+	comment, err := t.commentRepo.GetComment(request.CommentID)
+	if err != nil {
+		return err
+	}
+	chatID, err := t.discussionChatID(request.TeamID)
+	if err != nil {
+		return err
+	}
+
+	var editMsg tgbotapi.Chattable
+	if len(comment.Attachments) > 0 {
+		editMsg = tgbotapi.NewEditMessageCaption(chatID, comment.CommentPlatformID, request.Text)
+	} else {
+		editMsg = tgbotapi.NewEditMessageText(chatID, comment.CommentPlatformID, request.Text)
+	}
+	err = retry.Retry(func() error {
+		_, err := t.bot.Request(editMsg)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := t.commentRepo.EditCommentText(comment.ID, request.Text, &request.UserID, now); err != nil {
+		return err
+	}
+
+	// Публикуем событие об изменении комментария в Kafka
+	event := &entity.CommentEvent{
+		EventID:    fmt.Sprintf("tg-edit-%d-%d-%d", comment.TeamID, comment.ID, now.UnixNano()),
+		TeamID:     comment.TeamID,
+		PostID:     derefInt(comment.PostUnionID),
+		Type:       entity.CommentEdited,
+		CommentID:  comment.ID,
+		OccurredAt: now,
+	}
+	if err := t.eventRepo.PublishCommentEvent(context.Background(), event); err != nil {
+		log.Errorf("Не удалось опубликовать событие об изменении комментария в Kafka: %v", err)
 	}
 
 	return nil
 }
 
+// ReactComment ставит реакцию от имени бота. tgbotapi не поддерживает setMessageReaction,
+// поэтому метод вызывается напрямую
+func (t *Comment) ReactComment(request *entity.ReactCommentRequest) error {
+	comment, err := t.commentRepo.GetComment(request.CommentID)
+	if err != nil {
+		return err
+	}
+	chatID, err := t.discussionChatID(request.TeamID)
+	if err != nil {
+		return err
+	}
+
+	type reactionType struct {
+		Type  string `json:"type"`
+		Emoji string `json:"emoji"`
+	}
+	// пустой список снимает реакцию бота
+	reactions := make([]reactionType, 0, 1)
+	if request.Reaction != "" {
+		reactions = append(reactions, reactionType{Type: "emoji", Emoji: request.Reaction})
+	}
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonZero("message_id", comment.CommentPlatformID)
+	if err := params.AddInterface("reaction", reactions); err != nil {
+		return err
+	}
+	err = retry.Retry(func() error {
+		_, err := t.bot.MakeRequest("setMessageReaction", params)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return t.commentRepo.SetCommentReaction(comment.ID, request.Reaction, &request.UserID, time.Now())
+}
+
+// discussionChatID возвращает ID группы обсуждений канала команды
+func (t *Comment) discussionChatID(teamID int) (int64, error) {
+	tgChannel, err := t.teamRepo.GetTGChannelByTeamID(teamID)
+	if err != nil {
+		return 0, err
+	}
+	if tgChannel.DiscussionID == nil || *tgChannel.DiscussionID == 0 {
+		return 0, fmt.Errorf("у канала команды %d нет группы обсуждений", teamID)
+	}
+	return int64(*tgChannel.DiscussionID), nil
+}
+
+func (t *Comment) BanUser(ban *entity.CommenterBan) error {
+	chatID, err := t.discussionChatID(ban.TeamID)
+	if err != nil {
+		return err
+	}
+	banConfig := tgbotapi.BanChatMemberConfig{
+		ChatMemberConfig: tgbotapi.ChatMemberConfig{
+			ChatID: chatID,
+			UserID: int64(ban.UserPlatformID),
+		},
+		UntilDate:      0, // 0 означает бан навсегда
+		RevokeMessages: false,
+	}
+	if ban.ExpiresAt != nil {
+		banConfig.UntilDate = ban.ExpiresAt.Unix()
+	}
+	// Пробуем забанить пользователя с повторами в случае ошибки
+	return retry.Retry(func() error {
+		_, err := t.bot.Request(banConfig)
+		return err
+	})
+}
+
+func (t *Comment) UnbanUser(ban *entity.CommenterBan) error {
+	chatID, err := t.discussionChatID(ban.TeamID)
+	if err != nil {
+		return err
+	}
+	unbanConfig := tgbotapi.UnbanChatMemberConfig{
+		ChatMemberConfig: tgbotapi.ChatMemberConfig{
+			ChatID: chatID,
+			UserID: int64(ban.UserPlatformID),
+		},
+		OnlyIfBanned: true, // иначе Telegram удалит пользователя из группы
+	}
+	return retry.Retry(func() error {
+		_, err := t.bot.Request(unbanConfig)
+		return err
+	})
+}
+
 // Вспомогательная функция для безопасного получения int из *int
 func derefInt(ptr *int) int {
 	if ptr != nil {
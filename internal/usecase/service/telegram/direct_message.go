@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"postic-backend/internal/entity"
+	"postic-backend/internal/usecase"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// DirectMessage отправляет сообщения в личный чат пользователя с ботом
+type DirectMessage struct {
+	bot           *tgbotapi.BotAPI
+	uploadUseCase usecase.Upload
+}
+
+func NewTelegramDirectMessage(bot *tgbotapi.BotAPI, uploadUseCase usecase.Upload) *DirectMessage {
+	return &DirectMessage{
+		bot:           bot,
+		uploadUseCase: uploadUseCase,
+	}
+}
+
+func (d *DirectMessage) SendDirectMessage(conversation *entity.DMConversation, request *entity.SendDirectMessageRequest) (int64, error) {
+	chatID := conversation.PeerID
+	if len(request.Attachments) == 0 {
+		sent, err := d.bot.Send(tgbotapi.NewMessage(chatID, request.Text))
+		if err != nil {
+			return 0, err
+		}
+		return int64(sent.MessageID), nil
+	}
+
+	media := make([]any, 0, len(request.Attachments))
+	for i, attachmentID := range request.Attachments {
+		upload, err := d.uploadUseCase.GetUpload(attachmentID)
+		if err != nil {
+			return 0, err
+		}
+		file := tgbotapi.FileReader{Name: upload.FilePath, Reader: upload.RawBytes}
+		// текст становится подписью первого вложения
+		caption := ""
+		if i == 0 {
+			caption = request.Text
+		}
+		switch upload.FileType {
+		case "photo":
+			photo := tgbotapi.NewInputMediaPhoto(file)
+			photo.Caption = caption
+			media = append(media, photo)
+		case "video":
+			video := tgbotapi.NewInputMediaVideo(file)
+			video.Caption = caption
+			media = append(media, video)
+		case "audio", "voice":
+			audio := tgbotapi.NewInputMediaAudio(file)
+			audio.Caption = caption
+			media = append(media, audio)
+		default:
+			doc := tgbotapi.NewInputMediaDocument(file)
+			doc.Caption = caption
+			media = append(media, doc)
+		}
+	}
+
+	sent, err := d.bot.SendMediaGroup(tgbotapi.NewMediaGroup(chatID, media))
+	if err != nil {
+		return 0, err
+	}
+	if len(sent) == 0 {
+		return 0, usecase.ErrDirectMessageUnavailable
+	}
+	return int64(sent[0].MessageID), nil
+}
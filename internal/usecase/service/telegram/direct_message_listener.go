@@ -0,0 +1,118 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/labstack/gommon/log"
+)
+
+// directMessageStartPrefix параметр команды /start в ссылке на личную переписку с командой
+const directMessageStartPrefix = "dm"
+
+// startDirectConversation привязывает личный чат с ботом к команде и возвращает текст ответа пользователю
+func (t *EventListener) startDirectConversation(update *models.Update, rawTeamID string) string {
+	teamID, err := strconv.Atoi(rawTeamID)
+	if err != nil {
+		return "Ссылка на переписку с командой недействительна."
+	}
+	team, err := t.teamRepo.GetTeam(teamID)
+	if errors.Is(err, repo.ErrTeamNotFound) {
+		return "Ссылка на переписку с командой недействительна."
+	}
+	if err != nil {
+		log.Errorf("Failed to get team %d for direct conversation: %v", teamID, err)
+		return "Не удалось начать переписку. Попробуйте позже."
+	}
+
+	conversation := t.directConversation(update, teamID)
+	if _, err := t.dmRepo.PutConversation(conversation); err != nil {
+		log.Errorf("Failed to start direct conversation: %v", err)
+		return "Не удалось начать переписку. Попробуйте позже."
+	}
+	return fmt.Sprintf("✉️ Напишите сообщение — его получит команда «%s».", team.Name)
+}
+
+// directConversation собирает переписку из данных отправителя сообщения
+func (t *EventListener) directConversation(update *models.Update, teamID int) *entity.DMConversation {
+	from := update.Message.From
+	conversation := &entity.DMConversation{
+		TeamID:    teamID,
+		Platform:  "tg",
+		PeerID:    update.Message.Chat.ID,
+		FullName:  strings.TrimSpace(from.FirstName + " " + from.LastName),
+		Username:  from.Username,
+		CreatedAt: time.Now(),
+	}
+	avatar, err := t.getUserAvatar(from.ID)
+	if err != nil {
+		// без аватара переписка всё равно нужна
+		log.Errorf("Failed to get user avatar: %v", err)
+	}
+	conversation.AvatarMediaFile = avatar
+	return conversation
+}
+
+// handleDirectMessage сохраняет личное сообщение боту в переписку команды, которую пользователь открыл последней
+func (t *EventListener) handleDirectMessage(ctx context.Context, update *models.Update) error {
+	if update.Message.From == nil || update.Message.From.IsBot {
+		return nil
+	}
+	conversation, err := t.dmRepo.GetLastConversationByPeer("tg", update.Message.Chat.ID)
+	if errors.Is(err, repo.ErrDMConversationNotFound) {
+		// бот общий для всех команд, без ссылки непонятно, кому адресовано сообщение
+		_, err := t.bot.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: update.Message.Chat.ID,
+			Text:   "Чтобы написать команде, перейдите по ссылке на переписку из её канала.",
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	attachments, err := t.processAttachments(update)
+	if err != nil {
+		return err
+	}
+	text := update.Message.Text
+	if text == "" {
+		text = update.Message.Caption
+	}
+	message := &entity.DirectMessage{
+		ConversationID:    conversation.ID,
+		MessagePlatformID: int64(update.Message.ID),
+		Text:              text,
+		CreatedAt:         time.Unix(int64(update.Message.Date), 0),
+		Attachments:       attachments,
+	}
+	messageID, created, err := t.dmRepo.AddMessage(message)
+	if err != nil {
+		return err
+	}
+	if !created {
+		// повторная доставка обновления: событие уже отправлено
+		return nil
+	}
+
+	event := &entity.DirectMessageEvent{
+		EventID:        fmt.Sprintf("tg-dm-%d-%d", conversation.TeamID, messageID),
+		TeamID:         conversation.TeamID,
+		Type:           entity.DirectMessageCreated,
+		ConversationID: conversation.ID,
+		MessageID:      messageID,
+		OccurredAt:     message.CreatedAt,
+	}
+	if err := t.dmEventRepo.PublishDirectMessageEvent(ctx, event); err != nil {
+		log.Errorf("Failed to publish direct message event: %v", err)
+	}
+	return nil
+}
@@ -33,6 +33,10 @@ type EventListener struct {
 	commentRepo               repo.Comment
 	analyticsRepo             repo.Analytics
 	eventRepo                 repo.CommentEventRepository
+	moderator                 usecase.CommentModerator
+	autoReplier               usecase.CommentAutoReplier
+	dmRepo                    repo.DirectMessage
+	dmEventRepo               repo.DirectMessageEventRepository
 
 	// Буфер для медиагрупп: media_group_id -> []*models.Update
 	mediaGroupBuffer map[string][]*models.Update
@@ -52,6 +56,10 @@ func NewTelegramEventListener(
 	commentRepo repo.Comment,
 	analyticsRepo repo.Analytics,
 	eventRepo repo.CommentEventRepository,
+	moderator usecase.CommentModerator,
+	autoReplier usecase.CommentAutoReplier,
+	dmRepo repo.DirectMessage,
+	dmEventRepo repo.DirectMessageEventRepository,
 ) (usecase.Listener, error) {
 	lastUpdateID, err := telegramEventListenerRepo.GetLastUpdate()
 	for err != nil {
@@ -101,6 +109,10 @@ func NewTelegramEventListener(
 		commentRepo:               commentRepo,
 		analyticsRepo:             analyticsRepo,
 		eventRepo:                 eventRepo,
+		moderator:                 moderator,
+		autoReplier:               autoReplier,
+		dmRepo:                    dmRepo,
+		dmEventRepo:               dmEventRepo,
 		mediaGroupBuffer:          make(map[string][]*models.Update),
 		mediaGroupTimers:          make(map[string]*time.Timer),
 	}, nil
@@ -367,6 +379,11 @@ func (t *EventListener) handleCommand(update *models.Update) error {
 
 	switch command {
 	case "start":
+		// ссылка вида t.me/<бот>?start=dm<ID команды> открывает личную переписку с командой
+		if teamID, ok := strings.CutPrefix(args, directMessageStartPrefix); ok {
+			params.Text = t.startDirectConversation(update, teamID)
+			break
+		}
 		params.Text = "❇️ Привет! Я бот, управляющий телеграм-каналами пользователей Postic. " +
 			"Используйте команду /help, чтобы увидеть список доступных команд."
 	case "help":
@@ -582,6 +599,19 @@ func (t *EventListener) handleMessageUpdate(ctx context.Context, update *models.
 		return
 	}
 
+	if message.Chat.Type == models.ChatTypePrivate {
+		// правки личных сообщений не сохраняются, в переписке остаётся исходный текст
+		if !isEdit {
+			err := t.handleDirectMessage(ctx, update)
+			if err != nil {
+				log.Errorf("Failed to handle direct message: %v", err)
+			}
+		}
+		// Сохраняем ID последнего обработанного обновления
+		t.saveLastUpdateID(int(update.ID))
+		return
+	}
+
 	if t.isGroupMessage(message) {
 		if isEdit {
 			err := t.handleCommentEdit(ctx, update)
@@ -676,12 +706,25 @@ func (t *EventListener) handleNewComment(ctx context.Context, update *models.Upd
 				if newComment.Text == "" && len(newComment.Attachments) == 0 {
 					return
 				}
+				verdict := t.moderateComment(newComment)
 				commentID, err := t.commentRepo.AddComment(newComment)
 				if err != nil {
 					log.Errorf("Failed to save comment: %v", err)
 					return
 				}
+				newComment.ID = commentID
+				if verdict != nil {
+					messageIDs := make([]int, 0, len(updates))
+					for _, u := range updates {
+						messageIDs = append(messageIDs, u.Message.ID)
+					}
+					t.applyModeration(ctx, first.Message.Chat.ID, messageIDs, newComment, verdict)
+					if verdict.Action.HidesComment() {
+						return
+					}
+				}
 				t.publishCommentEvent(ctx, tgChannel.TeamID, commentID, newComment.PostUnionID, entity.CommentCreated, newComment.CreatedAt)
+				go t.autoReplier.AutoReply(newComment)
 			})
 		}
 		t.mediaGroupMutex.Unlock()
@@ -727,15 +770,31 @@ func (t *EventListener) handleNewComment(ctx context.Context, update *models.Upd
 		return nil
 	}
 
+	// Проверяем правилами модерации команды до сохранения, чтобы не учитывать сам комментарий при поиске флуда
+	verdict := t.moderateComment(newComment)
+
 	// Сохраняем комментарий
 	commentID, err := t.commentRepo.AddComment(newComment)
 	if err != nil {
 		log.Errorf("Failed to save comment: %v", err)
 		return err
 	}
+	newComment.ID = commentID
+
+	if verdict != nil {
+		t.applyModeration(ctx, update.Message.Chat.ID, []int{update.Message.ID}, newComment, verdict)
+		// Удалённые и отложенные комментарии подписчикам не рассылаются
+		if verdict.Action.HidesComment() {
+			return nil
+		}
+	}
 
 	// Уведомляем подписчиков
-	return t.publishCommentEvent(ctx, tgChannel.TeamID, commentID, newComment.PostUnionID, entity.CommentCreated, newComment.CreatedAt)
+	err = t.publishCommentEvent(ctx, tgChannel.TeamID, commentID, newComment.PostUnionID, entity.CommentCreated, newComment.CreatedAt)
+
+	// Автоответ отправляется через Bot API и не должен задерживать обработку обновлений
+	go t.autoReplier.AutoReply(newComment)
+	return err
 }
 
 func (t *EventListener) handleCommentEdit(ctx context.Context, update *models.Update) error {
@@ -775,6 +834,9 @@ func (t *EventListener) handleCommentEdit(ctx context.Context, update *models.Up
 		return nil
 	}
 
+	// Изменённый текст проверяется так же, как новый: иначе правила можно обойти, отредактировав комментарий
+	verdict := t.moderateCommentEdit(existingComment)
+
 	// Сохраняем изменения
 	err = t.commentRepo.EditComment(existingComment)
 	if err != nil {
@@ -782,6 +844,13 @@ func (t *EventListener) handleCommentEdit(ctx context.Context, update *models.Up
 		return err
 	}
 
+	if verdict != nil {
+		t.applyModeration(ctx, update.EditedMessage.Chat.ID, []int{update.EditedMessage.ID}, existingComment, verdict)
+		if verdict.Action.HidesComment() {
+			return nil
+		}
+	}
+
 	// Получаем team ID
 	tgChannel, err := t.teamRepo.GetTGChannelByDiscussionId(int(update.EditedMessage.Chat.ID))
 	if err != nil {
@@ -860,30 +929,38 @@ func (t *EventListener) setCommentRelations(comment *entity.Comment, post *entit
 }
 
 func (t *EventListener) setUserAvatar(comment *entity.Comment, userID int64) error {
+	upload, err := t.getUserAvatar(userID)
+	if err != nil {
+		return err
+	}
+	comment.AvatarMediaFile = upload
+	return nil
+}
+
+// getUserAvatar сохраняет фото профиля пользователя. nil — у пользователя нет фото
+func (t *EventListener) getUserAvatar(userID int64) (*entity.Upload, error) {
 	photos, err := t.bot.GetUserProfilePhotos(t.ctx, &bot.GetUserProfilePhotosParams{
 		UserID: userID,
 		Limit:  1,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to get user profile photos: %w", err)
+		return nil, fmt.Errorf("failed to get user profile photos: %w", err)
 	}
 
 	if len(photos.Photos) == 0 {
-		return nil // Нет фото профиля
+		return nil, nil // Нет фото профиля
 	}
 
 	uploadFileId, err := t.saveFile(photos.Photos[0][0].FileID, "photo")
 	if err != nil {
-		return fmt.Errorf("failed to save user profile photo: %w", err)
+		return nil, fmt.Errorf("failed to save user profile photo: %w", err)
 	}
 
 	upload, err := t.uploadUseCase.GetUpload(uploadFileId)
 	if err != nil {
-		return fmt.Errorf("failed to get uploaded avatar file: %w", err)
+		return nil, fmt.Errorf("failed to get uploaded avatar file: %w", err)
 	}
-
-	comment.AvatarMediaFile = upload
-	return nil
+	return upload, nil
 }
 
 func (t *EventListener) setCommentAttachments(comment *entity.Comment, update *models.Update) error {
@@ -0,0 +1,79 @@
+package telegram
+
+import (
+	"context"
+	"postic-backend/internal/entity"
+
+	"github.com/go-telegram/bot"
+	"github.com/labstack/gommon/log"
+)
+
+// moderateComment проверяет новый комментарий правилами команды до сохранения.
+// Отложенная проверка отмечается в самом комментарии, остальные действия выполняет applyModeration
+func (t *EventListener) moderateComment(comment *entity.Comment) *entity.ModerationVerdict {
+	return t.holdByVerdict(comment, t.moderator.CheckComment)
+}
+
+// moderateCommentEdit проверяет изменённый текст сохранённого комментария до записи изменений
+func (t *EventListener) moderateCommentEdit(comment *entity.Comment) *entity.ModerationVerdict {
+	return t.holdByVerdict(comment, t.moderator.CheckEditedComment)
+}
+
+func (t *EventListener) holdByVerdict(comment *entity.Comment, check func(*entity.Comment) (*entity.ModerationVerdict, error)) *entity.ModerationVerdict {
+	verdict, err := check(comment)
+	if err != nil {
+		// без правил комментарий всё равно должен дойти до команды
+		log.Errorf("Failed to check comment with moderation rules: %v", err)
+		return nil
+	}
+	if verdict == nil {
+		return nil
+	}
+	switch verdict.Action {
+	case entity.ModerationActionHold:
+		comment.HeldForReview = true
+	}
+	return verdict
+}
+
+// applyModeration выполняет действие над сохранённым комментарием и записывает его в журнал.
+// messageIDs — все сообщения комментария (у медиагруппы их несколько)
+func (t *EventListener) applyModeration(ctx context.Context, chatID int64, messageIDs []int, comment *entity.Comment, verdict *entity.ModerationVerdict) {
+	succeeded := true
+	switch verdict.Action {
+	case entity.ModerationActionTicket:
+		if err := t.moderator.OpenTicket(comment, verdict); err != nil {
+			log.Errorf("Failed to open ticket by moderation rule: %v", err)
+			succeeded = false
+		}
+	case entity.ModerationActionDelete, entity.ModerationActionBan:
+		for _, messageID := range messageIDs {
+			_, err := t.bot.DeleteMessage(ctx, &bot.DeleteMessageParams{
+				ChatID:    chatID,
+				MessageID: messageID,
+			})
+			if err != nil {
+				log.Errorf("Failed to delete message %d by moderation rule: %v", messageID, err)
+				succeeded = false
+			}
+		}
+		if err := t.commentRepo.DeleteComment(comment.ID); err != nil {
+			log.Errorf("Failed to mark comment as deleted: %v", err)
+		}
+		if verdict.Action == entity.ModerationActionBan {
+			_, err := t.bot.BanChatMember(ctx, &bot.BanChatMemberParams{
+				ChatID: chatID,
+				UserID: int64(comment.UserPlatformID),
+			})
+			if err != nil {
+				log.Errorf("Failed to ban user %d by moderation rule: %v", comment.UserPlatformID, err)
+				succeeded = false
+			} else if err := t.moderator.RecordBan(comment, verdict); err != nil {
+				log.Errorf("Failed to record ban by moderation rule: %v", err)
+			}
+		}
+	}
+	if err := t.moderator.LogAction(comment, verdict, succeeded); err != nil {
+		log.Errorf("Failed to log moderation action: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"postic-backend/internal/entity"
 	"postic-backend/internal/repo"
 	"postic-backend/internal/usecase"
+	"postic-backend/internal/usecase/service"
 	"postic-backend/pkg/retry"
 	"time"
 
@@ -11,11 +12,19 @@ import (
 	"github.com/labstack/gommon/log"
 )
 
+const (
+	// Ограничения Telegram Bot API на загружаемые файлы
+	maxPhotoSize   = 10 * 1024 * 1024
+	maxPhotoDimSum = 10000
+	maxVideoSize   = 50 * 1024 * 1024
+)
+
 type Post struct {
 	bot           *tgbotapi.BotAPI
 	postRepo      repo.Post
 	teamRepo      repo.Team
 	uploadUseCase usecase.Upload
+	teamEventRepo repo.TeamEventRepository
 }
 
 func NewTelegramPost(
@@ -23,12 +32,14 @@ func NewTelegramPost(
 	postRepo repo.Post,
 	teamRepo repo.Team,
 	uploadUseCase usecase.Upload,
+	teamEventRepo repo.TeamEventRepository,
 ) usecase.PostPlatform {
 	return &Post{
 		bot:           bot,
 		postRepo:      postRepo,
 		teamRepo:      teamRepo,
 		uploadUseCase: uploadUseCase,
+		teamEventRepo: teamEventRepo,
 	}
 }
 
@@ -51,8 +62,10 @@ func (p *Post) createPostAction(request *entity.PostUnion) (int, error) {
 func (p *Post) updatePostActionStatus(actionId int, status, errMsg string) {
 	// Иногда могут возникать ошибки, но они не должны прерывать выполнение ввиду асинхронности бизнес-логики.
 	// Поэтому экспоненциально делаем ретраи и логируем ошибки
+	var action *entity.PostAction
 	err := retry.Retry(func() error {
-		action, err := p.postRepo.GetPostAction(actionId)
+		var err error
+		action, err = p.postRepo.GetPostAction(actionId)
 		if err != nil {
 			log.Errorf("error getting post action: %v", err)
 			return err
@@ -69,7 +82,9 @@ func (p *Post) updatePostActionStatus(actionId int, status, errMsg string) {
 	})
 	if err != nil {
 		log.Errorf("error while updating post action status: %v", err)
+		return
 	}
+	service.PublishPostActionStatus(p.postRepo, p.teamEventRepo, action, status, errMsg)
 }
 
 func (p *Post) publishPost(request *entity.PostUnion, actionId int) {
@@ -135,7 +150,7 @@ func (p *Post) handleNoAttachments(request *entity.PostUnion, actionId int, tgCh
 
 func (p *Post) handleSingleAttachment(request *entity.PostUnion, actionId int, tgChannel *entity.TGChannel) {
 	attachment := request.Attachments[0]
-	upload, err := p.uploadUseCase.GetUpload(attachment.ID)
+	upload, err := p.getPlatformUpload(attachment.ID)
 	if err != nil {
 		p.updatePostActionStatus(actionId, "error", err.Error())
 		return
@@ -152,7 +167,7 @@ func (p *Post) handleSingleAttachment(request *entity.PostUnion, actionId int, t
 func (p *Post) handleMultipleAttachments(request *entity.PostUnion, actionId int, tgChannel *entity.TGChannel) {
 	var mediaGroup []any
 	for i, attachment := range request.Attachments {
-		upload, err := p.uploadUseCase.GetUpload(attachment.ID)
+		upload, err := p.getPlatformUpload(attachment.ID)
 		if err != nil {
 			p.updatePostActionStatus(actionId, "error", err.Error())
 			return
@@ -222,6 +237,31 @@ func (p *Post) handleMultipleAttachments(request *entity.PostUnion, actionId int
 	p.updatePostActionStatus(actionId, "success", "")
 }
 
+// getPlatformUpload возвращает файл вложения, подменяя его производной версией,
+// которая укладывается в ограничения Telegram (без EXIF для фото, H.264 до 50 МБ для видео)
+func (p *Post) getPlatformUpload(id int) (*entity.Upload, error) {
+	upload, err := p.uploadUseCase.GetUpload(id)
+	if err != nil {
+		return nil, err
+	}
+	var rendition *entity.UploadRendition
+	switch upload.FileType {
+	case "photo":
+		rendition = upload.PickRendition(maxPhotoSize, maxPhotoDimSum, entity.RenditionJPEG2560, entity.RenditionJPEG1280)
+	case "video":
+		rendition = upload.PickRendition(maxVideoSize, 0, entity.RenditionMP4720)
+	}
+	if rendition == nil {
+		return upload, nil
+	}
+	renditionUpload, err := p.uploadUseCase.GetUpload(rendition.MediaFileID)
+	if err != nil {
+		log.Errorf("error getting rendition %s of upload %d, using original: %v", rendition.Kind, id, err)
+		return upload, nil
+	}
+	return renditionUpload, nil
+}
+
 func (p *Post) sendPhoto(request *entity.PostUnion, actionId int, tgChannel *entity.TGChannel, upload *entity.Upload) {
 	req := tgbotapi.NewPhoto(int64(tgChannel.ChannelID), tgbotapi.FileReader{
 		Name:   upload.FilePath,
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"postic-backend/internal/usecase"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ticketTitleLength сколько символов текста комментария попадает в название тикета по умолчанию
+const ticketTitleLength = 100
+
+// ticketTitle возвращает название тикета по первой строке текста комментария
+func ticketTitle(comment *entity.Comment) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(comment.Text), "\n")
+	if runes := []rune(title); len(runes) > ticketTitleLength {
+		title = string(runes[:ticketTitleLength]) + "…"
+	}
+	if title == "" {
+		return fmt.Sprintf("Комментарий #%d", comment.ID)
+	}
+	return title
+}
+
+// newCommentTicket создаёт тикет по комментарию. userID равен nil, если тикет открывается автоматически
+func newCommentTicket(ticketRepo repo.Ticket, teamEventRepo repo.TeamEventRepository, comment *entity.Comment, userID *int, title string, priority entity.TicketPriority, assigneeID *int) (int, error) {
+	if title == "" {
+		title = ticketTitle(comment)
+	}
+	if priority == "" {
+		priority = entity.TicketPriorityNormal
+	}
+	now := time.Now()
+	ticketID, err := ticketRepo.AddTicket(&entity.Ticket{
+		TeamID:          comment.TeamID,
+		SourceCommentID: &comment.ID,
+		Title:           title,
+		Status:          entity.TicketOpen,
+		Priority:        priority,
+		AssigneeID:      assigneeID,
+		CreatedBy:       userID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+	if err != nil {
+		return 0, err
+	}
+	publishTeamEvent(teamEventRepo, &entity.TeamEvent{
+		TeamID:     comment.TeamID,
+		Type:       entity.TeamTicketCreated,
+		TicketID:   ticketID,
+		CommentID:  comment.ID,
+		OccurredAt: now,
+	})
+	return ticketID, nil
+}
+
+// publishTicketUpdated сообщает команде об изменении тикета
+func publishTicketUpdated(teamEventRepo repo.TeamEventRepository, teamID, ticketID int) {
+	publishTeamEvent(teamEventRepo, &entity.TeamEvent{
+		TeamID:     teamID,
+		Type:       entity.TeamTicketUpdated,
+		TicketID:   ticketID,
+		OccurredAt: time.Now(),
+	})
+}
+
+type Ticket struct {
+	ticketRepo    repo.Ticket
+	commentRepo   repo.Comment
+	teamRepo      repo.Team
+	teamEventRepo repo.TeamEventRepository
+}
+
+func NewTicket(ticketRepo repo.Ticket, commentRepo repo.Comment, teamRepo repo.Team, teamEventRepo repo.TeamEventRepository) usecase.Ticket {
+	return &Ticket{
+		ticketRepo:    ticketRepo,
+		commentRepo:   commentRepo,
+		teamRepo:      teamRepo,
+		teamEventRepo: teamEventRepo,
+	}
+}
+
+// checkRoles проверяет, что пользователь может работать с комментариями команды
+func (t *Ticket) checkRoles(teamID, userID int) error {
+	roles, err := t.teamRepo.GetTeamUserRoles(teamID, userID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(roles, repo.AdminRole) && !slices.Contains(roles, repo.CommentsRole) {
+		return usecase.ErrUserForbidden
+	}
+	return nil
+}
+
+// checkAssignee проверяет, что исполнитель состоит в команде
+func (t *Ticket) checkAssignee(teamID, assigneeID int) error {
+	roles, err := t.teamRepo.GetTeamUserRoles(teamID, assigneeID)
+	if err != nil {
+		return err
+	}
+	if len(roles) == 0 {
+		return usecase.ErrTicketAssigneeNotInTeam
+	}
+	return nil
+}
+
+// getTeamTicket возвращает тикет, только если он принадлежит команде
+func (t *Ticket) getTeamTicket(teamID, ticketID int) (*entity.Ticket, error) {
+	ticket, err := t.ticketRepo.GetTicket(ticketID)
+	switch {
+	case errors.Is(err, repo.ErrTicketNotFound):
+		return nil, usecase.ErrTicketNotFound
+	case err != nil:
+		return nil, err
+	}
+	if ticket.TeamID != teamID {
+		return nil, usecase.ErrTicketNotFound
+	}
+	return ticket, nil
+}
+
+// getTeamComment возвращает комментарий, только если он принадлежит команде
+func (t *Ticket) getTeamComment(teamID, commentID int) (*entity.Comment, error) {
+	comment, err := t.commentRepo.GetComment(commentID)
+	switch {
+	case errors.Is(err, repo.ErrCommentNotFound):
+		return nil, usecase.ErrCommentNotFound
+	case err != nil:
+		return nil, err
+	}
+	if comment.TeamID != teamID {
+		return nil, usecase.ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+func (t *Ticket) GetTickets(request *entity.GetTicketsRequest) ([]*entity.Ticket, error) {
+	if err := t.checkRoles(request.TeamID, request.UserID); err != nil {
+		return nil, err
+	}
+	if request.Offset.IsZero() {
+		request.Offset = time.Now()
+	}
+	if request.Limit <= 0 || request.Limit > 100 {
+		request.Limit = 100
+	}
+	return t.ticketRepo.GetTickets(request)
+}
+
+func (t *Ticket) GetTicket(request *entity.GetTicketRequest) (*entity.Ticket, error) {
+	if err := t.checkRoles(request.TeamID, request.UserID); err != nil {
+		return nil, err
+	}
+	ticket, err := t.getTeamTicket(request.TeamID, request.TicketID)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket.Comments = make([]*entity.Comment, 0, len(ticket.CommentIDs))
+	for _, commentID := range ticket.CommentIDs {
+		comment, err := t.commentRepo.GetComment(commentID)
+		if errors.Is(err, repo.ErrCommentNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ticket.Comments = append(ticket.Comments, comment)
+	}
+	ticket.Notes, err = t.ticketRepo.GetTicketNotes(ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+func (t *Ticket) CreateTicket(request *entity.CreateTicketRequest) (int, error) {
+	if err := t.checkRoles(request.TeamID, request.UserID); err != nil {
+		return 0, err
+	}
+	comment, err := t.getTeamComment(request.TeamID, request.CommentID)
+	if err != nil {
+		return 0, err
+	}
+	if request.AssigneeID != nil {
+		if err := t.checkAssignee(request.TeamID, *request.AssigneeID); err != nil {
+			return 0, err
+		}
+	}
+
+	ticketID, err := newCommentTicket(t.ticketRepo, t.teamEventRepo, comment, &request.UserID, request.Title, request.Priority, request.AssigneeID)
+	if errors.Is(err, repo.ErrTicketCommentLinked) {
+		return 0, usecase.ErrTicketCommentLinked
+	}
+	return ticketID, err
+}
+
+func (t *Ticket) UpdateTicket(request *entity.UpdateTicketRequest) error {
+	if err := t.checkRoles(request.TeamID, request.UserID); err != nil {
+		return err
+	}
+	ticket, err := t.getTeamTicket(request.TeamID, request.TicketID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if request.Title != nil {
+		ticket.Title = *request.Title
+	}
+	if request.Priority != nil {
+		ticket.Priority = *request.Priority
+	}
+	if request.AssigneeID != nil {
+		if *request.AssigneeID == 0 {
+			ticket.AssigneeID = nil
+		} else {
+			if err := t.checkAssignee(request.TeamID, *request.AssigneeID); err != nil {
+				return err
+			}
+			ticket.AssigneeID = request.AssigneeID
+		}
+	}
+	if request.Status != nil && *request.Status != ticket.Status {
+		ticket.Status = *request.Status
+		if ticket.Status == entity.TicketResolved {
+			ticket.ResolvedAt = &now
+		} else {
+			// тикет переоткрыт
+			ticket.ResolvedAt = nil
+		}
+	}
+	ticket.UpdatedAt = now
+
+	err = t.ticketRepo.EditTicket(ticket)
+	if errors.Is(err, repo.ErrTicketNotFound) {
+		return usecase.ErrTicketNotFound
+	}
+	if err != nil {
+		return err
+	}
+	publishTicketUpdated(t.teamEventRepo, request.TeamID, ticket.ID)
+	return nil
+}
+
+func (t *Ticket) AddNote(request *entity.AddTicketNoteRequest) (int, error) {
+	if err := t.checkRoles(request.TeamID, request.UserID); err != nil {
+		return 0, err
+	}
+	if _, err := t.getTeamTicket(request.TeamID, request.TicketID); err != nil {
+		return 0, err
+	}
+	noteID, err := t.ticketRepo.AddTicketNote(&entity.TicketNote{
+		TicketID:  request.TicketID,
+		UserID:    &request.UserID,
+		Text:      request.Text,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	publishTicketUpdated(t.teamEventRepo, request.TeamID, request.TicketID)
+	return noteID, nil
+}
+
+func (t *Ticket) LinkComment(request *entity.TicketCommentRequest) error {
+	if err := t.checkRoles(request.TeamID, request.UserID); err != nil {
+		return err
+	}
+	if _, err := t.getTeamTicket(request.TeamID, request.TicketID); err != nil {
+		return err
+	}
+	if _, err := t.getTeamComment(request.TeamID, request.CommentID); err != nil {
+		return err
+	}
+	err := t.ticketRepo.LinkComment(request.TicketID, request.CommentID)
+	if errors.Is(err, repo.ErrTicketCommentLinked) {
+		return usecase.ErrTicketCommentLinked
+	}
+	if err != nil {
+		return err
+	}
+	publishTicketUpdated(t.teamEventRepo, request.TeamID, request.TicketID)
+	return nil
+}
+
+func (t *Ticket) UnlinkComment(request *entity.TicketCommentRequest) error {
+	if err := t.checkRoles(request.TeamID, request.UserID); err != nil {
+		return err
+	}
+	if _, err := t.getTeamTicket(request.TeamID, request.TicketID); err != nil {
+		return err
+	}
+	err := t.ticketRepo.UnlinkComment(request.TicketID, request.CommentID)
+	if errors.Is(err, repo.ErrTicketCommentNotLinked) {
+		return usecase.ErrTicketCommentNotLinked
+	}
+	if err != nil {
+		return err
+	}
+	publishTicketUpdated(t.teamEventRepo, request.TeamID, request.TicketID)
+	return nil
+}
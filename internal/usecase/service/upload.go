@@ -1,74 +1,109 @@
 package service
 
 import (
+	"bufio"
 	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/gommon/log"
 
 	uploadgrpc "postic-backend/internal/delivery/grpc/upload-service"
+	uploadpb "postic-backend/internal/delivery/grpc/upload-service/proto"
 	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
 	"postic-backend/internal/usecase"
+	"postic-backend/pkg/media"
 	"strings"
 )
 
 type Upload struct {
-	uploadClient *uploadgrpc.Client
+	uploadClient     *uploadgrpc.Client
+	storageQuotaRepo repo.StorageQuota
 }
 
-func NewUpload(uploadClient *uploadgrpc.Client) usecase.Upload {
+func NewUpload(uploadClient *uploadgrpc.Client, storageQuotaRepo repo.StorageQuota) usecase.Upload {
 	return &Upload{
-		uploadClient: uploadClient,
+		uploadClient:     uploadClient,
+		storageQuotaRepo: storageQuotaRepo,
 	}
 }
 
 func (u *Upload) UploadFile(upload *entity.Upload) (int, error) {
 	log.Infof("upload file %s, type %s, userID %d", upload.FilePath, upload.FileType, derefInt(upload.UserID))
-	// Проверка типа файла по расширению
-	fileExt := strings.ToLower(upload.FilePath[strings.LastIndex(upload.FilePath, ".")+1:])
+	fileExt, err := validateExtension(upload.FileType, upload.FilePath)
+	if err != nil {
+		return 0, err
+	}
+
+	// Проверка MIME-типа на основе содержимого
+	if err := validateMimeType(upload); err != nil {
+		return 0, err
+	}
+
+	size, err := readerSize(upload.RawBytes)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка чтения файла: %v", err)
+	}
+	if err := u.checkQuota(upload.TeamID, upload.FileType, size); err != nil {
+		return 0, err
+	}
+
+	if upload.DisplayName == "" {
+		upload.DisplayName = upload.FilePath
+	}
+
+	upload.FilePath = storageFileName(upload.FilePath, fileExt)
+	resp, err := u.uploadClient.UploadFile(context.Background(), &uploadpb.UploadFileChunk{
+		FileName:    upload.FilePath,
+		FileType:    upload.FileType,
+		UserId:      int32(derefInt(upload.UserID)),
+		TeamId:      int32(derefInt(upload.TeamID)),
+		DisplayName: upload.DisplayName,
+	}, upload.RawBytes)
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.Id), nil
+}
+
+// validateExtension проверяет, что расширение файла соответствует заявленному типу, и возвращает расширение
+func validateExtension(fileType, filePath string) (string, error) {
+	fileExt := strings.ToLower(filePath[strings.LastIndex(filePath, ".")+1:])
 
 	// Проверяем соответствие типа файла и расширения
-	switch upload.FileType {
+	switch fileType {
 	case "photo":
 		if fileExt != "jpg" && fileExt != "jpeg" && fileExt != "png" {
-			return 0, errors.New("неподдерживаемое расширение фото: допустимы только jpg, jpeg, png")
+			return "", errors.New("неподдерживаемое расширение фото: допустимы только jpg, jpeg, png")
 		}
 	case "video":
 		if fileExt != "mp4" {
-			return 0, errors.New("неподдерживаемое расширение видео: допустимо только mp4")
+			return "", errors.New("неподдерживаемое расширение видео: допустимо только mp4")
 		}
 	case "sticker":
 		if fileExt != "png" && fileExt != "webp" && fileExt != "webm" && fileExt != "jpg" && fileExt != "jpeg" && fileExt != "tgs" && fileExt != "json" {
-			return 0, errors.New("неподдерживаемое расширение стикера: допустимы только png, webp, jpg, jpeg, tgs, json")
+			return "", errors.New("неподдерживаемое расширение стикера: допустимы только png, webp, jpg, jpeg, tgs, json")
 		}
 	default:
-		return 0, fmt.Errorf("неподдерживаемый тип файла %s: допустимы только photo, video и sticker", upload.FileType)
-	}
-
-	// Проверка MIME-типа на основе содержимого
-	if err := validateMimeType(upload); err != nil {
-		return 0, err
+		return "", fmt.Errorf("неподдерживаемый тип файла %s: допустимы только photo, video и sticker", fileType)
 	}
+	return fileExt, nil
+}
 
-	// переводим название файла в base64 (без учета расширения файла) и добавляем к нему префикс uuid,
-	// чтобы избежать проблем с юникодом
-	upload.FilePath = fmt.Sprintf(
+// storageFileName переводит название файла в base64 (без учета расширения файла) и добавляет к нему префикс uuid,
+// чтобы избежать проблем с юникодом
+func storageFileName(name, ext string) string {
+	return fmt.Sprintf(
 		"%s_%s.%s",
 		uuid.New().String(),
-		base64.StdEncoding.EncodeToString([]byte(upload.FilePath)),
-		fileExt,
+		base64.StdEncoding.EncodeToString([]byte(name)),
+		ext,
 	)
-	resp, err := u.uploadClient.UploadFile(context.Background(), upload.FilePath, upload.FileType, derefInt(upload.UserID), upload.RawBytes)
-	if err != nil {
-		return 0, err
-	}
-	return int(resp.Id), nil
 }
 
 // validateMimeType проверяет MIME-тип файла на основе его содержимого
@@ -89,24 +124,7 @@ func validateMimeType(upload *entity.Upload) error {
 	if err != nil && err != io.EOF {
 		return fmt.Errorf("ошибка чтения файла: %v", err)
 	}
-	buffer = buffer[:n]
-
-	// Определяем MIME-тип файла
-	mimeType := http.DetectContentType(buffer)
-
-	// Проверяем соответствие MIME-типа заявленному типу файла
-	switch upload.FileType {
-	case "photo":
-		if !strings.HasPrefix(mimeType, "image/jpeg") && !strings.HasPrefix(mimeType, "image/png") && !strings.HasPrefix(mimeType, "image/webp") {
-			return fmt.Errorf("содержимое не соответствует формату фото: обнаружен MIME-тип %s", mimeType)
-		}
-	case "video":
-		if !strings.HasPrefix(mimeType, "video/mp4") {
-			return fmt.Errorf("содержимое не соответствует формату видео: обнаружен MIME-тип %s", mimeType)
-		}
-	}
-
-	return nil
+	return media.CheckContentType(upload.FileType, buffer[:n])
 }
 
 func (u *Upload) GetUpload(id int) (*entity.Upload, error) {
@@ -117,15 +135,30 @@ func (u *Upload) GetUpload(id int) (*entity.Upload, error) {
 	// Получаем размер файла
 	size := info.Size
 	reader := uploadgrpc.NewRemoteReadSeeker(u.uploadClient, context.Background(), int64(id), size)
-	return &entity.Upload{
-		ID:        int(info.Id),
-		FilePath:  info.FilePath,
-		FileType:  info.FileType,
-		UserID:    intPtr(int(info.UserId)),
-		CreatedAt: parseTime(info.CreatedAt),
-		RawBytes:  reader,
-		Size:      size,
-	}, nil
+	upload := &entity.Upload{
+		ID:         int(info.Id),
+		FilePath:   info.FilePath,
+		FileType:   info.FileType,
+		UserID:     intPtr(int(info.UserId)),
+		TeamID:     optionalInt(int(info.TeamId)),
+		CreatedAt:  parseTime(info.CreatedAt),
+		RawBytes:   reader,
+		Size:       size,
+		Width:      int(info.Width),
+		Height:     int(info.Height),
+		DurationMs: int(info.DurationMs),
+	}
+	for _, rendition := range info.Renditions {
+		upload.Renditions = append(upload.Renditions, &entity.UploadRendition{
+			MediaFileID: int(rendition.Id),
+			Kind:        rendition.Kind,
+			MimeType:    rendition.MimeType,
+			Width:       int(rendition.Width),
+			Height:      int(rendition.Height),
+			Size:        rendition.Size,
+		})
+	}
+	return upload, nil
 }
 
 func derefInt(ptr *int) int {
@@ -139,7 +172,188 @@ func intPtr(i int) *int {
 	return &i
 }
 
+// optionalInt возвращает nil для нулевого значения, которым gRPC передаёт отсутствие идентификатора
+func optionalInt(i int) *int {
+	if i == 0 {
+		return nil
+	}
+	return &i
+}
+
 func parseTime(s string) time.Time {
 	t, _ := time.Parse("2006-01-02T15:04:05Z07:00", s)
 	return t
 }
+
+func (u *Upload) DeleteUpload(id int) error {
+	_, err := u.uploadClient.DeleteUpload(context.Background(), int64(id))
+	return err
+}
+
+func (u *Upload) CreateUploadSession(session *entity.UploadSession) (*entity.UploadSession, error) {
+	req, err := u.uploadSessionRequest(session)
+	if err != nil {
+		return nil, err
+	}
+	info, err := u.uploadClient.CreateUploadSession(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	return uploadSessionFromInfo(info), nil
+}
+
+// uploadSessionRequest проверяет параметры и квоты новой сессии и готовит запрос к upload-service
+func (u *Upload) uploadSessionRequest(session *entity.UploadSession) (*uploadpb.CreateUploadSessionRequest, error) {
+	fileExt, err := validateExtension(session.FileType, session.FileName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", usecase.ErrInvalidUpload, err)
+	}
+	if session.Size <= 0 {
+		return nil, fmt.Errorf("%w: размер файла должен быть больше нуля", usecase.ErrInvalidUpload)
+	}
+	if session.Size > usecase.MaxUploadSessionSize {
+		return nil, usecase.ErrUploadTooLarge
+	}
+	if err := u.checkQuota(&session.TeamID, session.FileType, session.Size); err != nil {
+		return nil, err
+	}
+	if session.DisplayName == "" {
+		session.DisplayName = session.FileName
+	}
+	return &uploadpb.CreateUploadSessionRequest{
+		UserId:      int32(session.UserID),
+		TeamId:      int32(session.TeamID),
+		FileName:    storageFileName(session.FileName, fileExt),
+		FileType:    session.FileType,
+		DisplayName: session.DisplayName,
+		Size:        session.Size,
+	}, nil
+}
+
+func (u *Upload) CreatePresignedUpload(session *entity.UploadSession) (*entity.UploadSession, *entity.PresignedURL, error) {
+	req, err := u.uploadSessionRequest(session)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := u.uploadClient.CreatePresignedUpload(context.Background(), req)
+	if err != nil {
+		return nil, nil, uploadSessionError(err)
+	}
+	return uploadSessionFromInfo(resp.Session), presignedURLFromProto(resp.UploadUrl), nil
+}
+
+func (u *Upload) FinalizePresignedUpload(id int) (*entity.UploadSession, error) {
+	if _, err := u.GetUploadSession(id); err != nil {
+		return nil, err
+	}
+	info, err := u.uploadClient.FinalizePresignedUpload(context.Background(), int64(id))
+	if err != nil {
+		return nil, uploadSessionError(err)
+	}
+	return uploadSessionFromInfo(info), nil
+}
+
+func (u *Upload) PresignDownload(id int) (*entity.PresignedURL, error) {
+	resp, err := u.uploadClient.PresignDownload(context.Background(), int64(id), 0)
+	if err != nil {
+		return nil, uploadSessionError(err)
+	}
+	return presignedURLFromProto(resp), nil
+}
+
+func presignedURLFromProto(link *uploadpb.PresignedURL) *entity.PresignedURL {
+	return &entity.PresignedURL{
+		URL:       link.Url,
+		ExpiresAt: parseTime(link.ExpiresAt),
+	}
+}
+
+func (u *Upload) GetUploadSession(id int) (*entity.UploadSession, error) {
+	info, err := u.uploadClient.GetUploadSession(context.Background(), int64(id))
+	if err != nil {
+		return nil, uploadSessionError(err)
+	}
+	session := uploadSessionFromInfo(info)
+	// Просроченная сессия может ещё не быть удалена фоновой очисткой
+	if session.ExpiresAt.Before(time.Now()) {
+		return nil, usecase.ErrUploadSessionNotFound
+	}
+	return session, nil
+}
+
+func (u *Upload) WriteUploadSession(id int, offset int64, r io.Reader) (*entity.UploadSession, error) {
+	session, err := u.GetUploadSession(id)
+	if err != nil {
+		return nil, err
+	}
+	if session.MediaFileID != nil {
+		// файл уже собран, повторная отправка ничего не меняет
+		return session, nil
+	}
+	if offset != session.Offset {
+		return nil, usecase.ErrUploadSessionOffsetMismatch
+	}
+	// Ограничиваем чтение оставшимся размером, лишние байты сессия всё равно не примет
+	r = io.LimitReader(r, session.Size-offset)
+
+	if offset == 0 {
+		// Содержимое проверяется по первой части, дальше файл собирается без повторной проверки
+		br := bufio.NewReaderSize(r, 512)
+		header, err := br.Peek(512)
+		if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, fmt.Errorf("ошибка чтения файла: %v", err)
+		}
+		if err := media.CheckContentType(session.FileType, header); err != nil {
+			return nil, fmt.Errorf("%w: %v", usecase.ErrInvalidUpload, err)
+		}
+		r = br
+	}
+
+	// Запрос не привязан к контексту клиента: при обрыве соединения полученные байты должны сохраниться
+	_, err = u.uploadClient.UploadSessionChunk(context.Background(), int64(id), offset, r)
+	if err != nil {
+		return nil, uploadSessionError(err)
+	}
+	return u.GetUploadSession(id)
+}
+
+func (u *Upload) DeleteUploadSession(id int) error {
+	if _, err := u.GetUploadSession(id); err != nil {
+		return err
+	}
+	return u.uploadClient.DeleteUploadSession(context.Background(), int64(id))
+}
+
+// uploadSessionError переводит ошибки репозитория сессий в ошибки юзкейса
+func uploadSessionError(err error) error {
+	switch {
+	case errors.Is(err, repo.ErrUploadSessionNotFound):
+		return usecase.ErrUploadSessionNotFound
+	case errors.Is(err, repo.ErrUploadSessionOffsetMismatch):
+		return usecase.ErrUploadSessionOffsetMismatch
+	case errors.Is(err, repo.ErrInvalidUploadContent):
+		// в ответ пользователю попадает только пояснение upload-service
+		detail := strings.TrimPrefix(err.Error(), repo.ErrInvalidUploadContent.Error()+": ")
+		return fmt.Errorf("%w: %s", usecase.ErrInvalidUpload, detail)
+	case errors.Is(err, repo.ErrPresignNotSupported):
+		return usecase.ErrPresignNotSupported
+	case errors.Is(err, repo.ErrMediaFileNotFound):
+		return usecase.ErrMediaFileNotFound
+	}
+	return err
+}
+
+func uploadSessionFromInfo(info *uploadpb.UploadSessionInfo) *entity.UploadSession {
+	return &entity.UploadSession{
+		ID:          int(info.Id),
+		UserID:      int(info.UserId),
+		TeamID:      int(info.TeamId),
+		FileType:    info.FileType,
+		DisplayName: info.DisplayName,
+		Size:        info.Size,
+		Offset:      info.Offset,
+		MediaFileID: optionalInt(int(info.MediafileId)),
+		ExpiresAt:   parseTime(info.ExpiresAt),
+		Presigned:   info.Presigned,
+	}
+}
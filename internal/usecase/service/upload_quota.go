@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"postic-backend/internal/usecase"
+)
+
+const (
+	// Квоты для команд без индивидуальных ограничений
+	defaultTeamStorageBytes int64 = 10 * 1024 * 1024 * 1024
+	defaultTeamFiles              = 10000
+)
+
+// maxFileSizeByType ограничения размера одного файла по типу
+var maxFileSizeByType = map[string]int64{
+	"photo":   20 * 1024 * 1024,
+	"video":   usecase.MaxUploadSessionSize,
+	"sticker": 5 * 1024 * 1024,
+}
+
+func (u *Upload) GetTeamStorageUsage(teamID int) (*entity.TeamStorageUsage, error) {
+	usage, err := u.storageQuotaRepo.GetTeamStorageUsage(teamID)
+	if err != nil {
+		return nil, err
+	}
+	usage.Quota, err = u.storageQuotaRepo.GetTeamStorageQuota(teamID)
+	if errors.Is(err, repo.ErrStorageQuotaNotFound) {
+		usage.Quota = &entity.TeamStorageQuota{
+			TeamID:          teamID,
+			MaxStorageBytes: defaultTeamStorageBytes,
+			MaxFiles:        defaultTeamFiles,
+		}
+		err = nil
+	}
+	return usage, err
+}
+
+// checkQuota проверяет ограничение размера файла по типу и, если файл загружается в команду, её квоты
+func (u *Upload) checkQuota(teamID *int, fileType string, size int64) error {
+	if maxSize, ok := maxFileSizeByType[fileType]; ok && size > maxSize {
+		return fmt.Errorf("%w: максимальный размер для %s - %d МБ", usecase.ErrUploadTooLarge, fileType, maxSize/1024/1024)
+	}
+	if teamID == nil {
+		// файлы из комментариев и аватары сохраняются без команды и не ограничиваются
+		return nil
+	}
+	usage, err := u.GetTeamStorageUsage(*teamID)
+	if err != nil {
+		return err
+	}
+	// вложения комментариев и аватары сохраняются автоматически, поэтому в квоте считаются только файлы команды
+	if usage.Counted.Files+1 > usage.Quota.MaxFiles {
+		return fmt.Errorf("%w: достигнут лимит в %d файлов", usecase.ErrStorageQuotaExceeded, usage.Quota.MaxFiles)
+	}
+	if usage.Counted.Bytes+size > usage.Quota.MaxStorageBytes {
+		return fmt.Errorf(
+			"%w: занято %d МБ из %d МБ, файл занимает %d МБ",
+			usecase.ErrStorageQuotaExceeded,
+			usage.Counted.Bytes/1024/1024,
+			usage.Quota.MaxStorageBytes/1024/1024,
+			(size+1024*1024-1)/1024/1024,
+		)
+	}
+	return nil
+}
+
+// readerSize возвращает размер содержимого, не сдвигая текущую позицию
+func readerSize(r io.ReadSeeker) (int64, error) {
+	current, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	_, err = r.Seek(current, io.SeekStart)
+	return size, err
+}
@@ -156,6 +156,8 @@ func (c *Comment) ReplyComment(request *entity.ReplyCommentRequest) (int, error)
 		Username:          "",
 		Text:              request.Text,
 		IsTeamReply:       true,
+		ReplySource:       request.Source(),
+		FAQRuleID:         request.FAQRuleID,
 		ReplyToCommentID:  request.CommentID,
 		CreatedAt:         time.Now(),
 		Attachments:       commentAttachments, // Передаем вложения для сохранения в БД
@@ -231,6 +233,160 @@ func (c *Comment) DeleteComment(request *entity.DeleteCommentRequest) error {
 	return nil
 }
 
+// EditComment меняет текст ответа команды. wall.editComment заменяет и вложения,
+// поэтому текущие вложения комментария передаются заново
+func (c *Comment) EditComment(request *entity.EditCommentRequest) error {
+	comment, err := c.commentRepo.GetComment(request.CommentID)
+	if err != nil {
+		return err
+	}
+
+	vkChannel, err := c.teamRepo.GetVKCredsByTeamID(request.TeamID)
+	if err != nil {
+		return err
+	}
+
+	vk := api.NewVK(vkChannel.AdminAPIKey)
+
+	var current api.WallGetCommentResponse
+	err = retry.Retry(func() error {
+		var err error
+		current, err = vk.WallGetComment(api.Params{
+			"owner_id":   -vkChannel.GroupID,
+			"comment_id": comment.CommentPlatformID,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get VK comment: %w", err)
+	}
+
+	params := api.Params{
+		"owner_id":   -vkChannel.GroupID,
+		"comment_id": comment.CommentPlatformID,
+		"message":    request.Text,
+	}
+	attachments := make([]string, 0)
+	for _, item := range current.Items {
+		for _, attachment := range item.Attachments {
+			switch attachment.Type {
+			case "photo":
+				attachments = append(attachments, attachment.Photo.ToAttachment())
+			case "video":
+				attachments = append(attachments, attachment.Video.ToAttachment())
+			case "audio":
+				attachments = append(attachments, attachment.Audio.ToAttachment())
+			case "doc":
+				attachments = append(attachments, attachment.Doc.ToAttachment())
+			}
+		}
+	}
+	if len(attachments) > 0 {
+		params["attachments"] = strings.Join(attachments, ",")
+	}
+
+	err = retry.Retry(func() error {
+		_, err := vk.WallEditComment(params)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to edit VK comment: %w", err)
+	}
+
+	now := time.Now()
+	if err := c.commentRepo.EditCommentText(comment.ID, request.Text, &request.UserID, now); err != nil {
+		return err
+	}
+
+	// Публикуем событие об изменении комментария в Kafka
+	event := &entity.CommentEvent{
+		EventID:    fmt.Sprintf("vk-edit-%d-%d-%d", comment.TeamID, comment.ID, now.UnixNano()),
+		TeamID:     comment.TeamID,
+		PostID:     derefInt(comment.PostUnionID),
+		Type:       entity.CommentEdited,
+		CommentID:  comment.ID,
+		OccurredAt: now,
+	}
+	if err := c.eventRepo.PublishCommentEvent(context.Background(), event); err != nil {
+		log.Errorf("Не удалось опубликовать событие об изменении комментария в Kafka: %v", err)
+	}
+
+	return nil
+}
+
+// ReactComment ставит или снимает лайк. Лайк ставится от имени владельца ключа администратора
+func (c *Comment) ReactComment(request *entity.ReactCommentRequest) error {
+	comment, err := c.commentRepo.GetComment(request.CommentID)
+	if err != nil {
+		return err
+	}
+
+	vkChannel, err := c.teamRepo.GetVKCredsByTeamID(request.TeamID)
+	if err != nil {
+		return err
+	}
+
+	vk := api.NewVK(vkChannel.AdminAPIKey)
+
+	params := api.Params{
+		"type":     "comment",
+		"owner_id": -vkChannel.GroupID,
+		"item_id":  comment.CommentPlatformID,
+	}
+	err = retry.Retry(func() error {
+		var err error
+		if request.Reaction == "" {
+			_, err = vk.LikesDelete(params)
+		} else {
+			_, err = vk.LikesAdd(params)
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to react to VK comment: %w", err)
+	}
+
+	return c.commentRepo.SetCommentReaction(comment.ID, request.Reaction, &request.UserID, time.Now())
+}
+
+func (c *Comment) BanUser(ban *entity.CommenterBan) error {
+	vkChannel, err := c.teamRepo.GetVKCredsByTeamID(ban.TeamID)
+	if err != nil {
+		return err
+	}
+	vk := api.NewVK(vkChannel.AdminAPIKey)
+
+	params := api.Params{
+		"group_id":        vkChannel.GroupID,
+		"owner_id":        ban.UserPlatformID,
+		"comment":         ban.Reason,
+		"comment_visible": 1, // пользователь увидит причину бана
+	}
+	if ban.ExpiresAt != nil {
+		params["end_date"] = ban.ExpiresAt.Unix()
+	}
+	return retry.Retry(func() error {
+		_, err := vk.GroupsBan(params)
+		return err
+	})
+}
+
+func (c *Comment) UnbanUser(ban *entity.CommenterBan) error {
+	vkChannel, err := c.teamRepo.GetVKCredsByTeamID(ban.TeamID)
+	if err != nil {
+		return err
+	}
+	vk := api.NewVK(vkChannel.AdminAPIKey)
+
+	return retry.Retry(func() error {
+		_, err := vk.GroupsUnban(api.Params{
+			"group_id": vkChannel.GroupID,
+			"owner_id": ban.UserPlatformID,
+		})
+		return err
+	})
+}
+
 func derefInt(ptr *int) int {
 	if ptr != nil {
 		return *ptr
@@ -0,0 +1,99 @@
+package vkontakte
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"postic-backend/internal/entity"
+	"postic-backend/internal/repo"
+	"postic-backend/internal/usecase"
+	"postic-backend/pkg/retry"
+	"strings"
+	"time"
+
+	"github.com/SevereCloud/vksdk/v3/api"
+)
+
+// DirectMessage отправляет сообщения от имени сообщества
+type DirectMessage struct {
+	teamRepo      repo.Team
+	uploadUseCase usecase.Upload
+}
+
+func NewVkontakteDirectMessage(teamRepo repo.Team, uploadUseCase usecase.Upload) *DirectMessage {
+	return &DirectMessage{
+		teamRepo:      teamRepo,
+		uploadUseCase: uploadUseCase,
+	}
+}
+
+func (d *DirectMessage) SendDirectMessage(conversation *entity.DMConversation, request *entity.SendDirectMessageRequest) (int64, error) {
+	vkChannel, err := d.teamRepo.GetVKCredsByTeamID(conversation.TeamID)
+	if err != nil {
+		return 0, err
+	}
+	// писать от имени сообщества можно только ключом сообщества
+	if vkChannel.GroupAPIKey == "" {
+		return 0, usecase.ErrDirectMessageUnavailable
+	}
+	vk := api.NewVK(vkChannel.GroupAPIKey)
+	peerID := int(conversation.PeerID)
+
+	attachments := make([]string, 0, len(request.Attachments))
+	for _, attachmentID := range request.Attachments {
+		upload, err := d.uploadUseCase.GetUpload(attachmentID)
+		if err != nil {
+			return 0, err
+		}
+		attachment, err := d.uploadAttachment(vk, peerID, upload)
+		if err != nil {
+			return 0, fmt.Errorf("failed to upload VK message attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	params := api.Params{
+		"peer_id":   peerID,
+		"random_id": time.Now().UnixNano() & 0x7fffffff, // защищает от повторной отправки при ретраях
+		"message":   request.Text,
+	}
+	if len(attachments) > 0 {
+		params["attachment"] = strings.Join(attachments, ",")
+	}
+	var messageID int
+	err = retry.Retry(func() error {
+		var err error
+		messageID, err = vk.MessagesSend(params)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to send VK message: %w", err)
+	}
+	return int64(messageID), nil
+}
+
+// uploadAttachment загружает файл в переписку: фото — как фото, остальное — как документ
+func (d *DirectMessage) uploadAttachment(vk *api.VK, peerID int, upload *entity.Upload) (string, error) {
+	var attachment string
+	err := retry.Retry(func() error {
+		_, _ = upload.RawBytes.Seek(0, io.SeekStart)
+		if upload.FileType == "photo" {
+			photos, err := vk.UploadMessagesPhoto(peerID, upload.RawBytes)
+			if err != nil {
+				return err
+			}
+			if len(photos) == 0 {
+				return fmt.Errorf("no photos uploaded")
+			}
+			attachment = photos[0].ToAttachment()
+			return nil
+		}
+		doc, err := vk.UploadMessagesDoc(peerID, "doc", path.Base(upload.FilePath), "", upload.RawBytes)
+		if err != nil {
+			return err
+		}
+		attachment = doc.Doc.ToAttachment()
+		return nil
+	})
+	return attachment, err
+}
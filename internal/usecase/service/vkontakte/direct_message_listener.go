@@ -0,0 +1,106 @@
+package vkontakte
+
+import (
+	"context"
+	"fmt"
+	"postic-backend/internal/entity"
+	"strings"
+	"time"
+
+	"github.com/SevereCloud/vksdk/v3/events"
+	"github.com/SevereCloud/vksdk/v3/object"
+	"github.com/labstack/gommon/log"
+)
+
+// messageNewHandler сохраняет входящее сообщение сообщества в личную переписку с командой
+func (e *EventListener) messageNewHandler(ctx context.Context, obj events.MessageNewObject, teamID int) {
+	message := obj.Message
+	// беседы и сообщения от других сообществ в личные переписки не попадают
+	if message.FromID <= 0 || message.PeerID != message.FromID {
+		return
+	}
+
+	userInfo, err := e.getUserInfo(teamID, message.FromID)
+	if err != nil {
+		log.Errorf("Failed to get user info: %v", err)
+		return
+	}
+	conversation := &entity.DMConversation{
+		TeamID:    teamID,
+		Platform:  "vk",
+		PeerID:    int64(message.PeerID),
+		FullName:  userInfo.FullName,
+		Username:  userInfo.Username,
+		CreatedAt: time.Now(),
+	}
+	conversation.AvatarMediaFile, err = e.getUserAvatar(userInfo.Avatar)
+	if err != nil {
+		log.Errorf("Failed to get user avatar: %v", err)
+		// ошибка не фатальна, продолжаем
+	}
+	conversationID, err := e.dmRepo.PutConversation(conversation)
+	if err != nil {
+		log.Errorf("Failed to save direct conversation: %v", err)
+		return
+	}
+
+	directMessage := &entity.DirectMessage{
+		ConversationID:    conversationID,
+		MessagePlatformID: int64(message.ID),
+		Text:              message.Text,
+		CreatedAt:         time.Unix(int64(message.Date), 0),
+	}
+	if attachments := messageAttachments(message.Attachments); len(attachments) > 0 {
+		uploadIDs, videosURL, err := e.processVKAttachments(attachments)
+		if err != nil {
+			log.Errorf("Failed to process attachments: %v", err)
+		} else {
+			if len(videosURL) > 0 {
+				directMessage.Text += "\n📎Пользователь прикрепил видео: " + strings.Join(videosURL, ", ")
+			}
+			for _, uploadID := range uploadIDs {
+				directMessage.Attachments = append(directMessage.Attachments, &entity.Upload{ID: uploadID})
+			}
+		}
+	}
+
+	messageID, created, err := e.dmRepo.AddMessage(directMessage)
+	if err != nil {
+		log.Errorf("Failed to save direct message: %v", err)
+		return
+	}
+	if !created {
+		// повторная доставка события: сообщение уже сохранено и отправлено подписчикам
+		return
+	}
+
+	event := &entity.DirectMessageEvent{
+		EventID:        fmt.Sprintf("vk-dm-%d-%d", teamID, messageID),
+		TeamID:         teamID,
+		Type:           entity.DirectMessageCreated,
+		ConversationID: conversationID,
+		MessageID:      messageID,
+		OccurredAt:     directMessage.CreatedAt,
+	}
+	if err := e.dmEventRepo.PublishDirectMessageEvent(ctx, event); err != nil {
+		log.Errorf("Failed to publish direct message event: %v", err)
+	}
+}
+
+// messageAttachments оставляет вложения сообщения, которые умеет сохранять processVKAttachments
+func messageAttachments(attachments []object.MessagesMessageAttachment) []object.WallCommentAttachment {
+	result := make([]object.WallCommentAttachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		switch attachment.Type {
+		case "photo", "video", "sticker", "doc":
+			result = append(result, object.WallCommentAttachment{
+				Type:    attachment.Type,
+				Photo:   attachment.Photo,
+				Video:   attachment.Video,
+				Sticker: attachment.Sticker,
+				Doc:     attachment.Doc,
+			})
+		}
+	}
+	return result
+}
@@ -35,10 +35,17 @@ type EventListener struct {
 	commentRepo           repo.Comment
 	mu                    sync.Mutex
 	eventRepo             repo.CommentEventRepository // Kafka-репозиторий событий
+	moderator             usecase.CommentModerator
+	autoReplier           usecase.CommentAutoReplier
+	dmRepo                repo.DirectMessage
+	dmEventRepo           repo.DirectMessageEventRepository
 	lpClients             map[int]*longpoll.LongPoll
 	vkClients             map[int]*api.VK
+	commentLocks          map[int]*sync.Mutex // сохранение новых комментариев команды из лонгпола и сверки
+	reconciling           map[int]struct{}    // команды, комментарии которых сейчас сверяются
 	stopCh                chan struct{}
 	ticker                *time.Ticker
+	reconcileTicker       *time.Ticker
 }
 
 func NewVKEventListener(
@@ -48,6 +55,10 @@ func NewVKEventListener(
 	uploadUseCase usecase.Upload,
 	commentRepo repo.Comment,
 	eventRepo repo.CommentEventRepository,
+	moderator usecase.CommentModerator,
+	autoReplier usecase.CommentAutoReplier,
+	dmRepo repo.DirectMessage,
+	dmEventRepo repo.DirectMessageEventRepository,
 ) usecase.Listener {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &EventListener{
@@ -59,8 +70,14 @@ func NewVKEventListener(
 		uploadUseCase:         uploadUseCase,
 		commentRepo:           commentRepo,
 		eventRepo:             eventRepo,
+		moderator:             moderator,
+		autoReplier:           autoReplier,
+		dmRepo:                dmRepo,
+		dmEventRepo:           dmEventRepo,
 		lpClients:             make(map[int]*longpoll.LongPoll),
 		vkClients:             make(map[int]*api.VK),
+		commentLocks:          make(map[int]*sync.Mutex),
+		reconciling:           make(map[int]struct{}),
 		stopCh:                make(chan struct{}),
 	}
 }
@@ -68,6 +85,8 @@ func NewVKEventListener(
 func (e *EventListener) StartListener() {
 	// Запускаем тикер для периодической проверки новых групп
 	e.ticker = time.NewTicker(1 * time.Minute)
+	// Комментарии, пропущенные лонгполлом, периодически сверяются с ВКонтакте
+	e.reconcileTicker = time.NewTicker(reconcileInterval)
 	go func() {
 		// Сразу проверяем при старте
 		e.checkForUnwatchedGroups()
@@ -86,6 +105,8 @@ func (e *EventListener) StartListener() {
 				}()
 				// Проверяем группы, которые давно не обновлялись
 				go e.checkForUnwatchedGroups()
+			case <-e.reconcileTicker.C:
+				go e.reconcileWatchedTeams()
 			case <-e.stopCh:
 				return
 			}
@@ -97,6 +118,9 @@ func (e *EventListener) StopListener() {
 	if e.ticker != nil {
 		e.ticker.Stop()
 	}
+	if e.reconcileTicker != nil {
+		e.reconcileTicker.Stop()
+	}
 
 	// Отменяем контекст
 	e.cancel()
@@ -170,6 +194,9 @@ func (e *EventListener) checkForUnwatchedGroups() {
 					e.mu.Unlock()
 				}
 			}(teamID, lp)
+
+			// Пока лонгполл не работал, комментарии могли появиться, измениться или удалиться
+			go e.reconcileTeam(teamID)
 		}
 		e.mu.Unlock()
 	}
@@ -195,6 +222,9 @@ func (e *EventListener) setupLongPollHandlers(lp *longpoll.LongPoll, teamID int)
 	lp.WallReplyRestore(func(ctx context.Context, object events.WallReplyRestoreObject) {
 		e.wallReplyRestoreHandler(ctx, object, teamID)
 	})
+	lp.MessageNew(func(ctx context.Context, object events.MessageNewObject) {
+		e.messageNewHandler(ctx, object, teamID)
+	})
 	/*
 		DEPRECATED
 		lp.LikeAdd(func(ctx context.Context, object events.LikeAddObject) {
@@ -207,6 +237,20 @@ func (e *EventListener) setupLongPollHandlers(lp *longpoll.LongPoll, teamID int)
 }
 
 func (e *EventListener) wallReplyNewHandler(ctx context.Context, obj events.WallReplyNewObject, teamID int) {
+	// Лонгполл после простоя и сверка могут получить один комментарий одновременно
+	lock := e.commentLock(teamID)
+	lock.Lock()
+	defer lock.Unlock()
+	// ID комментариев уникальны только в пределах стены, поэтому проверяем комментарий к посту этой команды
+	exists, err := e.commentRepo.PlatformCommentExists(teamID, "vk", obj.PostID, obj.ID)
+	if err != nil {
+		log.Errorf("Failed to check comment: %v", err)
+		return
+	}
+	if exists {
+		return // Комментарий уже сохранён
+	}
+
 	vkChannel, err := e.teamRepo.GetVKCredsByTeamID(teamID)
 	if err != nil {
 		log.Errorf("Failed to get VK credentials: %v", err)
@@ -242,7 +286,7 @@ func (e *EventListener) wallReplyNewHandler(ctx context.Context, obj events.Wall
 
 	// Возможно, это реплай на один из существующих комментариев
 	if obj.ReplyToComment != 0 {
-		replyComment, err := e.commentRepo.GetCommentByPlatformID(obj.ReplyToComment, "vk")
+		replyComment, err := e.commentRepo.GetPlatformComment(teamID, "vk", obj.PostID, obj.ReplyToComment)
 		if err != nil {
 			log.Errorf("Failed to get comment: %v", err)
 			return
@@ -263,7 +307,7 @@ func (e *EventListener) wallReplyNewHandler(ctx context.Context, obj events.Wall
 			log.Errorf("Failed to process attachments: %v", err)
 		} else {
 			if len(videosURL) > 0 {
-				newComment.Text += "\n📎Пользователь прикрепил видео: " + strings.Join(videosURL, ", ")
+				newComment.Text += vkVideoMarker + strings.Join(videosURL, ", ")
 			}
 			if len(attachments) > 0 {
 				uploads := make([]*entity.Upload, len(attachments))
@@ -277,12 +321,24 @@ func (e *EventListener) wallReplyNewHandler(ctx context.Context, obj events.Wall
 		}
 	}
 
+	// Проверяем правилами модерации команды до сохранения, чтобы не учитывать сам комментарий при поиске флуда
+	verdict := e.moderateComment(newComment)
+
 	// Сохраняем комментарий
 	commentID, err := e.commentRepo.AddComment(newComment)
 	if err != nil {
 		log.Errorf("Failed to save comment: %v", err)
 		return
 	}
+	newComment.ID = commentID
+
+	if verdict != nil {
+		e.applyModeration(vkChannel, newComment, verdict)
+		// Удалённые и отложенные комментарии подписчикам не рассылаются
+		if verdict.Action.HidesComment() {
+			return
+		}
+	}
 
 	// Уведомляем подписчиков о новом комментарии
 	event := &entity.CommentEvent{
@@ -297,11 +353,14 @@ func (e *EventListener) wallReplyNewHandler(ctx context.Context, obj events.Wall
 	if err != nil {
 		log.Errorf("Failed to notify subscribers: %v", err)
 	}
+
+	// Автоответ отправляется через API ВКонтакте и не должен задерживать обработку событий
+	go e.autoReplier.AutoReply(newComment)
 }
 
 func (e *EventListener) wallReplyDeleteHandler(ctx context.Context, obj events.WallReplyDeleteObject, teamID int) {
 	// Находим комментарий в нашей БД
-	comment, err := e.commentRepo.GetCommentByPlatformID(obj.ID, "vk")
+	comment, err := e.commentRepo.GetPlatformComment(teamID, "vk", obj.PostID, obj.ID)
 	if errors.Is(err, repo.ErrCommentNotFound) {
 		return // Комментарий не найден, так что ничего не делаем
 	}
@@ -337,7 +396,7 @@ func (e *EventListener) wallReplyDeleteHandler(ctx context.Context, obj events.W
 }
 
 func (e *EventListener) wallReplyEditHandler(ctx context.Context, obj events.WallReplyEditObject, teamID int) {
-	comment, err := e.commentRepo.GetCommentByPlatformID(obj.ID, "vk")
+	comment, err := e.commentRepo.GetPlatformComment(teamID, "vk", obj.PostID, obj.ID)
 	if errors.Is(err, repo.ErrCommentNotFound) {
 		return // Комментарий не найден, ничего не делаем
 	}
@@ -364,10 +423,13 @@ func (e *EventListener) wallReplyEditHandler(ctx context.Context, obj events.Wal
 			comment.Attachments = uploads
 		}
 		if len(videosURL) > 0 {
-			comment.Text += "\n📎Пользователь прикрепил видео: " + strings.Join(videosURL, ", ")
+			comment.Text += vkVideoMarker + strings.Join(videosURL, ", ")
 		}
 	}
 
+	// Изменённый текст проверяется так же, как новый: иначе правила можно обойти, отредактировав комментарий
+	verdict := e.moderateCommentEdit(comment)
+
 	// Сохраняем обновленный комментарий
 	err = e.commentRepo.EditComment(comment)
 	if err != nil {
@@ -375,6 +437,18 @@ func (e *EventListener) wallReplyEditHandler(ctx context.Context, obj events.Wal
 		return
 	}
 
+	if verdict != nil {
+		vkChannel, err := e.teamRepo.GetVKCredsByTeamID(teamID)
+		if err != nil {
+			log.Errorf("Failed to get VK credentials: %v", err)
+			return
+		}
+		e.applyModeration(vkChannel, comment, verdict)
+		if verdict.Action.HidesComment() {
+			return
+		}
+	}
+
 	// Уведомляем подписчиков об обновленном комментарии
 	postUnionID := 0
 	if comment.PostUnionID != nil {
@@ -396,7 +470,7 @@ func (e *EventListener) wallReplyEditHandler(ctx context.Context, obj events.Wal
 
 func (e *EventListener) wallReplyRestoreHandler(ctx context.Context, obj events.WallReplyRestoreObject, teamID int) {
 	// Это аналогично новому комментарию, но сначала проверяем, существует ли он уже
-	existingComment, err := e.commentRepo.GetCommentByPlatformID(obj.ID, "vk")
+	existingComment, err := e.commentRepo.GetPlatformComment(teamID, "vk", obj.PostID, obj.ID)
 	if err == nil {
 		// Комментарий существует, просто помечаем его как активный
 		// Для этого просто обновляем его текст
@@ -471,6 +545,18 @@ func (e *EventListener) wallReplyRestoreHandler(ctx context.Context, obj events.
 	}
 }
 
+// commentLock возвращает блокировку сохранения новых комментариев команды
+func (e *EventListener) commentLock(teamID int) *sync.Mutex {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	lock, ok := e.commentLocks[teamID]
+	if !ok {
+		lock = &sync.Mutex{}
+		e.commentLocks[teamID] = lock
+	}
+	return lock
+}
+
 type UserInfo struct {
 	FullName string
 	Username string
@@ -0,0 +1,85 @@
+package vkontakte
+
+import (
+	"postic-backend/internal/entity"
+	"postic-backend/pkg/retry"
+
+	"github.com/SevereCloud/vksdk/v3/api"
+	"github.com/labstack/gommon/log"
+)
+
+// moderateComment проверяет новый комментарий правилами команды до сохранения.
+// Отложенная проверка отмечается в самом комментарии, остальные действия выполняет applyModeration
+func (e *EventListener) moderateComment(comment *entity.Comment) *entity.ModerationVerdict {
+	return e.holdByVerdict(comment, e.moderator.CheckComment)
+}
+
+// moderateCommentEdit проверяет изменённый текст сохранённого комментария до записи изменений
+func (e *EventListener) moderateCommentEdit(comment *entity.Comment) *entity.ModerationVerdict {
+	return e.holdByVerdict(comment, e.moderator.CheckEditedComment)
+}
+
+func (e *EventListener) holdByVerdict(comment *entity.Comment, check func(*entity.Comment) (*entity.ModerationVerdict, error)) *entity.ModerationVerdict {
+	verdict, err := check(comment)
+	if err != nil {
+		// без правил комментарий всё равно должен дойти до команды
+		log.Errorf("Failed to check comment with moderation rules: %v", err)
+		return nil
+	}
+	if verdict == nil {
+		return nil
+	}
+	switch verdict.Action {
+	case entity.ModerationActionHold:
+		comment.HeldForReview = true
+	}
+	return verdict
+}
+
+// applyModeration выполняет действие над сохранённым комментарием и записывает его в журнал.
+// Удаление и бан в сообществе требуют ключа администратора
+func (e *EventListener) applyModeration(vkChannel *entity.VKChannel, comment *entity.Comment, verdict *entity.ModerationVerdict) {
+	succeeded := true
+	switch verdict.Action {
+	case entity.ModerationActionTicket:
+		if err := e.moderator.OpenTicket(comment, verdict); err != nil {
+			log.Errorf("Failed to open ticket by moderation rule: %v", err)
+			succeeded = false
+		}
+	case entity.ModerationActionDelete, entity.ModerationActionBan:
+		vk := api.NewVK(vkChannel.AdminAPIKey)
+		err := retry.Retry(func() error {
+			_, err := vk.WallDeleteComment(api.Params{
+				"owner_id":   -vkChannel.GroupID,
+				"comment_id": comment.CommentPlatformID,
+			})
+			return err
+		})
+		if err != nil {
+			log.Errorf("Failed to delete VK comment %d by moderation rule: %v", comment.CommentPlatformID, err)
+			succeeded = false
+		}
+		if err := e.commentRepo.DeleteComment(comment.ID); err != nil {
+			log.Errorf("Failed to mark comment as deleted: %v", err)
+		}
+		if verdict.Action == entity.ModerationActionBan {
+			err := retry.Retry(func() error {
+				_, err := vk.GroupsBan(api.Params{
+					"group_id": vkChannel.GroupID,
+					"owner_id": comment.UserPlatformID,
+					"comment":  verdict.Reason,
+				})
+				return err
+			})
+			if err != nil {
+				log.Errorf("Failed to ban VK user %d by moderation rule: %v", comment.UserPlatformID, err)
+				succeeded = false
+			} else if err := e.moderator.RecordBan(comment, verdict); err != nil {
+				log.Errorf("Failed to record ban by moderation rule: %v", err)
+			}
+		}
+	}
+	if err := e.moderator.LogAction(comment, verdict, succeeded); err != nil {
+		log.Errorf("Failed to log moderation action: %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"postic-backend/internal/entity"
 	"postic-backend/internal/repo"
 	"postic-backend/internal/usecase"
+	"postic-backend/internal/usecase/service"
 	"postic-backend/pkg/retry"
 	"strings"
 	"time"
@@ -14,21 +15,30 @@ import (
 	"github.com/labstack/gommon/log"
 )
 
+const (
+	// Ограничения VK на фото, загружаемые на стену
+	maxPhotoSize   = 50 * 1024 * 1024
+	maxPhotoDimSum = 14000
+)
+
 type Post struct {
 	postRepo      repo.Post
 	teamRepo      repo.Team
 	uploadUseCase usecase.Upload
+	teamEventRepo repo.TeamEventRepository
 }
 
 func NewPost(
 	postRepo repo.Post,
 	teamRepo repo.Team,
 	uploadUseCase usecase.Upload,
+	teamEventRepo repo.TeamEventRepository,
 ) usecase.PostPlatform {
 	return &Post{
 		postRepo:      postRepo,
 		teamRepo:      teamRepo,
 		uploadUseCase: uploadUseCase,
+		teamEventRepo: teamEventRepo,
 	}
 }
 
@@ -62,8 +72,10 @@ func (p *Post) createPostAction(request *entity.PostUnion) (int, error) {
 }
 
 func (p *Post) updatePostActionStatus(actionId int, status, errMsg string) {
+	var action *entity.PostAction
 	err := retry.Retry(func() error {
-		action, err := p.postRepo.GetPostAction(actionId)
+		var err error
+		action, err = p.postRepo.GetPostAction(actionId)
 		if err != nil {
 			log.Errorf("error getting post action: %v", err)
 			return err
@@ -80,7 +92,9 @@ func (p *Post) updatePostActionStatus(actionId int, status, errMsg string) {
 	})
 	if err != nil {
 		log.Errorf("error while updating post action status: %v", err)
+		return
 	}
+	service.PublishPostActionStatus(p.postRepo, p.teamEventRepo, action, status, errMsg)
 }
 
 func (p *Post) publishPost(request *entity.PostUnion, actionId int) {
@@ -172,6 +186,15 @@ func (p *Post) uploadAttachments(vk *api.VK, groupId int, attachments []*entity.
 }
 
 func (p *Post) uploadPhoto(vk *api.VK, groupId int, upload *entity.Upload) (string, error) {
+	// Отправляем копию без EXIF, подходящую под ограничения VK, если она есть
+	if rendition := upload.PickRendition(maxPhotoSize, maxPhotoDimSum, entity.RenditionJPEG2560, entity.RenditionJPEG1280); rendition != nil {
+		renditionUpload, err := p.uploadUseCase.GetUpload(rendition.MediaFileID)
+		if err != nil {
+			log.Errorf("error getting rendition %s of upload %d, using original: %v", rendition.Kind, upload.ID, err)
+		} else {
+			upload = renditionUpload
+		}
+	}
 	uploadResponse, err := vk.UploadGroupWallPhoto(groupId, upload.RawBytes)
 	if err != nil {
 		return "", err
@@ -187,6 +210,15 @@ func (p *Post) uploadPhoto(vk *api.VK, groupId int, upload *entity.Upload) (stri
 }
 
 func (p *Post) uploadVideo(vk *api.VK, groupId int, upload *entity.Upload) (string, error) {
+	// Перекодированная в H.264 версия есть только у видео, которые площадки могут не принять
+	if rendition := upload.PickRendition(0, 0, entity.RenditionMP4720); rendition != nil {
+		renditionUpload, err := p.uploadUseCase.GetUpload(rendition.MediaFileID)
+		if err != nil {
+			log.Errorf("error getting rendition %s of upload %d, using original: %v", rendition.Kind, upload.ID, err)
+		} else {
+			upload = renditionUpload
+		}
+	}
 	videoSaveResponse, err := vk.UploadVideo(api.Params{
 		"group_id": groupId,
 	}, upload.RawBytes)
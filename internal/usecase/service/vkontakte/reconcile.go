@@ -0,0 +1,221 @@
+package vkontakte
+
+import (
+	"fmt"
+	"postic-backend/internal/entity"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SevereCloud/vksdk/v3/api"
+	"github.com/SevereCloud/vksdk/v3/events"
+	"github.com/SevereCloud/vksdk/v3/object"
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	// reconcileInterval как часто комментарии сверяются с ВКонтакте, помимо запуска лонгполла
+	reconcileInterval = 10 * time.Minute
+	// reconcileWindow комментарии сверяются только у постов, опубликованных за это время
+	reconcileWindow = 7 * 24 * time.Hour
+	// reconcileRequestDelay пауза между запросами к API, чтобы не упереться в ограничение частоты
+	reconcileRequestDelay = 350 * time.Millisecond
+	// vkCommentsPageSize максимальное число комментариев, которое отдаёт wall.getComments
+	vkCommentsPageSize = 100
+	// vkVideoMarker с этой строки к тексту комментария дописываются ссылки на видео из вложений
+	vkVideoMarker = "\n📎Пользователь прикрепил видео: "
+)
+
+// reconcileWatchedTeams сверяет комментарии всех команд, за группами которых следит слушатель
+func (e *EventListener) reconcileWatchedTeams() {
+	e.mu.Lock()
+	teamIDs := make([]int, 0, len(e.lpClients))
+	for teamID := range e.lpClients {
+		teamIDs = append(teamIDs, teamID)
+	}
+	e.mu.Unlock()
+
+	for _, teamID := range teamIDs {
+		e.reconcileTeam(teamID)
+	}
+}
+
+// reconcileTeam восстанавливает комментарии, пропущенные лонгполлом: сохраняет новые, применяет правки
+// и удаления. Изменения проходят через обработчики событий, поэтому подписчики получают те же события
+func (e *EventListener) reconcileTeam(teamID int) {
+	e.mu.Lock()
+	_, running := e.reconciling[teamID]
+	vk, ok := e.vkClients[teamID]
+	if !running && ok {
+		e.reconciling[teamID] = struct{}{}
+	}
+	e.mu.Unlock()
+	if running || !ok {
+		return
+	}
+	defer func() {
+		e.mu.Lock()
+		delete(e.reconciling, teamID)
+		e.mu.Unlock()
+	}()
+
+	vkChannel, err := e.teamRepo.GetVKCredsByTeamID(teamID)
+	if err != nil {
+		log.Errorf("Failed to get VK credentials for team %d: %v", teamID, err)
+		return
+	}
+	postPlatforms, err := e.postRepo.GetRecentPostPlatforms(vkChannel.ID, "vk", time.Now().Add(-reconcileWindow))
+	if err != nil {
+		log.Errorf("Failed to get VK posts for team %d: %v", teamID, err)
+		return
+	}
+
+	for _, postPlatform := range postPlatforms {
+		if e.ctx.Err() != nil {
+			return
+		}
+		err := e.reconcilePost(vk, vkChannel.GroupID, teamID, postPlatform)
+		if err != nil {
+			log.Errorf("Failed to reconcile comments of VK post %d for team %d: %v", postPlatform.PostId, teamID, err)
+		}
+	}
+}
+
+// reconcilePost сверяет комментарии одного поста с сохранёнными
+func (e *EventListener) reconcilePost(vk *api.VK, groupID, teamID int, postPlatform *entity.PostPlatform) error {
+	// Сохранённые комментарии читаются до запроса к ВКонтакте: комментарий, который лонгполл сохранит во время
+	// сверки, не попадёт в stored и не будет помечен удалённым. Если же он есть в ответе, повторно его не сохранит
+	// проверка в wallReplyNewHandler
+	stored, err := e.commentRepo.GetPlatformPostComments(teamID, "vk", postPlatform.PostId)
+	if err != nil {
+		return err
+	}
+	vkComments, err := e.getPostComments(vk, groupID, postPlatform.PostId)
+	if err != nil {
+		return err
+	}
+	storedByID := make(map[int]*entity.PlatformComment, len(stored))
+	for _, comment := range stored {
+		storedByID[comment.CommentPlatformID] = comment
+	}
+
+	created, edited, deleted := 0, 0, 0
+	present := make(map[int]struct{}, len(vkComments))
+	for _, vkComment := range vkComments {
+		if bool(vkComment.Deleted) {
+			continue
+		}
+		present[vkComment.ID] = struct{}{}
+		// ответы от имени группы сохраняются при отправке
+		if vkComment.FromID == -groupID {
+			continue
+		}
+
+		comment, ok := storedByID[vkComment.ID]
+		switch {
+		case !ok:
+			e.wallReplyNewHandler(e.ctx, events.WallReplyNewObject(vkComment), teamID)
+			created++
+		case comment.IsDeleted || comment.HeldForReview || comment.IsTeamReply:
+			continue
+		case commentTextChanged(comment.Text, vkComment.Text):
+			e.wallReplyEditHandler(e.ctx, events.WallReplyEditObject(vkComment), teamID)
+			edited++
+		}
+	}
+
+	for _, comment := range stored {
+		if comment.IsDeleted || comment.HeldForReview || comment.IsTeamReply {
+			continue
+		}
+		if _, ok := present[comment.CommentPlatformID]; ok {
+			continue
+		}
+		e.wallReplyDeleteHandler(e.ctx, events.WallReplyDeleteObject{
+			OwnerID: -groupID,
+			ID:      comment.CommentPlatformID,
+			PostID:  postPlatform.PostId,
+		}, teamID)
+		deleted++
+	}
+
+	if created+edited+deleted > 0 {
+		log.Infof("Reconciled comments of VK post %d for team %d: %d created, %d edited, %d deleted",
+			postPlatform.PostId, teamID, created, edited, deleted)
+	}
+	return nil
+}
+
+// getPostComments возвращает все комментарии поста вместе с ветками ответов в порядке написания
+func (e *EventListener) getPostComments(vk *api.VK, groupID, postID int) ([]object.WallWallComment, error) {
+	roots, err := e.getCommentsPage(vk, api.Params{
+		"owner_id":           -groupID,
+		"post_id":            postID,
+		"sort":               "asc",
+		"thread_items_count": 10,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]object.WallWallComment, 0, len(roots))
+	for _, root := range roots {
+		root.PostID = postID
+		comments = append(comments, root)
+
+		thread := root.Thread.Items
+		if root.Thread.Count > len(thread) {
+			// в ответе приходят только первые ответы ветки, остальные запрашиваются отдельно
+			thread, err = e.getCommentsPage(vk, api.Params{
+				"owner_id":   -groupID,
+				"post_id":    postID,
+				"comment_id": root.ID,
+				"sort":       "asc",
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, reply := range thread {
+			reply.PostID = postID
+			comments = append(comments, reply)
+		}
+	}
+
+	// новые комментарии сохраняются по возрастанию ID, чтобы ответ сохранялся после исходного комментария
+	slices.SortFunc(comments, func(a, b object.WallWallComment) int {
+		return a.ID - b.ID
+	})
+	return comments, nil
+}
+
+// getCommentsPage постранично читает один уровень комментариев
+func (e *EventListener) getCommentsPage(vk *api.VK, params api.Params) ([]object.WallWallComment, error) {
+	var comments []object.WallWallComment
+	for offset := 0; ; offset += vkCommentsPageSize {
+		select {
+		case <-e.ctx.Done():
+			return nil, e.ctx.Err()
+		case <-time.After(reconcileRequestDelay):
+		}
+
+		params["count"] = vkCommentsPageSize
+		params["offset"] = offset
+		resp, err := vk.WallGetComments(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get comments: %w", err)
+		}
+		comments = append(comments, resp.Items...)
+		if len(resp.Items) < vkCommentsPageSize || offset+len(resp.Items) >= resp.CurrentLevelCount {
+			return comments, nil
+		}
+	}
+}
+
+// commentTextChanged сравнивает сохранённый текст с текстом из ВКонтакте без дописанных ссылок на видео
+func commentTextChanged(stored, text string) bool {
+	if stored == text {
+		return false
+	}
+	return !strings.HasPrefix(stored, text+vkVideoMarker)
+}
@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"postic-backend/internal/entity"
+)
+
+type TeamEvents interface {
+	// Subscribe подписывается на общий поток событий команды. Приходят только события, доступные по ролям пользователя
+	Subscribe(ctx context.Context, request *entity.TeamEventSubscriber) (<-chan *entity.TeamEvent, error)
+}
+
+var (
+	ErrInvalidTeamEventFilter = errors.New("invalid team event filter")
+)
@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"errors"
+	"postic-backend/internal/entity"
+)
+
+type Ticket interface {
+	// GetTickets возвращает тикеты команды по фильтрам
+	GetTickets(request *entity.GetTicketsRequest) ([]*entity.Ticket, error)
+	// GetTicket возвращает тикет с комментариями и заметками
+	GetTicket(request *entity.GetTicketRequest) (*entity.Ticket, error)
+	// CreateTicket открывает тикет по комментарию и возвращает его ID
+	CreateTicket(request *entity.CreateTicketRequest) (int, error)
+	// UpdateTicket меняет статус, приоритет, исполнителя или название тикета
+	UpdateTicket(request *entity.UpdateTicketRequest) error
+	// AddNote добавляет внутреннюю заметку к тикету и возвращает её ID
+	AddNote(request *entity.AddTicketNoteRequest) (int, error)
+	// LinkComment привязывает к тикету ещё один комментарий
+	LinkComment(request *entity.TicketCommentRequest) error
+	// UnlinkComment отвязывает комментарий от тикета
+	UnlinkComment(request *entity.TicketCommentRequest) error
+}
+
+var (
+	ErrTicketNotFound          = errors.New("тикет не найден")
+	ErrTicketCommentLinked     = errors.New("комментарий уже привязан к тикету")
+	ErrTicketCommentNotLinked  = errors.New("комментарий не привязан к тикету")
+	ErrTicketAssigneeNotInTeam = errors.New("исполнитель не состоит в команде")
+)
@@ -1,10 +1,45 @@
 package usecase
 
-import "postic-backend/internal/entity"
+import (
+	"errors"
+	"io"
+	"postic-backend/internal/entity"
+)
 
 type Upload interface {
 	// UploadFile сохраняет файл в папку и возвращает его айди
 	UploadFile(upload *entity.Upload) (int, error)
 	// GetUpload возвращает файл по его айди
 	GetUpload(id int) (*entity.Upload, error)
+	// DeleteUpload удаляет файл вместе с его производными версиями
+	DeleteUpload(id int) error
+	// CreateUploadSession создаёт сессию возобновляемой загрузки
+	CreateUploadSession(session *entity.UploadSession) (*entity.UploadSession, error)
+	// GetUploadSession возвращает сессию возобновляемой загрузки
+	GetUploadSession(id int) (*entity.UploadSession, error)
+	// WriteUploadSession дописывает данные в сессию начиная с offset и возвращает обновлённую сессию.
+	// Когда получены все байты, файл собирается и у сессии появляется MediaFileID
+	WriteUploadSession(id int, offset int64, r io.Reader) (*entity.UploadSession, error)
+	// DeleteUploadSession прерывает загрузку и удаляет полученные части
+	DeleteUploadSession(id int) error
+	// CreatePresignedUpload создаёт сессию и временную ссылку, по которой клиент загружает файл напрямую в хранилище
+	CreatePresignedUpload(session *entity.UploadSession) (*entity.UploadSession, *entity.PresignedURL, error)
+	// FinalizePresignedUpload проверяет размер и тип загруженного по ссылке файла и создаёт медиафайл
+	FinalizePresignedUpload(id int) (*entity.UploadSession, error)
+	// PresignDownload возвращает временную ссылку на скачивание файла напрямую из хранилища
+	PresignDownload(id int) (*entity.PresignedURL, error)
+	// GetTeamStorageUsage возвращает занятое командой место и её квоты
+	GetTeamStorageUsage(teamID int) (*entity.TeamStorageUsage, error)
 }
+
+// MaxUploadSessionSize максимальный размер файла для возобновляемой загрузки
+const MaxUploadSessionSize int64 = 512 * 1024 * 1024
+
+var (
+	ErrUploadSessionNotFound       = errors.New("сессия загрузки не найдена")
+	ErrUploadSessionOffsetMismatch = errors.New("смещение не совпадает с текущим смещением сессии загрузки")
+	ErrUploadTooLarge              = errors.New("файл превышает максимальный размер загрузки")
+	ErrInvalidUpload               = errors.New("некорректный файл")
+	ErrPresignNotSupported         = errors.New("хранилище не поддерживает временные ссылки")
+	ErrStorageQuotaExceeded        = errors.New("превышена квота хранилища команды")
+)
@@ -15,3 +15,13 @@ func GetMinioConnector(endpoint string, accessKey string, secretKey string, useS
 	}
 	return minioClient, nil
 }
+
+// GetMinioPresignConnector возвращает клиент для подписи ссылок на публичный адрес MinIO.
+// Регион задан явно, чтобы подпись не требовала запроса к серверу, который может быть недоступен изнутри
+func GetMinioPresignConnector(endpoint string, accessKey string, secretKey string, useSSL bool) (*minio.Client, error) {
+	return minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: useSSL,
+		Region: "eu-central-1",
+	})
+}
@@ -0,0 +1,151 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrForbiddenAddress  = errors.New("запрещённый адрес")
+	ErrUnsupportedScheme = errors.New("поддерживаются только http и https")
+	ErrTooManyRedirects  = errors.New("слишком много перенаправлений")
+	ErrTooLarge          = errors.New("файл слишком большой")
+	ErrBadStatus         = errors.New("сервер вернул ошибку")
+)
+
+// Диапазоны, которые не покрываются методами net.IP, но тоже не должны быть доступны извне
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "этот" хост
+	netip.MustParsePrefix("100.64.0.0/10"),   // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF
+	netip.MustParsePrefix("192.0.2.0/24"),    // TEST-NET-1
+	netip.MustParsePrefix("198.18.0.0/15"),   // тестирование сетей
+	netip.MustParsePrefix("198.51.100.0/24"), // TEST-NET-2
+	netip.MustParsePrefix("203.0.113.0/24"),  // TEST-NET-3
+	netip.MustParsePrefix("240.0.0.0/4"),     // зарезервировано, включая broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64 может вести во внутреннюю сеть
+	netip.MustParsePrefix("2001:db8::/32"),   // документация
+}
+
+// Response загруженное содержимое
+type Response struct {
+	Data        []byte
+	ContentType string   // заголовок Content-Type, как его прислал сервер
+	URL         *url.URL // адрес после перенаправлений
+}
+
+// Client скачивает файлы по ссылкам пользователей и не ходит во внутреннюю сеть
+type Client struct {
+	client  *http.Client
+	maxSize int64
+}
+
+// NewClient создаёт клиент с ограничением времени всего запроса, числа перенаправлений и размера ответа
+func NewClient(timeout time.Duration, maxRedirects int, maxSize int64) *Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		// Адрес проверяется при подключении, уже после разрешения имени: так не помогает ни DNS rebinding,
+		// ни перенаправление на внутренний адрес
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+			}
+			if !IsPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		// Прокси из окружения не используется: иначе проверялся бы адрес прокси, а не конечного сервера
+		Proxy:                  nil,
+		DialContext:            dialer.DialContext,
+		TLSHandshakeTimeout:    10 * time.Second,
+		ResponseHeaderTimeout:  15 * time.Second,
+		MaxResponseHeaderBytes: 64 * 1024,
+	}
+	return &Client{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) > maxRedirects {
+					return ErrTooManyRedirects
+				}
+				return checkScheme(req.URL)
+			},
+		},
+		maxSize: maxSize,
+	}
+}
+
+// IsPublicAddr сообщает, что адрес доступен из интернета, а не ведёт во внутреннюю или служебную сеть
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func checkScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrUnsupportedScheme
+	}
+	return nil
+}
+
+// Get скачивает содержимое по ссылке целиком
+func (c *Client) Get(ctx context.Context, rawURL string) (*Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkScheme(u); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: %s", ErrBadStatus, resp.Status)
+	}
+	if resp.ContentLength > c.maxSize {
+		return nil, ErrTooLarge
+	}
+	// Content-Length может отсутствовать или врать, поэтому ограничиваем и само чтение
+	data, err := io.ReadAll(io.LimitReader(resp.Body, c.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > c.maxSize {
+		return nil, ErrTooLarge
+	}
+	return &Response{
+		Data:        data,
+		ContentType: resp.Header.Get("Content-Type"),
+		URL:         resp.Request.URL,
+	}, nil
+}
@@ -0,0 +1,27 @@
+package media
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CheckContentType проверяет, что первые байты файла (достаточно 512) соответствуют заявленному типу
+func CheckContentType(fileType string, header []byte) error {
+	// Определяем MIME-тип файла
+	mimeType := http.DetectContentType(header)
+
+	// Проверяем соответствие MIME-типа заявленному типу файла
+	switch fileType {
+	case "photo":
+		if !strings.HasPrefix(mimeType, "image/jpeg") && !strings.HasPrefix(mimeType, "image/png") && !strings.HasPrefix(mimeType, "image/webp") {
+			return fmt.Errorf("содержимое не соответствует формату фото: обнаружен MIME-тип %s", mimeType)
+		}
+	case "video":
+		if !strings.HasPrefix(mimeType, "video/mp4") {
+			return fmt.Errorf("содержимое не соответствует формату видео: обнаружен MIME-тип %s", mimeType)
+		}
+	}
+
+	return nil
+}
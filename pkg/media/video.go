@@ -0,0 +1,219 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+var (
+	ErrNotMP4             = errors.New("файл не является корректным MP4")
+	ErrFFmpegNotAvailable = errors.New("ffmpeg не найден в PATH")
+)
+
+// VideoInfo метаданные MP4-видео
+type VideoInfo struct {
+	Duration time.Duration
+	Width    int
+	Height   int
+	// Codec fourcc кодека видеодорожки (avc1, hvc1, av01 и т.д.)
+	Codec string
+}
+
+// PlatformFriendlyCodec сообщает, поддерживается ли кодек видеодорожки всеми площадками без перекодирования
+func (v *VideoInfo) PlatformFriendlyCodec() bool {
+	return v.Codec == "avc1" || v.Codec == "avc3"
+}
+
+// maxDurationSeconds наибольшая длительность, которую можно представить в time.Duration
+const maxDurationSeconds = uint64(math.MaxInt64 / int64(time.Second))
+
+// maxMoovSize наибольший размер бокса moov, который читается в память. В нём только индексы сэмплов,
+// поэтому даже у многочасовых видео он в разы меньше
+const maxMoovSize = 64 * 1024 * 1024
+
+// ProbeMP4 достаёт длительность, размеры и кодек из боксов moov/mvhd и trak/tkhd без внешних зависимостей
+func ProbeMP4(data []byte) (*VideoInfo, error) {
+	return ProbeMP4At(bytes.NewReader(data), int64(len(data)))
+}
+
+// ProbeMP4At делает то же, что ProbeMP4, но читает из r только заголовки боксов верхнего уровня и сам moov,
+// чтобы не загружать в память всё видео
+func ProbeMP4At(r io.ReaderAt, size int64) (*VideoInfo, error) {
+	header := make([]byte, 16)
+	for offset := int64(0); offset+8 <= size; {
+		n, _ := r.ReadAt(header, offset)
+		if n < 8 {
+			return nil, ErrNotMP4
+		}
+		boxSize := int64(binary.BigEndian.Uint32(header))
+		headerSize := int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = size - offset
+		case 1:
+			if n < 16 {
+				return nil, ErrNotMP4
+			}
+			// значение больше MaxInt64 станет отрицательным и отсеется проверкой ниже
+			boxSize = int64(binary.BigEndian.Uint64(header[8:]))
+			headerSize = 16
+		}
+		if boxSize < headerSize || boxSize > size-offset {
+			return nil, ErrNotMP4
+		}
+		if string(header[4:8]) == "moov" {
+			if boxSize-headerSize > maxMoovSize {
+				return nil, ErrNotMP4
+			}
+			moov := make([]byte, boxSize-headerSize)
+			if n, _ := r.ReadAt(moov, offset+headerSize); n < len(moov) {
+				return nil, ErrNotMP4
+			}
+			return probeMoov(moov), nil
+		}
+		offset += boxSize
+	}
+	return nil, ErrNotMP4
+}
+
+func probeMoov(moov []byte) *VideoInfo {
+	info := &VideoInfo{}
+	if mvhd := findBox(moov, "mvhd"); len(mvhd) >= 32 {
+		var timescale, duration uint64
+		if mvhd[0] == 1 {
+			timescale = uint64(binary.BigEndian.Uint32(mvhd[20:]))
+			duration = binary.BigEndian.Uint64(mvhd[24:])
+		} else {
+			timescale = uint64(binary.BigEndian.Uint32(mvhd[12:]))
+			duration = uint64(binary.BigEndian.Uint32(mvhd[16:]))
+		}
+		// секунды и остаток считаются отдельно, чтобы большие значения из файла не переполнили произведение
+		if timescale > 0 && duration/timescale <= maxDurationSeconds {
+			fraction := duration % timescale * uint64(time.Second) / timescale
+			info.Duration = time.Duration(duration/timescale)*time.Second + time.Duration(fraction)
+		}
+	}
+	eachBox(moov, func(typ string, trak []byte) {
+		if typ != "trak" || info.Codec != "" {
+			return
+		}
+		mdia := findBox(trak, "mdia")
+		hdlr := findBox(mdia, "hdlr")
+		if len(hdlr) < 12 || string(hdlr[8:12]) != "vide" {
+			return
+		}
+		if tkhd := findBox(trak, "tkhd"); len(tkhd) > 0 {
+			off := 76
+			if tkhd[0] == 1 {
+				off = 88
+			}
+			if len(tkhd) >= off+8 {
+				// значения в формате fixed-point 16.16
+				info.Width = int(binary.BigEndian.Uint32(tkhd[off:]) >> 16)
+				info.Height = int(binary.BigEndian.Uint32(tkhd[off+4:]) >> 16)
+			}
+		}
+		stsd := findBox(findBox(findBox(mdia, "minf"), "stbl"), "stsd")
+		if len(stsd) >= 16 {
+			info.Codec = string(stsd[12:16])
+		}
+	})
+	return info
+}
+
+// eachBox обходит боксы верхнего уровня внутри data
+func eachBox(data []byte, fn func(typ string, body []byte)) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data))
+		typ := string(data[4:8])
+		header := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return
+			}
+			size = binary.BigEndian.Uint64(data[8:])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return
+		}
+		fn(typ, data[header:size])
+		data = data[size:]
+	}
+}
+
+func findBox(data []byte, typ string) []byte {
+	var found []byte
+	eachBox(data, func(t string, body []byte) {
+		if found == nil && t == typ {
+			found = body
+		}
+	})
+	return found
+}
+
+// VideoThumbnail извлекает кадр из видео в файле path в JPEG с помощью ffmpeg
+func VideoThumbnail(ctx context.Context, path string, at time.Duration, maxSide int) ([]byte, error) {
+	return runFFmpeg(ctx, path, "thumb.jpg",
+		"-ss", fmt.Sprintf("%.3f", at.Seconds()),
+		"-i", "{in}",
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale='min(%d,iw)':-2", maxSide),
+		"-q:v", "3",
+		"{out}",
+	)
+}
+
+// TranscodeVideo перекодирует видео в файле path в H.264/AAC с высотой не больше maxHeight
+func TranscodeVideo(ctx context.Context, path string, maxHeight int) ([]byte, error) {
+	return runFFmpeg(ctx, path, "out.mp4",
+		"-i", "{in}",
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", "26",
+		"-vf", fmt.Sprintf("scale=-2:'min(%d,ih)'", maxHeight),
+		"-c:a", "aac", "-b:a", "128k",
+		"-movflags", "+faststart",
+		"{out}",
+	)
+}
+
+// runFFmpeg запускает ffmpeg над файлом in (MP4 требует произвольного доступа, поэтому не поток)
+// и возвращает содержимое результата
+func runFFmpeg(ctx context.Context, in string, outName string, args ...string) ([]byte, error) {
+	bin, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, ErrFFmpegNotAvailable
+	}
+	dir, err := os.MkdirTemp("", "media-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, outName)
+	cmdArgs := []string{"-y", "-loglevel", "error"}
+	for _, a := range args {
+		switch a {
+		case "{in}":
+			a = in
+		case "{out}":
+			a = out
+		}
+		cmdArgs = append(cmdArgs, a)
+	}
+	if output, err := exec.CommandContext(ctx, bin, cmdArgs...).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("ошибка ffmpeg: %w: %s", err, output)
+	}
+	return os.ReadFile(out)
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Незаконченные записи лежат рядом с объектом под временным именем и не видны в List
+const fsTempPrefix = ".tmp-"
+
+// FS хранит объекты файлами в каталоге root, "/" в имени объекта соответствует подкаталогу
+type FS struct {
+	root string
+}
+
+// NewFS создаёт каталог root, если его ещё нет
+func NewFS(root string) (Storage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &FS{root: root}, nil
+}
+
+// path переводит имя объекта в путь к файлу, не выпуская его за пределы root
+func (f *FS) path(name string) (string, error) {
+	clean := path.Clean("/" + name)
+	if name == "" || clean == "/" || strings.HasPrefix(path.Base(clean), fsTempPrefix) {
+		return "", fmt.Errorf("недопустимое имя объекта %q", name)
+	}
+	return filepath.Join(f.root, filepath.FromSlash(clean)), nil
+}
+
+func fsError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrObjectNotFound
+	}
+	return err
+}
+
+func (f *FS) Put(ctx context.Context, name string, r io.Reader, size int64, contentType string) error {
+	filePath, err := f.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+	// Пишем во временный файл и переименовываем, чтобы читатели не видели объект наполовину записанным
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), fsTempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("записано %d байт вместо %d", written, size)
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+func (f *FS) Get(ctx context.Context, name string) (io.ReadSeekCloser, error) {
+	filePath, err := f.path(name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fsError(err)
+	}
+	return file, nil
+}
+
+func (f *FS) Stat(ctx context.Context, name string) (*ObjectInfo, error) {
+	filePath, err := f.path(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fsError(err)
+	}
+	if info.IsDir() {
+		return nil, ErrObjectNotFound
+	}
+	return &ObjectInfo{Name: name, Size: info.Size(), LastModified: info.ModTime()}, nil
+}
+
+func (f *FS) Remove(ctx context.Context, name string) (int64, error) {
+	info, err := f.Stat(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	filePath, _ := f.path(name)
+	if err := os.Remove(filePath); err != nil {
+		return 0, fsError(err)
+	}
+	// Пустые каталоги удаляем, чтобы List и сам каталог хранилища не разрастались
+	for dir := filepath.Dir(filePath); dir != filepath.Clean(f.root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return info.Size, nil
+}
+
+func (f *FS) List(ctx context.Context, prefix string) iter.Seq2[*ObjectInfo, error] {
+	return func(yield func(*ObjectInfo, error) bool) {
+		// Обходим только каталог, в котором могут лежать объекты с таким префиксом
+		start := f.root
+		if i := strings.LastIndex(prefix, "/"); i >= 0 {
+			start = filepath.Join(f.root, filepath.FromSlash(path.Clean("/"+prefix[:i])))
+		}
+		err := filepath.WalkDir(start, func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), fsTempPrefix) {
+				return nil
+			}
+			rel, err := filepath.Rel(f.root, filePath)
+			if err != nil {
+				return err
+			}
+			name := filepath.ToSlash(rel)
+			if !strings.HasPrefix(name, prefix) {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return fsError(err)
+			}
+			if !yield(&ObjectInfo{Name: name, Size: info.Size(), LastModified: info.ModTime()}, nil) {
+				return fs.SkipAll
+			}
+			return nil
+		})
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
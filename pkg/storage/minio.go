@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+type Minio struct {
+	client        *minio.Client
+	presignClient *minio.Client
+	bucket        string
+}
+
+// NewMinio создаёт бакет, если его ещё нет. presignClient подписывает ссылки для клиентов и должен смотреть
+// на публично доступный адрес MinIO; nil означает, что ссылки подписываются основным клиентом
+func NewMinio(client, presignClient *minio.Client, bucket string) (Storage, error) {
+	ctx := context.Background()
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		err = client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{
+			Region:        "eu-central-1", // Предположим, что мы центральные европейцы
+			ObjectLocking: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if presignClient == nil {
+		presignClient = client
+	}
+	return &Minio{
+		client:        client,
+		presignClient: presignClient,
+		bucket:        bucket,
+	}, nil
+}
+
+func minioError(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrObjectNotFound
+	}
+	return err
+}
+
+func (m *Minio) Put(ctx context.Context, name string, r io.Reader, size int64, contentType string) error {
+	_, err := m.client.PutObject(ctx, m.bucket, name, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+func (m *Minio) Get(ctx context.Context, name string) (io.ReadSeekCloser, error) {
+	object, err := m.client.GetObject(ctx, m.bucket, name, minio.GetObjectOptions{Checksum: true})
+	if err != nil {
+		return nil, minioError(err)
+	}
+	// GetObject не обращается к серверу до первого чтения, поэтому отсутствие объекта проверяем сразу
+	if _, err := object.Stat(); err != nil {
+		_ = object.Close()
+		return nil, minioError(err)
+	}
+	return object, nil
+}
+
+func (m *Minio) Stat(ctx context.Context, name string) (*ObjectInfo, error) {
+	stat, err := m.client.StatObject(ctx, m.bucket, name, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, minioError(err)
+	}
+	return &ObjectInfo{
+		Name:         stat.Key,
+		Size:         stat.Size,
+		LastModified: stat.LastModified,
+	}, nil
+}
+
+func (m *Minio) Remove(ctx context.Context, name string) (int64, error) {
+	// Бакет создан с блокировкой объектов, а значит с версионированием: без удаления версий место не освободится
+	var size int64
+	found := false
+	for object := range m.client.ListObjects(ctx, m.bucket, minio.ListObjectsOptions{
+		Prefix:       name,
+		WithVersions: true,
+	}) {
+		if object.Err != nil {
+			return size, object.Err
+		}
+		if object.Key != name {
+			continue
+		}
+		found = true
+		err := m.client.RemoveObject(ctx, m.bucket, object.Key, minio.RemoveObjectOptions{VersionID: object.VersionID})
+		if err != nil {
+			return size, err
+		}
+		size += object.Size
+	}
+	if !found {
+		return 0, ErrObjectNotFound
+	}
+	return size, nil
+}
+
+func (m *Minio) List(ctx context.Context, prefix string) iter.Seq2[*ObjectInfo, error] {
+	return func(yield func(*ObjectInfo, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		// отмена останавливает горутину листинга, если перебор прервали раньше
+		defer cancel()
+		for object := range m.client.ListObjects(ctx, m.bucket, minio.ListObjectsOptions{
+			Prefix:    prefix,
+			Recursive: true,
+		}) {
+			if object.Err != nil {
+				yield(nil, object.Err)
+				return
+			}
+			if !yield(&ObjectInfo{Name: object.Key, Size: object.Size, LastModified: object.LastModified}, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (m *Minio) PresignPut(ctx context.Context, name string, size int64, expires time.Duration) (*url.URL, error) {
+	// Content-Length входит в подпись, поэтому загрузить по ссылке файл другого размера не получится
+	headers := http.Header{}
+	headers.Set("Content-Length", strconv.FormatInt(size, 10))
+	return m.presignClient.PresignHeader(ctx, http.MethodPut, m.bucket, name, expires, nil, headers)
+}
+
+func (m *Minio) PresignGet(ctx context.Context, name string, expires time.Duration) (*url.URL, error) {
+	return m.presignClient.PresignedGetObject(ctx, m.bucket, name, expires, nil)
+}
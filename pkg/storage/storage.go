@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net/url"
+	"time"
+)
+
+// ErrObjectNotFound возвращается, если объекта с таким именем нет
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectInfo метаданные объекта
+type ObjectInfo struct {
+	Name         string
+	Size         int64
+	LastModified time.Time
+}
+
+// Storage хранилище объектов. Имена объектов - пути через "/", одинаковые для всех реализаций
+type Storage interface {
+	// Put сохраняет объект размером size байт, перезаписывая существующий
+	Put(ctx context.Context, name string, r io.Reader, size int64, contentType string) error
+	// Get открывает объект для чтения с произвольного смещения. Объект нужно закрыть после чтения
+	Get(ctx context.Context, name string) (io.ReadSeekCloser, error)
+	// Stat возвращает метаданные объекта
+	Stat(ctx context.Context, name string) (*ObjectInfo, error)
+	// Remove удаляет объект и возвращает освобождённый объём в байтах
+	Remove(ctx context.Context, name string) (int64, error)
+	// List перечисляет объекты, имена которых начинаются с prefix
+	List(ctx context.Context, prefix string) iter.Seq2[*ObjectInfo, error]
+}
+
+// Presigner реализуется хранилищами, которые умеют выдавать временные ссылки для прямой загрузки и скачивания
+type Presigner interface {
+	// PresignPut возвращает ссылку, по которой объект размером ровно size байт можно загрузить PUT-запросом
+	PresignPut(ctx context.Context, name string, size int64, expires time.Duration) (*url.URL, error)
+	// PresignGet возвращает ссылку на скачивание объекта
+	PresignGet(ctx context.Context, name string, expires time.Duration) (*url.URL, error)
+}
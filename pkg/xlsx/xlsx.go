@@ -0,0 +1,137 @@
+// Package xlsx пишет книгу Excel с одним листом построчно, не держа строки в памяти.
+// Ячейки записываются как строки (inline strings) или числа, без стилей и общих строк
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	contentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	rootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	workbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	workbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	sheetStart = xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	sheetEnd   = `</sheetData></worksheet>`
+)
+
+// maxCellLength ограничение Excel на длину текста в ячейке
+const maxCellLength = 32767
+
+// Writer пишет лист построчно. После последней строки обязательно вызвать Close
+type Writer struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	rows  int
+}
+
+// NewWriter начинает книгу с листом sheetName
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	zw := zip.NewWriter(w)
+	var name bytes.Buffer
+	if err := xml.EscapeText(&name, []byte(sheetName)); err != nil {
+		return nil, err
+	}
+	parts := []struct {
+		path    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", fmt.Sprintf(workbook, name.String())},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	// лист пишется последним, поэтому строки можно дописывать в открытую запись архива
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(sheet, sheetStart); err != nil {
+		return nil, err
+	}
+	return &Writer{zw: zw, sheet: sheet}, nil
+}
+
+// WriteRow дописывает строку. Целые числа записываются числами, остальное — текстом
+func (w *Writer) WriteRow(cells ...any) error {
+	w.rows++
+	if _, err := fmt.Fprintf(w.sheet, `<row r="%d">`, w.rows); err != nil {
+		return err
+	}
+	for _, cell := range cells {
+		var err error
+		switch v := cell.(type) {
+		case int:
+			_, err = fmt.Fprintf(w.sheet, `<c><v>%d</v></c>`, v)
+		case int64:
+			_, err = fmt.Fprintf(w.sheet, `<c><v>%d</v></c>`, v)
+		case bool:
+			_, err = fmt.Fprintf(w.sheet, `<c t="b"><v>%s</v></c>`, boolValue(v))
+		case string:
+			err = w.writeText(v)
+		default:
+			err = w.writeText(fmt.Sprint(v))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w.sheet, `</row>`)
+	return err
+}
+
+func (w *Writer) writeText(text string) error {
+	if utf8.RuneCountInString(text) > maxCellLength {
+		text = string([]rune(text)[:maxCellLength])
+	}
+	if _, err := io.WriteString(w.sheet, `<c t="inlineStr"><is><t xml:space="preserve">`); err != nil {
+		return err
+	}
+	// EscapeText заменяет недопустимые в XML символы на U+FFFD
+	if err := xml.EscapeText(w.sheet, []byte(text)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w.sheet, `</t></is></c>`)
+	return err
+}
+
+// Close закрывает лист и архив. Нижележащий io.Writer не закрывается
+func (w *Writer) Close() error {
+	if _, err := io.WriteString(w.sheet, sheetEnd); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+func boolValue(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}